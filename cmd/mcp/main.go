@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"subtrackr/internal/config"
 	"subtrackr/internal/database"
 	"subtrackr/internal/models"
@@ -31,8 +32,15 @@ func main() {
 
 	subscriptionRepo := repository.NewSubscriptionRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
+	exchangeRateRepo := repository.NewExchangeRateRepository(db)
+	settingsRepo := repository.NewSettingsRepository(db)
 	categoryService := service.NewCategoryService(categoryRepo)
+	currencyService := service.NewCurrencyService(exchangeRateRepo)
 	subscriptionService := service.NewSubscriptionService(subscriptionRepo, categoryService)
+	settingsService := service.NewSettingsService(settingsRepo)
+	subscriptionService.SetSettingsService(settingsService)
+	models.SetLocation(settingsService.GetLocation())
+	models.SetCostBasis(settingsService.GetCostBasis())
 
 	server := mcp.NewServer(
 		&mcp.Implementation{Name: "subtrackr", Version: version.GetVersion()},
@@ -56,6 +64,32 @@ func main() {
 		return nil, ListOutput{Subscriptions: subs, Count: len(subs)}, nil
 	})
 
+	// search_subscriptions
+	type SearchInput struct {
+		NameContains string   `json:"name_contains" jsonschema:"case-insensitive text; every word must appear, as part of a word, in the name, notes, account or payment method"`
+		Status       string   `json:"status" jsonschema:"exact status: Active, Cancelled, Paused, Trial, or Archived"`
+		CategoryID   uint     `json:"category_id" jsonschema:"category ID"`
+		MinCost      *float64 `json:"min_cost" jsonschema:"minimum cost, inclusive"`
+		MaxCost      *float64 `json:"max_cost" jsonschema:"maximum cost, inclusive"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "search_subscriptions",
+		Description: "Search subscriptions by text, status, category, and cost range. All filters are optional and combined with AND",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input SearchInput) (*mcp.CallToolResult, ListOutput, error) {
+		filter := models.SubscriptionFilter{
+			Query:      input.NameContains,
+			Status:     input.Status,
+			CategoryID: input.CategoryID,
+			MinCost:    input.MinCost,
+			MaxCost:    input.MaxCost,
+		}
+		subs, err := subscriptionService.Search(filter, "", "")
+		if err != nil {
+			return nil, ListOutput{}, fmt.Errorf("failed to search subscriptions: %w", err)
+		}
+		return nil, ListOutput{Subscriptions: subs, Count: len(subs)}, nil
+	})
+
 	// get_subscription
 	type GetInput struct {
 		ID uint `json:"id" jsonschema:"required,the subscription ID to retrieve"`
@@ -76,7 +110,7 @@ func main() {
 		Name             string `json:"name" jsonschema:"required,the subscription name"`
 		Cost             float64 `json:"cost" jsonschema:"required,the subscription cost"`
 		Schedule         string `json:"schedule" jsonschema:"required,billing schedule: Monthly, Annual, Weekly, Daily, or Quarterly"`
-		Status           string `json:"status" jsonschema:"subscription status: Active, Cancelled, Paused, or Trial"`
+		Status           string `json:"status" jsonschema:"subscription status: Active, Cancelled, Paused, Trial, or Archived"`
 		OriginalCurrency string `json:"original_currency" jsonschema:"currency code e.g. USD, EUR"`
 		PaymentMethod    string `json:"payment_method" jsonschema:"payment method"`
 		Account          string `json:"account" jsonschema:"account identifier"`
@@ -131,7 +165,7 @@ func main() {
 		Name             string  `json:"name" jsonschema:"new name"`
 		Cost             float64 `json:"cost" jsonschema:"new cost"`
 		Schedule         string  `json:"schedule" jsonschema:"new schedule: Monthly, Annual, Weekly, Daily, or Quarterly"`
-		Status           string  `json:"status" jsonschema:"new status: Active, Cancelled, Paused, or Trial"`
+		Status           string  `json:"status" jsonschema:"new status: Active, Cancelled, Paused, Trial, or Archived"`
 		OriginalCurrency string  `json:"original_currency" jsonschema:"new currency code"`
 		PaymentMethod    string  `json:"payment_method" jsonschema:"new payment method"`
 		Account          string  `json:"account" jsonschema:"new account"`
@@ -220,6 +254,72 @@ func main() {
 		return nil, DeleteOutput{Message: "Subscription " + strconv.Itoa(int(input.ID)) + " deleted"}, nil
 	})
 
+	// list_categories
+	type ListCategoriesInput struct{}
+	type CategorySummary struct {
+		ID   uint   `json:"id"`
+		Name string `json:"name"`
+	}
+	type ListCategoriesOutput struct {
+		Categories []CategorySummary `json:"categories"`
+		Count      int               `json:"count"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_categories",
+		Description: "List all categories with their IDs, for use as category_id when creating or updating subscriptions",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input ListCategoriesInput) (*mcp.CallToolResult, ListCategoriesOutput, error) {
+		categories, err := categoryService.GetAll()
+		if err != nil {
+			return nil, ListCategoriesOutput{}, fmt.Errorf("failed to list categories: %w", err)
+		}
+		summaries := make([]CategorySummary, 0, len(categories))
+		for _, c := range categories {
+			summaries = append(summaries, CategorySummary{ID: c.ID, Name: c.Name})
+		}
+		return nil, ListCategoriesOutput{Categories: summaries, Count: len(summaries)}, nil
+	})
+
+	// get_category
+	type GetCategoryInput struct {
+		ID uint `json:"id" jsonschema:"required,the category ID to retrieve"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_category",
+		Description: "Get a category by ID",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input GetCategoryInput) (*mcp.CallToolResult, *models.Category, error) {
+		category, err := categoryService.GetByID(input.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("category not found: %w", err)
+		}
+		return nil, category, nil
+	})
+
+	// recategorize_subscriptions
+	type RecategorizeInput struct {
+		IDs        []uint `json:"ids" jsonschema:"required,the subscription IDs to move"`
+		CategoryID uint   `json:"category_id" jsonschema:"required,the target category ID, see list_categories"`
+	}
+	type RecategorizeOutput struct {
+		Updated    int64 `json:"updated"`
+		CategoryID uint  `json:"category_id"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "recategorize_subscriptions",
+		Description: "Move several subscriptions to one category in a single transaction",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input RecategorizeInput) (*mcp.CallToolResult, RecategorizeOutput, error) {
+		if len(input.IDs) == 0 {
+			return nil, RecategorizeOutput{}, fmt.Errorf("no subscription IDs provided")
+		}
+		if _, err := categoryService.GetByID(input.CategoryID); err != nil {
+			return nil, RecategorizeOutput{}, fmt.Errorf("category not found: %w", err)
+		}
+		updated, err := subscriptionService.Recategorize(input.IDs, input.CategoryID)
+		if err != nil {
+			return nil, RecategorizeOutput{}, fmt.Errorf("failed to recategorize subscriptions: %w", err)
+		}
+		return nil, RecategorizeOutput{Updated: updated, CategoryID: input.CategoryID}, nil
+	})
+
 	// get_stats
 	type StatsInput struct{}
 	mcp.AddTool(server, &mcp.Tool{
@@ -233,6 +333,94 @@ func main() {
 		return nil, stats, nil
 	})
 
+	// upcoming_renewals
+	type UpcomingInput struct {
+		Days int `json:"days" jsonschema:"how many days ahead to look, defaults to 7"`
+	}
+	type UpcomingRenewal struct {
+		ID               uint    `json:"id"`
+		Name             string  `json:"name"`
+		Cost             float64 `json:"cost"`
+		OriginalCurrency string  `json:"original_currency"`
+		Schedule         string  `json:"schedule"`
+		RenewalDate      string  `json:"renewal_date"`
+		DaysUntil        int     `json:"days_until"`
+	}
+	type UpcomingOutput struct {
+		Days     int               `json:"days"`
+		Renewals []UpcomingRenewal `json:"renewals"`
+		Count    int               `json:"count"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "upcoming_renewals",
+		Description: "List active subscriptions that renew within the next N days, soonest first",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input UpcomingInput) (*mcp.CallToolResult, UpcomingOutput, error) {
+		days := input.Days
+		if days <= 0 {
+			days = service.DefaultUpcomingRenewalDays
+		}
+		subs, err := subscriptionService.GetUpcomingRenewals(days)
+		if err != nil {
+			return nil, UpcomingOutput{}, fmt.Errorf("failed to get upcoming renewals: %w", err)
+		}
+		renewals := make([]UpcomingRenewal, 0, len(subs))
+		for _, sub := range subs {
+			if sub.RenewalDate == nil {
+				continue
+			}
+			renewals = append(renewals, UpcomingRenewal{
+				ID:               sub.ID,
+				Name:             sub.Name,
+				Cost:             sub.Cost,
+				OriginalCurrency: sub.OriginalCurrency,
+				Schedule:         sub.DisplaySchedule(),
+				RenewalDate:      sub.RenewalDate.Format("2006-01-02"),
+				DaysUntil:        subscriptionService.DaysUntil(*sub.RenewalDate),
+			})
+		}
+		return nil, UpcomingOutput{Days: days, Renewals: renewals, Count: len(renewals)}, nil
+	})
+
+	// convert_currency
+	type ConvertInput struct {
+		Amount float64 `json:"amount" jsonschema:"required,the amount to convert"`
+		From   string  `json:"from" jsonschema:"required,source currency code e.g. EUR"`
+		To     string  `json:"to" jsonschema:"required,target currency code e.g. USD"`
+	}
+	type ConvertOutput struct {
+		Amount          float64 `json:"amount"`
+		From            string  `json:"from"`
+		To              string  `json:"to"`
+		Rate            float64 `json:"rate"`
+		ConvertedAmount float64 `json:"converted_amount"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "convert_currency",
+		Description: "Convert an amount between currencies using the latest cached exchange rates",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input ConvertInput) (*mcp.CallToolResult, ConvertOutput, error) {
+		if !currencyService.IsEnabled() {
+			return nil, ConvertOutput{}, fmt.Errorf("currency conversion is not enabled: set FIXER_API_KEY to use it")
+		}
+		from := strings.ToUpper(strings.TrimSpace(input.From))
+		to := strings.ToUpper(strings.TrimSpace(input.To))
+
+		rate, err := currencyService.GetExchangeRate(from, to)
+		if err != nil {
+			return nil, ConvertOutput{}, fmt.Errorf("failed to get exchange rate: %w", err)
+		}
+		converted, err := currencyService.ConvertAmount(input.Amount, from, to)
+		if err != nil {
+			return nil, ConvertOutput{}, fmt.Errorf("failed to convert amount: %w", err)
+		}
+		return nil, ConvertOutput{
+			Amount:          input.Amount,
+			From:            from,
+			To:              to,
+			Rate:            rate,
+			ConvertedAmount: converted,
+		}, nil
+	})
+
 	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
 		log.Fatal(err)
 	}
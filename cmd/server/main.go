@@ -1,25 +1,32 @@
 package main
 
 import (
+	"context"
 	"crypto/subtle"
+	"errors"
 	"flag"
 	"fmt"
 	"html/template"
 	"log"
+	"log/slog"
 	"math"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
 	"subtrackr/internal/config"
 	"subtrackr/internal/database"
 	"subtrackr/internal/handlers"
+	"subtrackr/internal/metrics"
 	"subtrackr/internal/middleware"
+	"subtrackr/internal/models"
 	"subtrackr/internal/repository"
 	"subtrackr/internal/service"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"golang.org/x/term"
 )
 
@@ -32,6 +39,7 @@ func main() {
 
 	// Load configuration
 	cfg := config.Load()
+	slog.SetDefault(cfg.Logger(os.Stderr))
 
 	// Initialize database
 	db, err := database.Initialize(cfg.DatabasePath)
@@ -39,11 +47,14 @@ func main() {
 		log.Fatal("Failed to initialize database:", err)
 	}
 
+	healthHandler := handlers.NewHealthHandler(db)
+
 	// Run database migrations
 	err = database.RunMigrations(db)
 	if err != nil {
 		log.Fatal("Failed to run migrations:", err)
 	}
+	healthHandler.MarkMigrated()
 
 	// Initialize repositories
 	subscriptionRepo := repository.NewSubscriptionRepository(db)
@@ -54,12 +65,23 @@ func main() {
 	// Initialize services
 	categoryService := service.NewCategoryService(categoryRepo)
 	currencyService := service.NewCurrencyService(exchangeRateRepo)
+	healthHandler.SetCurrencyService(currencyService)
 	subscriptionService := service.NewSubscriptionService(subscriptionRepo, categoryService)
 	settingsService := service.NewSettingsService(settingsRepo)
+	settingsService.SetPasswordHashCost(cfg.BcryptCost)
+	subscriptionService.SetSettingsService(settingsService)
+	models.SetLocation(settingsService.GetLocation())
+	models.SetCostBasis(settingsService.GetCostBasis())
 	emailService := service.NewEmailService(settingsService)
-	pushoverService := service.NewPushoverService(settingsService)
-	webhookService := service.NewWebhookService(settingsService)
+	notificationService := service.NewNotificationService(settingsService)
+	notificationService.SetHistory(repository.NewNotificationLogRepository(db))
+	notificationService.SetCurrencyService(currencyService)
 	logoService := service.NewLogoService()
+	if cfg.LogoProviders != "" {
+		if err := logoService.SetProviders(strings.Split(cfg.LogoProviders, ",")); err != nil {
+			slog.Warn("Ignoring LOGO_PROVIDERS", "error", err)
+		}
+	}
 
 	// Handle CLI commands (run before starting HTTP server)
 	if *disableAuth {
@@ -72,25 +94,37 @@ func main() {
 		return
 	}
 
+	if cfg.DisableAuth {
+		settingsService.SetAuthDisabledByConfig(true)
+		slog.Warn("Built-in authentication is DISABLED (DISABLE_AUTH=true). Anyone who can reach this server can view and change data; only use this behind a trusted authenticating reverse proxy.")
+	}
+
 	// Initialize session service (get or generate session secret)
 	sessionSecret, err := settingsService.GetOrGenerateSessionSecret()
 	if err != nil {
 		log.Fatal("Failed to initialize session secret:", err)
 	}
-	sessionService := service.NewSessionService(sessionSecret)
+	sessionService := service.NewSessionService(sessionSecret, repository.NewSessionRepository(db), settingsService)
 
 	// Initialize handlers
-	subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionService, settingsService, currencyService, emailService, pushoverService, webhookService, logoService, categoryService)
+	subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionService, settingsService, currencyService, notificationService, logoService, categoryService)
+	subscriptionHandler.SetIdempotencyKeys(service.NewIdempotencyKeys(time.Duration(cfg.IdempotencyKeyTTLHours) * time.Hour))
 	settingsHandler := handlers.NewSettingsHandler(settingsService)
+	settingsHandler.SetNotificationService(notificationService)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
-	authHandler := handlers.NewAuthHandler(settingsService, sessionService, emailService)
+	loginLimiter := service.NewLoginLimiter(cfg.LoginMaxAttempts, time.Duration(cfg.LoginLockoutMinutes)*time.Minute)
+	authHandler := handlers.NewAuthHandler(settingsService, sessionService, emailService, loginLimiter)
+	dateMigrationHandler := handlers.NewDateMigrationHandler(models.NewDateMigrationSafetyCheck(db))
+	backupService := service.NewBackupService(db, cfg.DatabasePath, cfg.BackupDir, cfg.BackupKeep)
+	backupHandler := handlers.NewBackupHandler(backupService)
 
 	// Setup Gin router
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.Logger(), middleware.Recovery())
 
 	// Create template functions
 	router.SetFuncMap(template.FuncMap{
@@ -121,19 +155,22 @@ func main() {
 			if t == nil {
 				return ""
 			}
-			return t.Format(format)
+			return t.In(models.Location()).Format(format)
 		},
 		"fmtTime": func(t time.Time, format string) string {
 			return t.Format(format)
 		},
+		"money":    settingsService.FormatMoney,
+		"moneyIn":  settingsService.FormatMoneyIn,
+		"markdown": service.RenderMarkdown,
 	})
 
 	// Load HTML templates with error handling
-	tmpl := loadTemplates()
+	tmpl := loadTemplates(settingsService)
 	if tmpl != nil && len(tmpl.Templates()) > 0 {
 		router.SetHTMLTemplate(tmpl)
 	} else {
-		log.Printf("Warning: Template loading failed, using fallback")
+		slog.Warn("Template loading failed, using fallback")
 		// Fallback to LoadHTMLGlob for compatibility
 		router.LoadHTMLGlob("templates/*")
 	}
@@ -143,37 +180,23 @@ func main() {
 	router.StaticFile("/favicon.ico", "./web/static/favicon.ico")
 	router.StaticFile("/manifest.json", "./web/static/manifest.json")
 
-	// Health check endpoint with database connectivity check
-	router.GET("/healthz", func(c *gin.Context) {
-		// Check database connectivity
-		sqlDB, err := db.DB()
-		if err != nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"status": "unhealthy",
-				"error":  "database connection unavailable",
-			})
-			return
-		}
-
-		// Ping the database to verify connectivity
-		if err := sqlDB.Ping(); err != nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"status": "unhealthy",
-				"error":  "database ping failed",
-			})
-			return
-		}
+	// Health checks: component status for diagnosis, readiness for orchestrators
+	router.GET("/healthz", healthHandler.Healthz)
+	router.GET("/readyz", healthHandler.Readyz)
 
-		c.JSON(http.StatusOK, gin.H{
-			"status": "healthy",
-		})
-	})
+	// Prometheus metrics, open for a scraper unless METRICS_REQUIRE_API_KEY is set
+	metricsHandler := gin.WrapH(promhttp.HandlerFor(metrics.NewRegistry(subscriptionService), promhttp.HandlerOpts{}))
+	if cfg.MetricsRequireAPIKey {
+		router.GET("/metrics", middleware.APIKeyAuth(settingsService), metricsHandler)
+	} else {
+		router.GET("/metrics", metricsHandler)
+	}
 
 	// Apply auth middleware
 	router.Use(middleware.AuthMiddleware(settingsService, sessionService))
 
 	// Routes
-	setupRoutes(router, subscriptionHandler, settingsHandler, settingsService, categoryHandler, authHandler)
+	setupRoutes(router, cfg, subscriptionHandler, settingsHandler, settingsService, categoryHandler, authHandler, dateMigrationHandler, backupHandler)
 
 	// Seed sample data if database is empty
 	// Commented out - no sample data by default
@@ -181,11 +204,20 @@ func main() {
 	// 	seedSampleData(subscriptionService)
 	// }
 
+	// Cancelled on SIGINT/SIGTERM so the schedulers and server can shut down cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Start renewal reminder scheduler
-	go startRenewalReminderScheduler(subscriptionService, emailService, pushoverService, webhookService, settingsService)
+	go startRenewalReminderScheduler(ctx, healthHandler, subscriptionService, notificationService, settingsService)
 
 	// Start cancellation reminder scheduler
-	go startCancellationReminderScheduler(subscriptionService, emailService, pushoverService, webhookService, settingsService)
+	go startCancellationReminderScheduler(ctx, subscriptionService, notificationService, settingsService)
+
+	// Start database backup scheduler
+	if backupService.Enabled() && cfg.BackupIntervalHours > 0 {
+		go startBackupScheduler(ctx, backupService, time.Duration(cfg.BackupIntervalHours)*time.Hour)
+	}
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -193,12 +225,29 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("SubTrackr server starting on port %s", port)
-	log.Fatal(router.Run(":" + port))
+	srv := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		slog.Info("SubTrackr server starting", "port", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server error: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	slog.Info("Shutting down server")
+
+	// Give in-flight requests a moment to finish
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Server shutdown failed", "error", err)
+	}
+	slog.Info("Server stopped")
 }
 
 // loadTemplates loads HTML templates with better error handling for arm64 compatibility
-func loadTemplates() *template.Template {
+func loadTemplates(settingsService *service.SettingsService) *template.Template {
 	tmpl := template.New("")
 
 	// Add template functions
@@ -208,7 +257,7 @@ func loadTemplates() *template.Template {
 		"mul": func(a, b float64) float64 { return a * b },
 		"div": func(a, b float64) float64 {
 			if b == 0 {
-				log.Printf("Warning: Division by zero attempted in template")
+				slog.Warn("Division by zero attempted in template")
 				return math.NaN()
 			}
 			return a / b
@@ -231,11 +280,14 @@ func loadTemplates() *template.Template {
 			if t == nil {
 				return ""
 			}
-			return t.Format(format)
+			return t.In(models.Location()).Format(format)
 		},
 		"fmtTime": func(t time.Time, format string) string {
 			return t.Format(format)
 		},
+		"money":    settingsService.FormatMoney,
+		"moneyIn":  settingsService.FormatMoneyIn,
+		"markdown": service.RenderMarkdown,
 	})
 
 	// Critical templates required for basic functionality
@@ -268,6 +320,8 @@ func loadTemplates() *template.Template {
 		"templates/reset-password-error.html",
 		"templates/reset-password-success.html",
 		"templates/auth-message.html",
+		"templates/api-docs.html",
+		"templates/sessions-list.html",
 	}
 
 	var parsedCount int
@@ -277,7 +331,7 @@ func loadTemplates() *template.Template {
 	// Load templates individually to catch arm64-specific issues
 	for _, file := range templateFiles {
 		if _, err := os.Stat(file); err != nil {
-			log.Printf("Warning: Template file not found: %s", file)
+			slog.Warn("Template file not found", "file", file)
 			// Check if this is a critical template
 			for _, critical := range criticalTemplates {
 				if critical == file {
@@ -288,7 +342,7 @@ func loadTemplates() *template.Template {
 		}
 
 		if _, err := tmpl.ParseFiles(file); err != nil {
-			log.Printf("Error: Failed to parse template %s: %v", file, err)
+			slog.Error("Failed to parse template", "file", file, "error", err)
 			failedCount++
 			// Check if this is a critical template
 			for _, critical := range criticalTemplates {
@@ -302,7 +356,7 @@ func loadTemplates() *template.Template {
 	}
 
 	// Log template loading summary
-	log.Printf("Template loading summary: %d parsed, %d failed, %d total", parsedCount, failedCount, len(templateFiles))
+	slog.Info("Templates loaded", "parsed", parsedCount, "failed", failedCount, "total", len(templateFiles))
 
 	// Fatal error if critical templates are missing
 	if len(missingCritical) > 0 {
@@ -311,13 +365,13 @@ func loadTemplates() *template.Template {
 
 	// Warn if too many templates failed
 	if failedCount > len(templateFiles)/2 {
-		log.Printf("Warning: More than half of templates failed to load (%d/%d). Application may not function correctly.", failedCount, len(templateFiles))
+		slog.Warn("More than half of templates failed to load. Application may not function correctly.", "failed", failedCount, "total", len(templateFiles))
 	}
 
 	return tmpl
 }
 
-func setupRoutes(router *gin.Engine, handler *handlers.SubscriptionHandler, settingsHandler *handlers.SettingsHandler, settingsService *service.SettingsService, categoryHandler *handlers.CategoryHandler, authHandler *handlers.AuthHandler) {
+func setupRoutes(router *gin.Engine, cfg *config.Config, handler *handlers.SubscriptionHandler, settingsHandler *handlers.SettingsHandler, settingsService *service.SettingsService, categoryHandler *handlers.CategoryHandler, authHandler *handlers.AuthHandler, dateMigrationHandler *handlers.DateMigrationHandler, backupHandler *handlers.BackupHandler) {
 	// Auth routes (public)
 	router.GET("/login", authHandler.ShowLoginPage)
 	router.GET("/forgot-password", authHandler.ShowForgotPasswordPage)
@@ -333,6 +387,7 @@ func setupRoutes(router *gin.Engine, handler *handlers.SubscriptionHandler, sett
 	router.GET("/analytics", handler.Analytics)
 	router.GET("/calendar", handler.Calendar)
 	router.GET("/settings", handler.Settings)
+	router.GET("/logo", handler.ProxyLogo)
 
 	// Form routes for HTMX modals
 	form := router.Group("/form")
@@ -345,17 +400,37 @@ func setupRoutes(router *gin.Engine, handler *handlers.SubscriptionHandler, sett
 	api := router.Group("/api")
 	{
 		api.GET("/subscriptions", handler.GetSubscriptions)
+		api.GET("/subscriptions/search", handler.SearchSubscriptions)
+		api.GET("/subscriptions/preview-renewal", handler.PreviewRenewal)
+		api.POST("/subscriptions/recategorize", handler.RecategorizeSubscriptions)
+		api.POST("/subscriptions/recalculate-renewals", handler.RecalculateRenewals)
 		api.POST("/subscriptions", handler.CreateSubscription)
 		api.GET("/subscriptions/:id", handler.GetSubscription)
 		api.PUT("/subscriptions/:id", handler.UpdateSubscription)
 		api.DELETE("/subscriptions/:id", handler.DeleteSubscription)
+		api.POST("/subscriptions/:id/duplicate", handler.DuplicateSubscription)
+		api.POST("/subscriptions/:id/logo", handler.UploadLogo)
+		api.DELETE("/subscriptions/:id/logo", handler.RemoveLogo)
+		api.GET("/subscriptions/:id/notes/html", handler.GetNotesHTML)
 		api.GET("/stats", handler.GetStats)
+		api.GET("/stats/trend", handler.GetSpendingTrend)
+		api.GET("/stats/category-trend", handler.GetCategoryTrend)
+		api.GET("/stats/savings-timeline", handler.GetSavingsTimeline)
+		api.GET("/stats/forecast", handler.GetChargeForecast)
+		api.GET("/stats/renewal-calendar", handler.GetRenewalCalendar)
+		api.GET("/stats/categories", handler.GetCategoryStats)
+		api.GET("/stats/payment-methods", handler.GetPaymentMethodStats)
+		api.GET("/stats/by-account", handler.GetAccountStats)
+		api.GET("/stats/waste", handler.GetWasteReport)
+		api.GET("/stats/my-share", handler.GetMyShare)
 
 		// Export and data management routes
 		api.GET("/export/csv", handler.ExportCSV)
 		api.GET("/export/json", handler.ExportJSON)
 		api.GET("/export/ical", handler.ExportICal)
 		api.GET("/backup", handler.BackupData)
+		api.POST("/import/:format", handler.ImportSubscriptions)
+		api.POST("/backup/db", backupHandler.CreateBackup)
 		api.POST("/restore", handler.RestoreData)
 		api.DELETE("/clear-all", handler.ClearAllData)
 
@@ -367,8 +442,11 @@ func setupRoutes(router *gin.Engine, handler *handlers.SubscriptionHandler, sett
 		api.GET("/settings/pushover", settingsHandler.GetPushoverConfig)
 		api.POST("/settings/webhook", settingsHandler.SaveWebhookSettings)
 		api.POST("/settings/webhook/test", settingsHandler.TestWebhookConnection)
+		api.POST("/settings/apprise", settingsHandler.SaveAppriseSettings)
+		api.POST("/settings/apprise/test", settingsHandler.TestAppriseConnection)
 		api.POST("/settings/notifications/:setting", settingsHandler.UpdateNotificationSetting)
 		api.GET("/settings/notifications", settingsHandler.GetNotificationSettings)
+		api.GET("/settings/notifications/history", settingsHandler.GetNotificationHistory)
 		api.GET("/settings/smtp", settingsHandler.GetSMTPConfig)
 
 		// API Key management routes
@@ -378,9 +456,14 @@ func setupRoutes(router *gin.Engine, handler *handlers.SubscriptionHandler, sett
 
 		// Currency setting
 		api.POST("/settings/currency", settingsHandler.UpdateCurrency)
+		api.GET("/currency/rate", handler.GetExchangeRate)
 
 		// Date format setting
 		api.POST("/settings/date-format", settingsHandler.UpdateDateFormat)
+		api.POST("/settings/number-format", settingsHandler.UpdateNumberFormat)
+		api.POST("/settings/week-start", settingsHandler.UpdateWeekStart)
+		api.POST("/settings/cost-basis", settingsHandler.UpdateCostBasis)
+		api.POST("/settings/timezone", settingsHandler.UpdateTimezone)
 
 		// Dark mode setting
 		api.POST("/settings/dark-mode", settingsHandler.ToggleDarkMode)
@@ -388,8 +471,10 @@ func setupRoutes(router *gin.Engine, handler *handlers.SubscriptionHandler, sett
 		// Category management routes
 		api.GET("/categories", categoryHandler.ListCategories)
 		api.POST("/categories", categoryHandler.CreateCategory)
+		api.PUT("/categories/reorder", categoryHandler.ReorderCategories)
 		api.PUT("/categories/:id", categoryHandler.UpdateCategory)
 		api.DELETE("/categories/:id", categoryHandler.DeleteCategory)
+		api.POST("/categories/:id/merge", categoryHandler.MergeCategory)
 
 		// Auth routes
 		api.POST("/auth/login", authHandler.Login)
@@ -401,6 +486,17 @@ func setupRoutes(router *gin.Engine, handler *handlers.SubscriptionHandler, sett
 		api.POST("/settings/auth/setup", settingsHandler.SetupAuth)
 		api.POST("/settings/auth/disable", settingsHandler.DisableAuth)
 		api.GET("/settings/auth/status", settingsHandler.GetAuthStatus)
+		api.POST("/settings/auth/remember-me", settingsHandler.UpdateRememberMeDays)
+		api.POST("/settings/date-calculation-version", settingsHandler.UpdateDateCalculationVersion)
+		api.GET("/settings/date-migration/stats", dateMigrationHandler.GetStats)
+		api.GET("/settings/date-migration/compare", dateMigrationHandler.Compare)
+		api.POST("/settings/date-migration/migrate", dateMigrationHandler.Migrate)
+		api.POST("/settings/password", settingsHandler.ChangePassword)
+
+		// Login session management
+		api.GET("/sessions", authHandler.ListSessions)
+		api.DELETE("/sessions", authHandler.RevokeAllSessions)
+		api.DELETE("/sessions/:id", authHandler.RevokeSession)
 
 		// Theme settings routes
 		api.GET("/settings/theme", settingsHandler.GetTheme)
@@ -413,236 +509,332 @@ func setupRoutes(router *gin.Engine, handler *handlers.SubscriptionHandler, sett
 
 		// Base URL setting
 		api.POST("/settings/base-url", settingsHandler.UpdateBaseURL)
+
+		// Logo caching
+		api.POST("/settings/logo-cache/toggle", settingsHandler.ToggleLogoCache)
+		api.POST("/settings/trial-spend/toggle", settingsHandler.ToggleTrialSpend)
 	}
 
+	// API documentation (public, read-only)
+	router.StaticFile("/api/v1/openapi.json", "./web/static/openapi.json")
+	router.GET("/api/v1/docs", func(c *gin.Context) {
+		c.HTML(http.StatusOK, "api-docs.html", nil)
+	})
+
 	// Public API routes (require API key authentication)
 	v1 := router.Group("/api/v1")
 	v1.Use(middleware.APIKeyAuth(settingsService))
+	v1.Use(middleware.NewRateLimiter(cfg.APIRateLimit).Middleware())
 	{
 		// Subscription endpoints
 		v1.GET("/subscriptions", handler.GetSubscriptionsAPI)
+		v1.GET("/subscriptions/search", handler.SearchSubscriptions)
+		v1.POST("/subscriptions/bulk", handler.BulkSubscriptions)
+		v1.POST("/subscriptions/recategorize", handler.RecategorizeSubscriptions)
+		v1.POST("/subscriptions/recalculate-renewals", handler.RecalculateRenewals)
 		v1.POST("/subscriptions", handler.CreateSubscription)
 		v1.GET("/subscriptions/:id", handler.GetSubscription)
 		v1.PUT("/subscriptions/:id", handler.UpdateSubscription)
+		v1.PATCH("/subscriptions/:id", handler.PatchSubscription)
 		v1.DELETE("/subscriptions/:id", handler.DeleteSubscription)
 
 		// Stats and export endpoints
 		v1.GET("/stats", handler.GetStats)
+		v1.GET("/summary", handler.GetSummary)
 		v1.GET("/export/csv", handler.ExportCSV)
 		v1.GET("/export/json", handler.ExportJSON)
 	}
 }
 
-// startRenewalReminderScheduler starts a background goroutine that checks for
-// upcoming renewals and sends reminder emails and Pushover notifications daily
-func startRenewalReminderScheduler(subscriptionService *service.SubscriptionService, emailService *service.EmailService, pushoverService *service.PushoverService, webhookService *service.WebhookService, settingsService *service.SettingsService) {
-	// Run immediately on startup (after a short delay to let server initialize)
-	go func() {
-		time.Sleep(30 * time.Second) // Wait 30 seconds for server to fully start
-		checkAndSendRenewalReminders(subscriptionService, emailService, pushoverService, webhookService, settingsService)
+// runScheduledCheck calls check, logging rather than propagating a panic so the
+// schedule keeps running
+func runScheduledCheck(name string, check func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Panic in scheduled check", "check", name, "panic", r)
+		}
 	}()
+	check()
+}
 
-	// Then run daily at midnight
-	// Note: Ticker is intentionally not stopped as this is a long-running server process.
-	// The ticker will run for the lifetime of the application, which is the desired behavior.
-	ticker := time.NewTicker(24 * time.Hour)
-	go func() {
-		defer ticker.Stop() // Clean up ticker if goroutine exits (defensive programming)
-		for range ticker.C {
-			// Recover from any panics in the reminder check to keep the scheduler running
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						log.Printf("Panic in renewal reminder check: %v", r)
-					}
-				}()
-				checkAndSendRenewalReminders(subscriptionService, emailService, pushoverService, webhookService, settingsService)
-			}()
+// runSchedule calls check once after initialDelay and then every interval, returning
+// when ctx is cancelled. A panic in check is logged and the schedule keeps running.
+func runSchedule(ctx context.Context, name string, initialDelay, interval time.Duration, check func()) {
+	safeCheck := func() { runScheduledCheck(name, check) }
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(initialDelay):
+		safeCheck()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			safeCheck()
 		}
-	}()
+	}
+}
+
+// startBackupScheduler backs up the database every interval, starting shortly
+// after startup. It blocks until ctx is cancelled.
+func startBackupScheduler(ctx context.Context, backupService *service.BackupService, interval time.Duration) {
+	runSchedule(ctx, "database backup", time.Minute, interval, func() {
+		name, err := backupService.Backup(time.Now())
+		if err != nil {
+			slog.Error("Database backup failed", "error", err)
+			return
+		}
+		slog.Info("Database backed up", "file", name)
+	})
+}
+
+// waitForQuietHours blocks until quiet hours end if they're in effect. It
+// returns false if ctx is cancelled first.
+func waitForQuietHours(ctx context.Context, settingsService *service.SettingsService) bool {
+	now := time.Now()
+	end := settingsService.QuietHoursEnd(now)
+	if !end.After(now) {
+		return true
+	}
+
+	slog.Info("Holding reminders until quiet hours end", "until", end)
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(end.Sub(now)):
+		return true
+	}
+}
+
+// sendHeldReminders waits for quiet hours to end and then sends reminders, held
+// back from the push channels, through send
+func sendHeldReminders(ctx context.Context, settingsService *service.SettingsService, kind string, reminders map[*models.Subscription]int, send func(*models.Subscription, int) error) {
+	if len(reminders) == 0 {
+		return
+	}
+	slog.Info("Holding push reminders until quiet hours end", "kind", kind, "subscriptions", len(reminders))
+	if !waitForQuietHours(ctx, settingsService) {
+		return
+	}
+	for sub, daysUntil := range reminders {
+		if err := send(sub, daysUntil); err != nil {
+			slog.Warn("Failed to send held push reminder", "kind", kind, "subscription", sub.Name, "failed", err)
+		}
+	}
 }
 
-// checkAndSendRenewalReminders checks for subscriptions needing reminders and sends emails and Pushover notifications
-func checkAndSendRenewalReminders(subscriptionService *service.SubscriptionService, emailService *service.EmailService, pushoverService *service.PushoverService, webhookService *service.WebhookService, settingsService *service.SettingsService) {
+// runDelayedSchedule calls check each time the delay returned by next elapses,
+// returning when ctx is cancelled. next is asked again after every check, with
+// first set only for the initial one, so a changed setting applies to the
+// following run. A panic in check is logged and the schedule keeps running.
+func runDelayedSchedule(ctx context.Context, name string, next func(first bool) time.Duration, check func()) {
+	for first := true; ; first = false {
+		timer := time.NewTimer(next(first))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			runScheduledCheck(name, check)
+		}
+	}
+}
+
+// reminderDelay returns how long the reminder schedulers wait before their next
+// check: until the reminder send hour when one is set, otherwise shortly after
+// startup (to let the server initialize) and then daily.
+func reminderDelay(settingsService *service.SettingsService) func(first bool) time.Duration {
+	return func(first bool) time.Duration {
+		now := time.Now()
+		if next, ok := settingsService.NextReminderSendTime(now); ok {
+			return next.Sub(now)
+		}
+		if first {
+			return 30 * time.Second
+		}
+		return 24 * time.Hour
+	}
+}
+
+// startRenewalReminderScheduler checks daily for upcoming renewals and sends reminder
+// emails, push notifications and webhooks. Push notifications due during quiet
+// hours are sent once they end. It blocks until ctx is cancelled.
+func startRenewalReminderScheduler(ctx context.Context, healthHandler *handlers.HealthHandler, subscriptionService *service.SubscriptionService, notificationService *service.NotificationService, settingsService *service.SettingsService) {
+	runDelayedSchedule(ctx, "renewal reminder", reminderDelay(settingsService), func() {
+		held := checkAndSendRenewalReminders(healthHandler, subscriptionService, notificationService, settingsService)
+		sendHeldReminders(ctx, settingsService, "renewal", held, notificationService.SendHeldRenewalReminder)
+	})
+}
+
+// checkAndSendRenewalReminders checks for subscriptions needing reminders and
+// notifies every channel. It returns the reminders the push channels held back
+// for quiet hours, with their days until renewal.
+func checkAndSendRenewalReminders(healthHandler *handlers.HealthHandler, subscriptionService *service.SubscriptionService, notificationService *service.NotificationService, settingsService *service.SettingsService) map[*models.Subscription]int {
+	// Record the run for /healthz, even when reminders turn out to be disabled
+	healthHandler.RecordReminderRun(time.Now())
+
 	// Check if renewal reminders are enabled
 	enabled, err := settingsService.GetBoolSetting("renewal_reminders", false)
 	if err != nil || !enabled {
-		return // Silently skip if disabled or error
+		return nil // Silently skip if disabled or error
 	}
 
 	// Get reminder days setting
 	reminderDays := settingsService.GetIntSettingWithDefault("reminder_days", 7)
 	if reminderDays <= 0 {
-		return // No reminders if days is 0 or negative
+		return nil // No reminders if days is 0 or negative
 	}
 
 	// Get subscriptions needing reminders
 	subscriptions, err := subscriptionService.GetSubscriptionsNeedingReminders(reminderDays)
 	if err != nil {
-		log.Printf("Error getting subscriptions for renewal reminders: %v", err)
-		return
+		slog.Error("Failed to get subscriptions for renewal reminders", "error", err)
+		return nil
 	}
 
 	if len(subscriptions) == 0 {
-		log.Printf("No subscriptions need renewal reminders today")
-		return
+		slog.Info("No subscriptions need renewal reminders today")
+		return nil
 	}
 
-	log.Printf("Checking %d subscription(s) for renewal reminders", len(subscriptions))
+	slog.Info("Sending renewal reminders", "subscriptions", len(subscriptions))
 
-	// Send reminder for each subscription (both email and Pushover)
+	// Send reminder for each subscription through every notification channel
 	sentCount := 0
 	failedCount := 0
+	held := make(map[*models.Subscription]int)
 	for sub, daysUntil := range subscriptions {
-		emailErr := emailService.SendRenewalReminder(sub, daysUntil)
-		pushoverErr := pushoverService.SendRenewalReminder(sub, daysUntil)
-		webhookErr := webhookService.SendRenewalReminder(sub, daysUntil)
-
-		// If all fail, count as failed; otherwise consider it sent
-		if emailErr != nil && pushoverErr != nil && webhookErr != nil {
-			log.Printf("Error sending renewal reminder for subscription %s (ID: %d): email=%v, pushover=%v, webhook=%v", sub.Name, sub.ID, emailErr, pushoverErr, webhookErr)
+		// Push channels skip the reminder during quiet hours; it is sent to them later
+		quiet := settingsService.IsWithinQuietHours(time.Now())
+		err := notificationService.SendRenewalReminder(sub, daysUntil)
+
+		// If every channel failed, count as failed; otherwise consider it sent
+		var notifyErr *service.NotificationError
+		if errors.As(err, &notifyErr) && notifyErr.AllFailed {
+			slog.Error("Failed to send renewal reminder", "subscription", sub.Name, "id", sub.ID, "failed", err)
 			failedCount++
-		} else {
-			// Mark reminder as sent for this renewal date
-			now := time.Now()
-			sub.LastReminderSent = &now
-			if sub.RenewalDate != nil {
-				renewalDateCopy := *sub.RenewalDate
-				sub.LastReminderRenewalDate = &renewalDateCopy
-			}
+			continue
+		}
 
-			// Update the subscription in the database
-			_, updateErr := subscriptionService.Update(sub.ID, sub)
-			if updateErr != nil {
-				log.Printf("Warning: Failed to update last reminder sent for subscription %s (ID: %d): %v", sub.Name, sub.ID, updateErr)
-			}
+		// Mark reminder as sent for this charge date
+		now := time.Now()
+		sub.LastReminderSent = &now
+		if chargeDate := sub.UpcomingChargeDate(now); chargeDate != nil {
+			chargeDateCopy := *chargeDate
+			sub.LastReminderRenewalDate = &chargeDateCopy
+		}
 
-			var failed []string
-			if emailErr != nil {
-				failed = append(failed, fmt.Sprintf("email=%v", emailErr))
-			}
-			if pushoverErr != nil {
-				failed = append(failed, fmt.Sprintf("pushover=%v", pushoverErr))
-			}
-			if webhookErr != nil {
-				failed = append(failed, fmt.Sprintf("webhook=%v", webhookErr))
-			}
-			if len(failed) > 0 {
-				log.Printf("Sent renewal reminder for subscription %s (renews in %d days) - some channels failed: %s", sub.Name, daysUntil, strings.Join(failed, ", "))
-			} else {
-				log.Printf("Sent renewal reminders for subscription %s (renews in %d days)", sub.Name, daysUntil)
-			}
-			sentCount++
+		// Update the subscription in the database
+		_, updateErr := subscriptionService.Update(sub.ID, sub)
+		if updateErr != nil {
+			slog.Warn("Failed to update last reminder sent", "subscription", sub.Name, "id", sub.ID, "error", updateErr)
 		}
+
+		if err != nil {
+			slog.Warn("Sent renewal reminder, some channels failed", "subscription", sub.Name, "days_until", daysUntil, "failed", err)
+		} else {
+			slog.Info("Sent renewal reminder", "subscription", sub.Name, "days_until", daysUntil)
+		}
+		if quiet {
+			held[sub] = daysUntil
+		}
+		sentCount++
 	}
 
-	log.Printf("Renewal reminder check complete: %d sent, %d failed", sentCount, failedCount)
+	slog.Info("Renewal reminder check complete", "sent", sentCount, "failed", failedCount)
+	return held
 }
 
-// startCancellationReminderScheduler starts a background goroutine that checks for
-// upcoming cancellations and sends reminder emails and Pushover notifications daily
-func startCancellationReminderScheduler(subscriptionService *service.SubscriptionService, emailService *service.EmailService, pushoverService *service.PushoverService, webhookService *service.WebhookService, settingsService *service.SettingsService) {
-	// Run immediately on startup (after a short delay to let server initialize)
-	go func() {
-		time.Sleep(30 * time.Second) // Wait 30 seconds for server to fully start
-		checkAndSendCancellationReminders(subscriptionService, emailService, pushoverService, webhookService, settingsService)
-	}()
-
-	// Then run daily at midnight
-	// Note: Ticker is intentionally not stopped as this is a long-running server process.
-	// The ticker will run for the lifetime of the application, which is the desired behavior.
-	ticker := time.NewTicker(24 * time.Hour)
-	go func() {
-		defer ticker.Stop() // Clean up ticker if goroutine exits (defensive programming)
-		for range ticker.C {
-			// Recover from any panics in the reminder check to keep the scheduler running
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						log.Printf("Panic in cancellation reminder check: %v", r)
-					}
-				}()
-				checkAndSendCancellationReminders(subscriptionService, emailService, pushoverService, webhookService, settingsService)
-			}()
-		}
-	}()
+// startCancellationReminderScheduler checks daily for upcoming cancellations and sends
+// reminder emails, push notifications and webhooks. Push notifications due during
+// quiet hours are sent once they end. It blocks until ctx is cancelled.
+func startCancellationReminderScheduler(ctx context.Context, subscriptionService *service.SubscriptionService, notificationService *service.NotificationService, settingsService *service.SettingsService) {
+	runDelayedSchedule(ctx, "cancellation reminder", reminderDelay(settingsService), func() {
+		held := checkAndSendCancellationReminders(subscriptionService, notificationService, settingsService)
+		sendHeldReminders(ctx, settingsService, "cancellation", held, notificationService.SendHeldCancellationReminder)
+	})
 }
 
-// checkAndSendCancellationReminders checks for subscriptions needing cancellation reminders and sends emails and Pushover notifications
-func checkAndSendCancellationReminders(subscriptionService *service.SubscriptionService, emailService *service.EmailService, pushoverService *service.PushoverService, webhookService *service.WebhookService, settingsService *service.SettingsService) {
+// checkAndSendCancellationReminders checks for subscriptions needing cancellation
+// reminders and notifies every channel. It returns the reminders the push channels
+// held back for quiet hours, with their days until cancellation.
+func checkAndSendCancellationReminders(subscriptionService *service.SubscriptionService, notificationService *service.NotificationService, settingsService *service.SettingsService) map[*models.Subscription]int {
 	// Check if cancellation reminders are enabled
 	enabled, err := settingsService.GetBoolSetting("cancellation_reminders", false)
 	if err != nil || !enabled {
-		return // Silently skip if disabled or error
+		return nil // Silently skip if disabled or error
 	}
 
 	// Get reminder days setting
 	reminderDays := settingsService.GetIntSettingWithDefault("cancellation_reminder_days", 7)
 	if reminderDays <= 0 {
-		return // No reminders if days is 0 or negative
+		return nil // No reminders if days is 0 or negative
 	}
 
 	// Get subscriptions needing cancellation reminders
 	subscriptions, err := subscriptionService.GetSubscriptionsNeedingCancellationReminders(reminderDays)
 	if err != nil {
-		log.Printf("Error getting subscriptions for cancellation reminders: %v", err)
-		return
+		slog.Error("Failed to get subscriptions for cancellation reminders", "error", err)
+		return nil
 	}
 
 	if len(subscriptions) == 0 {
-		log.Printf("No subscriptions need cancellation reminders today")
-		return
+		slog.Info("No subscriptions need cancellation reminders today")
+		return nil
 	}
 
-	log.Printf("Checking %d subscription(s) for cancellation reminders", len(subscriptions))
+	slog.Info("Sending cancellation reminders", "subscriptions", len(subscriptions))
 
-	// Send reminder for each subscription (both email and Pushover)
+	// Send reminder for each subscription through every notification channel
 	sentCount := 0
 	failedCount := 0
+	held := make(map[*models.Subscription]int)
 	for sub, daysUntil := range subscriptions {
-		emailErr := emailService.SendCancellationReminder(sub, daysUntil)
-		pushoverErr := pushoverService.SendCancellationReminder(sub, daysUntil)
-		webhookErr := webhookService.SendCancellationReminder(sub, daysUntil)
-
-		// If all fail, count as failed; otherwise consider it sent
-		if emailErr != nil && pushoverErr != nil && webhookErr != nil {
-			log.Printf("Error sending cancellation reminder for subscription %s (ID: %d): email=%v, pushover=%v, webhook=%v", sub.Name, sub.ID, emailErr, pushoverErr, webhookErr)
+		// Push channels skip the reminder during quiet hours; it is sent to them later
+		quiet := settingsService.IsWithinQuietHours(time.Now())
+		err := notificationService.SendCancellationReminder(sub, daysUntil)
+
+		// If every channel failed, count as failed; otherwise consider it sent
+		var notifyErr *service.NotificationError
+		if errors.As(err, &notifyErr) && notifyErr.AllFailed {
+			slog.Error("Failed to send cancellation reminder", "subscription", sub.Name, "id", sub.ID, "failed", err)
 			failedCount++
-		} else {
-			// Mark reminder as sent for this cancellation date
-			now := time.Now()
-			sub.LastCancellationReminderSent = &now
-			if sub.CancellationDate != nil {
-				cancellationDateCopy := *sub.CancellationDate
-				sub.LastCancellationReminderDate = &cancellationDateCopy
-			}
+			continue
+		}
 
-			// Update the subscription in the database
-			_, updateErr := subscriptionService.Update(sub.ID, sub)
-			if updateErr != nil {
-				log.Printf("Warning: Failed to update last cancellation reminder sent for subscription %s (ID: %d): %v", sub.Name, sub.ID, updateErr)
-			}
+		// Mark reminder as sent for this cancellation date
+		now := time.Now()
+		sub.LastCancellationReminderSent = &now
+		if sub.CancellationDate != nil {
+			cancellationDateCopy := *sub.CancellationDate
+			sub.LastCancellationReminderDate = &cancellationDateCopy
+		}
 
-			var failed []string
-			if emailErr != nil {
-				failed = append(failed, fmt.Sprintf("email=%v", emailErr))
-			}
-			if pushoverErr != nil {
-				failed = append(failed, fmt.Sprintf("pushover=%v", pushoverErr))
-			}
-			if webhookErr != nil {
-				failed = append(failed, fmt.Sprintf("webhook=%v", webhookErr))
-			}
-			if len(failed) > 0 {
-				log.Printf("Sent cancellation reminder for subscription %s (ends in %d days) - some channels failed: %s", sub.Name, daysUntil, strings.Join(failed, ", "))
-			} else {
-				log.Printf("Sent cancellation reminders for subscription %s (ends in %d days)", sub.Name, daysUntil)
-			}
-			sentCount++
+		// Update the subscription in the database
+		_, updateErr := subscriptionService.Update(sub.ID, sub)
+		if updateErr != nil {
+			slog.Warn("Failed to update last cancellation reminder sent", "subscription", sub.Name, "id", sub.ID, "error", updateErr)
+		}
+
+		if err != nil {
+			slog.Warn("Sent cancellation reminder, some channels failed", "subscription", sub.Name, "days_until", daysUntil, "failed", err)
+		} else {
+			slog.Info("Sent cancellation reminder", "subscription", sub.Name, "days_until", daysUntil)
+		}
+		if quiet {
+			held[sub] = daysUntil
 		}
+		sentCount++
 	}
 
-	log.Printf("Cancellation reminder check complete: %d sent, %d failed", sentCount, failedCount)
+	slog.Info("Cancellation reminder check complete", "sent", sentCount, "failed", failedCount)
+	return held
 }
 
 // handleResetPassword handles the --reset-password CLI command
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"subtrackr/internal/service"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRunSchedule_StopsWhenContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	done := make(chan struct{})
+
+	go func() {
+		runSchedule(ctx, "test", time.Millisecond, 5*time.Millisecond, func() {
+			calls.Add(1)
+		})
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("schedule did not stop after the context was cancelled")
+	}
+
+	stopped := calls.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, calls.Load())
+}
+
+func TestRunSchedule_CancelledBeforeFirstRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	runSchedule(ctx, "test", time.Hour, time.Hour, func() { called = true })
+	assert.False(t, called)
+}
+
+func TestRunSchedule_SurvivesPanics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls atomic.Int32
+
+	go runSchedule(ctx, "test", time.Millisecond, time.Millisecond, func() {
+		calls.Add(1)
+		panic("boom")
+	})
+
+	assert.Eventually(t, func() bool { return calls.Load() >= 3 }, time.Second, time.Millisecond)
+}
+
+func TestRunDelayedSchedule_AsksForEachDelay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls, firsts atomic.Int32
+	done := make(chan struct{})
+
+	go func() {
+		runDelayedSchedule(ctx, "test", func(first bool) time.Duration {
+			if first {
+				firsts.Add(1)
+			}
+			return time.Millisecond
+		}, func() {
+			calls.Add(1)
+			panic("boom")
+		})
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return calls.Load() >= 3 }, time.Second, time.Millisecond, "A panic shouldn't stop the schedule")
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("schedule did not stop after the context was cancelled")
+	}
+	assert.Equal(t, int32(1), firsts.Load(), "Only the initial delay should be marked first")
+}
+
+func TestWaitForQuietHours(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Settings{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	settingsService := service.NewSettingsService(repository.NewSettingsRepository(db))
+
+	assert.True(t, waitForQuietHours(context.Background(), settingsService), "Should not wait without quiet hours")
+
+	now := time.Now().UTC()
+	assert.NoError(t, settingsService.SetQuietHours(now.Add(-time.Hour).Format("15:04"), now.Add(time.Hour).Format("15:04")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan bool)
+	go func() { done <- waitForQuietHours(ctx, settingsService) }()
+
+	select {
+	case <-done:
+		t.Fatal("reminders were not held during quiet hours")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case proceed := <-done:
+		assert.False(t, proceed, "Should not send reminders after shutdown")
+	case <-time.After(time.Second):
+		t.Fatal("wait did not stop after the context was cancelled")
+	}
+}
+
+func TestSendHeldReminders(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Settings{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	settingsService := service.NewSettingsService(repository.NewSettingsRepository(db))
+
+	var sent []string
+	send := func(sub *models.Subscription, daysUntil int) error {
+		sent = append(sent, sub.Name)
+		return nil
+	}
+	reminders := map[*models.Subscription]int{{Name: "Netflix"}: 3}
+
+	// Quiet hours have already ended, so the push reminders go out straight away
+	sendHeldReminders(context.Background(), settingsService, "renewal", reminders, send)
+	assert.Equal(t, []string{"Netflix"}, sent)
+
+	// Shutting down during quiet hours drops them
+	sent = nil
+	now := time.Now().UTC()
+	assert.NoError(t, settingsService.SetQuietHours(now.Add(-time.Hour).Format("15:04"), now.Add(time.Hour).Format("15:04")))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sendHeldReminders(ctx, settingsService, "renewal", reminders, send)
+	assert.Empty(t, sent)
+}
@@ -1,13 +1,41 @@
 package config
 
 import (
+	"io"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
 )
 
 type Config struct {
 	DatabasePath string
 	Port         string
 	Environment  string
+	APIRateLimit int // Requests per minute per API key, 0 disables
+
+	LoginMaxAttempts    int // Failed logins before lockout, 0 disables
+	LoginLockoutMinutes int
+	BcryptCost          int // Work factor for password hashes, 0 uses the bcrypt default
+
+	// LogoProviders is a comma-separated favicon provider order, empty uses the default
+	LogoProviders string
+
+	// DisableAuth turns off the built-in login, for deployments behind a trusted auth proxy
+	DisableAuth bool
+
+	// MetricsRequireAPIKey gates /metrics behind an API key instead of leaving it open for a scraper
+	MetricsRequireAPIKey bool
+
+	LogLevel  string // debug, info, warn or error
+	LogFormat string // text (human-readable) or json
+
+	// BackupDir holds database backups, empty uses a backups directory next to the database
+	BackupDir           string
+	BackupKeep          int // Newest backups kept when rotating, 0 keeps all
+	BackupIntervalHours int // Hours between scheduled backups, 0 disables them
+
+	IdempotencyKeyTTLHours int // Hours an API Idempotency-Key is remembered, 0 disables them
 }
 
 func Load() *Config {
@@ -15,12 +43,70 @@ func Load() *Config {
 		DatabasePath: getEnv("DATABASE_PATH", "./data/subtrackr.db"),
 		Port:         getEnv("PORT", "8080"),
 		Environment:  getEnv("GIN_MODE", "debug"),
+		APIRateLimit: getEnvInt("API_RATE_LIMIT", 60),
+
+		LoginMaxAttempts:    getEnvInt("LOGIN_MAX_ATTEMPTS", 5),
+		LoginLockoutMinutes: getEnvInt("LOGIN_LOCKOUT_MINUTES", 15),
+		BcryptCost:          getEnvInt("BCRYPT_COST", 0),
+
+		LogoProviders: getEnv("LOGO_PROVIDERS", ""),
+
+		DisableAuth: getEnvBool("DISABLE_AUTH", false),
+
+		MetricsRequireAPIKey: getEnvBool("METRICS_REQUIRE_API_KEY", false),
+
+		LogLevel:  strings.ToLower(getEnv("LOG_LEVEL", "info")),
+		LogFormat: strings.ToLower(getEnv("LOG_FORMAT", "text")),
+
+		BackupDir:           getEnv("BACKUP_DIR", ""),
+		BackupKeep:          getEnvInt("BACKUP_KEEP", 7),
+		BackupIntervalHours: getEnvInt("BACKUP_INTERVAL_HOURS", 24),
+
+		IdempotencyKeyTTLHours: getEnvInt("IDEMPOTENCY_KEY_TTL_HOURS", 24),
+	}
+}
+
+// Logger builds the application logger writing to w. Unknown levels fall back
+// to info and unknown formats to text.
+func (c *Config) Logger(w io.Writer) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		level = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	if c.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts))
 	}
+	return slog.New(slog.NewTextHandler(w, opts))
 }
 
+// getEnv returns the environment variable key, or defaultValue if it is unset or empty
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+// getEnvInt returns the environment variable key as an integer, or defaultValue
+// if it is unset or not a valid integer
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBool returns the environment variable key as a boolean, or defaultValue
+// if it is unset or not a valid boolean
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_DisableAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected bool
+	}{
+		{"Defaults to false", "", false},
+		{"Enabled with true", "true", true},
+		{"Enabled with 1", "1", true},
+		{"Explicitly false", "false", false},
+		{"Invalid value falls back to false", "yes-please", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DISABLE_AUTH", tt.value)
+			assert.Equal(t, tt.expected, Load().DisableAuth)
+		})
+	}
+}
+
+func TestLoad_Logging(t *testing.T) {
+	t.Run("Defaults to info text", func(t *testing.T) {
+		t.Setenv("LOG_LEVEL", "")
+		t.Setenv("LOG_FORMAT", "")
+		cfg := Load()
+		assert.Equal(t, "info", cfg.LogLevel)
+		assert.Equal(t, "text", cfg.LogFormat)
+
+		var buf bytes.Buffer
+		logger := cfg.Logger(&buf)
+		logger.Debug("hidden")
+		logger.Info("started", "port", "8080")
+		assert.NotContains(t, buf.String(), "hidden")
+		assert.Contains(t, buf.String(), "level=INFO msg=started port=8080")
+	})
+
+	t.Run("JSON with debug level", func(t *testing.T) {
+		t.Setenv("LOG_LEVEL", "DEBUG")
+		t.Setenv("LOG_FORMAT", "json")
+		cfg := Load()
+		assert.Equal(t, "debug", cfg.LogLevel)
+		assert.Equal(t, "json", cfg.LogFormat)
+
+		var buf bytes.Buffer
+		cfg.Logger(&buf).Debug("checking", "count", 2)
+
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "DEBUG", entry["level"])
+		assert.Equal(t, "checking", entry["msg"])
+		assert.Equal(t, float64(2), entry["count"])
+	})
+
+	t.Run("Warn level drops info", func(t *testing.T) {
+		t.Setenv("LOG_LEVEL", "warn")
+		t.Setenv("LOG_FORMAT", "")
+
+		var buf bytes.Buffer
+		logger := Load().Logger(&buf)
+		logger.Info("hidden")
+		logger.Warn("shown")
+		assert.NotContains(t, buf.String(), "hidden")
+		assert.Contains(t, buf.String(), "shown")
+	})
+
+	t.Run("Invalid level falls back to info", func(t *testing.T) {
+		t.Setenv("LOG_LEVEL", "verbose")
+		t.Setenv("LOG_FORMAT", "")
+
+		var buf bytes.Buffer
+		logger := Load().Logger(&buf)
+		logger.Debug("hidden")
+		logger.Info("shown")
+		assert.NotContains(t, buf.String(), "hidden")
+		assert.Contains(t, buf.String(), "shown")
+	})
+}
@@ -1,13 +1,22 @@
 package database
 
 import (
+	"strings"
+
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// sqliteOptions are applied to every pooled connection. The web server and the
+// MCP server open the same database file, so WAL lets readers and a writer work
+// at the same time, and the busy timeout makes a blocked writer wait for the
+// lock instead of failing with "database is locked". Immediate transactions take
+// the write lock up front so two writers can't deadlock upgrading read locks.
+const sqliteOptions = "_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate"
+
 func Initialize(dbPath string) (*gorm.DB, error) {
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
+	db, err := gorm.Open(sqlite.Open(withSQLiteOptions(dbPath)), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
@@ -26,4 +35,13 @@ func Initialize(dbPath string) (*gorm.DB, error) {
 	}
 
 	return db, nil
-}
\ No newline at end of file
+}
+
+// withSQLiteOptions appends sqliteOptions to a database path, keeping any
+// query parameters the caller already supplied
+func withSQLiteOptions(dbPath string) string {
+	if strings.Contains(dbPath, "?") {
+		return dbPath + "&" + sqliteOptions
+	}
+	return dbPath + "?" + sqliteOptions
+}
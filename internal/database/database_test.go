@@ -0,0 +1,182 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"subtrackr/internal/models"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type counter struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+func openTestDB(t *testing.T, path string) *gorm.DB {
+	db, err := Initialize(path)
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	return db
+}
+
+func TestWithSQLiteOptions(t *testing.T) {
+	assert.Equal(t, "data.db?"+sqliteOptions, withSQLiteOptions("data.db"))
+	assert.Equal(t, "data.db?cache=shared&"+sqliteOptions, withSQLiteOptions("data.db?cache=shared"))
+}
+
+func TestInitialize_EnablesWALAndBusyTimeout(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "wal.db"))
+
+	var mode string
+	assert.NoError(t, db.Raw("PRAGMA journal_mode").Scan(&mode).Error)
+	assert.Equal(t, "wal", mode)
+
+	var timeout int
+	assert.NoError(t, db.Raw("PRAGMA busy_timeout").Scan(&timeout).Error)
+	assert.Equal(t, 5000, timeout)
+}
+
+func TestInitialize_ConcurrentWritersShareFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared.db")
+
+	// Simulate the web server and the MCP server opening the same file
+	web := openTestDB(t, path)
+	mcp := openTestDB(t, path)
+	if err := web.AutoMigrate(&counter{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	const writesPerWorker = 25
+	conns := []*gorm.DB{web, mcp, web, mcp}
+	errs := make(chan error, len(conns)*writesPerWorker)
+
+	var wg sync.WaitGroup
+	for w, db := range conns {
+		wg.Add(1)
+		go func(w int, db *gorm.DB) {
+			defer wg.Done()
+			for i := 0; i < writesPerWorker; i++ {
+				if err := db.Create(&counter{Name: fmt.Sprintf("worker-%d-%d", w, i)}).Error; err != nil {
+					errs <- err
+				}
+			}
+		}(w, db)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Concurrent write failed: %v", err)
+	}
+
+	var count int64
+	assert.NoError(t, mcp.Model(&counter{}).Count(&count).Error)
+	assert.Equal(t, int64(len(conns)*writesPerWorker), count)
+}
+
+func TestRunMigrations_SeedsStarterCategoriesOnce(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "seed.db"))
+
+	assert.NoError(t, RunMigrations(db))
+	assert.NoError(t, RunMigrations(db))
+
+	var names []string
+	assert.NoError(t, db.Model(&models.Category{}).Order("id").Pluck("name", &names).Error)
+	assert.Equal(t, starterCategories, names)
+
+	// Deleting the starter set must not bring it back on the next start
+	assert.NoError(t, db.Where("1 = 1").Delete(&models.Category{}).Error)
+	assert.NoError(t, RunMigrations(db))
+
+	var count int64
+	db.Model(&models.Category{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestRunMigrations_KeepsExistingCategories(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "existing.db"))
+	assert.NoError(t, db.AutoMigrate(&models.Category{}))
+	assert.NoError(t, db.Create(&models.Category{Name: "Streaming"}).Error)
+
+	assert.NoError(t, RunMigrations(db))
+
+	var names []string
+	assert.NoError(t, db.Model(&models.Category{}).Pluck("name", &names).Error)
+	assert.Equal(t, []string{"Streaming"}, names)
+}
+
+func TestRunMigrations_AddsRenewalIndexIdempotently(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "index.db"))
+
+	assert.NoError(t, RunMigrations(db))
+	assert.NoError(t, RunMigrations(db))
+
+	var indexes []string
+	assert.NoError(t, db.Raw("SELECT name FROM pragma_index_list('subscriptions')").Scan(&indexes).Error)
+	assert.Contains(t, indexes, renewalIndexName)
+
+	var columns []string
+	assert.NoError(t, db.Raw("SELECT name FROM pragma_index_info(?) ORDER BY seqno", renewalIndexName).Scan(&columns).Error)
+	assert.Equal(t, []string{"status", "renewal_date"}, columns)
+}
+
+func TestRunMigrations_SearchIndexStaysInSync(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "search.db"))
+
+	assert.NoError(t, RunMigrations(db))
+	assert.NoError(t, RunMigrations(db))
+
+	var tables int64
+	db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", searchTableName).Scan(&tables)
+	if tables == 0 {
+		t.Skip("SQLite was built without FTS5; build with -tags sqlite_fts5 to run")
+	}
+
+	matches := func(term string) []string {
+		var names []string
+		assert.NoError(t, db.Raw("SELECT s.name FROM subscriptions s JOIN subscriptions_fts f ON f.rowid = s.id WHERE subscriptions_fts MATCH ? ORDER BY s.name", term).Scan(&names).Error)
+		return names
+	}
+
+	sub := models.Subscription{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active", CategoryID: 1, Notes: "family plan"}
+	assert.NoError(t, db.Create(&sub).Error)
+	assert.Equal(t, []string{"Netflix"}, matches("family"))
+
+	assert.NoError(t, db.Model(&sub).Update("notes", "shared with parents").Error)
+	assert.Empty(t, matches("family"))
+	assert.Equal(t, []string{"Netflix"}, matches("parents"))
+
+	assert.NoError(t, db.Delete(&sub).Error)
+	assert.Empty(t, matches("parents"))
+}
+
+func TestRunMigrations_SearchIndexCatchesUpAfterTriggersDropped(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "search.db"))
+	assert.NoError(t, RunMigrations(db))
+
+	var tables int64
+	db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", searchTableName).Scan(&tables)
+	if tables == 0 {
+		var triggers int64
+		db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'subscriptions_fts_%'").Scan(&triggers)
+		assert.Zero(t, triggers, "Without FTS5 there are no triggers to fail writes")
+		t.Skip("SQLite was built without FTS5; build with -tags sqlite_fts5 to run")
+	}
+
+	// A binary built without FTS5 drops the triggers and writes unindexed
+	for _, trigger := range searchTriggers {
+		assert.NoError(t, db.Exec("DROP TRIGGER "+trigger.name).Error)
+	}
+	assert.NoError(t, db.Create(&models.Subscription{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active", CategoryID: 1, Notes: "family plan"}).Error)
+
+	assert.NoError(t, RunMigrations(db))
+
+	var names []string
+	assert.NoError(t, db.Raw("SELECT s.name FROM subscriptions s JOIN subscriptions_fts f ON f.rowid = s.id WHERE subscriptions_fts MATCH ?", "family").Scan(&names).Error)
+	assert.Equal(t, []string{"Netflix"}, names)
+}
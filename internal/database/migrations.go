@@ -10,7 +10,7 @@ import (
 // RunMigrations executes all database migrations
 func RunMigrations(db *gorm.DB) error {
 	// Auto-migrate non-problematic models first
-	err := db.AutoMigrate(&models.Category{}, &models.Settings{}, &models.APIKey{}, &models.ExchangeRate{})
+	err := db.AutoMigrate(&models.Category{}, &models.Settings{}, &models.APIKey{}, &models.ExchangeRate{}, &models.Session{}, &models.DateMigrationLog{}, &models.NotificationLog{})
 	if err != nil {
 		return err
 	}
@@ -25,6 +25,7 @@ func RunMigrations(db *gorm.DB) error {
 		migrateCancellationReminderTracking,
 		migrateScheduleInterval,
 		migrateReminderEnabled,
+		seedDefaultCategories,
 	}
 
 	for _, migration := range migrations {
@@ -37,6 +38,117 @@ func RunMigrations(db *gorm.DB) error {
 	// This might fail on existing databases but that's okay
 	db.AutoMigrate(&models.Subscription{})
 
+	// Indexes need the subscriptions table, which may only exist after the auto-migrate above
+	if err := migrateRenewalIndex(db); err != nil {
+		return err
+	}
+	return migrateSearchIndex(db)
+}
+
+// renewalIndexName indexes the status and renewal_date filters used by the
+// upcoming-renewal and reminder queries
+const renewalIndexName = "idx_subscriptions_status_renewal_date"
+
+// migrateRenewalIndex adds a composite index on (status, renewal_date)
+func migrateRenewalIndex(db *gorm.DB) error {
+	var count int64
+	db.Raw("SELECT COUNT(*) FROM pragma_index_list('subscriptions') WHERE name = ?", renewalIndexName).Scan(&count)
+
+	if count > 0 {
+		return nil
+	}
+
+	log.Println("Running migration: Adding status/renewal_date index...")
+
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS " + renewalIndexName + " ON subscriptions(status, renewal_date)").Error; err != nil {
+		return err
+	}
+
+	log.Println("Migration completed: status/renewal_date index added")
+	return nil
+}
+
+// searchTableName is the FTS5 table indexing subscription name, notes, account
+// and payment method. It only exists when SQLite was built with FTS5.
+const searchTableName = "subscriptions_fts"
+
+// searchTriggers keep the search table in sync with subscriptions, in creation order
+var searchTriggers = []struct{ name, statement string }{
+	{"subscriptions_fts_ai", `CREATE TRIGGER IF NOT EXISTS subscriptions_fts_ai AFTER INSERT ON subscriptions BEGIN
+		INSERT INTO subscriptions_fts(rowid, name, notes, account, payment_method)
+		VALUES (new.id, new.name, new.notes, new.account, new.payment_method);
+	END`},
+	{"subscriptions_fts_ad", `CREATE TRIGGER IF NOT EXISTS subscriptions_fts_ad AFTER DELETE ON subscriptions BEGIN
+		INSERT INTO subscriptions_fts(subscriptions_fts, rowid, name, notes, account, payment_method)
+		VALUES ('delete', old.id, old.name, old.notes, old.account, old.payment_method);
+	END`},
+	{"subscriptions_fts_au", `CREATE TRIGGER IF NOT EXISTS subscriptions_fts_au AFTER UPDATE ON subscriptions BEGIN
+		INSERT INTO subscriptions_fts(subscriptions_fts, rowid, name, notes, account, payment_method)
+		VALUES ('delete', old.id, old.name, old.notes, old.account, old.payment_method);
+		INSERT INTO subscriptions_fts(rowid, name, notes, account, payment_method)
+		VALUES (new.id, new.name, new.notes, new.account, new.payment_method);
+	END`},
+}
+
+// migrateSearchIndex creates the full-text search table, adds the triggers that
+// keep it in sync with subscriptions and rebuilds it. FTS5 is only compiled in
+// with the sqlite_fts5 build tag; without it search falls back to LIKE.
+//
+// A binary built without FTS5 can't write through the triggers of a database
+// indexed by one built with it, so they are dropped at startup. The table itself
+// can't be dropped without the module; the next FTS5 build recreates the
+// triggers and rebuilds the index to pick up the writes made in between.
+func migrateSearchIndex(db *gorm.DB) error {
+	names := make([]string, len(searchTriggers))
+	for i, trigger := range searchTriggers {
+		names[i] = trigger.name
+	}
+
+	var enabled bool
+	db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled)
+	if !enabled {
+		for _, name := range names {
+			if err := db.Exec("DROP TRIGGER IF EXISTS " + name).Error; err != nil {
+				return err
+			}
+		}
+		log.Println("Skipping full-text search index, FTS5 is not available")
+		return nil
+	}
+
+	var count int64
+	db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN ?", names).Scan(&count)
+
+	if count == int64(len(searchTriggers)) {
+		return nil
+	}
+
+	log.Println("Running migration: Adding full-text search index...")
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			`CREATE VIRTUAL TABLE IF NOT EXISTS subscriptions_fts USING fts5(
+				name, notes, account, payment_method,
+				content='subscriptions', content_rowid='id'
+			)`,
+		}
+		for _, trigger := range searchTriggers {
+			statements = append(statements, trigger.statement)
+		}
+		statements = append(statements, `INSERT INTO subscriptions_fts(subscriptions_fts) VALUES ('rebuild')`)
+
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Println("Migration completed: full-text search index added")
 	return nil
 }
 
@@ -284,3 +396,35 @@ func migrateReminderEnabled(db *gorm.DB) error {
 	log.Println("Migration completed: reminder_enabled field added")
 	return nil
 }
+
+// starterCategories are created on a fresh install so subscriptions have somewhere to go
+var starterCategories = []string{"Entertainment", "Productivity", "Utilities", "Storage", "Finance", "Other"}
+
+// categoriesSeededKey marks that the starter categories were considered, so they
+// are not recreated after a user deletes them
+const categoriesSeededKey = "categories_seeded"
+
+// seedDefaultCategories inserts the starter categories once, when the categories table is empty
+func seedDefaultCategories(db *gorm.DB) error {
+	var seeded int64
+	db.Model(&models.Settings{}).Where("key = ?", categoriesSeededKey).Count(&seeded)
+	if seeded > 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Category{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			log.Println("Running migration: Seeding starter categories...")
+			for i, name := range starterCategories {
+				if err := tx.Create(&models.Category{Name: name, Color: models.DefaultCategoryColor(name), SortOrder: i + 1}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Create(&models.Settings{Key: categoriesSeededKey, Value: "true"}).Error
+	})
+}
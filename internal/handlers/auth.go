@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"crypto/subtle"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"subtrackr/internal/service"
 
@@ -15,13 +19,15 @@ type AuthHandler struct {
 	settingsService *service.SettingsService
 	sessionService  *service.SessionService
 	emailService    *service.EmailService
+	loginLimiter    *service.LoginLimiter
 }
 
-func NewAuthHandler(settingsService *service.SettingsService, sessionService *service.SessionService, emailService *service.EmailService) *AuthHandler {
+func NewAuthHandler(settingsService *service.SettingsService, sessionService *service.SessionService, emailService *service.EmailService, loginLimiter *service.LoginLimiter) *AuthHandler {
 	return &AuthHandler{
 		settingsService: settingsService,
 		sessionService:  sessionService,
 		emailService:    emailService,
+		loginLimiter:    loginLimiter,
 	}
 }
 
@@ -42,8 +48,8 @@ func isValidRedirect(redirect string) bool {
 
 // ShowLoginPage displays the login page
 func (h *AuthHandler) ShowLoginPage(c *gin.Context) {
-	// If already authenticated, redirect to dashboard
-	if h.sessionService.IsAuthenticated(c.Request) {
+	// If already authenticated or login is turned off, redirect to dashboard
+	if h.settingsService.IsAuthDisabledByConfig() || h.sessionService.IsAuthenticated(c.Request) {
 		c.Redirect(http.StatusFound, "/")
 		return
 	}
@@ -70,6 +76,17 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		redirect = "/"
 	}
 
+	// Reject attempts from locked-out clients before checking credentials
+	limiterKeys := []string{"ip:" + c.ClientIP(), "user:" + strings.ToLower(username)}
+	if remaining := h.loginLimiter.LockedFor(limiterKeys...); remaining > 0 {
+		minutes := int(math.Ceil(remaining.Minutes()))
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+		c.HTML(http.StatusTooManyRequests, "login-error.html", gin.H{
+			"Error": fmt.Sprintf("Too many failed login attempts. Try again in %d minute(s).", minutes),
+		})
+		return
+	}
+
 	// Validate credentials using constant-time comparison to prevent timing attacks
 	storedUsername, err := h.settingsService.GetAuthUsername()
 	if err != nil {
@@ -89,14 +106,22 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	// Only fail after both checks to prevent username enumeration via timing
 	if !validUsername || !validPassword {
+		h.loginLimiter.RecordFailure(limiterKeys...)
 		c.HTML(http.StatusUnauthorized, "login-error.html", gin.H{
 			"Error": "Invalid username or password",
 		})
 		return
 	}
 
+	h.loginLimiter.Reset(limiterKeys...)
+
+	// Best effort: the login still succeeds if the upgraded hash can't be saved
+	if err := h.settingsService.UpgradePasswordHash(password); err != nil {
+		slog.Warn("Failed to upgrade password hash", "error", err)
+	}
+
 	// Create session
-	if err := h.sessionService.CreateSession(c.Writer, c.Request, rememberMe); err != nil {
+	if err := h.sessionService.CreateSession(c.Writer, c.Request, rememberMe, c.ClientIP()); err != nil {
 		c.HTML(http.StatusInternalServerError, "login-error.html", gin.H{
 			"Error": "Failed to create session",
 		})
@@ -118,6 +143,63 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	c.Redirect(http.StatusFound, "/login")
 }
 
+// ListSessions shows all active login sessions, marking the current one
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	sessionList, err := h.sessionService.ListSessions(c.Request)
+	if err != nil {
+		if c.GetHeader("HX-Request") != "" {
+			c.HTML(http.StatusInternalServerError, "sessions-list.html", gin.H{"Error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "" {
+		c.JSON(http.StatusOK, sessionList)
+		return
+	}
+
+	c.HTML(http.StatusOK, "sessions-list.html", gin.H{
+		"Sessions":     sessionList,
+		"GoDateFormat": h.settingsService.GetGoDateFormat(),
+	})
+}
+
+// RevokeSession signs out a single session, which may belong to another device
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	id := c.Param("id")
+	if id == h.sessionService.CurrentSessionID(c.Request) {
+		if err := h.sessionService.DestroySession(c.Writer, c.Request); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+			return
+		}
+		c.Header("HX-Redirect", "/login")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.ListSessions(c)
+}
+
+// RevokeAllSessions signs out every session, including the current one
+func (h *AuthHandler) RevokeAllSessions(c *gin.Context) {
+	if err := h.sessionService.RevokeAllSessions(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Clear this browser's cookie too; its record is already gone
+	h.sessionService.DestroySession(c.Writer, c.Request)
+	c.Header("HX-Redirect", "/login")
+	c.Status(http.StatusOK)
+}
+
 // ShowForgotPasswordPage displays the forgot password page
 func (h *AuthHandler) ShowForgotPasswordPage(c *gin.Context) {
 	c.HTML(http.StatusOK, "forgot-password.html", gin.H{})
@@ -215,25 +297,20 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	// Validate token
-	if err := h.settingsService.ValidateResetToken(token); err != nil {
-		c.HTML(http.StatusBadRequest, "reset-password-error.html", gin.H{
-			"Error": "Invalid or expired reset token",
-		})
-		return
-	}
-
-	// Update password
-	if err := h.settingsService.SetAuthPassword(newPassword); err != nil {
+	// Update password; the token is only consumed once the new password is stored
+	if err := h.settingsService.ResetPasswordWithToken(token, newPassword); err != nil {
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			c.HTML(http.StatusBadRequest, "reset-password-error.html", gin.H{
+				"Error": "Invalid or expired reset token",
+			})
+			return
+		}
 		c.HTML(http.StatusInternalServerError, "reset-password-error.html", gin.H{
 			"Error": "Failed to update password",
 		})
 		return
 	}
 
-	// Clear reset token
-	h.settingsService.ClearResetToken()
-
 	c.HTML(http.StatusOK, "reset-password-success.html", gin.H{
 		"Message": "Password reset successfully. You can now login with your new password.",
 	})
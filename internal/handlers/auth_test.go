@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupLoginTest(t *testing.T) *gin.Engine {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Settings{}, &models.Session{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	settingsService := service.NewSettingsService(repository.NewSettingsRepository(db))
+	if err := settingsService.SetupAuth("admin", "correct-password"); err != nil {
+		t.Fatalf("Failed to set up auth: %v", err)
+	}
+	secret, _ := settingsService.GetOrGenerateSessionSecret()
+
+	handler := NewAuthHandler(settingsService, service.NewSessionService(secret, repository.NewSessionRepository(db), settingsService), nil, service.NewLoginLimiter(5, 15*time.Minute))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.SetHTMLTemplate(template.Must(template.New("login-error.html").Parse("{{.Error}}")))
+	router.POST("/api/auth/login", handler.Login)
+	return router
+}
+
+func postLogin(router *gin.Engine, username, password string) *httptest.ResponseRecorder {
+	form := url.Values{"username": {username}, "password": {password}}
+	req := httptest.NewRequest("POST", "/api/auth/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLogin_LockoutAfterFailedAttempts(t *testing.T) {
+	router := setupLoginTest(t)
+
+	for i := 0; i < 5; i++ {
+		w := postLogin(router, "admin", "wrong-password")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid username or password")
+	}
+
+	// Correct credentials are still rejected during the lockout window
+	w := postLogin(router, "admin", "correct-password")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "Too many failed login attempts")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestLogin_SuccessResetsFailures(t *testing.T) {
+	router := setupLoginTest(t)
+
+	for i := 0; i < 4; i++ {
+		postLogin(router, "admin", "wrong-password")
+	}
+	w := postLogin(router, "admin", "correct-password")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The counter starts over, so four more failures do not lock the account
+	for i := 0; i < 4; i++ {
+		postLogin(router, "admin", "wrong-password")
+	}
+	w = postLogin(router, "admin", "correct-password")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
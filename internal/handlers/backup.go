@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BackupHandler triggers database backups on demand
+type BackupHandler struct {
+	service *service.BackupService
+}
+
+func NewBackupHandler(service *service.BackupService) *BackupHandler {
+	return &BackupHandler{service: service}
+}
+
+// CreateBackup writes a database backup now and returns its file name
+func (h *BackupHandler) CreateBackup(c *gin.Context) {
+	name, err := h.service.Backup(time.Now())
+	if errors.Is(err, service.ErrBackupUnavailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"filename": name})
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"subtrackr/internal/models"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxBulkOperations caps how many operations a single bulk request may carry
+const maxBulkOperations = 1000
+
+// bulkOperation is one entry of a bulk request. Create and update read their
+// fields from Data using the same keys as the PATCH endpoint.
+type bulkOperation struct {
+	Op   string          `json:"op"`
+	ID   uint            `json:"id"`
+	Data json.RawMessage `json:"data"`
+}
+
+// bulkResult reports the outcome of a single bulk operation
+type bulkResult struct {
+	Index        int                  `json:"index"`
+	Op           string               `json:"op"`
+	ID           uint                 `json:"id,omitempty"`
+	Success      bool                 `json:"success"`
+	Error        string               `json:"error,omitempty"`
+	Subscription *models.Subscription `json:"subscription,omitempty"`
+}
+
+// BulkSubscriptions executes a list of create/update/delete operations in one transaction.
+// Invalid items are reported individually and skipped; a database error rolls back the
+// whole batch. High-cost alerts are not sent for bulk writes.
+func (h *SubscriptionHandler) BulkSubscriptions(c *gin.Context) {
+	var ops []bulkOperation
+	if err := c.ShouldBindJSON(&ops); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request body must be a JSON array of operations"})
+		return
+	}
+	if len(ops) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No operations provided"})
+		return
+	}
+	if len(ops) > maxBulkOperations {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Too many operations (max %d)", maxBulkOperations)})
+		return
+	}
+
+	results := make([]bulkResult, len(ops))
+	creates := make(map[int]*models.Subscription)
+
+	// Decode and validate creates up front so logo lookups happen outside the transaction
+	for i, op := range ops {
+		results[i] = bulkResult{Index: i, Op: op.Op, ID: op.ID}
+		switch op.Op {
+		case "create":
+			subscription, err := newSubscriptionFromJSON(op.Data)
+			if err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+			h.fetchAndSetLogo(subscription)
+			creates[i] = subscription
+		case "update", "delete":
+			if op.ID == 0 {
+				results[i].Error = "id is required"
+			}
+		default:
+			results[i].Error = fmt.Sprintf("unknown op %q", op.Op)
+		}
+	}
+
+	failedIndex := -1
+	err := h.service.Transaction(func(tx *service.SubscriptionService) error {
+		for i, op := range ops {
+			if results[i].Error != "" {
+				continue
+			}
+			failedIndex = i
+
+			switch op.Op {
+			case "create":
+				created, err := tx.Create(creates[i])
+				if err != nil {
+					return err
+				}
+				results[i].ID = created.ID
+				results[i].Subscription = created
+			case "update":
+				existing, err := tx.GetByID(op.ID)
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					results[i].Error = "subscription not found"
+					continue
+				} else if err != nil {
+					return err
+				}
+				previousCost := existing.Cost
+				if err := applySubscriptionPatch(existing, op.Data); err != nil {
+					results[i].Error = err.Error()
+					continue
+				}
+				if err := existing.ValidateUpdate(previousCost); err != nil {
+					results[i].Error = err.Error()
+					continue
+				}
+				updated, err := tx.Update(op.ID, existing)
+				if err != nil {
+					return err
+				}
+				results[i].Subscription = updated
+			case "delete":
+				if _, err := tx.GetByID(op.ID); errors.Is(err, gorm.ErrRecordNotFound) {
+					results[i].Error = "subscription not found"
+					continue
+				} else if err != nil {
+					return err
+				}
+				if err := tx.Delete(op.ID); err != nil {
+					return err
+				}
+			}
+			results[i].Success = true
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Operation %d failed, no changes were saved: %v", failedIndex, err),
+			"index": failedIndex,
+		})
+		return
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":   results,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+	})
+}
+
+// recategorizeRequest is the body of a bulk category change
+type recategorizeRequest struct {
+	IDs        []uint `json:"ids"`
+	CategoryID uint   `json:"category_id"`
+}
+
+// RecategorizeSubscriptions moves a list of subscriptions to one category in a single transaction
+func (h *SubscriptionHandler) RecategorizeSubscriptions(c *gin.Context) {
+	var req recategorizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON body"})
+		return
+	}
+	if len(req.IDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No subscription IDs provided"})
+		return
+	}
+	if len(req.IDs) > maxBulkOperations {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Too many subscription IDs (max %d)", maxBulkOperations)})
+		return
+	}
+	if req.CategoryID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category_id is required"})
+		return
+	}
+
+	updated, err := h.service.Recategorize(req.IDs, req.CategoryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"updated":     updated,
+		"category_id": req.CategoryID,
+	})
+}
+
+// newSubscriptionFromJSON builds and validates a new subscription from a JSON object,
+// applying the same defaults as the form-based create
+func newSubscriptionFromJSON(data json.RawMessage) (*models.Subscription, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("data is required")
+	}
+
+	subscription := &models.Subscription{
+		OriginalCurrency: "USD",
+		ScheduleInterval: 1,
+		ReminderEnabled:  true,
+	}
+	if err := applySubscriptionPatch(subscription, data); err != nil {
+		return nil, err
+	}
+	if err := subscription.Validate(); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSubscriptionFromJSON(t *testing.T) {
+	t.Run("Applies defaults", func(t *testing.T) {
+		sub, err := newSubscriptionFromJSON(json.RawMessage(`{"name":"Netflix","cost":15.99,"schedule":"Monthly","status":"Active"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "Netflix", sub.Name)
+		assert.Equal(t, "USD", sub.OriginalCurrency)
+		assert.Equal(t, 1, sub.ScheduleInterval)
+		assert.True(t, sub.ReminderEnabled)
+	})
+
+	t.Run("Explicit values override defaults", func(t *testing.T) {
+		sub, err := newSubscriptionFromJSON(json.RawMessage(`{"name":"Domain","cost":12,"schedule":"Annual","status":"Active","original_currency":"EUR","schedule_interval":2,"reminder_enabled":false,"start_date":"2025-03-01"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "EUR", sub.OriginalCurrency)
+		assert.Equal(t, 2, sub.ScheduleInterval)
+		assert.False(t, sub.ReminderEnabled)
+		assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), *sub.StartDate)
+	})
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"Missing data", ``, "data is required"},
+		{"Not an object", `[1,2]`, "invalid JSON body"},
+		{"Missing name", `{"cost":5,"schedule":"Monthly","status":"Active"}`, "name is required"},
+		{"Invalid status", `{"name":"X","cost":5,"schedule":"Monthly","status":"Gone"}`, "invalid status"},
+		{"Invalid date", `{"name":"X","cost":5,"schedule":"Monthly","status":"Active","renewal_date":"03/01/2025"}`, "invalid renewal_date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSubscriptionFromJSON(json.RawMessage(tt.data))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
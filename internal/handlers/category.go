@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"subtrackr/internal/models"
 	"subtrackr/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type CategoryHandler struct {
@@ -35,7 +37,10 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		return
 	}
 	created, err := h.service.Create(&category)
-	if err != nil {
+	if errors.Is(err, service.ErrInvalidCategoryColor) || errors.Is(err, service.ErrCategoryIconTooLong) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -55,23 +60,119 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 	updated, err := h.service.Update(uint(id), &category)
-	if err != nil {
+	if errors.Is(err, service.ErrInvalidCategoryColor) || errors.Is(err, service.ErrCategoryIconTooLong) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, updated)
 }
 
-// Delete a category
+// Delete a category. With ?reassign_to=<id> its subscriptions are moved there first;
+// without it, a category that is still in use is refused with 409.
 func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
 		return
 	}
-	if err := h.service.Delete(uint(id)); err != nil {
+
+	if reassignParam := c.Query("reassign_to"); reassignParam != "" {
+		reassignTo, err := strconv.ParseUint(reassignParam, 10, 32)
+		if err != nil || reassignTo == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reassign_to"})
+			return
+		}
+		moved, err := h.service.DeleteAndReassign(uint(id), uint(reassignTo))
+		if errors.Is(err, service.ErrMergeIntoSelf) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		} else if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"moved": moved, "reassigned_to": reassignTo})
+		return
+	}
+
+	var inUse *service.CategoryInUseError
+	if err := h.service.Delete(uint(id)); errors.As(err, &inUse) {
+		c.JSON(http.StatusConflict, gin.H{"error": inUse.Error(), "subscription_count": inUse.Subscriptions})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
+
+type reorderCategoriesRequest struct {
+	IDs []uint `json:"ids"`
+}
+
+// Reorder categories, returning them in their new order
+func (h *CategoryHandler) ReorderCategories(c *gin.Context) {
+	var req reorderCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON body"})
+		return
+	}
+	if len(req.IDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No category IDs provided"})
+		return
+	}
+
+	if err := h.service.Reorder(req.IDs); errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown category ID"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.ListCategories(c)
+}
+
+type mergeCategoryRequest struct {
+	TargetID uint `json:"target_id" form:"target_id"`
+}
+
+// Merge a category into another, moving its subscriptions
+func (h *CategoryHandler) MergeCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	var req mergeCategoryRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.TargetID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target_id is required"})
+		return
+	}
+
+	moved, err := h.service.MergeCategory(uint(id), req.TargetID)
+	if errors.Is(err, service.ErrMergeIntoSelf) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"moved":     moved,
+		"target_id": req.TargetID,
+	})
+}
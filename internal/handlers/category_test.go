@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupCategoryHandlerTest(t *testing.T) (*gin.Engine, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Category{}, &models.Subscription{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	handler := NewCategoryHandler(service.NewCategoryService(repository.NewCategoryRepository(db)))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.DELETE("/api/categories/:id", handler.DeleteCategory)
+	return router, db
+}
+
+func TestDeleteCategory(t *testing.T) {
+	router, db := setupCategoryHandlerTest(t)
+
+	source := models.Category{Name: "Streaming"}
+	target := models.Category{Name: "Entertainment"}
+	assert.NoError(t, db.Create(&source).Error)
+	assert.NoError(t, db.Create(&target).Error)
+	sub := models.Subscription{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active", CategoryID: source.ID}
+	assert.NoError(t, db.Create(&sub).Error)
+
+	del := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("DELETE", path, nil))
+		return w
+	}
+
+	t.Run("blocked while in use", func(t *testing.T) {
+		w := del(fmt.Sprintf("/api/categories/%d", source.ID))
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, float64(1), body["subscription_count"])
+		assert.Contains(t, body["error"], "1 subscription(s)")
+	})
+
+	t.Run("invalid reassign target", func(t *testing.T) {
+		w := del(fmt.Sprintf("/api/categories/%d?reassign_to=abc", source.ID))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = del(fmt.Sprintf("/api/categories/%d?reassign_to=9999", source.ID))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("reassign then delete", func(t *testing.T) {
+		w := del(fmt.Sprintf("/api/categories/%d?reassign_to=%d", source.ID, target.ID))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, fmt.Sprintf(`{"moved":1,"reassigned_to":%d}`, target.ID), w.Body.String())
+
+		var moved models.Subscription
+		assert.NoError(t, db.First(&moved, sub.ID).Error)
+		assert.Equal(t, target.ID, moved.CategoryID)
+
+		var count int64
+		db.Model(&models.Category{}).Where("id = ?", source.ID).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("only empty categories delete", func(t *testing.T) {
+		w := del(fmt.Sprintf("/api/categories/%d", target.ID))
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		empty := models.Category{Name: "Empty"}
+		assert.NoError(t, db.Create(&empty).Error)
+		w = del(fmt.Sprintf("/api/categories/%d", empty.ID))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetExchangeRate returns the rate SubTrackr uses to convert between the from
+// and to currencies, and when that rate was published, so conversions can be
+// checked by hand. The rate comes from the cache when it's fresh, otherwise
+// from Fixer.io.
+func (h *SubscriptionHandler) GetExchangeRate(c *gin.Context) {
+	from := strings.ToUpper(strings.TrimSpace(c.Query("from")))
+	to := strings.ToUpper(strings.TrimSpace(c.Query("to")))
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Both from and to currencies are required"})
+		return
+	}
+	for _, code := range []string{from, to} {
+		if !service.IsSupportedCurrency(code) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid currency: %s", code)})
+			return
+		}
+	}
+
+	if h.currencyService == nil || !h.currencyService.IsEnabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Currency conversion is disabled - set FIXER_API_KEY to enable it"})
+		return
+	}
+
+	rate, date, err := h.currencyService.GetExchangeRateWithDate(from, to)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":      from,
+		"to":        to,
+		"rate":      rate,
+		"timestamp": date,
+	})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestGetExchangeRate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.ExchangeRate{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	rateRepo := repository.NewExchangeRateRepository(db)
+	published := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	err = rateRepo.SaveRates([]models.ExchangeRate{{BaseCurrency: "USD", Currency: "EUR", Rate: 0.92, Date: published}})
+	assert.NoError(t, err)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		handler := &SubscriptionHandler{currencyService: service.NewCurrencyService(rateRepo)}
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/api/currency/rate", handler.GetExchangeRate)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/currency/rate"+query, nil))
+		return w
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Setenv("FIXER_API_KEY", "")
+		w := get("?from=USD&to=EUR")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("Cached rate", func(t *testing.T) {
+		t.Setenv("FIXER_API_KEY", "test-key")
+		w := get("?from=usd&to=EUR")
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var body struct {
+			From      string    `json:"from"`
+			To        string    `json:"to"`
+			Rate      float64   `json:"rate"`
+			Timestamp time.Time `json:"timestamp"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "USD", body.From)
+		assert.Equal(t, "EUR", body.To)
+		assert.Equal(t, 0.92, body.Rate)
+		assert.True(t, published.Equal(body.Timestamp), "Expected the cached rate's date, got %v", body.Timestamp)
+	})
+
+	t.Run("Invalid currencies", func(t *testing.T) {
+		t.Setenv("FIXER_API_KEY", "test-key")
+		assert.Equal(t, http.StatusBadRequest, get("?from=USD").Code)
+		assert.Equal(t, http.StatusBadRequest, get("?from=USD&to=XYZ").Code)
+	})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"subtrackr/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DateMigrationHandler exposes the V1 to V2 date calculation migration tooling
+type DateMigrationHandler struct {
+	checker *models.DateMigrationSafetyCheck
+}
+
+func NewDateMigrationHandler(checker *models.DateMigrationSafetyCheck) *DateMigrationHandler {
+	return &DateMigrationHandler{checker: checker}
+}
+
+// GetStats returns how many subscriptions use each date calculation version
+func (h *DateMigrationHandler) GetStats(c *gin.Context) {
+	stats, err := h.checker.GetMigrationStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// Compare returns the V1 and V2 renewal date of every subscription without changing data
+func (h *DateMigrationHandler) Compare(c *gin.Context) {
+	comparisons, err := h.checker.CompareAllCalculationVersions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscriptions": comparisons,
+		"count":         len(comparisons),
+	})
+}
+
+// Migrate moves every V1 subscription to V2. With dry_run=true it only records
+// significant differences; a real run must be confirmed with confirm=true.
+func (h *DateMigrationHandler) Migrate(c *gin.Context) {
+	dryRun := c.PostForm("dry_run") == "true"
+	if !dryRun && c.PostForm("confirm") != "true" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Set confirm=true to migrate, or dry_run=true to preview"})
+		return
+	}
+
+	if err := h.checker.BatchMigrateToV2WithAudit(dryRun); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := h.checker.GetMigrationStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run": dryRun,
+		"stats":   stats,
+	})
+}
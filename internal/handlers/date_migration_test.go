@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"subtrackr/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupDateMigrationTest(t *testing.T) (*gin.Engine, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Category{}, &models.Subscription{}, &models.DateMigrationLog{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	handler := NewDateMigrationHandler(models.NewDateMigrationSafetyCheck(db))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/settings/date-migration/stats", handler.GetStats)
+	router.GET("/api/settings/date-migration/compare", handler.Compare)
+	router.POST("/api/settings/date-migration/migrate", handler.Migrate)
+	return router, db
+}
+
+func postMigrate(router *gin.Engine, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/settings/date-migration/migrate", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func countVersion(db *gorm.DB, version int) int64 {
+	var count int64
+	db.Model(&models.Subscription{}).Where("date_calculation_version = ?", version).Count(&count)
+	return count
+}
+
+func TestDateMigrationHandler(t *testing.T) {
+	router, db := setupDateMigrationTest(t)
+
+	start := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	for _, name := range []string{"Monthly", "Other"} {
+		sub := models.Subscription{Name: name, Cost: 10, Schedule: "Monthly", Status: "Active", StartDate: &start, DateCalculationVersion: 1}
+		assert.NoError(t, db.Create(&sub).Error)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/settings/date-migration/compare", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var compare struct {
+		Subscriptions []models.VersionComparison `json:"subscriptions"`
+		Count         int                        `json:"count"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &compare))
+	assert.Equal(t, 2, compare.Count)
+	assert.Equal(t, "Monthly", compare.Subscriptions[0].Name)
+	assert.Equal(t, 1, compare.Subscriptions[0].CurrentVersion)
+	assert.NotNil(t, compare.Subscriptions[0].V1Date)
+	assert.NotNil(t, compare.Subscriptions[0].V2Date)
+
+	t.Run("Requires confirmation", func(t *testing.T) {
+		w := postMigrate(router, url.Values{})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, int64(2), countVersion(db, 1))
+	})
+
+	t.Run("Dry run changes nothing", func(t *testing.T) {
+		w := postMigrate(router, url.Values{"dry_run": {"true"}})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(2), countVersion(db, 1))
+	})
+
+	t.Run("Confirmed migration", func(t *testing.T) {
+		w := postMigrate(router, url.Values{"confirm": {"true"}})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(0), countVersion(db, 1))
+		assert.Equal(t, int64(2), countVersion(db, 2))
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/settings/date-migration/stats", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		var stats map[string]any
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+		assert.Equal(t, float64(2), stats["v2_subscriptions"])
+		assert.Equal(t, float64(0), stats["v1_subscriptions"])
+	})
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HealthHandler serves the liveness and readiness checks and tracks the
+// background state they report
+type HealthHandler struct {
+	db              *gorm.DB
+	currencyService *service.CurrencyService
+	migrated        atomic.Bool
+	lastReminderRun atomic.Int64 // Unix seconds, 0 until the scheduler first runs
+}
+
+func NewHealthHandler(db *gorm.DB) *HealthHandler {
+	return &HealthHandler{db: db}
+}
+
+// SetCurrencyService sets the currency service whose status is reported
+func (h *HealthHandler) SetCurrencyService(currencyService *service.CurrencyService) {
+	h.currencyService = currencyService
+}
+
+// MarkMigrated records that database migrations have completed
+func (h *HealthHandler) MarkMigrated() {
+	h.migrated.Store(true)
+}
+
+// RecordReminderRun records when the renewal reminder scheduler last ran
+func (h *HealthHandler) RecordReminderRun(t time.Time) {
+	h.lastReminderRun.Store(t.Unix())
+}
+
+// pingDB returns an error message when the database can't be reached, or "" when it can
+func (h *HealthHandler) pingDB() string {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return "database connection unavailable"
+	}
+	if err := sqlDB.Ping(); err != nil {
+		return "database ping failed"
+	}
+	return ""
+}
+
+// Healthz reports overall health along with the status of each component.
+// Only a database failure makes the service unhealthy.
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	status, code := "healthy", http.StatusOK
+	database := gin.H{"status": "healthy"}
+	if errMsg := h.pingDB(); errMsg != "" {
+		status, code = "unhealthy", http.StatusServiceUnavailable
+		database = gin.H{"status": "unhealthy", "error": errMsg}
+	}
+
+	migrations := "pending"
+	if h.migrated.Load() {
+		migrations = "complete"
+	}
+
+	var lastRun *time.Time
+	if unix := h.lastReminderRun.Load(); unix > 0 {
+		t := time.Unix(unix, 0).UTC()
+		lastRun = &t
+	}
+
+	c.JSON(code, gin.H{
+		"status": status,
+		"components": gin.H{
+			"database":           database,
+			"migrations":         gin.H{"status": migrations},
+			"currency":           gin.H{"enabled": h.currencyService != nil && h.currencyService.IsEnabled()},
+			"reminder_scheduler": gin.H{"last_run": lastRun},
+		},
+	})
+}
+
+// Readyz reports ready once migrations have finished and the database is reachable
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	if !h.migrated.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "error": "migrations have not completed"})
+		return
+	}
+	if errMsg := h.pingDB(); errMsg != "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "error": errMsg})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupHealthTest(t *testing.T) (*gin.Engine, *HealthHandler, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+
+	handler := NewHealthHandler(db)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/healthz", handler.Healthz)
+	router.GET("/readyz", handler.Readyz)
+	return router, handler, db
+}
+
+func getJSON(t *testing.T, router *gin.Engine, path string) (int, map[string]interface{}) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func TestReadyz(t *testing.T) {
+	router, handler, _ := setupHealthTest(t)
+
+	code, body := getJSON(t, router, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not ready", body["status"])
+
+	handler.MarkMigrated()
+	code, body = getJSON(t, router, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", body["status"])
+}
+
+func TestHealthz(t *testing.T) {
+	router, handler, db := setupHealthTest(t)
+
+	t.Run("Reports component status", func(t *testing.T) {
+		code, body := getJSON(t, router, "/healthz")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "healthy", body["status"])
+
+		components := body["components"].(map[string]interface{})
+		assert.Equal(t, "pending", components["migrations"].(map[string]interface{})["status"])
+		assert.Equal(t, false, components["currency"].(map[string]interface{})["enabled"])
+		assert.Nil(t, components["reminder_scheduler"].(map[string]interface{})["last_run"])
+	})
+
+	t.Run("Includes migration and scheduler progress", func(t *testing.T) {
+		handler.MarkMigrated()
+		handler.RecordReminderRun(time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC))
+
+		_, body := getJSON(t, router, "/healthz")
+		components := body["components"].(map[string]interface{})
+		assert.Equal(t, "complete", components["migrations"].(map[string]interface{})["status"])
+		assert.Equal(t, "2026-03-01T09:30:00Z", components["reminder_scheduler"].(map[string]interface{})["last_run"])
+	})
+
+	t.Run("Unhealthy when the database is closed", func(t *testing.T) {
+		sqlDB, err := db.DB()
+		assert.NoError(t, err)
+		assert.NoError(t, sqlDB.Close())
+
+		code, body := getJSON(t, router, "/healthz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "unhealthy", body["status"])
+		database := body["components"].(map[string]interface{})["database"].(map[string]interface{})
+		assert.Equal(t, "database ping failed", database["error"])
+
+		code, _ = getJSON(t, router, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+	})
+}
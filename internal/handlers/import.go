@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"subtrackr/internal/models"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportSubscriptions creates subscriptions from another tracker's JSON export,
+// translated by the mapper named in the :format path parameter. Rows that can't
+// be mapped are reported under "unmapped" and skipped; a database error rolls
+// back the whole import. Categories are matched by name and created if missing.
+func (h *SubscriptionHandler) ImportSubscriptions(c *gin.Context) {
+	format := strings.ToLower(c.Param("format"))
+	mapper, ok := service.ImportMapperFor(format)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown import format %q (supported: %s)", format, strings.Join(service.ImportFormats(), ", "))})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	rows, err := mapper(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No subscriptions found in the export"})
+		return
+	}
+	if len(rows) > maxBulkOperations {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Too many subscriptions (max %d)", maxBulkOperations)})
+		return
+	}
+
+	unmapped := make([]service.ImportRow, 0)
+	var mapped []service.ImportRow
+	for _, row := range rows {
+		if row.Subscription == nil {
+			unmapped = append(unmapped, row)
+			continue
+		}
+		mapped = append(mapped, row)
+	}
+
+	created := make([]*models.Subscription, 0, len(mapped))
+	err = h.service.Transaction(func(tx *service.SubscriptionService) error {
+		categoryIDs := make(map[string]uint)
+		for _, row := range mapped {
+			if row.Category != "" {
+				id, err := importCategoryID(tx, row.Category, categoryIDs)
+				if err != nil {
+					return err
+				}
+				row.Subscription.CategoryID = id
+			}
+			saved, err := tx.Create(row.Subscription)
+			if err != nil {
+				return err
+			}
+			created = append(created, saved)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Import failed, no subscriptions were saved: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"format":        format,
+		"imported":      len(created),
+		"subscriptions": created,
+		"unmapped":      unmapped,
+	})
+}
+
+// importCategoryID returns the ID of the category with name, creating it in
+// tx if it doesn't exist yet. cache remembers names already resolved in this import.
+func importCategoryID(tx *service.SubscriptionService, name string, cache map[string]uint) (uint, error) {
+	if id, ok := cache[name]; ok {
+		return id, nil
+	}
+
+	category, err := tx.FindOrCreateCategory(name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve category %q: %w", name, err)
+	}
+
+	cache[name] = category.ID
+	return category.ID, nil
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupImportTest(t *testing.T) (*gin.Engine, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Category{}, &models.Subscription{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	subscriptionService := service.NewSubscriptionService(repository.NewSubscriptionRepository(db), nil)
+	handler := &SubscriptionHandler{service: subscriptionService}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/import/:format", handler.ImportSubscriptions)
+	return router, db
+}
+
+func TestImportSubscriptions(t *testing.T) {
+	router, db := setupImportTest(t)
+	db.Create(&models.Category{Name: "Entertainment"})
+
+	payload := `[
+		{"Name": "Netflix", "Payment Cycle": "Monthly", "Next Payment": "2025-07-15", "Category": "Entertainment", "Price": "$15.99"},
+		{"Name": "iCloud", "Payment Cycle": "Monthly", "Category": "Storage", "Price": "€2.99"},
+		{"Name": "Dropbox", "Payment Cycle": "Yearly", "Category": "Storage", "Price": "€119.88"},
+		{"Name": "Gym", "Payment Cycle": "Fortnightly", "Price": "$30"}
+	]`
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/import/wallos", strings.NewReader(payload)))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var body struct {
+		Imported int `json:"imported"`
+		Unmapped []struct {
+			Index int    `json:"index"`
+			Name  string `json:"name"`
+			Error string `json:"error"`
+		} `json:"unmapped"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 3, body.Imported)
+	if assert.Len(t, body.Unmapped, 1) {
+		assert.Equal(t, 3, body.Unmapped[0].Index)
+		assert.Equal(t, "Gym", body.Unmapped[0].Name)
+		assert.Contains(t, body.Unmapped[0].Error, "billing cycle")
+	}
+
+	var categories []models.Category
+	db.Order("id").Find(&categories)
+	assert.Len(t, categories, 2, "existing categories are reused and missing ones created once")
+
+	var subscriptions []models.Subscription
+	db.Order("id").Find(&subscriptions)
+	if assert.Len(t, subscriptions, 3) {
+		assert.Equal(t, categories[0].ID, subscriptions[0].CategoryID)
+		assert.Equal(t, categories[1].ID, subscriptions[1].CategoryID)
+		assert.Equal(t, categories[1].ID, subscriptions[2].CategoryID)
+		assert.Equal(t, "EUR", subscriptions[2].OriginalCurrency)
+	}
+}
+
+func TestImportSubscriptions_Generic(t *testing.T) {
+	router, db := setupImportTest(t)
+
+	payload := `[{"name": "Spotify", "price": 9.99, "cycle": "monthly", "currency": "GBP"}]`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/import/generic", strings.NewReader(payload)))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var subscription models.Subscription
+	assert.NoError(t, db.First(&subscription).Error)
+	assert.Equal(t, "Spotify", subscription.Name)
+	assert.Equal(t, "GBP", subscription.OriginalCurrency)
+	assert.Equal(t, uint(0), subscription.CategoryID)
+}
+
+func TestImportSubscriptions_RollsBackCategories(t *testing.T) {
+	router, db := setupImportTest(t)
+	// Fail the second insert, after the first row has created its category
+	assert.NoError(t, db.Exec(`CREATE TRIGGER fail_import BEFORE INSERT ON subscriptions
+		WHEN NEW.name = 'Dropbox' BEGIN SELECT RAISE(ABORT, 'disk full'); END`).Error)
+
+	payload := `[
+		{"name": "iCloud", "price": 2.99, "cycle": "monthly", "category": "Storage"},
+		{"name": "Dropbox", "price": 119.88, "cycle": "yearly", "category": "Backup"}
+	]`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/import/generic", strings.NewReader(payload)))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var subscriptions, categories int64
+	db.Model(&models.Subscription{}).Count(&subscriptions)
+	db.Model(&models.Category{}).Count(&categories)
+	assert.Zero(t, subscriptions)
+	assert.Zero(t, categories, "categories created by a failed import are rolled back")
+}
+
+func TestImportSubscriptions_BadRequests(t *testing.T) {
+	router, _ := setupImportTest(t)
+
+	tests := []struct {
+		name    string
+		path    string
+		payload string
+		wantErr string
+	}{
+		{"Unknown format", "/api/import/bobby", `[]`, "supported: generic, wallos"},
+		{"Not JSON", "/api/import/generic", `name,cost`, "expected a JSON array"},
+		{"Empty export", "/api/import/wallos", `[]`, "No subscriptions found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", tt.path, strings.NewReader(tt.payload)))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantErr)
+		})
+	}
+}
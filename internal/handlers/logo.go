@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadLogo stores a PNG or JPEG uploaded as the "logo" form file and makes it the subscription's icon
+func (h *SubscriptionHandler) UploadLogo(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	existing, err := h.service.GetByID(uint(id))
+	if err != nil || existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+		return
+	}
+
+	// Leave room for the multipart envelope around the image
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxLogoUploadSize+64<<10)
+
+	file, header, err := c.Request.FormFile("logo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No logo file provided or file too large"})
+		return
+	}
+	defer file.Close()
+
+	if contentType := header.Header.Get("Content-Type"); contentType != "image/png" && contentType != "image/jpeg" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.ErrUnsupportedLogoType.Error()})
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, service.MaxLogoUploadSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read logo file"})
+		return
+	}
+
+	iconURL, err := h.logoService.SaveUploadedLogo(data)
+	if errors.Is(err, service.ErrLogoTooLarge) || errors.Is(err, service.ErrUnsupportedLogoType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous := existing.IconURL
+	existing.IconURL = iconURL
+	updated, err := h.service.Update(uint(id), existing)
+	if err != nil {
+		h.logoService.RemoveUploadedLogo(iconURL)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.logoService.RemoveUploadedLogo(previous); err != nil {
+		slog.Warn("Failed to remove old logo", "subscription_id", id, "error", err)
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// RemoveLogo clears a subscription's icon, deleting it if it was uploaded
+func (h *SubscriptionHandler) RemoveLogo(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	existing, err := h.service.GetByID(uint(id))
+	if err != nil || existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+		return
+	}
+
+	previous := existing.IconURL
+	existing.IconURL = ""
+	updated, err := h.service.Update(uint(id), existing)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.logoService.RemoveUploadedLogo(previous); err != nil {
+		slog.Warn("Failed to remove logo", "subscription_id", id, "error", err)
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// ProxyLogo serves the favicon for the domain query parameter, fetched server-side
+// so pages don't load images from third-party favicon services
+func (h *SubscriptionHandler) ProxyLogo(c *gin.Context) {
+	logo, err := h.logoService.GetProxiedLogo(c.Query("domain"))
+	if errors.Is(err, service.ErrInvalidLogoDomain) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain"})
+		return
+	} else if err != nil {
+		c.Header("Cache-Control", "public, max-age=3600")
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, logo.ContentType, logo.Data)
+}
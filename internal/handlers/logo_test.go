@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var testPNG = append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+
+func setupLogoTest(t *testing.T) (*gin.Engine, *service.SubscriptionService, string) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Category{}, &models.Subscription{}, &models.Settings{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	categoryService := service.NewCategoryService(repository.NewCategoryRepository(db))
+	subscriptionService := service.NewSubscriptionService(repository.NewSubscriptionRepository(db), categoryService)
+	settingsService := service.NewSettingsService(repository.NewSettingsRepository(db))
+	logoService := service.NewLogoService()
+	cacheDir := t.TempDir()
+	logoService.SetCacheDir(cacheDir)
+
+	handler := NewSubscriptionHandler(subscriptionService, settingsService, nil, nil, logoService, categoryService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/subscriptions/:id/logo", handler.UploadLogo)
+	router.DELETE("/api/subscriptions/:id/logo", handler.RemoveLogo)
+	return router, subscriptionService, cacheDir
+}
+
+func postLogo(router *gin.Engine, id, contentType string, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	partHeader := textproto.MIMEHeader{}
+	partHeader.Set("Content-Disposition", `form-data; name="logo"; filename="logo"`)
+	partHeader.Set("Content-Type", contentType)
+	part, _ := writer.CreatePart(partHeader)
+	part.Write(data)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/subscriptions/"+id+"/logo", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUploadLogo(t *testing.T) {
+	router, subscriptionService, cacheDir := setupLogoTest(t)
+	sub, err := subscriptionService.Create(&models.Subscription{Name: "Local Gym", Cost: 30, Schedule: "Monthly", Status: "Active"})
+	assert.NoError(t, err)
+	id := strconv.Itoa(int(sub.ID))
+
+	t.Run("Rejects bad uploads", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			contentType string
+			data        []byte
+		}{
+			{"Non-image content type", "text/plain", []byte("hello")},
+			{"Image type with non-image data", "image/png", []byte("<html></html>")},
+			{"GIF is not accepted", "image/gif", []byte("GIF89a......")},
+			{"Too large", "image/png", append(append([]byte{}, testPNG...), make([]byte, service.MaxLogoUploadSize)...)},
+		}
+		for _, tt := range tests {
+			w := postLogo(router, id, tt.contentType, tt.data)
+			assert.Equal(t, http.StatusBadRequest, w.Code, tt.name)
+		}
+		entries, _ := os.ReadDir(cacheDir)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Unknown subscription", func(t *testing.T) {
+		w := postLogo(router, "9999", "image/png", testPNG)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	var iconURL string
+	t.Run("Stores a PNG and sets the icon", func(t *testing.T) {
+		w := postLogo(router, id, "image/png", testPNG)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var updated models.Subscription
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+		iconURL = updated.IconURL
+		assert.True(t, strings.HasPrefix(iconURL, service.LogoCachePath+"/"))
+
+		data, err := os.ReadFile(filepath.Join(cacheDir, filepath.Base(iconURL)))
+		assert.NoError(t, err)
+		assert.Equal(t, testPNG, data)
+	})
+
+	t.Run("Remove clears the icon and deletes the file", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/subscriptions/"+id+"/logo", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		stored, err := subscriptionService.GetByID(sub.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, stored.IconURL)
+		_, err = os.Stat(filepath.Join(cacheDir, filepath.Base(iconURL)))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestProxyLogo_RejectsInternalHosts(t *testing.T) {
+	router, _, _ := setupLogoTest(t)
+	router.GET("/logo", NewSubscriptionHandler(nil, nil, nil, nil, service.NewLogoService(), nil).ProxyLogo)
+
+	for _, domain := range []string{"", "localhost", "127.0.0.1", "169.254.169.254", "nas.local"} {
+		req := httptest.NewRequest("GET", "/logo?domain="+domain, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, domain)
+	}
+}
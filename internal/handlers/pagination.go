@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPageSize caps how many subscriptions a single page may return
+const maxPageSize = 500
+
+// pagination holds the resolved limit/offset for a list request.
+// Enabled is false when the client did not ask for pagination, in which
+// case the full list should be returned as before.
+type pagination struct {
+	Enabled bool
+	Limit   int
+	Offset  int
+}
+
+// parsePagination reads either limit/offset or page/page_size query params.
+// limit/offset take precedence when both styles are supplied.
+// Invalid or missing values fall back to an unpaginated request.
+func parsePagination(c *gin.Context) pagination {
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return pagination{}
+		}
+		offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+		if err != nil || offset < 0 {
+			offset = 0
+		}
+		return pagination{Enabled: true, Limit: clampPageSize(limit), Offset: offset}
+	}
+
+	pageStr := c.Query("page")
+	pageSizeStr := c.Query("page_size")
+	if pageStr == "" && pageSizeStr == "" {
+		return pagination{}
+	}
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = 25
+	}
+	pageSize = clampPageSize(pageSize)
+
+	return pagination{Enabled: true, Limit: pageSize, Offset: (page - 1) * pageSize}
+}
+
+func clampPageSize(size int) int {
+	if size > maxPageSize {
+		return maxPageSize
+	}
+	return size
+}
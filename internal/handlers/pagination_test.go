@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected pagination
+	}{
+		{
+			name:     "No params returns full list",
+			query:    "",
+			expected: pagination{},
+		},
+		{
+			name:     "Limit and offset",
+			query:    "limit=10&offset=20",
+			expected: pagination{Enabled: true, Limit: 10, Offset: 20},
+		},
+		{
+			name:     "Limit without offset",
+			query:    "limit=5",
+			expected: pagination{Enabled: true, Limit: 5, Offset: 0},
+		},
+		{
+			name:     "Negative offset is reset to zero",
+			query:    "limit=5&offset=-3",
+			expected: pagination{Enabled: true, Limit: 5, Offset: 0},
+		},
+		{
+			name:     "Invalid limit falls back to full list",
+			query:    "limit=abc",
+			expected: pagination{},
+		},
+		{
+			name:     "Zero limit falls back to full list",
+			query:    "limit=0",
+			expected: pagination{},
+		},
+		{
+			name:     "Limit is capped",
+			query:    "limit=10000",
+			expected: pagination{Enabled: true, Limit: maxPageSize, Offset: 0},
+		},
+		{
+			name:     "Page and page size",
+			query:    "page=3&page_size=10",
+			expected: pagination{Enabled: true, Limit: 10, Offset: 20},
+		},
+		{
+			name:     "Page without page size uses default size",
+			query:    "page=2",
+			expected: pagination{Enabled: true, Limit: 25, Offset: 25},
+		},
+		{
+			name:     "Invalid page defaults to first page",
+			query:    "page=0&page_size=10",
+			expected: pagination{Enabled: true, Limit: 10, Offset: 0},
+		},
+		{
+			name:     "Limit takes precedence over page",
+			query:    "limit=7&offset=1&page=4&page_size=50",
+			expected: pagination{Enabled: true, Limit: 7, Offset: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/api/v1/subscriptions?"+tt.query, nil)
+
+			assert.Equal(t, tt.expected, parsePagination(c))
+		})
+	}
+}
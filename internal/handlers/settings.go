@@ -5,7 +5,7 @@ import (
 	"crypto/tls"
 	"encoding/hex"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/smtp"
 	"strconv"
@@ -22,13 +22,19 @@ func trimSpace(s string) string    { return strings.TrimSpace(s) }
 func splitN(s, sep string, n int) []string { return strings.SplitN(s, sep, n) }
 
 type SettingsHandler struct {
-	service *service.SettingsService
+	service             *service.SettingsService
+	notificationService *service.NotificationService
 }
 
 func NewSettingsHandler(service *service.SettingsService) *SettingsHandler {
 	return &SettingsHandler{service: service}
 }
 
+// SetNotificationService gives the handler access to the notification history
+func (h *SettingsHandler) SetNotificationService(notificationService *service.NotificationService) {
+	h.notificationService = notificationService
+}
+
 // SaveSMTPSettings saves SMTP configuration
 func (h *SettingsHandler) SaveSMTPSettings(c *gin.Context) {
 	var config models.SMTPConfig
@@ -216,7 +222,7 @@ func (h *SettingsHandler) UpdateNotificationSetting(c *gin.Context) {
 
 	case "threshold":
 		thresholdStr := c.PostForm("high_cost_threshold")
-		if threshold, err := strconv.ParseFloat(thresholdStr, 64); err == nil && threshold >= 0 && threshold <= 10000 {
+		if threshold, err := strconv.ParseFloat(thresholdStr, 64); err == nil && threshold >= 0 && threshold <= 100000 {
 			err := h.service.SetFloatSetting("high_cost_threshold", threshold)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -224,8 +230,16 @@ func (h *SettingsHandler) UpdateNotificationSetting(c *gin.Context) {
 			}
 			c.JSON(http.StatusOK, gin.H{"threshold": threshold})
 		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid threshold value (must be between 0 and 10000)"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid threshold value (must be between 0 and 100000)"})
+		}
+
+	case "basis":
+		basis := c.PostForm("high_cost_basis")
+		if err := h.service.SetHighCostBasis(basis); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
+		c.JSON(http.StatusOK, gin.H{"basis": basis})
 
 	case "cancellation":
 		current, _ := h.service.GetBoolSetting("cancellation_reminders", false)
@@ -249,6 +263,32 @@ func (h *SettingsHandler) UpdateNotificationSetting(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days value"})
 		}
 
+	case "quiet_hours":
+		start := strings.TrimSpace(c.PostForm("quiet_hours_start"))
+		end := strings.TrimSpace(c.PostForm("quiet_hours_end"))
+		if err := h.service.SetQuietHours(start, end); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"start": start, "end": end})
+
+	case "send_hour":
+		hour := strings.TrimSpace(c.PostForm("reminder_send_hour"))
+		if err := h.service.SetReminderSendHour(hour); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"hour": hour})
+
+	case service.NotificationChannelEmail, service.NotificationChannelPushover, service.NotificationChannelWebhook, service.NotificationChannelApprise:
+		current := h.service.IsNotificationChannelEnabled(setting)
+		err := h.service.SetNotificationChannelEnabled(setting, !current)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"enabled": !current})
+
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown setting"})
 	}
@@ -259,15 +299,52 @@ func (h *SettingsHandler) GetNotificationSettings(c *gin.Context) {
 	settings := models.NotificationSettings{
 		RenewalReminders:         h.service.GetBoolSettingWithDefault("renewal_reminders", false),
 		HighCostAlerts:           h.service.GetBoolSettingWithDefault("high_cost_alerts", true),
-		HighCostThreshold:        h.service.GetFloatSettingWithDefault("high_cost_threshold", 50.0),
+		HighCostThreshold:        h.service.GetHighCostThreshold(),
+		HighCostBasis:            h.service.GetHighCostBasis(),
 		ReminderDays:             h.service.GetIntSettingWithDefault("reminder_days", 7),
 		CancellationReminders:    h.service.GetBoolSettingWithDefault("cancellation_reminders", false),
 		CancellationReminderDays: h.service.GetIntSettingWithDefault("cancellation_reminder_days", 7),
+		NotifyEmail:              h.service.IsNotificationChannelEnabled(service.NotificationChannelEmail),
+		NotifyPushover:           h.service.IsNotificationChannelEnabled(service.NotificationChannelPushover),
+		NotifyWebhook:            h.service.IsNotificationChannelEnabled(service.NotificationChannelWebhook),
+		NotifyApprise:            h.service.IsNotificationChannelEnabled(service.NotificationChannelApprise),
+	}
+	settings.QuietHoursStart, settings.QuietHoursEnd = h.service.GetQuietHours()
+	if hour, ok := h.service.GetReminderSendHour(); ok {
+		settings.ReminderSendHour = &hour
 	}
 
 	c.JSON(http.StatusOK, settings)
 }
 
+// defaultHistoryLimit is how many notification history entries are returned
+// when the request doesn't ask for a number
+const defaultHistoryLimit = 50
+
+// GetNotificationHistory returns recent notification delivery attempts, newest
+// first. The optional limit query parameter is capped at maxPageSize.
+func (h *SettingsHandler) GetNotificationHistory(c *gin.Context) {
+	limit := defaultHistoryLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		limit = clampPageSize(n)
+	}
+
+	entries := []models.NotificationLog{}
+	if h.notificationService != nil {
+		var err error
+		if entries, err = h.notificationService.GetHistory(limit); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load notification history"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
 // GetSMTPConfig returns current SMTP configuration (without password)
 func (h *SettingsHandler) GetSMTPConfig(c *gin.Context) {
 	config, err := h.service.GetSMTPConfig()
@@ -301,6 +378,11 @@ func (h *SettingsHandler) ListAPIKeys(c *gin.Context) {
 		}
 	}
 
+	if c.GetHeader("HX-Request") == "" {
+		c.JSON(http.StatusOK, keys)
+		return
+	}
+
 	c.HTML(http.StatusOK, "api-keys-list.html", gin.H{
 		"Keys":         keys,
 		"GoDateFormat": h.service.GetGoDateFormat(),
@@ -329,7 +411,7 @@ func (h *SettingsHandler) CreateAPIKey(c *gin.Context) {
 	apiKey := "sk_" + hex.EncodeToString(keyBytes)
 
 	// Save the API key
-	newKey, err := h.service.CreateAPIKey(name, apiKey)
+	newKey, err := h.service.CreateAPIKey(name, apiKey, c.PostForm("scope"))
 	if err != nil {
 		c.HTML(http.StatusInternalServerError, "api-keys-list.html", gin.H{
 			"Error": err.Error(),
@@ -414,6 +496,65 @@ func (h *SettingsHandler) UpdateDateFormat(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"date_format": format})
 }
 
+// UpdateWeekStart updates the first day of the week shown in the calendar
+func (h *SettingsHandler) UpdateWeekStart(c *gin.Context) {
+	day := c.PostForm("week_start")
+
+	if err := h.service.SetWeekStart(day); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"week_start": day})
+}
+
+// UpdateCostBasis sets whether monthly costs use the average or the current calendar month
+func (h *SettingsHandler) UpdateCostBasis(c *gin.Context) {
+	basis := c.PostForm("cost_basis")
+
+	if err := h.service.SetCostBasis(basis); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("HX-Refresh", "true")
+	c.JSON(http.StatusOK, gin.H{"cost_basis": basis})
+}
+
+// UpdateNumberFormat updates the number format and currency symbol position; either may be omitted
+func (h *SettingsHandler) UpdateNumberFormat(c *gin.Context) {
+	if format, ok := c.GetPostForm("number_format"); ok {
+		if err := h.service.SetNumberFormat(format); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if position, ok := c.GetPostForm("symbol_position"); ok {
+		if err := h.service.SetSymbolPosition(position); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"number_format":   h.service.GetNumberFormat(),
+		"symbol_position": h.service.GetSymbolPosition(),
+		"example":         h.service.FormatMoney(1234.56),
+	})
+}
+
+// UpdateTimezone saves the IANA time zone dates are calculated and displayed in
+func (h *SettingsHandler) UpdateTimezone(c *gin.Context) {
+	timezone := strings.TrimSpace(c.PostForm("timezone"))
+
+	if err := h.service.SetTimezone(timezone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timezone": timezone})
+}
+
 // ToggleDarkMode toggles dark mode preference
 func (h *SettingsHandler) ToggleDarkMode(c *gin.Context) {
 	enabled := c.PostForm("enabled") == "true"
@@ -486,6 +627,59 @@ func (h *SettingsHandler) SetupAuth(c *gin.Context) {
 	})
 }
 
+// ChangePassword updates the admin password after verifying the current one.
+// Unlike the reset flow, this does not require email to be configured.
+func (h *SettingsHandler) ChangePassword(c *gin.Context) {
+	currentPassword := c.PostForm("current_password")
+	newPassword := c.PostForm("new_password")
+	confirmPassword := c.PostForm("confirm_password")
+
+	if currentPassword == "" || newPassword == "" {
+		c.HTML(http.StatusBadRequest, "auth-message.html", gin.H{
+			"Error": "Current and new password are required",
+			"Type":  "error",
+		})
+		return
+	}
+
+	if err := h.service.ValidatePassword(currentPassword); err != nil {
+		c.HTML(http.StatusBadRequest, "auth-message.html", gin.H{
+			"Error": "Current password is incorrect",
+			"Type":  "error",
+		})
+		return
+	}
+
+	if newPassword != confirmPassword {
+		c.HTML(http.StatusBadRequest, "auth-message.html", gin.H{
+			"Error": "Passwords do not match",
+			"Type":  "error",
+		})
+		return
+	}
+
+	if len(newPassword) < 8 {
+		c.HTML(http.StatusBadRequest, "auth-message.html", gin.H{
+			"Error": "Password must be at least 8 characters long",
+			"Type":  "error",
+		})
+		return
+	}
+
+	if err := h.service.SetAuthPassword(newPassword); err != nil {
+		c.HTML(http.StatusInternalServerError, "auth-message.html", gin.H{
+			"Error": err.Error(),
+			"Type":  "error",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "auth-message.html", gin.H{
+		"Message": "Password changed successfully",
+		"Type":    "success",
+	})
+}
+
 // DisableAuth disables authentication
 func (h *SettingsHandler) DisableAuth(c *gin.Context) {
 	err := h.service.DisableAuth()
@@ -514,6 +708,38 @@ func (h *SettingsHandler) GetAuthStatus(c *gin.Context) {
 	})
 }
 
+// UpdateRememberMeDays saves how long "remember me" logins last
+func (h *SettingsHandler) UpdateRememberMeDays(c *gin.Context) {
+	days, err := strconv.Atoi(c.PostForm("remember_me_days"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days value"})
+		return
+	}
+
+	if err := h.service.SetRememberMeDays(days); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"days": days})
+}
+
+// UpdateDateCalculationVersion saves the date calculation version used for new subscriptions
+func (h *SettingsHandler) UpdateDateCalculationVersion(c *gin.Context) {
+	version, err := strconv.Atoi(c.PostForm("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version value"})
+		return
+	}
+
+	if err := h.service.SetDateCalculationVersion(version); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"version": version})
+}
+
 // GetTheme returns the current theme setting
 func (h *SettingsHandler) GetTheme(c *gin.Context) {
 	theme, err := h.service.GetTheme()
@@ -527,13 +753,71 @@ func (h *SettingsHandler) GetTheme(c *gin.Context) {
 	})
 }
 
+// validPushoverDevice reports whether device is a comma-separated list of Pushover
+// device names, each up to 25 letters, digits, underscores or hyphens
+func validPushoverDevice(device string) bool {
+	for _, name := range strings.Split(device, ",") {
+		if name == "" || len(name) > 25 {
+			return false
+		}
+		for _, r := range name {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// pushoverConfigFromForm reads a Pushover configuration from the settings form,
+// returning a problem message if it's invalid
+func pushoverConfigFromForm(c *gin.Context) (*models.PushoverConfig, string) {
+	config := &models.PushoverConfig{
+		UserKey:           c.PostForm("pushover_user_key"),
+		AppToken:          c.PostForm("pushover_app_token"),
+		Device:            trimSpace(c.PostForm("pushover_device")),
+		Sound:             trimSpace(c.PostForm("pushover_sound")),
+		HighPrioritySound: trimSpace(c.PostForm("pushover_high_priority_sound")),
+	}
+
+	if config.Device != "" && !validPushoverDevice(config.Device) {
+		return nil, "Device must be a comma-separated list of Pushover device names"
+	}
+
+	if v := trimSpace(c.PostForm("pushover_emergency_threshold")); v != "" {
+		threshold, err := strconv.ParseFloat(v, 64)
+		if err != nil || threshold < 0 || threshold > 100000 {
+			return nil, "Emergency threshold must be between 0 and 100000"
+		}
+		config.EmergencyThreshold = threshold
+	}
+	if v := trimSpace(c.PostForm("pushover_emergency_retry")); v != "" {
+		retry, err := strconv.Atoi(v)
+		if err != nil || retry < service.PushoverMinRetry {
+			return nil, fmt.Sprintf("Emergency retry must be at least %d seconds", service.PushoverMinRetry)
+		}
+		config.EmergencyRetry = retry
+	}
+	if v := trimSpace(c.PostForm("pushover_emergency_expire")); v != "" {
+		expire, err := strconv.Atoi(v)
+		if err != nil || expire < 1 || expire > service.PushoverMaxExpire {
+			return nil, fmt.Sprintf("Emergency expiry must be between 1 and %d seconds", service.PushoverMaxExpire)
+		}
+		config.EmergencyExpire = expire
+	}
+	return config, ""
+}
+
 // SavePushoverSettings saves Pushover configuration
 func (h *SettingsHandler) SavePushoverSettings(c *gin.Context) {
-	var config models.PushoverConfig
-
-	// Parse form data
-	config.UserKey = c.PostForm("pushover_user_key")
-	config.AppToken = c.PostForm("pushover_app_token")
+	config, problem := pushoverConfigFromForm(c)
+	if problem != "" {
+		c.HTML(http.StatusBadRequest, "smtp-message.html", gin.H{
+			"Error": problem,
+			"Type":  "error",
+		})
+		return
+	}
 
 	// Validate required fields
 	if config.UserKey == "" || config.AppToken == "" {
@@ -545,7 +829,7 @@ func (h *SettingsHandler) SavePushoverSettings(c *gin.Context) {
 	}
 
 	// Save configuration
-	err := h.service.SavePushoverConfig(&config)
+	err := h.service.SavePushoverConfig(config)
 	if err != nil {
 		c.HTML(http.StatusInternalServerError, "smtp-message.html", gin.H{
 			"Error": err.Error(),
@@ -562,56 +846,31 @@ func (h *SettingsHandler) SavePushoverSettings(c *gin.Context) {
 
 // TestPushoverConnection tests Pushover configuration
 func (h *SettingsHandler) TestPushoverConnection(c *gin.Context) {
-	var config models.PushoverConfig
-
-	// Parse form data
-	config.UserKey = c.PostForm("pushover_user_key")
-	config.AppToken = c.PostForm("pushover_app_token")
-
-	// Validate required fields
-	if config.UserKey == "" || config.AppToken == "" {
+	config, problem := pushoverConfigFromForm(c)
+	if problem != "" {
 		c.HTML(http.StatusBadRequest, "smtp-message.html", gin.H{
-			"Error": "User Key and App Token are required for testing",
+			"Error": problem,
 			"Type":  "error",
 		})
 		return
 	}
 
-	// Create a temporary PushoverService to test
-	pushoverService := service.NewPushoverService(h.service)
-
-	// Temporarily save config for testing
-	originalConfig, _ := h.service.GetPushoverConfig()
-	defer func() {
-		var restoreErr error
-		if originalConfig != nil {
-			restoreErr = h.service.SavePushoverConfig(originalConfig)
-		} else {
-			// No original config existed, so delete the test config by saving empty values
-			restoreErr = h.service.SavePushoverConfig(&models.PushoverConfig{
-				UserKey:  "",
-				AppToken: "",
-			})
-		}
-		if restoreErr != nil {
-			log.Printf("Warning: failed to restore Pushover config after test: %v", restoreErr)
-		}
-	}()
-
-	// Save test config
-	if err := h.service.SavePushoverConfig(&config); err != nil {
+	// Validate required fields
+	if config.UserKey == "" || config.AppToken == "" {
 		c.HTML(http.StatusBadRequest, "smtp-message.html", gin.H{
-			"Error": fmt.Sprintf("Failed to save test config: %v", err),
+			"Error": "User Key and App Token are required for testing",
 			"Type":  "error",
 		})
 		return
 	}
 
-	// Send test notification
-	err := pushoverService.SendNotification("SubTrackr Test", "This is a test notification from SubTrackr. If you received this, your Pushover configuration is working correctly!", 0)
+	pushoverService := service.NewPushoverService(h.service)
+
+	// Send test notification with the submitted config, without saving it
+	err := pushoverService.Send(config, "SubTrackr Test", "This is a test notification from SubTrackr. If you received this, your Pushover configuration is working correctly!", 0)
 	if err != nil {
 		c.HTML(http.StatusBadRequest, "smtp-message.html", gin.H{
-			"Error": fmt.Sprintf("Failed to send test notification: %v", err),
+			"Error": fmt.Sprintf("Pushover test failed: %v", err),
 			"Type":  "error",
 		})
 		return
@@ -623,44 +882,56 @@ func (h *SettingsHandler) TestPushoverConnection(c *gin.Context) {
 	})
 }
 
-// SaveWebhookSettings saves Webhook configuration
-func (h *SettingsHandler) SaveWebhookSettings(c *gin.Context) {
-	var config models.WebhookConfig
-	config.URL = c.PostForm("webhook_url")
-
+// webhookConfigFromForm reads webhook settings from the form: the URL, custom
+// headers as "Key: Value" lines, an optional timeout in seconds and whether to
+// skip TLS verification. It returns a message when they're invalid.
+func webhookConfigFromForm(c *gin.Context) (*models.WebhookConfig, string) {
+	config := &models.WebhookConfig{
+		URL:                c.PostForm("webhook_url"),
+		Headers:            make(map[string]string),
+		InsecureSkipVerify: c.PostForm("webhook_insecure_skip_verify") == "true",
+	}
 	if config.URL == "" {
-		c.HTML(http.StatusBadRequest, "smtp-message.html", gin.H{
-			"Error": "Webhook URL is required",
-			"Type":  "error",
-		})
-		return
+		return nil, "Webhook URL is required"
 	}
-
 	// Validate URL scheme to prevent SSRF
 	if !strings.HasPrefix(config.URL, "http://") && !strings.HasPrefix(config.URL, "https://") {
-		c.HTML(http.StatusBadRequest, "smtp-message.html", gin.H{
-			"Error": "Webhook URL must use http:// or https:// scheme",
-			"Type":  "error",
-		})
-		return
+		return nil, "Webhook URL must use http:// or https:// scheme"
 	}
 
-	// Parse headers from textarea (Key: Value format, one per line)
-	headersRaw := c.PostForm("webhook_headers")
-	headers := make(map[string]string)
-	for _, line := range splitLines(headersRaw) {
+	for _, line := range splitLines(c.PostForm("webhook_headers")) {
 		line = trimSpace(line)
 		if line == "" {
 			continue
 		}
 		parts := splitN(line, ":", 2)
 		if len(parts) == 2 {
-			headers[trimSpace(parts[0])] = trimSpace(parts[1])
+			config.Headers[trimSpace(parts[0])] = trimSpace(parts[1])
+		}
+	}
+
+	if timeout := trimSpace(c.PostForm("webhook_timeout")); timeout != "" {
+		seconds, err := strconv.Atoi(timeout)
+		if err != nil || seconds < 1 || seconds > service.MaxWebhookTimeoutSeconds {
+			return nil, fmt.Sprintf("Webhook timeout must be between 1 and %d seconds", service.MaxWebhookTimeoutSeconds)
 		}
+		config.TimeoutSeconds = seconds
 	}
-	config.Headers = headers
+	return config, ""
+}
 
-	err := h.service.SaveWebhookConfig(&config)
+// SaveWebhookSettings saves Webhook configuration
+func (h *SettingsHandler) SaveWebhookSettings(c *gin.Context) {
+	config, problem := webhookConfigFromForm(c)
+	if problem != "" {
+		c.HTML(http.StatusBadRequest, "smtp-message.html", gin.H{
+			"Error": problem,
+			"Type":  "error",
+		})
+		return
+	}
+
+	err := h.service.SaveWebhookConfig(config)
 	if err != nil {
 		c.HTML(http.StatusInternalServerError, "smtp-message.html", gin.H{
 			"Error": err.Error(),
@@ -677,40 +948,15 @@ func (h *SettingsHandler) SaveWebhookSettings(c *gin.Context) {
 
 // TestWebhookConnection tests Webhook configuration
 func (h *SettingsHandler) TestWebhookConnection(c *gin.Context) {
-	webhookURL := c.PostForm("webhook_url")
-	if webhookURL == "" {
-		c.HTML(http.StatusBadRequest, "smtp-message.html", gin.H{
-			"Error": "Webhook URL is required for testing",
-			"Type":  "error",
-		})
-		return
-	}
-
-	// Validate URL scheme to prevent SSRF
-	if !strings.HasPrefix(webhookURL, "http://") && !strings.HasPrefix(webhookURL, "https://") {
+	testConfig, problem := webhookConfigFromForm(c)
+	if problem != "" {
 		c.HTML(http.StatusBadRequest, "smtp-message.html", gin.H{
-			"Error": "Webhook URL must use http:// or https:// scheme",
+			"Error": problem,
 			"Type":  "error",
 		})
 		return
 	}
 
-	// Parse headers
-	headersRaw := c.PostForm("webhook_headers")
-	headers := make(map[string]string)
-	for _, line := range splitLines(headersRaw) {
-		line = trimSpace(line)
-		if line == "" {
-			continue
-		}
-		parts := splitN(line, ":", 2)
-		if len(parts) == 2 {
-			headers[trimSpace(parts[0])] = trimSpace(parts[1])
-		}
-	}
-
-	testConfig := &models.WebhookConfig{URL: webhookURL, Headers: headers}
-
 	// Temporarily save config for testing
 	originalConfig, _ := h.service.GetWebhookConfig()
 	defer func() {
@@ -721,7 +967,7 @@ func (h *SettingsHandler) TestWebhookConnection(c *gin.Context) {
 			restoreErr = h.service.SaveWebhookConfig(&models.WebhookConfig{})
 		}
 		if restoreErr != nil {
-			log.Printf("Warning: failed to restore webhook config after test: %v", restoreErr)
+			slog.Warn("Failed to restore webhook config after test", "error", restoreErr)
 		}
 	}()
 
@@ -756,6 +1002,79 @@ func (h *SettingsHandler) TestWebhookConnection(c *gin.Context) {
 	})
 }
 
+// appriseConfigFromForm reads Apprise settings from the form: one Apprise URL per
+// line and an optional API server URL. It returns a message when they're invalid.
+func appriseConfigFromForm(c *gin.Context) (*models.AppriseConfig, string) {
+	config := &models.AppriseConfig{ServerURL: trimSpace(c.PostForm("apprise_server_url"))}
+	for _, line := range splitLines(c.PostForm("apprise_urls")) {
+		if line = trimSpace(line); line != "" {
+			config.URLs = append(config.URLs, line)
+		}
+	}
+
+	if len(config.URLs) == 0 {
+		return nil, "At least one Apprise URL is required"
+	}
+	// Validate URL scheme to prevent SSRF
+	if config.ServerURL != "" && !strings.HasPrefix(config.ServerURL, "http://") && !strings.HasPrefix(config.ServerURL, "https://") {
+		return nil, "Apprise API server URL must use http:// or https:// scheme"
+	}
+	return config, ""
+}
+
+// SaveAppriseSettings saves Apprise configuration
+func (h *SettingsHandler) SaveAppriseSettings(c *gin.Context) {
+	config, problem := appriseConfigFromForm(c)
+	if problem != "" {
+		c.HTML(http.StatusBadRequest, "smtp-message.html", gin.H{
+			"Error": problem,
+			"Type":  "error",
+		})
+		return
+	}
+
+	if err := h.service.SaveAppriseConfig(config); err != nil {
+		c.HTML(http.StatusInternalServerError, "smtp-message.html", gin.H{
+			"Error": err.Error(),
+			"Type":  "error",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "smtp-message.html", gin.H{
+		"Message": "Apprise settings saved successfully",
+		"Type":    "success",
+	})
+}
+
+// TestAppriseConnection sends a test notification with the submitted Apprise
+// configuration, without saving it
+func (h *SettingsHandler) TestAppriseConnection(c *gin.Context) {
+	config, problem := appriseConfigFromForm(c)
+	if problem != "" {
+		c.HTML(http.StatusBadRequest, "smtp-message.html", gin.H{
+			"Error": problem,
+			"Type":  "error",
+		})
+		return
+	}
+
+	appriseService := service.NewAppriseService(h.service)
+	err := appriseService.Send(config, "SubTrackr Test", "This is a test notification from SubTrackr. If you received this, your Apprise configuration is working correctly!", service.AppriseTypeInfo)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "smtp-message.html", gin.H{
+			"Error": fmt.Sprintf("Apprise test failed: %v", err),
+			"Type":  "error",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "smtp-message.html", gin.H{
+		"Message": "Apprise test successful! Check your services for the test notification.",
+		"Type":    "success",
+	})
+}
+
 // GetPushoverConfig returns current Pushover configuration (without sensitive data)
 func (h *SettingsHandler) GetPushoverConfig(c *gin.Context) {
 	config, err := h.service.GetPushoverConfig()
@@ -766,9 +1085,13 @@ func (h *SettingsHandler) GetPushoverConfig(c *gin.Context) {
 
 	// Don't send the full token, just indicate if configured
 	c.JSON(http.StatusOK, gin.H{
-		"configured":    true,
-		"has_user_key":  config.UserKey != "",
-		"has_app_token": config.AppToken != "",
+		"configured":          true,
+		"has_user_key":        config.UserKey != "",
+		"has_app_token":       config.AppToken != "",
+		"device":              config.Device,
+		"sound":               config.Sound,
+		"high_priority_sound": config.HighPrioritySound,
+		"emergency_threshold": config.EmergencyThreshold,
 	})
 }
 
@@ -798,6 +1121,30 @@ func (h *SettingsHandler) ToggleICalSubscription(c *gin.Context) {
 	})
 }
 
+// ToggleTrialSpend toggles whether trials count towards the spending totals
+func (h *SettingsHandler) ToggleTrialSpend(c *gin.Context) {
+	newState := !h.service.IsTrialSpendIncluded()
+
+	if err := h.service.SetTrialSpendIncluded(newState); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enabled": newState})
+}
+
+// ToggleLogoCache toggles storing fetched logos locally instead of hot-linking them
+func (h *SettingsHandler) ToggleLogoCache(c *gin.Context) {
+	newState := !h.service.IsLogoCacheEnabled()
+
+	if err := h.service.SetLogoCacheEnabled(newState); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enabled": newState})
+}
+
 // GetICalSubscriptionURL returns the current iCal subscription status and URL
 func (h *SettingsHandler) GetICalSubscriptionURL(c *gin.Context) {
 	enabled := h.service.IsICalSubscriptionEnabled()
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupChangePasswordTest(t *testing.T) (*gin.Engine, *service.SettingsService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Settings{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	settingsService := service.NewSettingsService(repository.NewSettingsRepository(db))
+	if err := settingsService.SetupAuth("admin", "old-password"); err != nil {
+		t.Fatalf("Failed to set up auth: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.SetHTMLTemplate(template.Must(template.New("auth-message.html").Parse("{{if .Error}}{{.Error}}{{else}}{{.Message}}{{end}}")))
+	router.POST("/api/settings/password", NewSettingsHandler(settingsService).ChangePassword)
+	return router, settingsService
+}
+
+func postChangePassword(router *gin.Engine, current, newPassword, confirm string) *httptest.ResponseRecorder {
+	form := url.Values{
+		"current_password": {current},
+		"new_password":     {newPassword},
+		"confirm_password": {confirm},
+	}
+	req := httptest.NewRequest("POST", "/api/settings/password", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestChangePassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     string
+		newPassword string
+		confirm     string
+		code        int
+		message     string
+		changed     bool
+	}{
+		{"Wrong current password is rejected", "not-my-password", "new-password", "new-password", http.StatusBadRequest, "Current password is incorrect", false},
+		{"Mismatched confirmation", "old-password", "new-password", "other-password", http.StatusBadRequest, "Passwords do not match", false},
+		{"Too short", "old-password", "short", "short", http.StatusBadRequest, "at least 8 characters", false},
+		{"Missing fields", "", "", "", http.StatusBadRequest, "required", false},
+		{"Valid change", "old-password", "new-password", "new-password", http.StatusOK, "Password changed successfully", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, settingsService := setupChangePasswordTest(t)
+
+			w := postChangePassword(router, tt.current, tt.newPassword, tt.confirm)
+			assert.Equal(t, tt.code, w.Code)
+			assert.Contains(t, w.Body.String(), tt.message)
+
+			if tt.changed {
+				assert.NoError(t, settingsService.ValidatePassword("new-password"))
+				assert.Error(t, settingsService.ValidatePassword("old-password"))
+			} else {
+				assert.NoError(t, settingsService.ValidatePassword("old-password"))
+			}
+		})
+	}
+}
+
+// setupSettingsHandlerTest creates a settings handler backed by an in-memory
+// database that can also hold notification history
+func setupSettingsHandlerTest(t *testing.T) (*SettingsHandler, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Settings{}, &models.NotificationLog{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	return NewSettingsHandler(service.NewSettingsService(repository.NewSettingsRepository(db))), db
+}
+
+func TestAppriseSettings(t *testing.T) {
+	handler, _ := setupSettingsHandlerTest(t)
+
+	notified := 0
+	apprise := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/notify/", r.URL.Path)
+		notified++
+	}))
+	defer apprise.Close()
+
+	router := gin.New()
+	router.SetHTMLTemplate(template.Must(template.New("smtp-message.html").Parse("{{if .Error}}{{.Error}}{{else}}{{.Message}}{{end}}")))
+	router.POST("/api/settings/apprise", handler.SaveAppriseSettings)
+	router.POST("/api/settings/apprise/test", handler.TestAppriseConnection)
+
+	post := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	form := url.Values{
+		"apprise_urls":       {"tgram://bottoken/ChatID\n\n  discord://id/token  \n"},
+		"apprise_server_url": {apprise.URL},
+	}
+
+	t.Run("Test sends without saving", func(t *testing.T) {
+		w := post("/api/settings/apprise/test", form)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, 1, notified)
+
+		_, err := handler.service.GetAppriseConfig()
+		assert.Error(t, err, "testing must not save the configuration")
+	})
+
+	t.Run("Save stores one URL per line", func(t *testing.T) {
+		w := post("/api/settings/apprise", form)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		config, err := handler.service.GetAppriseConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tgram://bottoken/ChatID", "discord://id/token"}, config.URLs)
+		assert.Equal(t, apprise.URL, config.ServerURL)
+	})
+
+	t.Run("Rejects missing URLs and non-HTTP servers", func(t *testing.T) {
+		w := post("/api/settings/apprise", url.Values{"apprise_urls": {" "}})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "At least one Apprise URL")
+
+		w = post("/api/settings/apprise/test", url.Values{"apprise_urls": {"tgram://x"}, "apprise_server_url": {"file:///etc/passwd"}})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "http:// or https://")
+	})
+}
+
+func TestPushoverConnection(t *testing.T) {
+	handler, _ := setupSettingsHandlerTest(t)
+
+	var received url.Values
+	pushover := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		received = r.PostForm
+		if r.PostForm.Get("token") != "good-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":0,"request":"r2","errors":["application token is invalid"]}`))
+			return
+		}
+		w.Write([]byte(`{"status":1,"request":"r1"}`))
+	}))
+	defer pushover.Close()
+	defer func(original string) { service.PushoverAPIURL = original }(service.PushoverAPIURL)
+	service.PushoverAPIURL = pushover.URL
+
+	router := gin.New()
+	router.SetHTMLTemplate(template.Must(template.New("smtp-message.html").Parse("{{if .Error}}{{.Error}}{{else}}{{.Message}}{{end}}")))
+	router.POST("/api/settings/pushover/test", handler.TestPushoverConnection)
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/settings/pushover/test", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Sends a test notification without saving", func(t *testing.T) {
+		w := post(url.Values{"pushover_user_key": {"user-key"}, "pushover_app_token": {"good-token"}})
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "Pushover connection test successful")
+		assert.Equal(t, "user-key", received.Get("user"))
+		assert.Equal(t, "SubTrackr Test", received.Get("title"))
+
+		_, err := handler.service.GetPushoverConfig()
+		assert.Error(t, err, "testing must not save the configuration")
+	})
+
+	t.Run("Surfaces the Pushover API error", func(t *testing.T) {
+		w := post(url.Values{"pushover_user_key": {"user-key"}, "pushover_app_token": {"bad-token"}})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "application token is invalid")
+	})
+
+	t.Run("Requires both keys", func(t *testing.T) {
+		received = nil
+		w := post(url.Values{"pushover_user_key": {"user-key"}})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "User Key and App Token are required")
+		assert.Nil(t, received, "Should not call Pushover without both keys")
+	})
+}
+
+func TestGetNotificationHistory(t *testing.T) {
+	handler, db := setupSettingsHandlerTest(t)
+	history := repository.NewNotificationLogRepository(db)
+	for _, name := range []string{"Netflix", "Spotify", "Hulu"} {
+		assert.NoError(t, history.Create(&models.NotificationLog{Channel: service.NotificationChannelWebhook, Event: service.NotificationEventRenewal, SubscriptionName: name, Success: true}))
+	}
+
+	notificationService := service.NewNotificationService(handler.service)
+	notificationService.SetHistory(history)
+	handler.SetNotificationService(notificationService)
+
+	router := gin.New()
+	router.GET("/api/settings/notifications/history", handler.GetNotificationHistory)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/settings/notifications/history"+query, nil))
+		return w
+	}
+
+	t.Run("Returns the newest entries first", func(t *testing.T) {
+		w := get("?limit=2")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var entries []models.NotificationLog
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+		if assert.Len(t, entries, 2) {
+			assert.Equal(t, "Hulu", entries[0].SubscriptionName)
+			assert.Equal(t, "Spotify", entries[1].SubscriptionName)
+		}
+	})
+
+	t.Run("Rejects an invalid limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("?limit=zero").Code)
+	})
+}
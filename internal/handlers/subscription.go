@@ -3,54 +3,67 @@ package handlers
 import (
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
-	"log"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"subtrackr/internal/middleware"
 	"subtrackr/internal/models"
 	"subtrackr/internal/service"
 	"subtrackr/internal/version"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // SubscriptionWithConversion represents a subscription with currency conversion info
 type SubscriptionWithConversion struct {
 	*models.Subscription
-	ConvertedCost         float64 `json:"converted_cost"`
-	ConvertedAnnualCost   float64 `json:"converted_annual_cost"`
-	ConvertedMonthlyCost  float64 `json:"converted_monthly_cost"`
-	DisplayCurrency       string  `json:"display_currency"`
-	DisplayCurrencySymbol string  `json:"display_currency_symbol"`
-	ShowConversion        bool    `json:"show_conversion"`
+	ConvertedCost         float64    `json:"converted_cost"`
+	ConvertedAnnualCost   float64    `json:"converted_annual_cost"`
+	ConvertedMonthlyCost  float64    `json:"converted_monthly_cost"`
+	ConvertedNextCharge   float64    `json:"converted_next_charge"` // Amount of the next renewal
+	DisplayCurrency       string     `json:"display_currency"`
+	DisplayCurrencySymbol string     `json:"display_currency_symbol"`
+	ShowConversion        bool       `json:"show_conversion"`
+	Stale                 bool       `json:"stale"`               // The conversion used a rate older than models.ExchangeRateMaxAge
+	RateDate              *time.Time `json:"rate_date,omitempty"` // When the conversion's exchange rate was published
 }
 
 type SubscriptionHandler struct {
-	service         *service.SubscriptionService
-	settingsService *service.SettingsService
-	currencyService *service.CurrencyService
-	emailService    *service.EmailService
-	pushoverService *service.PushoverService
-	webhookService  *service.WebhookService
-	logoService     *service.LogoService
-	categoryService *service.CategoryService
+	service             *service.SubscriptionService
+	settingsService     *service.SettingsService
+	currencyService     *service.CurrencyService
+	notificationService *service.NotificationService
+	logoService         *service.LogoService
+	categoryService     *service.CategoryService
+	idempotencyKeys     *service.IdempotencyKeys
 }
 
-func NewSubscriptionHandler(service *service.SubscriptionService, settingsService *service.SettingsService, currencyService *service.CurrencyService, emailService *service.EmailService, pushoverService *service.PushoverService, webhookService *service.WebhookService, logoService *service.LogoService, categoryService *service.CategoryService) *SubscriptionHandler {
+func NewSubscriptionHandler(service *service.SubscriptionService, settingsService *service.SettingsService, currencyService *service.CurrencyService, notificationService *service.NotificationService, logoService *service.LogoService, categoryService *service.CategoryService) *SubscriptionHandler {
 	return &SubscriptionHandler{
-		service:         service,
-		settingsService: settingsService,
-		currencyService: currencyService,
-		emailService:    emailService,
-		pushoverService: pushoverService,
-		webhookService:  webhookService,
-		logoService:     logoService,
-		categoryService: categoryService,
+		service:             service,
+		settingsService:     settingsService,
+		currencyService:     currencyService,
+		notificationService: notificationService,
+		logoService:         logoService,
+		categoryService:     categoryService,
 	}
 }
 
+// SetIdempotencyKeys enables Idempotency-Key handling for subscription creation
+func (h *SubscriptionHandler) SetIdempotencyKeys(idempotencyKeys *service.IdempotencyKeys) {
+	h.idempotencyKeys = idempotencyKeys
+}
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header kept in memory
+const maxIdempotencyKeyLength = 255
+
 // enrichWithCurrencyConversion adds currency conversion info to subscriptions
 func (h *SubscriptionHandler) enrichWithCurrencyConversion(subscriptions []models.Subscription) []SubscriptionWithConversion {
 	displayCurrency := h.settingsService.GetCurrency()
@@ -68,19 +81,22 @@ func (h *SubscriptionHandler) enrichWithCurrencyConversion(subscriptions []model
 			ShowConversion:        false,
 		}
 
-		if h.currencyService.IsEnabled() && sub.OriginalCurrency != "" && sub.OriginalCurrency != displayCurrency {
-			if convertedCost, err := h.currencyService.ConvertAmount(sub.Cost, sub.OriginalCurrency, displayCurrency); err == nil {
-				enriched.ConvertedCost = convertedCost
-				ratio := convertedCost / sub.Cost
-				enriched.ConvertedAnnualCost = sub.AnnualCost() * ratio
-				enriched.ConvertedMonthlyCost = sub.MonthlyCost() * ratio
+		if h.currencyService.IsEnabled() && !sub.DisplayInOriginal && sub.OriginalCurrency != "" && sub.OriginalCurrency != displayCurrency {
+			if rate, rateDate, err := h.currencyService.GetExchangeRateWithDate(sub.OriginalCurrency, displayCurrency); err == nil {
+				enriched.ConvertedCost = sub.Cost * rate
+				enriched.ConvertedAnnualCost = sub.AnnualCost() * rate
+				enriched.ConvertedMonthlyCost = sub.MonthlyCost() * rate
+				enriched.ConvertedNextCharge = sub.NextChargeCost() * rate
+				enriched.RateDate = &rateDate
+				enriched.Stale = models.IsRateStale(rateDate)
 				enriched.ShowConversion = true
 			}
 		} else if sub.OriginalCurrency != "" && sub.OriginalCurrency != displayCurrency {
-			// Different currency but conversion not available - show original currency
+			// Different currency but conversion not available or opted out - show original currency
 			enriched.ConvertedCost = sub.Cost
 			enriched.ConvertedAnnualCost = sub.AnnualCost()
 			enriched.ConvertedMonthlyCost = sub.MonthlyCost()
+			enriched.ConvertedNextCharge = sub.NextChargeCost()
 			enriched.DisplayCurrency = sub.OriginalCurrency
 			enriched.DisplayCurrencySymbol = service.CurrencySymbolForCode(sub.OriginalCurrency)
 		} else {
@@ -88,6 +104,7 @@ func (h *SubscriptionHandler) enrichWithCurrencyConversion(subscriptions []model
 			enriched.ConvertedCost = sub.Cost
 			enriched.ConvertedAnnualCost = sub.AnnualCost()
 			enriched.ConvertedMonthlyCost = sub.MonthlyCost()
+			enriched.ConvertedNextCharge = sub.NextChargeCost()
 		}
 
 		result[i] = enriched
@@ -97,47 +114,58 @@ func (h *SubscriptionHandler) enrichWithCurrencyConversion(subscriptions []model
 }
 
 // isHighCostWithCurrency checks if a subscription is high-cost, respecting currency conversion
-// The threshold is in the user's display currency, so we convert the subscription's monthly cost
-// to the display currency before comparing
+// The threshold is in the user's display currency and applies to the configured basis (monthly or
+// annual), so we convert the subscription's cost on that basis to the display currency before comparing
 func (h *SubscriptionHandler) isHighCostWithCurrency(subscription *models.Subscription) bool {
-	threshold := h.settingsService.GetFloatSettingWithDefault("high_cost_threshold", 50.0)
+	threshold := h.settingsService.GetHighCostThreshold()
+	basis := h.settingsService.GetHighCostBasis()
 	displayCurrency := h.settingsService.GetCurrency()
 
-	// Get monthly cost in subscription's original currency
-	monthlyCost := subscription.MonthlyCost()
+	// If the rate isn't available the unconverted cost is compared
+	cost, _ := h.toDisplayCurrency(subscription.CostForBasis(basis), subscription.OriginalCurrency, displayCurrency)
+	return cost > threshold
+}
 
-	// If currencies match or conversion is disabled, compare directly
-	if subscription.OriginalCurrency == displayCurrency || !h.currencyService.IsEnabled() {
-		return monthlyCost > threshold
+// fetchAndSetLogo fetches a logo for a subscription if URL is provided and icon_url is empty
+// This is a helper method to avoid code duplication between create and update handlers
+func (h *SubscriptionHandler) fetchAndSetLogo(subscription *models.Subscription) {
+	if subscription.URL == "" || subscription.IconURL != "" {
+		return
 	}
 
-	// Convert monthly cost to display currency
-	convertedMonthlyCost, err := h.currencyService.ConvertAmount(monthlyCost, subscription.OriginalCurrency, displayCurrency)
-	if err != nil {
-		// If conversion fails, fall back to direct comparison
-		// Note: This may not be accurate if currencies differ, but prevents silent failures
-		// The warning log helps identify when this fallback is used
-		log.Printf("Warning: Failed to convert currency for high-cost check (%s to %s): %v. Using direct comparison.", subscription.OriginalCurrency, displayCurrency, err)
-		return monthlyCost > threshold
+	// Store the logo on disk when enabled
+	if h.settingsService.IsLogoCacheEnabled() {
+		iconURL, err := h.logoService.FetchAndValidateLogo(subscription.URL)
+		if err == nil {
+			if localURL, err := h.logoService.CacheLogo(iconURL); err == nil {
+				subscription.IconURL = localURL
+				slog.Debug("Cached logo", "url", subscription.URL, "logo", localURL)
+				return
+			}
+			slog.Warn("Failed to cache logo", "logo", iconURL, "error", err)
+		} else {
+			slog.Warn("Failed to fetch logo", "url", subscription.URL, "error", err)
+		}
 	}
 
-	// Compare converted monthly cost against threshold
-	return convertedMonthlyCost > threshold
+	// Otherwise serve it through the logo proxy so pages never hot-link favicon services
+	if proxiedURL := h.logoService.ProxiedLogoURL(subscription.URL); proxiedURL != "" {
+		subscription.IconURL = proxiedURL
+		slog.Debug("Fetched logo", "url", subscription.URL, "logo", proxiedURL)
+	}
 }
 
-// fetchAndSetLogo fetches a logo for a subscription if URL is provided and icon_url is empty
-// This is a helper method to avoid code duplication between create and update handlers
-func (h *SubscriptionHandler) fetchAndSetLogo(subscription *models.Subscription) {
-	if subscription.URL == "" || subscription.IconURL != "" {
+// sendHighCostAlerts notifies every configured channel about a high-cost subscription.
+// Failures are logged but never fail the request.
+func (h *SubscriptionHandler) sendHighCostAlerts(id uint) {
+	// Reload subscription with category for email template
+	subscription, err := h.service.GetByID(id)
+	if err != nil || subscription == nil {
 		return
 	}
 
-	iconURL, err := h.logoService.FetchLogoFromURL(subscription.URL)
-	if err == nil && iconURL != "" {
-		subscription.IconURL = iconURL
-		log.Printf("Fetched logo: %s -> %s", subscription.URL, iconURL)
-	} else if err != nil {
-		log.Printf("Failed to fetch logo for URL %s: %v", subscription.URL, err)
+	if err := h.notificationService.SendHighCostAlert(subscription); err != nil {
+		slog.Warn("Failed to send high-cost alert", "subscription", subscription.Name, "failed", err)
 	}
 }
 
@@ -152,6 +180,20 @@ func parseScheduleInterval(s string) int {
 	return v
 }
 
+// maxSharedWith caps how many people a subscription can be split between
+const maxSharedWith = 100
+
+// parseSharedWith parses the number of people sharing a subscription, including
+// the user. Blank or invalid values mean it is not shared; larger values are
+// capped at maxSharedWith.
+func parseSharedWith(s string) int {
+	v, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || v < 1 {
+		return 1
+	}
+	return min(v, maxSharedWith)
+}
+
 // parseDatePtr parses a date string in "2006-01-02" format and returns a pointer to time.Time.
 // Returns nil if the string is empty or if parsing fails.
 // Logs parsing errors for debugging purposes.
@@ -159,14 +201,41 @@ func parseDatePtr(dateStr string) *time.Time {
 	if dateStr == "" {
 		return nil
 	}
-	if date, err := time.Parse("2006-01-02", dateStr); err == nil {
+	if date, err := time.ParseInLocation("2006-01-02", dateStr, models.Location()); err == nil {
 		return &date
 	}
 	// Log parsing errors for debugging (invalid date format from form)
-	log.Printf("Failed to parse date string '%s': expected format YYYY-MM-DD", dateStr)
+	slog.Debug("Failed to parse date string, expected YYYY-MM-DD", "value", dateStr)
 	return nil
 }
 
+// parseAmountPtr parses an optional amount from a form field.
+// Returns nil if the string is empty or not a number.
+func parseAmountPtr(amountStr string) *float64 {
+	if amountStr == "" {
+		return nil
+	}
+	amount, err := strconv.ParseFloat(amountStr, 64)
+	if err != nil {
+		return nil
+	}
+	return &amount
+}
+
+// parseIntPtr parses an optional whole number from the form field named field.
+// Returns nil if the string is empty, and an error if it isn't a whole number.
+func parseIntPtr(field, numberStr string) (*int, error) {
+	numberStr = strings.TrimSpace(numberStr)
+	if numberStr == "" {
+		return nil, nil
+	}
+	number, err := strconv.Atoi(numberStr)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a whole number", field)
+	}
+	return &number, nil
+}
+
 // Dashboard renders the main dashboard page
 func (h *SubscriptionHandler) Dashboard(c *gin.Context) {
 	stats, err := h.service.GetStats()
@@ -181,6 +250,13 @@ func (h *SubscriptionHandler) Dashboard(c *gin.Context) {
 		return
 	}
 
+	now := time.Now().In(h.settingsService.GetLocation())
+	renewals, err := h.service.GetRenewalsInMonth(now.Year(), int(now.Month()))
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
 	// Enrich with currency conversion
 	enrichedSubs := h.enrichWithCurrencyConversion(subscriptions)
 
@@ -189,6 +265,9 @@ func (h *SubscriptionHandler) Dashboard(c *gin.Context) {
 		"CurrentPage":    "dashboard",
 		"Stats":          stats,
 		"Subscriptions":  enrichedSubs,
+		"MonthRenewals":  h.enrichWithCurrencyConversion(renewals),
+		"CurrentMonth":   now.Format("January 2006"),
+		"GoDateFormat":   h.settingsService.GetGoDateFormat(),
 		"CurrencySymbol": h.settingsService.GetCurrencySymbol(),
 		"DarkMode":       h.settingsService.IsDarkModeEnabled(),
 	})
@@ -196,12 +275,17 @@ func (h *SubscriptionHandler) Dashboard(c *gin.Context) {
 
 // SubscriptionsList renders the subscriptions list page
 func (h *SubscriptionHandler) SubscriptionsList(c *gin.Context) {
-	// Get sort parameters from query string
-	sortBy := c.DefaultQuery("sort", "created_at")
-	order := c.DefaultQuery("order", "desc")
+	// Get sort parameters from query string; a bad link still renders the page
+	sortBy, order, err := parseSort(c)
+	if err != nil {
+		slog.Warn("Using default sort", "error", err)
+		sortBy, order = defaultSortBy, defaultSortOrder
+	}
 
-	// Get sorted subscriptions
-	subscriptions, err := h.service.GetAllSorted(sortBy, order)
+	page := parsePagination(c)
+
+	// Get sorted subscriptions (a single page if pagination was requested)
+	subscriptions, total, err := h.getSortedSubscriptions(sortBy, order, page)
 	if err != nil {
 		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
 		return
@@ -210,15 +294,30 @@ func (h *SubscriptionHandler) SubscriptionsList(c *gin.Context) {
 	// Enrich with currency conversion
 	enrichedSubs := h.enrichWithCurrencyConversion(subscriptions)
 
+	// The account grouping is optional, so the page still renders without it
+	accounts, err := h.service.GetAccountStats()
+	if err != nil {
+		slog.Warn("Failed to group subscriptions by account", "error", err)
+	}
+
 	c.HTML(http.StatusOK, "subscriptions.html", gin.H{
 		"Title":          "Subscriptions",
 		"CurrentPage":    "subscriptions",
 		"Subscriptions":  enrichedSubs,
+		"Accounts":       accounts,
 		"CurrencySymbol": h.settingsService.GetCurrencySymbol(),
 		"DarkMode":       h.settingsService.IsDarkModeEnabled(),
 		"SortBy":         sortBy,
 		"Order":          order,
 		"GoDateFormat":   h.settingsService.GetGoDateFormat(),
+		"Paginated":      page.Enabled,
+		"Limit":          page.Limit,
+		"Offset":         page.Offset,
+		"Total":          total,
+		"HasPrev":        page.Enabled && page.Offset > 0,
+		"HasNext":        page.Enabled && int64(page.Offset+page.Limit) < total,
+		"PrevOffset":     max(page.Offset-page.Limit, 0),
+		"NextOffset":     page.Offset + page.Limit,
 	})
 }
 
@@ -230,10 +329,38 @@ func (h *SubscriptionHandler) Analytics(c *gin.Context) {
 		return
 	}
 
+	trend, err := h.service.GetMonthlyTrend(service.DefaultTrendMonths)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+	var trendMax float64
+	for _, point := range trend {
+		if point.Total > trendMax {
+			trendMax = point.Total
+		}
+	}
+
+	paymentMethods, err := h.service.GetPaymentMethodStats()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	categories, err := h.service.GetCategoryStats()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
 	c.HTML(http.StatusOK, "analytics.html", gin.H{
 		"Title":          "Analytics",
 		"CurrentPage":    "analytics",
 		"Stats":          stats,
+		"Trend":          trend,
+		"TrendMax":       trendMax,
+		"PaymentMethods": paymentMethods,
+		"Categories":     categories,
 		"CurrencySymbol": h.settingsService.GetCurrencySymbol(),
 		"DarkMode":       h.settingsService.IsDarkModeEnabled(),
 	})
@@ -251,20 +378,26 @@ func (h *SubscriptionHandler) Calendar(c *gin.Context) {
 	// Filter subscriptions with renewal dates and group by date
 	// Create a simplified structure for JavaScript
 	type Event struct {
-		Name    string  `json:"name"`
-		Cost    float64 `json:"cost"`
-		ID      uint    `json:"id"`
-		IconURL string  `json:"icon_url"`
+		Name          string  `json:"name"`
+		Cost          float64 `json:"cost"`
+		FormattedCost string  `json:"formatted_cost"` // Cost in the subscription's own currency and the configured number format
+		ID            uint    `json:"id"`
+		IconURL       string  `json:"icon_url"`
 	}
 	eventsByDate := make(map[string][]Event)
 	for _, sub := range subscriptions {
 		if sub.RenewalDate != nil && sub.Status == "Active" {
-			dateKey := sub.RenewalDate.Format("2006-01-02")
+			dateKey := sub.RenewalDate.In(models.Location()).Format("2006-01-02")
+			currency := sub.OriginalCurrency
+			if currency == "" {
+				currency = h.settingsService.GetCurrency()
+			}
 			eventsByDate[dateKey] = append(eventsByDate[dateKey], Event{
-				Name:    sub.Name,
-				Cost:    sub.Cost,
-				ID:      sub.ID,
-				IconURL: sub.IconURL,
+				Name:          sub.Name,
+				Cost:          sub.Cost,
+				FormattedCost: h.settingsService.FormatMoneyIn(sub.Cost, currency),
+				ID:            sub.ID,
+				IconURL:       sub.IconURL,
 			})
 		}
 	}
@@ -301,6 +434,12 @@ func (h *SubscriptionHandler) Calendar(c *gin.Context) {
 	// Serialize events to JSON for JavaScript
 	eventsJSON, _ := json.Marshal(eventsByDate)
 
+	weekStart := h.settingsService.GetWeekStart()
+	weekdays := make([]string, 7)
+	for i := range weekdays {
+		weekdays[i] = time.Weekday((int(weekStart) + i) % 7).String()[:3]
+	}
+
 	// Prevent caching to ensure calendar updates when navigating months
 	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
 	c.Header("Pragma", "no-cache")
@@ -324,6 +463,8 @@ func (h *SubscriptionHandler) Calendar(c *gin.Context) {
 		"MonthName":               firstOfMonth.Format("January 2006"),
 		"EventsByDate":            template.JS(string(eventsJSON)),
 		"FirstOfMonth":            firstOfMonth,
+		"Weekdays":                weekdays,
+		"LeadingDays":             calendarLeadingDays(firstOfMonth, weekStart),
 		"PrevMonth":               prevMonth,
 		"NextMonth":               nextMonth,
 		"CurrencySymbol":          h.settingsService.GetCurrencySymbol(),
@@ -333,6 +474,12 @@ func (h *SubscriptionHandler) Calendar(c *gin.Context) {
 	})
 }
 
+// calendarLeadingDays returns how many cells of the previous month precede the
+// first of the month in a grid whose weeks begin on weekStart
+func calendarLeadingDays(firstOfMonth time.Time, weekStart time.Weekday) int {
+	return (int(firstOfMonth.Weekday()) - int(weekStart) + 7) % 7
+}
+
 // generateICalContent generates iCal content for all active subscriptions
 // If forSubscription is true, adds subscription-friendly properties for calendar polling
 func (h *SubscriptionHandler) generateICalContent(forSubscription bool) (string, error) {
@@ -354,19 +501,22 @@ func (h *SubscriptionHandler) generateICalContent(forSubscription bool) (string,
 	}
 
 	now := time.Now()
+	loc := models.Location()
 	for _, sub := range subscriptions {
 		if sub.RenewalDate != nil && sub.Status == "Active" {
-			dtStart := sub.RenewalDate.Format("20060102T150000Z")
-			dtEnd := sub.RenewalDate.Add(1 * time.Hour).Format("20060102T150000Z")
-			dtStamp := now.Format("20060102T150000Z")
+			// Renewals are all-day events on the date as seen in the configured time zone
+			renewal := sub.RenewalDate.In(loc)
+			dtStart := renewal.Format("20060102")
+			dtEnd := renewal.AddDate(0, 0, 1).Format("20060102")
+			dtStamp := now.UTC().Format("20060102T150405Z")
 			uid := fmt.Sprintf("subtrackr-%d-%d@subtrackr", sub.ID, sub.RenewalDate.Unix())
 
 			summary := fmt.Sprintf("%s Renewal", sub.Name)
-			subCurrencySymbol := h.settingsService.GetCurrencySymbol()
-			if sub.OriginalCurrency != "" && sub.OriginalCurrency != h.settingsService.GetCurrency() {
-				subCurrencySymbol = service.CurrencySymbolForCode(sub.OriginalCurrency)
+			currency := sub.OriginalCurrency
+			if currency == "" {
+				currency = h.settingsService.GetCurrency()
 			}
-			description := fmt.Sprintf("Subscription: %s\\nCost: %s%.2f\\nSchedule: %s", sub.Name, subCurrencySymbol, sub.Cost, sub.DisplaySchedule())
+			description := fmt.Sprintf("Subscription: %s\\nCost: %s\\nSchedule: %s", sub.Name, h.settingsService.FormatMoneyIn(sub.Cost, currency), sub.DisplaySchedule())
 			if sub.URL != "" {
 				description += fmt.Sprintf("\\nURL: %s", sub.URL)
 			}
@@ -374,8 +524,8 @@ func (h *SubscriptionHandler) generateICalContent(forSubscription bool) (string,
 			icalContent += "BEGIN:VEVENT\r\n"
 			icalContent += fmt.Sprintf("UID:%s\r\n", uid)
 			icalContent += fmt.Sprintf("DTSTAMP:%s\r\n", dtStamp)
-			icalContent += fmt.Sprintf("DTSTART:%s\r\n", dtStart)
-			icalContent += fmt.Sprintf("DTEND:%s\r\n", dtEnd)
+			icalContent += fmt.Sprintf("DTSTART;VALUE=DATE:%s\r\n", dtStart)
+			icalContent += fmt.Sprintf("DTEND;VALUE=DATE:%s\r\n", dtEnd)
 			icalContent += fmt.Sprintf("SUMMARY:%s\r\n", summary)
 			icalContent += fmt.Sprintf("DESCRIPTION:%s\r\n", description)
 			icalContent += "STATUS:CONFIRMED\r\n"
@@ -474,6 +624,18 @@ func (h *SubscriptionHandler) Settings(c *gin.Context) {
 		webhookConfigured = true
 	}
 
+	// Load Apprise config if available
+	var appriseConfig *models.AppriseConfig
+	if appriseCfg, err := h.settingsService.GetAppriseConfig(); err == nil && len(appriseCfg.URLs) > 0 {
+		appriseConfig = appriseCfg
+	}
+
+	quietHoursStart, quietHoursEnd := h.settingsService.GetQuietHours()
+	var reminderSendHour string
+	if hour, ok := h.settingsService.GetReminderSendHour(); ok {
+		reminderSendHour = strconv.Itoa(hour)
+	}
+
 	// Get auth settings
 	authEnabled := h.settingsService.IsAuthEnabled()
 	authUsername, _ := h.settingsService.GetAuthUsername()
@@ -497,7 +659,13 @@ func (h *SubscriptionHandler) Settings(c *gin.Context) {
 		"HighCostAlerts":           h.settingsService.GetBoolSettingWithDefault("high_cost_alerts", true),
 		"PushoverConfig":           pushoverConfig,
 		"PushoverConfigured":       pushoverConfigured,
-		"HighCostThreshold":        h.settingsService.GetFloatSettingWithDefault("high_cost_threshold", 50.0),
+		"PushoverSounds":           service.PushoverSounds,
+		"PushoverMinRetry":         service.PushoverMinRetry,
+		"PushoverMaxExpire":        service.PushoverMaxExpire,
+		"DefaultPushoverRetry":     service.DefaultPushoverRetry,
+		"DefaultPushoverExpire":    service.DefaultPushoverExpire,
+		"HighCostThreshold":        h.settingsService.GetHighCostThreshold(),
+		"HighCostBasis":            h.settingsService.GetHighCostBasis(),
 		"ReminderDays":             h.settingsService.GetIntSettingWithDefault("reminder_days", 7),
 		"CancellationReminders":    h.settingsService.GetBoolSettingWithDefault("cancellation_reminders", false),
 		"CancellationReminderDays": h.settingsService.GetIntSettingWithDefault("cancellation_reminder_days", 7),
@@ -507,13 +675,31 @@ func (h *SubscriptionHandler) Settings(c *gin.Context) {
 		"SMTPConfigured":           smtpConfigured,
 		"AuthEnabled":              authEnabled,
 		"AuthUsername":             authUsername,
+		"AuthDisabledByConfig":     h.settingsService.IsAuthDisabledByConfig(),
+		"RememberMeDays":           h.settingsService.GetRememberMeDays(),
 		"ICalSubscriptionEnabled":  icalSubscriptionEnabled,
 		"ICalSubscriptionURL":      icalSubscriptionURL,
+		"LogoCacheEnabled":         h.settingsService.IsLogoCacheEnabled(),
+		"TrialSpendIncluded":       h.settingsService.IsTrialSpendIncluded(),
 		"BaseURL":                  h.settingsService.GetBaseURL(),
 		"Currencies":               service.GetAvailableCurrencies(),
 		"DateFormat":               h.settingsService.GetDateFormat(),
+		"NumberFormat":             h.settingsService.GetNumberFormat(),
+		"SymbolPosition":           h.settingsService.GetSymbolPosition(),
+		"WeekStartMonday":          h.settingsService.GetWeekStart() == time.Monday,
+		"CostBasisCalendar":        h.settingsService.GetCostBasis() == models.CostBasisCalendar,
+		"Timezone":                 h.settingsService.GetTimezone(),
 		"WebhookConfig":            webhookConfig,
 		"WebhookConfigured":        webhookConfigured,
+		"AppriseConfig":            appriseConfig,
+		"DefaultAppriseServerURL":  service.DefaultAppriseServerURL,
+		"NotifyEmail":              h.settingsService.IsNotificationChannelEnabled(service.NotificationChannelEmail),
+		"NotifyPushover":           h.settingsService.IsNotificationChannelEnabled(service.NotificationChannelPushover),
+		"NotifyWebhook":            h.settingsService.IsNotificationChannelEnabled(service.NotificationChannelWebhook),
+		"NotifyApprise":            h.settingsService.IsNotificationChannelEnabled(service.NotificationChannelApprise),
+		"QuietHoursStart":          quietHoursStart,
+		"QuietHoursEnd":            quietHoursEnd,
+		"ReminderSendHour":         reminderSendHour,
 	})
 }
 
@@ -522,11 +708,16 @@ func (h *SubscriptionHandler) Settings(c *gin.Context) {
 // GetSubscriptions returns subscriptions as HTML fragments
 func (h *SubscriptionHandler) GetSubscriptions(c *gin.Context) {
 	// Get sort parameters from query string
-	sortBy := c.DefaultQuery("sort", "created_at")
-	order := c.DefaultQuery("order", "desc")
+	sortBy, order, err := parseSort(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Get sorted subscriptions
-	subscriptions, err := h.service.GetAllSorted(sortBy, order)
+	page := parsePagination(c)
+
+	// Get sorted subscriptions (a single page if pagination was requested)
+	subscriptions, total, err := h.getSortedSubscriptions(sortBy, order, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -541,22 +732,150 @@ func (h *SubscriptionHandler) GetSubscriptions(c *gin.Context) {
 		"SortBy":         sortBy,
 		"Order":          order,
 		"GoDateFormat":   h.settingsService.GetGoDateFormat(),
+		"Paginated":      page.Enabled,
+		"Limit":          page.Limit,
+		"Offset":         page.Offset,
+		"Total":          total,
+		"HasPrev":        page.Enabled && page.Offset > 0,
+		"HasNext":        page.Enabled && int64(page.Offset+page.Limit) < total,
+		"PrevOffset":     max(page.Offset-page.Limit, 0),
+		"NextOffset":     page.Offset + page.Limit,
 	})
 }
 
-// GetSubscriptionsAPI returns subscriptions as JSON for API calls
+// GetSubscriptionsAPI returns subscriptions as JSON for API calls.
+// Supports optional limit/offset or page/page_size query params; the
+// total number of subscriptions is returned in the X-Total-Count header.
 func (h *SubscriptionHandler) GetSubscriptionsAPI(c *gin.Context) {
-	subscriptions, err := h.service.GetAll()
+	sortBy, order, err := parseSort(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscriptions, total, err := h.getSortedSubscriptions(sortBy, order, parsePagination(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
 	c.JSON(http.StatusOK, subscriptions)
 }
 
+// SearchSubscriptions filters subscriptions by name, status, category and cost range.
+// HTMX requests receive the subscription list fragment; other callers receive JSON.
+func (h *SubscriptionHandler) SearchSubscriptions(c *gin.Context) {
+	sortBy, order, err := parseSort(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := models.SubscriptionFilter{
+		Query:  c.Query("q"),
+		Status: c.Query("status"),
+	}
+	if val := c.Query("category_id"); val != "" {
+		categoryID, err := strconv.ParseUint(val, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category_id"})
+			return
+		}
+		filter.CategoryID = uint(categoryID)
+	}
+	if val := c.Query("min_cost"); val != "" {
+		minCost, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_cost"})
+			return
+		}
+		filter.MinCost = &minCost
+	}
+	if val := c.Query("max_cost"); val != "" {
+		maxCost, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_cost"})
+			return
+		}
+		filter.MaxCost = &maxCost
+	}
+
+	subscriptions, err := h.service.Search(filter, sortBy, order)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	enrichedSubs := h.enrichWithCurrencyConversion(subscriptions)
+
+	if c.GetHeader("HX-Request") != "" {
+		c.HTML(http.StatusOK, "subscription-list.html", gin.H{
+			"Subscriptions":  enrichedSubs,
+			"CurrencySymbol": h.settingsService.GetCurrencySymbol(),
+			"SortBy":         sortBy,
+			"Order":          order,
+			"GoDateFormat":   h.settingsService.GetGoDateFormat(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrichedSubs)
+}
+
+// Sort applied when the request does not name one
+const (
+	defaultSortBy    = "created_at"
+	defaultSortOrder = "desc"
+)
+
+// parseSort reads the sort and order query params, defaulting to newest first,
+// and returns service.ErrInvalidSort for values that cannot be sorted by
+func parseSort(c *gin.Context) (sortBy, order string, err error) {
+	sortBy = c.DefaultQuery("sort", defaultSortBy)
+	order = c.DefaultQuery("order", defaultSortOrder)
+	return sortBy, order, service.ValidateSort(sortBy, order)
+}
+
+// getSortedSubscriptions returns either the full sorted list or a single page,
+// depending on whether the client requested pagination
+func (h *SubscriptionHandler) getSortedSubscriptions(sortBy, order string, page pagination) ([]models.Subscription, int64, error) {
+	if page.Enabled {
+		return h.service.GetPaged(sortBy, order, page.Limit, page.Offset)
+	}
+
+	subscriptions, err := h.service.GetAllSorted(sortBy, order)
+	if err != nil {
+		return nil, 0, err
+	}
+	return subscriptions, int64(len(subscriptions)), nil
+}
+
 // CreateSubscription handles creating a new subscription
 func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
+	var createdID uint
+	if key := c.GetHeader("Idempotency-Key"); key != "" && h.idempotencyKeys.Enabled() {
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			return
+		}
+		// Keys are scoped to the API key, which APIKeyAuth records on /api/v1 requests
+		apiKeyID := c.GetUint(middleware.APIKeyIDContextKey)
+		id, claimed := h.idempotencyKeys.Claim(apiKeyID, key)
+		if !claimed {
+			h.replayCreatedSubscription(c, id)
+			return
+		}
+		// Only remember the key if a subscription was created, so failed requests can be retried
+		defer func() {
+			if createdID != 0 {
+				h.idempotencyKeys.Complete(apiKeyID, key, createdID)
+			} else {
+				h.idempotencyKeys.Release(apiKeyID, key)
+			}
+		}()
+	}
+
 	var subscription models.Subscription
 
 	// Parse form data
@@ -569,6 +888,7 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 	}
 	subscription.Schedule = c.PostForm("schedule")
 	subscription.ScheduleInterval = parseScheduleInterval(c.PostForm("schedule_interval"))
+	subscription.SharedWith = parseSharedWith(c.PostForm("shared_with"))
 	subscription.Status = c.PostForm("status")
 	subscription.OriginalCurrency = c.PostForm("original_currency")
 	if subscription.OriginalCurrency == "" {
@@ -577,6 +897,7 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 	subscription.PaymentMethod = c.PostForm("payment_method")
 	subscription.Account = c.PostForm("account")
 	subscription.URL = c.PostForm("url")
+	subscription.CancellationURL = c.PostForm("cancellation_url")
 	subscription.IconURL = c.PostForm("icon_url")
 	subscription.Notes = c.PostForm("notes")
 	subscription.Usage = c.PostForm("usage")
@@ -588,6 +909,19 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 	} else {
 		subscription.ReminderEnabled = reminderVal == "true"
 	}
+	reminderDaysOverride, err := parseIntPtr("reminder_days_override", c.PostForm("reminder_days_override"))
+	if err != nil {
+		if c.GetHeader("HX-Request") != "" {
+			c.Header("HX-Retarget", "#form-errors")
+			c.HTML(http.StatusBadRequest, "form-errors.html", gin.H{
+				"Error": err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	subscription.ReminderDaysOverride = reminderDaysOverride
 
 	// Parse cost
 	if costStr := c.PostForm("cost"); costStr != "" {
@@ -595,11 +929,37 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 			subscription.Cost = cost
 		}
 	}
+	subscription.NextChargeAmount = parseAmountPtr(c.PostForm("next_charge_amount"))
+	subscription.FirstChargeAmount = parseAmountPtr(c.PostForm("first_charge_amount"))
 
 	// Parse dates using helper function
 	subscription.StartDate = parseDatePtr(c.PostForm("start_date"))
 	subscription.RenewalDate = parseDatePtr(c.PostForm("renewal_date"))
 	subscription.CancellationDate = parseDatePtr(c.PostForm("cancellation_date"))
+	subscription.FirstChargeDate = parseDatePtr(c.PostForm("first_charge_date"))
+	subscription.LockRenewalDate = c.PostForm("lock_renewal_date") == "true"
+	subscription.DisplayInOriginal = c.PostForm("display_in_original") == "true"
+
+	// Suggest a name from the website when only a URL was given
+	if strings.TrimSpace(subscription.Name) == "" && subscription.URL != "" {
+		if name, err := h.logoService.FetchSiteName(subscription.URL); err == nil {
+			subscription.Name = name
+		} else {
+			slog.Warn("Failed to fetch site name", "url", subscription.URL, "error", err)
+		}
+	}
+
+	if err := subscription.Validate(); err != nil {
+		if c.GetHeader("HX-Request") != "" {
+			c.Header("HX-Retarget", "#form-errors")
+			c.HTML(http.StatusBadRequest, "form-errors.html", gin.H{
+				"Error": err.Error(),
+			})
+		} else {
+			respondValidationError(c, err)
+		}
+		return
+	}
 
 	// Fetch logo synchronously before creation if URL is provided and icon_url is empty
 	h.fetchAndSetLogo(&subscription)
@@ -608,9 +968,8 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 	created, err := h.service.Create(&subscription)
 	if err != nil {
 		// Log the error for debugging
-		log.Printf("Failed to create subscription: %v", err)
-		log.Printf("Subscription data: Name=%s, CategoryID=%d, Status=%s, Schedule=%s",
-			subscription.Name, subscription.CategoryID, subscription.Status, subscription.Schedule)
+		slog.Error("Failed to create subscription", "error", err,
+			"name", subscription.Name, "category_id", subscription.CategoryID, "status", subscription.Status, "schedule", subscription.Schedule)
 
 		if c.GetHeader("HX-Request") != "" {
 			c.Header("HX-Retarget", "#form-errors")
@@ -623,26 +982,11 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 		return
 	}
 
-	// Send high-cost alert email and Pushover notification if applicable
+	createdID = created.ID
+
+	// Send high-cost alerts if applicable
 	if h.isHighCostWithCurrency(created) {
-		// Reload subscription with category for email template
-		subscriptionWithCategory, err := h.service.GetByID(created.ID)
-		if err == nil && subscriptionWithCategory != nil {
-			// Send email notification
-			if err := h.emailService.SendHighCostAlert(subscriptionWithCategory); err != nil {
-				// Log error but don't fail the request
-				log.Printf("Failed to send high-cost alert email: %v", err)
-			}
-			// Send Pushover notification
-			if err := h.pushoverService.SendHighCostAlert(subscriptionWithCategory); err != nil {
-				// Log error but don't fail the request
-				log.Printf("Failed to send high-cost alert Pushover notification: %v", err)
-			}
-			// Send Webhook notification
-			if err := h.webhookService.SendHighCostAlert(subscriptionWithCategory); err != nil {
-				log.Printf("Failed to send high-cost alert webhook: %v", err)
-			}
-		}
+		h.sendHighCostAlerts(created.ID)
 	}
 
 	if c.GetHeader("HX-Request") != "" {
@@ -653,6 +997,35 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 	}
 }
 
+// respondValidationError sends a 422 with a message for each invalid field, or a
+// 400 for errors that aren't tied to fields
+func respondValidationError(c *gin.Context, err error) {
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Validation failed", "fields": validationErr.Fields})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// replayCreatedSubscription answers a retried create with the subscription its
+// Idempotency-Key already created
+func (h *SubscriptionHandler) replayCreatedSubscription(c *gin.Context, id uint) {
+	if id == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still in progress"})
+		return
+	}
+
+	subscription, err := h.service.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Idempotency-Key was used for a subscription that no longer exists"})
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.JSON(http.StatusCreated, subscription)
+}
+
 // GetSubscription returns a single subscription
 func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -670,6 +1043,48 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 	c.JSON(http.StatusOK, subscription)
 }
 
+// DuplicateSubscription creates a copy of an existing subscription and returns it
+func (h *SubscriptionHandler) DuplicateSubscription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	duplicate, err := h.service.Duplicate(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") != "" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusCreated)
+	} else {
+		c.JSON(http.StatusCreated, duplicate)
+	}
+}
+
+// GetNotesHTML returns a subscription's notes rendered from Markdown to sanitized HTML
+func (h *SubscriptionHandler) GetNotesHTML(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	subscription, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(service.RenderMarkdown(subscription.Notes)))
+}
+
 // UpdateSubscription handles updating an existing subscription
 func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -702,6 +1117,9 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 	if val, ok := c.GetPostForm("schedule_interval"); ok {
 		existing.ScheduleInterval = parseScheduleInterval(val)
 	}
+	if val, ok := c.GetPostForm("shared_with"); ok {
+		existing.SharedWith = parseSharedWith(val)
+	}
 	if val, ok := c.GetPostForm("status"); ok {
 		existing.Status = val
 	}
@@ -733,6 +1151,9 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 		existing.IconURL = ""
 	}
 
+	if val, ok := c.GetPostForm("cancellation_url"); ok {
+		existing.CancellationURL = val
+	}
 	if val, ok := c.GetPostForm("notes"); ok {
 		existing.Notes = val
 	}
@@ -742,11 +1163,28 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 	if val, ok := c.GetPostForm("reminder_enabled"); ok {
 		existing.ReminderEnabled = val == "true"
 	}
+	if val, ok := c.GetPostForm("reminder_days_override"); ok {
+		reminderDaysOverride, err := parseIntPtr("reminder_days_override", val)
+		if err != nil {
+			c.Header("HX-Retarget", "#form-errors")
+			c.HTML(http.StatusBadRequest, "form-errors.html", gin.H{
+				"Error": err.Error(),
+			})
+			return
+		}
+		existing.ReminderDaysOverride = reminderDaysOverride
+	}
 	if val, ok := c.GetPostForm("cost"); ok && val != "" {
 		if cost, err := strconv.ParseFloat(val, 64); err == nil {
 			existing.Cost = cost
 		}
 	}
+	if val, ok := c.GetPostForm("next_charge_amount"); ok {
+		existing.NextChargeAmount = parseAmountPtr(val)
+	}
+	if val, ok := c.GetPostForm("first_charge_amount"); ok {
+		existing.FirstChargeAmount = parseAmountPtr(val)
+	}
 
 	// Parse dates — only update if the field was submitted
 	if val, ok := c.GetPostForm("start_date"); ok {
@@ -758,6 +1196,15 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 	if val, ok := c.GetPostForm("cancellation_date"); ok {
 		existing.CancellationDate = parseDatePtr(val)
 	}
+	if val, ok := c.GetPostForm("first_charge_date"); ok {
+		existing.FirstChargeDate = parseDatePtr(val)
+	}
+	if val, ok := c.GetPostForm("lock_renewal_date"); ok {
+		existing.LockRenewalDate = val == "true"
+	}
+	if val, ok := c.GetPostForm("display_in_original"); ok {
+		existing.DisplayInOriginal = val == "true"
+	}
 
 	// Fetch new logo if URL changed or URL is set but no icon
 	if urlChanged || (existing.URL != "" && existing.IconURL == "") {
@@ -774,26 +1221,9 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 		return
 	}
 
-	// Send high-cost alert email and Pushover notification if subscription became high-cost (wasn't before, but is now)
+	// Send high-cost alerts if subscription became high-cost (wasn't before, but is now)
 	if updated != nil && !wasHighCost && h.isHighCostWithCurrency(updated) {
-		// Reload subscription with category for email template
-		subscriptionWithCategory, err := h.service.GetByID(updated.ID)
-		if err == nil && subscriptionWithCategory != nil {
-			// Send email notification
-			if err := h.emailService.SendHighCostAlert(subscriptionWithCategory); err != nil {
-				// Log error but don't fail the request
-				log.Printf("Failed to send high-cost alert email: %v", err)
-			}
-			// Send Pushover notification
-			if err := h.pushoverService.SendHighCostAlert(subscriptionWithCategory); err != nil {
-				// Log error but don't fail the request
-				log.Printf("Failed to send high-cost alert Pushover notification: %v", err)
-			}
-			// Send Webhook notification
-			if err := h.webhookService.SendHighCostAlert(subscriptionWithCategory); err != nil {
-				log.Printf("Failed to send high-cost alert webhook: %v", err)
-			}
-		}
+		h.sendHighCostAlerts(updated.ID)
 	}
 
 	// Return success response that triggers a page refresh
@@ -801,6 +1231,196 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+// subscriptionPatch lists the fields a PATCH request may change.
+// Only keys present in the request body are applied to the stored subscription.
+type subscriptionPatch struct {
+	Name                 string   `json:"name"`
+	Cost                 float64  `json:"cost"`
+	NextChargeAmount     *float64 `json:"next_charge_amount"`
+	FirstChargeAmount    *float64 `json:"first_charge_amount"`
+	Schedule             string   `json:"schedule"`
+	ScheduleInterval     int      `json:"schedule_interval"`
+	SharedWith           int      `json:"shared_with"`
+	Status               string   `json:"status"`
+	OriginalCurrency     string   `json:"original_currency"`
+	CategoryID           uint     `json:"category_id"`
+	PaymentMethod        string   `json:"payment_method"`
+	Account              string   `json:"account"`
+	URL                  string   `json:"url"`
+	CancellationURL      string   `json:"cancellation_url"`
+	IconURL              string   `json:"icon_url"`
+	Notes                string   `json:"notes"`
+	Usage                string   `json:"usage"`
+	ReminderEnabled      bool     `json:"reminder_enabled"`
+	ReminderDaysOverride *int     `json:"reminder_days_override"`
+	LockRenewalDate      bool     `json:"lock_renewal_date"`
+	DisplayInOriginal    bool     `json:"display_in_original"`
+	StartDate            string   `json:"start_date"`
+	RenewalDate          string   `json:"renewal_date"`
+	CancellationDate     string   `json:"cancellation_date"`
+	FirstChargeDate      string   `json:"first_charge_date"`
+}
+
+// applySubscriptionPatch merges the fields present in a JSON object into subscription.
+// Dates use YYYY-MM-DD and may be set to an empty string to clear them.
+func applySubscriptionPatch(subscription *models.Subscription, body []byte) error {
+	// Detect which fields were explicitly provided via raw JSON
+	var provided map[string]json.RawMessage
+	if err := json.Unmarshal(body, &provided); err != nil {
+		return fmt.Errorf("invalid JSON body")
+	}
+	var input subscriptionPatch
+	if err := json.Unmarshal(body, &input); err != nil {
+		return err
+	}
+
+	if _, ok := provided["name"]; ok {
+		subscription.Name = input.Name
+	}
+	if _, ok := provided["cost"]; ok {
+		subscription.Cost = input.Cost
+	}
+	if _, ok := provided["next_charge_amount"]; ok {
+		subscription.NextChargeAmount = input.NextChargeAmount
+	}
+	if _, ok := provided["first_charge_amount"]; ok {
+		subscription.FirstChargeAmount = input.FirstChargeAmount
+	}
+	if _, ok := provided["schedule"]; ok {
+		subscription.Schedule = input.Schedule
+	}
+	if _, ok := provided["schedule_interval"]; ok {
+		subscription.ScheduleInterval = max(input.ScheduleInterval, 1)
+	}
+	if _, ok := provided["shared_with"]; ok {
+		subscription.SharedWith = max(input.SharedWith, 1)
+	}
+	if _, ok := provided["status"]; ok {
+		subscription.Status = input.Status
+	}
+	if _, ok := provided["original_currency"]; ok {
+		if input.OriginalCurrency == "" {
+			subscription.OriginalCurrency = "USD"
+		} else {
+			subscription.OriginalCurrency = input.OriginalCurrency
+		}
+	}
+	if _, ok := provided["category_id"]; ok {
+		subscription.CategoryID = input.CategoryID
+	}
+	if _, ok := provided["payment_method"]; ok {
+		subscription.PaymentMethod = input.PaymentMethod
+	}
+	if _, ok := provided["account"]; ok {
+		subscription.Account = input.Account
+	}
+	if _, ok := provided["url"]; ok && input.URL != subscription.URL {
+		subscription.URL = input.URL
+		// URL changed — re-fetch the logo unless one is supplied below
+		subscription.IconURL = ""
+	}
+	if _, ok := provided["icon_url"]; ok {
+		subscription.IconURL = input.IconURL
+	}
+	if _, ok := provided["cancellation_url"]; ok {
+		subscription.CancellationURL = input.CancellationURL
+	}
+	if _, ok := provided["notes"]; ok {
+		subscription.Notes = input.Notes
+	}
+	if _, ok := provided["usage"]; ok {
+		subscription.Usage = input.Usage
+	}
+	if _, ok := provided["reminder_enabled"]; ok {
+		subscription.ReminderEnabled = input.ReminderEnabled
+	}
+	if _, ok := provided["reminder_days_override"]; ok {
+		subscription.ReminderDaysOverride = input.ReminderDaysOverride
+	}
+	if _, ok := provided["lock_renewal_date"]; ok {
+		subscription.LockRenewalDate = input.LockRenewalDate
+	}
+	if _, ok := provided["display_in_original"]; ok {
+		subscription.DisplayInOriginal = input.DisplayInOriginal
+	}
+
+	dateFields := []struct {
+		key   string
+		value string
+		dest  **time.Time
+	}{
+		{"start_date", input.StartDate, &subscription.StartDate},
+		{"renewal_date", input.RenewalDate, &subscription.RenewalDate},
+		{"cancellation_date", input.CancellationDate, &subscription.CancellationDate},
+		{"first_charge_date", input.FirstChargeDate, &subscription.FirstChargeDate},
+	}
+	for _, field := range dateFields {
+		if _, ok := provided[field.key]; !ok {
+			continue
+		}
+		if field.value == "" {
+			*field.dest = nil
+			continue
+		}
+		date, err := time.ParseInLocation("2006-01-02", field.value, models.Location())
+		if err != nil {
+			return fmt.Errorf("invalid %s: expected format YYYY-MM-DD", field.key)
+		}
+		*field.dest = &date
+	}
+
+	return nil
+}
+
+// PatchSubscription applies a partial JSON update to an existing subscription.
+// Fields omitted from the body are left untouched.
+func (h *SubscriptionHandler) PatchSubscription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	existing, err := h.service.GetByID(uint(id))
+	if err != nil || existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	wasHighCost := h.isHighCostWithCurrency(existing)
+	previousCost := existing.Cost
+
+	if err := applySubscriptionPatch(existing, body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := existing.ValidateUpdate(previousCost); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	h.fetchAndSetLogo(existing)
+
+	updated, err := h.service.Update(uint(id), existing)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Send high-cost alerts if the subscription became high-cost (wasn't before, but is now)
+	if updated != nil && !wasHighCost && h.isHighCostWithCurrency(updated) {
+		h.sendHighCostAlerts(updated.ID)
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
 // DeleteSubscription handles deleting a subscription
 func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -809,20 +1429,76 @@ func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
 		return
 	}
 
+	existing, _ := h.service.GetByID(uint(id))
+
 	err = h.service.Delete(uint(id))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Uploaded logos belong to this subscription alone
+	if existing != nil {
+		if err := h.logoService.RemoveUploadedLogo(existing.IconURL); err != nil {
+			slog.Warn("Failed to remove logo", "subscription_id", id, "error", err)
+		}
+	}
+
 	// Return success response that triggers a page refresh
 	c.Header("HX-Refresh", "true")
 	c.Status(http.StatusOK)
 }
 
-// GetStats returns current statistics
+// GetStats returns current statistics, optionally scoped by ?category_id=
 func (h *SubscriptionHandler) GetStats(c *gin.Context) {
-	stats, err := h.service.GetStats()
+	var filter models.StatsFilter
+	if val := c.Query("category_id"); val != "" {
+		categoryID, err := strconv.ParseUint(val, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category_id"})
+			return
+		}
+		filter.CategoryID = uint(categoryID)
+	}
+	if c.Query("tag") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Filtering by tag is not supported; subscriptions have no tags"})
+		return
+	}
+
+	stats, err := h.service.GetStatsFiltered(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetCategoryStats returns monthly spend per category of the subscriptions counted as spend
+func (h *SubscriptionHandler) GetCategoryStats(c *gin.Context) {
+	stats, err := h.service.GetCategoryStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetPaymentMethodStats returns monthly spend of active subscriptions per payment method
+func (h *SubscriptionHandler) GetPaymentMethodStats(c *gin.Context) {
+	stats, err := h.service.GetPaymentMethodStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetAccountStats returns monthly spend of active subscriptions per account
+func (h *SubscriptionHandler) GetAccountStats(c *gin.Context) {
+	stats, err := h.service.GetAccountStats()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -831,6 +1507,251 @@ func (h *SubscriptionHandler) GetStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// PreviewRenewal returns the next renewal date for a start date and schedule
+// under both date calculation versions, without saving anything. Query
+// parameters: schedule (required), interval, start (YYYY-MM-DD, default now)
+// and version (1 or 2, default 1) picking which result is renewal_date.
+func (h *SubscriptionHandler) PreviewRenewal(c *gin.Context) {
+	schedule := c.Query("schedule")
+	switch schedule {
+	case "Daily", "Weekly", "Monthly", "Quarterly", "Annual":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "schedule must be one of Daily, Weekly, Monthly, Quarterly, Annual"})
+		return
+	}
+
+	var start *time.Time
+	if startStr := c.Query("start"); startStr != "" {
+		date, err := time.ParseInLocation("2006-01-02", startStr, models.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start must be a date in YYYY-MM-DD format"})
+			return
+		}
+		start = &date
+	}
+
+	version := 1
+	switch c.DefaultQuery("version", "1") {
+	case "1":
+	case "2":
+		version = 2
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version must be 1 or 2"})
+		return
+	}
+
+	interval := parseScheduleInterval(c.Query("interval"))
+	formatDate := func(date *time.Time) string {
+		if date == nil {
+			return ""
+		}
+		return date.Format("2006-01-02")
+	}
+	v1 := formatDate(models.PreviewRenewalDate(schedule, interval, start, 1))
+	v2 := formatDate(models.PreviewRenewalDate(schedule, interval, start, 2))
+
+	renewalDate := v1
+	if version == 2 {
+		renewalDate = v2
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"schedule":     schedule,
+		"interval":     interval,
+		"start":        formatDate(start),
+		"version":      version,
+		"renewal_date": renewalDate,
+		"v1":           v1,
+		"v2":           v2,
+		"differs":      v1 != v2,
+	})
+}
+
+// GetMyShare returns my personal monthly total across active subscriptions,
+// with shared ones split between the people sharing them
+func (h *SubscriptionHandler) GetMyShare(c *gin.Context) {
+	stat, err := h.service.GetMyShare()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stat)
+}
+
+// GetWasteReport returns active low-usage subscriptions and the monthly amount
+// that would be saved by cancelling all of them
+func (h *SubscriptionHandler) GetWasteReport(c *gin.Context) {
+	subscriptions, err := h.service.GetLowUsageSubscriptions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items := make([]gin.H, 0, len(subscriptions))
+	var savings float64
+	for _, sub := range subscriptions {
+		monthly := sub.MonthlyCost()
+		savings += monthly
+		items = append(items, gin.H{
+			"id":                sub.ID,
+			"name":              sub.Name,
+			"usage":             sub.Usage,
+			"monthly_cost":      monthly,
+			"original_currency": sub.OriginalCurrency,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscriptions":             items,
+		"count":                     len(items),
+		"potential_monthly_savings": savings,
+	})
+}
+
+// parseMonthsQuery reads the ?months= query parameter, defaulting to defaultMonths.
+// It responds with 400 and returns false when the value isn't between 1 and maxMonths.
+func parseMonthsQuery(c *gin.Context, defaultMonths, maxMonths int) (int, bool) {
+	val := c.Query("months")
+	if val == "" {
+		return defaultMonths, true
+	}
+	months, err := strconv.Atoi(val)
+	if err != nil || months < 1 || months > maxMonths {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("months must be between 1 and %d", maxMonths)})
+		return 0, false
+	}
+	return months, true
+}
+
+// GetSpendingTrend returns total monthly spend for the last ?months= months (default 6)
+func (h *SubscriptionHandler) GetSpendingTrend(c *gin.Context) {
+	months, ok := parseMonthsQuery(c, service.DefaultTrendMonths, service.MaxTrendMonths)
+	if !ok {
+		return
+	}
+
+	trend, err := h.service.GetMonthlyTrend(months)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"months": months,
+		"series": trend,
+	})
+}
+
+// GetChargeForecast returns every renewal charge expected in the next ?days= days
+// (default 30), converted to the display currency when conversion is enabled
+func (h *SubscriptionHandler) GetChargeForecast(c *gin.Context) {
+	days := service.DefaultForecastDays
+	if val := c.Query("days"); val != "" {
+		parsed, err := strconv.Atoi(val)
+		if err != nil || parsed < 1 || parsed > service.MaxForecastDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days must be between 1 and %d", service.MaxForecastDays)})
+			return
+		}
+		days = parsed
+	}
+
+	charges, err := h.service.GetForecast(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	displayCurrency := h.settingsService.GetCurrency()
+	total := 0.0
+	for i := range charges {
+		charge := &charges[i]
+		charge.Amount, charge.Currency = h.toDisplayCurrency(charge.Amount, charge.Currency, displayCurrency)
+		total += charge.Amount
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"days":     days,
+		"currency": displayCurrency,
+		"charges":  charges,
+		"total":    total,
+	})
+}
+
+// GetRenewalCalendar returns the subscriptions charged in each of the next ?months=
+// calendar months (default 12), with each month's total in the display currency
+// when conversion is enabled
+func (h *SubscriptionHandler) GetRenewalCalendar(c *gin.Context) {
+	months, ok := parseMonthsQuery(c, service.DefaultRenewalCalendarMonths, service.MaxRenewalCalendarMonths)
+	if !ok {
+		return
+	}
+
+	calendar, err := h.service.GetRenewalCalendar(months)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	displayCurrency := h.settingsService.GetCurrency()
+	for i := range calendar {
+		month := &calendar[i]
+		total, converted := 0.0, true
+		for j := range month.Subscriptions {
+			entry := &month.Subscriptions[j]
+			entry.Amount, entry.Currency = h.toDisplayCurrency(entry.Amount, entry.Currency, displayCurrency)
+			total += entry.Amount
+			converted = converted && entry.Currency == displayCurrency
+		}
+		// Amounts left in another currency can't be added up
+		if converted {
+			month.Total = &total
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"months":   months,
+		"currency": displayCurrency,
+		"calendar": calendar,
+	})
+}
+
+// GetCategoryTrend returns monthly spend per category for the last ?months= months (default 6)
+func (h *SubscriptionHandler) GetCategoryTrend(c *gin.Context) {
+	months, ok := parseMonthsQuery(c, service.DefaultTrendMonths, service.MaxTrendMonths)
+	if !ok {
+		return
+	}
+
+	trend, err := h.service.GetCategoryTrend(months)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trend)
+}
+
+// GetSavingsTimeline returns cumulative monthly savings from cancellations for the
+// last ?months= months (default 6)
+func (h *SubscriptionHandler) GetSavingsTimeline(c *gin.Context) {
+	months, ok := parseMonthsQuery(c, service.DefaultTrendMonths, service.MaxTrendMonths)
+	if !ok {
+		return
+	}
+
+	timeline, err := h.service.GetSavingsTimeline(months)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"months": months,
+		"series": timeline,
+	})
+}
+
 // GetSubscriptionForm returns the subscription form (for add/edit)
 func (h *SubscriptionHandler) GetSubscriptionForm(c *gin.Context) {
 	var subscription *models.Subscription
@@ -862,7 +1783,12 @@ func (h *SubscriptionHandler) GetSubscriptionForm(c *gin.Context) {
 	})
 }
 
-// ExportCSV exports all subscriptions as CSV
+// utf8BOM marks a file as UTF-8 for Excel, which otherwise reads it in a legacy
+// code page and mangles symbols such as €
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ExportCSV exports all subscriptions as CSV. With ?bom=1 the file starts with a
+// UTF-8 byte order mark so Excel detects the encoding.
 func (h *SubscriptionHandler) ExportCSV(c *gin.Context) {
 	subscriptions, err := h.service.GetAll()
 	if err != nil {
@@ -870,14 +1796,17 @@ func (h *SubscriptionHandler) ExportCSV(c *gin.Context) {
 		return
 	}
 
-	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Type", "text/csv; charset=utf-8")
 	c.Header("Content-Disposition", "attachment; filename=subscriptions.csv")
+	if bom, _ := strconv.ParseBool(c.Query("bom")); bom {
+		c.Writer.Write(utf8BOM)
+	}
 
 	writer := csv.NewWriter(c.Writer)
 	defer writer.Flush()
 
 	// Write CSV header
-	header := []string{"ID", "Name", "Category", "Cost", "Currency", "Schedule", "Schedule Interval", "Status", "Payment Method", "Account", "Start Date", "Renewal Date", "Cancellation Date", "URL", "Notes", "Usage", "Created At"}
+	header := []string{"ID", "Name", "Category", "Cost", "Currency", "Schedule", "Schedule Interval", "Status", "Payment Method", "Account", "Start Date", "Renewal Date", "Cancellation Date", "URL", "Cancellation URL", "Notes", "Usage", "Created At"}
 	writer.Write(header)
 
 	// Write subscription data
@@ -905,6 +1834,7 @@ func (h *SubscriptionHandler) ExportCSV(c *gin.Context) {
 			formatDate(sub.RenewalDate),
 			formatDate(sub.CancellationDate),
 			sub.URL,
+			sub.CancellationURL,
 			sub.Notes,
 			sub.Usage,
 			sub.CreatedAt.Format("2006-01-02 15:04:05"),
@@ -1059,26 +1989,44 @@ func (h *SubscriptionHandler) RestoreData(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-// ClearAllData removes all subscription data
+// RecalculateRenewals recomputes the renewal date of every active subscription
+// from its schedule and start date. This is a maintenance action for dates that
+// have drifted or gone missing; it doesn't change the calculation version.
+func (h *SubscriptionHandler) RecalculateRenewals(c *gin.Context) {
+	changed, err := h.service.RecalculateRenewals()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") != "" {
+		c.Header("HX-Refresh", "true")
+	}
+	c.JSON(http.StatusOK, gin.H{"changed": changed})
+}
+
+// ClearAllData removes all subscription data. The request must carry
+// ?confirm=true so the endpoint can't be triggered by accident.
 func (h *SubscriptionHandler) ClearAllData(c *gin.Context) {
-	subscriptions, err := h.service.GetAll()
+	if c.Query("confirm") != "true" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "confirm=true is required to clear all data"})
+		return
+	}
+
+	deleted, err := h.service.DeleteAll()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Delete all subscriptions
-	for _, sub := range subscriptions {
-		err := h.service.Delete(sub.ID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete subscription %d: %v", sub.ID, err)})
-			return
-		}
+	// With every subscription gone, no uploaded logo is referenced any more
+	if err := h.logoService.RemoveAllUploadedLogos(); err != nil {
+		slog.Warn("Failed to remove uploaded logos", "error", err)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":       "All subscription data has been cleared",
-		"deleted_count": len(subscriptions),
+		"deleted_count": deleted,
 	})
 }
 
@@ -1092,5 +2040,5 @@ func formatDate(date *time.Time) string {
 	if date == nil {
 		return ""
 	}
-	return date.Format("2006-01-02")
+	return date.In(models.Location()).Format("2006-01-02")
 }
@@ -1,10 +1,26 @@
 package handlers
 
 import (
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"subtrackr/internal/middleware"
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 func TestParseDatePtr(t *testing.T) {
@@ -106,3 +122,475 @@ func timePtr(t time.Time) *time.Time {
 	return &t
 }
 
+func TestCalendarLeadingDays(t *testing.T) {
+	tests := []struct {
+		name      string
+		first     time.Time
+		weekStart time.Weekday
+		expected  int
+	}{
+		{"sunday start, month begins on sunday", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Sunday, 0},
+		{"monday start, month begins on sunday", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Monday, 6},
+		{"sunday start, month begins on monday", time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), time.Sunday, 1},
+		{"monday start, month begins on monday", time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), time.Monday, 0},
+		{"monday start, month begins on thursday", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), time.Monday, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, calendarLeadingDays(tt.first, tt.weekStart))
+		})
+	}
+}
+
+func TestParseMonthsQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		query    string
+		expected int
+		ok       bool
+	}{
+		{"", 6, true},
+		{"?months=1", 1, true},
+		{"?months=12", 12, true},
+		{"?months=0", 0, false},
+		{"?months=13", 0, false},
+		{"?months=abc", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/stats/trend"+tt.query, nil)
+
+			months, ok := parseMonthsQuery(c, 6, 12)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, months)
+			if !ok {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.Contains(t, w.Body.String(), "months must be between 1 and 12")
+			}
+		})
+	}
+}
+
+func TestParseSharedWith(t *testing.T) {
+	assert.Equal(t, 1, parseSharedWith(""))
+	assert.Equal(t, 1, parseSharedWith("abc"))
+	assert.Equal(t, 1, parseSharedWith("0"))
+	assert.Equal(t, 3, parseSharedWith(" 3 "))
+	assert.Equal(t, maxSharedWith, parseSharedWith("5000"))
+}
+
+// setupHandlerTest creates a subscription handler backed by an in-memory database.
+// It has no currency service; tests that convert currencies set one.
+func setupHandlerTest(t *testing.T) (*SubscriptionHandler, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Settings{}, &models.Category{}, &models.Subscription{}, &models.ExchangeRate{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	settingsService := service.NewSettingsService(repository.NewSettingsRepository(db))
+	subscriptionService := service.NewSubscriptionService(repository.NewSubscriptionRepository(db), nil)
+	handler := NewSubscriptionHandler(subscriptionService, settingsService, nil, nil, nil, nil)
+	gin.SetMode(gin.TestMode)
+	return handler, db
+}
+
+func TestGetSubscriptionsAPI_RejectsBadSort(t *testing.T) {
+	handler, _ := setupHandlerTest(t)
+	router := gin.New()
+	router.GET("/api/v1/subscriptions", handler.GetSubscriptionsAPI)
+
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{query: "", expected: http.StatusOK},
+		{query: "?sort=name&order=asc", expected: http.StatusOK},
+		{query: "?sort=monthly_cost", expected: http.StatusOK},
+		{query: "?sort=password", expected: http.StatusBadRequest},
+		{query: "?sort=name%3B%20DROP%20TABLE%20subscriptions", expected: http.StatusBadRequest},
+		{query: "?sort=name&order=sideways", expected: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/subscriptions"+tt.query, nil))
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestEnrichWithCurrencyConversion_DisplayInOriginal(t *testing.T) {
+	t.Setenv("FIXER_API_KEY", "test-key")
+	handler, db := setupHandlerTest(t)
+
+	rateRepo := repository.NewExchangeRateRepository(db)
+	assert.NoError(t, rateRepo.SaveRates([]models.ExchangeRate{{BaseCurrency: "GBP", Currency: "USD", Rate: 1.25, Date: time.Now()}}))
+	handler.currencyService = service.NewCurrencyService(rateRepo)
+
+	enriched := handler.enrichWithCurrencyConversion([]models.Subscription{
+		{Name: "Domain", Cost: 10, Schedule: "Annual", OriginalCurrency: "GBP", DisplayInOriginal: true},
+		{Name: "Hosting", Cost: 10, Schedule: "Monthly", OriginalCurrency: "GBP"},
+	})
+
+	assert.False(t, enriched[0].ShowConversion)
+	assert.Equal(t, "GBP", enriched[0].DisplayCurrency)
+	assert.Equal(t, "£", enriched[0].DisplayCurrencySymbol)
+	assert.Equal(t, 10.0, enriched[0].ConvertedCost)
+
+	assert.True(t, enriched[1].ShowConversion)
+	assert.Equal(t, "USD", enriched[1].DisplayCurrency)
+	assert.Equal(t, "$", enriched[1].DisplayCurrencySymbol)
+	assert.InDelta(t, 12.5, enriched[1].ConvertedCost, 0.001)
+}
+
+// roundTripFunc lets a function stand in for an HTTP transport
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestEnrichWithCurrencyConversion_StaleRate(t *testing.T) {
+	t.Setenv("FIXER_API_KEY", "test-key")
+	handler, db := setupHandlerTest(t)
+
+	rateRepo := repository.NewExchangeRateRepository(db)
+	published := time.Now().Add(-3 * 24 * time.Hour)
+	assert.NoError(t, rateRepo.SaveRates([]models.ExchangeRate{
+		{BaseCurrency: "GBP", Currency: "USD", Rate: 1.25, Date: published},
+		{BaseCurrency: "EUR", Currency: "USD", Rate: 1.10, Date: time.Now()},
+		{BaseCurrency: "EUR", Currency: "EUR", Rate: 1.0, Date: time.Now()},
+	}))
+
+	// Fixer.io rejects the refresh, leaving only the stale cached rate
+	currencyService := service.NewCurrencyService(rateRepo)
+	currencyService.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"success":false,"error":{"code":101,"info":"Invalid access key"}}`)),
+			Request:    req,
+		}, nil
+	})})
+
+	handler.currencyService = currencyService
+
+	enriched := handler.enrichWithCurrencyConversion([]models.Subscription{
+		{Name: "Domain", Cost: 10, Schedule: "Annual", OriginalCurrency: "GBP"},
+		{Name: "Hosting", Cost: 10, Schedule: "Monthly", OriginalCurrency: "EUR"},
+	})
+
+	assert.True(t, enriched[0].ShowConversion, "A stale rate is still used when it can't be refreshed")
+	assert.InDelta(t, 12.5, enriched[0].ConvertedCost, 0.001)
+	assert.True(t, enriched[0].Stale)
+	if assert.NotNil(t, enriched[0].RateDate) {
+		assert.WithinDuration(t, published, *enriched[0].RateDate, time.Second)
+	}
+
+	assert.True(t, enriched[1].ShowConversion)
+	assert.InDelta(t, 11.0, enriched[1].ConvertedCost, 0.001)
+	assert.False(t, enriched[1].Stale)
+}
+
+func TestCreateSubscription_IdempotencyKey(t *testing.T) {
+	handler, db := setupHandlerTest(t)
+	handler.SetIdempotencyKeys(service.NewIdempotencyKeys(time.Hour))
+	category := models.Category{Name: "Streaming"}
+	db.Create(&category)
+
+	router := gin.New()
+	router.POST("/api/v1/subscriptions", func(c *gin.Context) {
+		// Stand in for APIKeyAuth, which records the authenticated key
+		id, _ := strconv.Atoi(c.GetHeader("X-Test-API-Key-ID"))
+		c.Set(middleware.APIKeyIDContextKey, uint(id))
+	}, handler.CreateSubscription)
+
+	create := func(apiKeyID int, key string) *httptest.ResponseRecorder {
+		form := url.Values{
+			"name":        {"Netflix"},
+			"cost":        {"15.99"},
+			"schedule":    {"Monthly"},
+			"status":      {"Active"},
+			"category_id": {strconv.Itoa(int(category.ID))},
+		}
+		req := httptest.NewRequest("POST", "/api/v1/subscriptions", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Test-API-Key-ID", strconv.Itoa(apiKeyID))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := create(1, "retry-1")
+	assert.Equal(t, http.StatusCreated, first.Code)
+	second := create(1, "retry-1")
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	var original, replayed models.Subscription
+	assert.NoError(t, json.Unmarshal(first.Body.Bytes(), &original))
+	assert.NoError(t, json.Unmarshal(second.Body.Bytes(), &replayed))
+	assert.Equal(t, original.ID, replayed.ID)
+
+	var count int64
+	db.Model(&models.Subscription{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	assert.Equal(t, http.StatusCreated, create(1, "retry-2").Code)
+	db.Model(&models.Subscription{}).Count(&count)
+	assert.Equal(t, int64(2), count, "a different key creates a new subscription")
+
+	other := create(2, "retry-1")
+	assert.Equal(t, http.StatusCreated, other.Code)
+	assert.Empty(t, other.Header().Get("Idempotent-Replayed"), "keys aren't shared between API keys")
+	db.Model(&models.Subscription{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestCreateSubscription_ValidationErrors(t *testing.T) {
+	handler, _ := setupHandlerTest(t)
+	router := gin.New()
+	router.POST("/api/v1/subscriptions", handler.CreateSubscription)
+
+	tests := []struct {
+		name   string
+		form   url.Values
+		fields map[string]string
+	}{
+		{
+			name:   "Missing name",
+			form:   url.Values{"cost": {"9.99"}, "schedule": {"Monthly"}, "status": {"Active"}},
+			fields: map[string]string{"name": "name is required"},
+		},
+		{
+			name:   "Zero cost",
+			form:   url.Values{"name": {"Netflix"}, "cost": {"0"}, "schedule": {"Monthly"}, "status": {"Active"}},
+			fields: map[string]string{"cost": "cost is required unless status is Trial"},
+		},
+		{
+			name:   "Negative cost",
+			form:   url.Values{"name": {"Netflix"}, "cost": {"-5"}, "schedule": {"Monthly"}, "status": {"Active"}},
+			fields: map[string]string{"cost": "cost cannot be negative"},
+		},
+		{
+			name: "Several fields",
+			form: url.Values{"cost": {"-5"}, "schedule": {"Hourly"}, "status": {"Active"}},
+			fields: map[string]string{
+				"name":     "name is required",
+				"cost":     "cost cannot be negative",
+				"schedule": `invalid schedule "Hourly", must be one of Monthly, Annual, Weekly, Daily, Quarterly`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/subscriptions", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			var body struct {
+				Error  string            `json:"error"`
+				Fields map[string]string `json:"fields"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.fields, body.Fields)
+		})
+	}
+
+	t.Run("HTMX requests keep the form errors partial", func(t *testing.T) {
+		router := gin.New()
+		router.SetHTMLTemplate(template.Must(template.New("form-errors.html").Parse(`{{.Error}}`)))
+		router.POST("/api/subscriptions", handler.CreateSubscription)
+
+		form := url.Values{"cost": {"9.99"}, "schedule": {"Monthly"}, "status": {"Active"}}
+		req := httptest.NewRequest("POST", "/api/subscriptions", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "#form-errors", w.Header().Get("HX-Retarget"))
+		assert.Equal(t, "name is required", w.Body.String())
+	})
+}
+
+func TestCreateSubscription_InvalidReminderDaysOverride(t *testing.T) {
+	handler, _ := setupHandlerTest(t)
+	router := gin.New()
+	router.POST("/api/v1/subscriptions", handler.CreateSubscription)
+
+	form := url.Values{"name": {"Netflix"}, "cost": {"9.99"}, "schedule": {"Monthly"}, "status": {"Active"}, "reminder_days_override": {"seven"}}
+	req := httptest.NewRequest("POST", "/api/v1/subscriptions", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "reminder_days_override must be a whole number")
+
+	subscriptions, err := handler.service.GetAll()
+	assert.NoError(t, err)
+	assert.Empty(t, subscriptions, "Nothing is created when the override is invalid")
+}
+
+func TestParseIntPtr(t *testing.T) {
+	number, err := parseIntPtr("days", "")
+	assert.NoError(t, err)
+	assert.Nil(t, number)
+
+	number, err = parseIntPtr("days", " 14 ")
+	assert.NoError(t, err)
+	if assert.NotNil(t, number) {
+		assert.Equal(t, 14, *number)
+	}
+
+	_, err = parseIntPtr("days", "1.5")
+	assert.EqualError(t, err, "days must be a whole number")
+}
+
+func TestPatchSubscription_LegacyZeroCost(t *testing.T) {
+	handler, db := setupHandlerTest(t)
+	router := gin.New()
+	router.PATCH("/api/v1/subscriptions/:id", handler.PatchSubscription)
+
+	// Saved before a zero cost was limited to trials
+	legacy := models.Subscription{Name: "Free Tier", Cost: 0, Schedule: "Monthly", Status: "Active"}
+	assert.NoError(t, db.Create(&legacy).Error)
+	paid := models.Subscription{Name: "Netflix", Cost: 15.99, Schedule: "Monthly", Status: "Active"}
+	assert.NoError(t, db.Create(&paid).Error)
+
+	patch := func(id uint, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", "/api/v1/subscriptions/"+strconv.FormatUint(uint64(id), 10), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, patch(legacy.ID, `{"notes": "Still free"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, patch(paid.ID, `{"cost": 0}`).Code)
+}
+
+func TestGetRenewalCalendar_TotalsInDisplayCurrency(t *testing.T) {
+	// Without a Fixer API key euros can't be converted to dollars
+	t.Setenv("FIXER_API_KEY", "")
+
+	handler, db := setupHandlerTest(t)
+	handler.settingsService.SetCurrency("USD")
+	handler.currencyService = service.NewCurrencyService(repository.NewExchangeRateRepository(db))
+
+	router := gin.New()
+	router.GET("/api/v1/stats/renewal-calendar", handler.GetRenewalCalendar)
+
+	now := time.Now()
+	tomorrow := now.AddDate(0, 0, 1)
+	nextMonth := time.Date(now.Year(), now.Month()+1, 15, 0, 0, 0, 0, now.Location())
+	assert.NoError(t, db.Create(&models.Subscription{Name: "Netflix", Cost: 10, OriginalCurrency: "USD", Schedule: "Monthly", Status: "Active", RenewalDate: &tomorrow}).Error)
+	assert.NoError(t, db.Create(&models.Subscription{Name: "Domain", Cost: 12, OriginalCurrency: "EUR", Schedule: "Annual", Status: "Active", RenewalDate: &nextMonth}).Error)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/stats/renewal-calendar?months=2", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Calendar []models.RenewalMonth `json:"calendar"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	if assert.Len(t, body.Calendar, 2) {
+		assert.NotNil(t, body.Calendar[0].Total, "Every charge this month is in dollars")
+		assert.Nil(t, body.Calendar[1].Total, "Euros and dollars aren't added up")
+	}
+}
+
+func TestPreviewRenewal(t *testing.T) {
+	handler := &SubscriptionHandler{}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/subscriptions/preview-renewal", handler.PreviewRenewal)
+
+	preview := func(query string) (int, map[string]any) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/subscriptions/preview-renewal?"+query, nil))
+		var body map[string]any
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body
+	}
+	// Start dates are in the future so the renewals don't depend on today's date:
+	// V1 returns the first period after the start, V2 the start itself
+	t.Run("Month-end monthly start clamps to February", func(t *testing.T) {
+		code, body := preview("schedule=Monthly&start=2099-01-31")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "2099-02-28", body["v1"])
+		assert.Equal(t, "2099-01-31", body["v2"])
+		assert.Equal(t, body["v1"], body["renewal_date"], "version defaults to 1")
+	})
+
+	t.Run("Month-end start lands on a leap day", func(t *testing.T) {
+		code, body := preview("schedule=Monthly&interval=2&start=2095-12-31")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "2096-02-29", body["v1"])
+	})
+
+	t.Run("Leap day annual start overflows into March in a common year", func(t *testing.T) {
+		code, body := preview("schedule=Annual&start=2096-02-29&version=2")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "2097-03-01", body["v1"])
+		assert.Equal(t, "2096-02-29", body["v2"])
+		assert.Equal(t, body["v2"], body["renewal_date"])
+		assert.Equal(t, true, body["differs"])
+	})
+
+	t.Run("Interval is applied", func(t *testing.T) {
+		code, body := preview("schedule=Weekly&interval=2&start=2099-01-01")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, float64(2), body["interval"])
+		assert.Equal(t, "2099-01-15", body["v1"])
+	})
+
+	for _, query := range []string{"", "schedule=Hourly", "schedule=Monthly&start=31/01/2025", "schedule=Monthly&version=3"} {
+		t.Run("Rejects "+query, func(t *testing.T) {
+			code, body := preview(query)
+			assert.Equal(t, http.StatusBadRequest, code)
+			assert.NotEmpty(t, body["error"])
+		})
+	}
+}
+
+func TestExportCSV_BOM(t *testing.T) {
+	handler, db := setupHandlerTest(t)
+	db.Create(&models.Subscription{Name: "Café Pass", Cost: 9.99, OriginalCurrency: "EUR", Schedule: "Monthly", Status: "Active"})
+
+	router := gin.New()
+	router.GET("/api/v1/export/csv", handler.ExportCSV)
+	export := func(query string) string {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/export/csv"+query, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		return w.Body.String()
+	}
+
+	t.Run("Writes a BOM when requested", func(t *testing.T) {
+		body := export("?bom=1")
+		assert.True(t, strings.HasPrefix(body, "\xEF\xBB\xBFID,Name,"), "The BOM should come before the header")
+		assert.Contains(t, body, "Café Pass")
+	})
+
+	t.Run("Leaves the BOM out by default", func(t *testing.T) {
+		assert.True(t, strings.HasPrefix(export(""), "ID,Name,"))
+	})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"subtrackr/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// summaryUpcomingDays is the window the summary counts upcoming renewals in
+const summaryUpcomingDays = 7
+
+// GetSummary returns a compact overview for home dashboard widgets such as
+// Homepage or Glance: monthly spend in the display currency, the active count,
+// renewals in the next week and the next renewal due.
+func (h *SubscriptionHandler) GetSummary(c *gin.Context) {
+	active, err := h.service.GetActive()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	upcoming, err := h.service.GetUpcomingRenewals(summaryUpcomingDays)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	displayCurrency := h.settingsService.GetCurrency()
+	summary := models.Summary{
+		Currency:            displayCurrency,
+		ActiveSubscriptions: len(active),
+		UpcomingRenewals:    len(upcoming),
+	}
+
+	now := time.Now()
+	for _, sub := range active {
+		monthly, _ := h.toDisplayCurrency(sub.MonthlyCost(), sub.OriginalCurrency, displayCurrency)
+		summary.MonthlySpend += monthly
+
+		if sub.RenewalDate == nil || sub.RenewalDate.Before(now) {
+			continue
+		}
+		if summary.NextRenewal == nil || sub.RenewalDate.Before(summary.NextRenewal.Date) {
+			summary.NextRenewal = &models.SummaryRenewal{Name: sub.Name, Date: *sub.RenewalDate}
+		}
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// toDisplayCurrency converts amount when conversion is enabled, returning it
+// with the currency it is now in. An amount without a currency is already in
+// displayCurrency; one whose rate isn't available is returned unchanged.
+func (h *SubscriptionHandler) toDisplayCurrency(amount float64, currency, displayCurrency string) (float64, string) {
+	if currency == "" {
+		return amount, displayCurrency
+	}
+	if currency == displayCurrency || !h.currencyService.IsEnabled() {
+		return amount, currency
+	}
+	converted, err := h.currencyService.ConvertAmount(amount, currency, displayCurrency)
+	if err != nil {
+		slog.Debug("Failed to convert currency, using the unconverted amount", "from", currency, "to", displayCurrency, "error", err)
+		return amount, currency
+	}
+	return converted, displayCurrency
+}
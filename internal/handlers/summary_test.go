@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSummary(t *testing.T) {
+	t.Setenv("FIXER_API_KEY", "test-key")
+	handler, db := setupHandlerTest(t)
+
+	rateRepo := repository.NewExchangeRateRepository(db)
+	assert.NoError(t, rateRepo.SaveRates([]models.ExchangeRate{{BaseCurrency: "GBP", Currency: "USD", Rate: 1.25, Date: time.Now()}}))
+	handler.currencyService = service.NewCurrencyService(rateRepo)
+
+	_, err := handler.service.Create(&models.Subscription{Name: "Netflix", Cost: 10, OriginalCurrency: "USD", Schedule: "Monthly", Status: "Active"})
+	assert.NoError(t, err)
+	hosting, err := handler.service.Create(&models.Subscription{Name: "Hosting", Cost: 20, OriginalCurrency: "GBP", Schedule: "Monthly", Status: "Active"})
+	assert.NoError(t, err)
+	_, err = handler.service.Create(&models.Subscription{Name: "Hulu", Cost: 8, OriginalCurrency: "USD", Schedule: "Monthly", Status: "Cancelled"})
+	assert.NoError(t, err)
+
+	soon := time.Now().AddDate(0, 0, 3)
+	assert.NoError(t, db.Model(&models.Subscription{}).Where("id = ?", hosting.ID).UpdateColumn("renewal_date", soon).Error)
+
+	router := gin.New()
+	router.GET("/api/v1/summary", handler.GetSummary)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/summary", nil))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Widgets depend on exactly these fields
+	var fields map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &fields))
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	assert.ElementsMatch(t, []string{"currency", "monthly_spend", "active_subscriptions", "upcoming_renewals", "next_renewal"}, keys)
+
+	var summary models.Summary
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+	assert.Equal(t, "USD", summary.Currency)
+	assert.InDelta(t, 10+20*1.25, summary.MonthlySpend, 0.001, "GBP is converted to the display currency")
+	assert.Equal(t, 2, summary.ActiveSubscriptions)
+	assert.Equal(t, 1, summary.UpcomingRenewals)
+	if assert.NotNil(t, summary.NextRenewal) {
+		assert.Equal(t, "Hosting", summary.NextRenewal.Name)
+		assert.WithinDuration(t, soon, summary.NextRenewal.Date, time.Second)
+	}
+}
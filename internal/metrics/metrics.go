@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"subtrackr/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+var (
+	// ReminderEmails counts reminder emails by type (renewal, cancellation) and result (sent, failed)
+	ReminderEmails = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "subtrackr_reminder_emails_total",
+		Help: "Reminder emails attempted, by reminder type and result.",
+	}, []string{"type", "result"})
+
+	// WebhookFailures counts webhook deliveries that errored or got a non-2xx response
+	WebhookFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "subtrackr_webhook_failures_total",
+		Help: "Webhook deliveries that failed.",
+	})
+)
+
+// RecordReminderEmail counts one reminder email of the given type as sent or failed
+func RecordReminderEmail(reminderType string, err error) {
+	result := "sent"
+	if err != nil {
+		result = "failed"
+	}
+	ReminderEmails.WithLabelValues(reminderType, result).Inc()
+}
+
+// StatsSource provides the subscription figures reported as gauges
+type StatsSource interface {
+	Count() int64
+	GetStats() (*models.Stats, error)
+}
+
+var (
+	subscriptionsDesc = prometheus.NewDesc("subtrackr_subscriptions", "Subscriptions stored.", nil, nil)
+	activeDesc        = prometheus.NewDesc("subtrackr_active_subscriptions", "Subscriptions with status Active.", nil, nil)
+	monthlySpendDesc  = prometheus.NewDesc("subtrackr_monthly_spend", "Total monthly spend of active subscriptions, summed without currency conversion.", nil, nil)
+)
+
+// subscriptionCollector reads the subscription gauges from the database on each scrape
+type subscriptionCollector struct {
+	source StatsSource
+}
+
+func (c subscriptionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- subscriptionsDesc
+	ch <- activeDesc
+	ch <- monthlySpendDesc
+}
+
+func (c subscriptionCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(subscriptionsDesc, prometheus.GaugeValue, float64(c.source.Count()))
+
+	stats, err := c.source.GetStats()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(activeDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(activeDesc, prometheus.GaugeValue, float64(stats.ActiveSubscriptions))
+	ch <- prometheus.MustNewConstMetric(monthlySpendDesc, prometheus.GaugeValue, stats.TotalMonthlySpend)
+}
+
+// NewRegistry returns a registry with the SubTrackr metrics plus the standard Go
+// runtime and process collectors
+func NewRegistry(source StatsSource) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		ReminderEmails,
+		WebhookFailures,
+		subscriptionCollector{source: source},
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
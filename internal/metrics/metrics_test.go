@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"subtrackr/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStats struct {
+	count int64
+	stats *models.Stats
+	err   error
+}
+
+func (f fakeStats) Count() int64                     { return f.count }
+func (f fakeStats) GetStats() (*models.Stats, error) { return f.stats, f.err }
+
+// gather returns the value of each unlabelled gauge or counter family in the registry
+func gather(t *testing.T, source StatsSource) map[string]float64 {
+	families, err := NewRegistry(source).Gather()
+	assert.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			if len(m.GetLabel()) > 0 {
+				continue
+			}
+			if m.GetGauge() != nil {
+				values[family.GetName()] = m.GetGauge().GetValue()
+			} else if m.GetCounter() != nil {
+				values[family.GetName()] = m.GetCounter().GetValue()
+			}
+		}
+	}
+	return values
+}
+
+func TestNewRegistry_SubscriptionGauges(t *testing.T) {
+	values := gather(t, fakeStats{count: 5, stats: &models.Stats{ActiveSubscriptions: 3, TotalMonthlySpend: 42.5}})
+
+	assert.Equal(t, 5.0, values["subtrackr_subscriptions"])
+	assert.Equal(t, 3.0, values["subtrackr_active_subscriptions"])
+	assert.Equal(t, 42.5, values["subtrackr_monthly_spend"])
+	assert.Contains(t, values, "subtrackr_webhook_failures_total")
+}
+
+func TestNewRegistry_StatsError(t *testing.T) {
+	_, err := NewRegistry(fakeStats{err: errors.New("db down")}).Gather()
+	assert.ErrorContains(t, err, "db down")
+}
+
+func TestRecordReminderEmail(t *testing.T) {
+	sent := ReminderEmails.WithLabelValues("renewal", "sent")
+	failed := ReminderEmails.WithLabelValues("renewal", "failed")
+	before := map[string]float64{"sent": counterValue(t, sent), "failed": counterValue(t, failed)}
+
+	RecordReminderEmail("renewal", nil)
+	RecordReminderEmail("renewal", nil)
+	RecordReminderEmail("renewal", errors.New("smtp down"))
+
+	assert.Equal(t, before["sent"]+2, counterValue(t, sent))
+	assert.Equal(t, before["failed"]+1, counterValue(t, failed))
+}
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	var m dto.Metric
+	assert.NoError(t, c.Write(&m))
+	return m.GetCounter().GetValue()
+}
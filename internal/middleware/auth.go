@@ -9,8 +9,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware creates middleware that requires authentication
+// AuthMiddleware creates middleware that requires authentication.
+// It is a no-op when authentication is disabled by configuration.
 func AuthMiddleware(settingsService *service.SettingsService, sessionService *service.SessionService) gin.HandlerFunc {
+	if settingsService.IsAuthDisabledByConfig() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
 	return func(c *gin.Context) {
 		// Check if auth is enabled
 		if !settingsService.IsAuthEnabled() {
@@ -57,6 +64,8 @@ func isPublicRoute(path string) bool {
 		"/static/",
 		"/favicon.ico",
 		"/healthz",
+		"/readyz",
+		"/metrics",
 		"/ical/",
 	}
 
@@ -80,6 +89,9 @@ func isHTMLRequest(r *http.Request) bool {
 	return strings.Contains(accept, "text/html") || accept == ""
 }
 
+// APIKeyIDContextKey is the gin context key holding the authenticated API key's ID
+const APIKeyIDContextKey = "apiKeyID"
+
 // APIKeyAuth creates middleware that requires API key authentication
 func APIKeyAuth(settingsService *service.SettingsService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -100,13 +112,30 @@ func APIKeyAuth(settingsService *service.SettingsService) gin.HandlerFunc {
 		}
 
 		// Validate API key
-		_, err := settingsService.ValidateAPIKey(apiKey)
+		key, err := settingsService.ValidateAPIKey(apiKey)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
 			c.Abort()
 			return
 		}
+		c.Set(APIKeyIDContextKey, key.ID)
+
+		// Read-only keys may only use safe methods
+		if !key.CanWrite() && !isReadMethod(c.Request.Method) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key is read-only"})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
 }
+
+// isReadMethod reports whether an HTTP method never modifies data
+func isReadMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"subtrackr/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAPIKeyTest(t *testing.T) (*gin.Engine, *service.SettingsService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Settings{}, &models.APIKey{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	settingsService := service.NewSettingsService(repository.NewSettingsRepository(db))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	v1 := router.Group("/api/v1")
+	v1.Use(APIKeyAuth(settingsService))
+	v1.GET("/subscriptions", func(c *gin.Context) {
+		c.JSON(http.StatusOK, []models.Subscription{})
+	})
+	v1.POST("/subscriptions", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, models.Subscription{ID: 1})
+	})
+	return router, settingsService
+}
+
+func TestAPIKeyAuth_Scopes(t *testing.T) {
+	router, settingsService := setupAPIKeyTest(t)
+
+	_, err := settingsService.CreateAPIKey("Reader", "sk_read", models.APIKeyScopeRead)
+	assert.NoError(t, err)
+	_, err = settingsService.CreateAPIKey("Writer", "sk_write", models.APIKeyScopeWrite)
+	assert.NoError(t, err)
+	_, err = settingsService.CreateAPIKey("Legacy", "sk_legacy", "")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		method   string
+		key      string
+		expected int
+	}{
+		{"Read key can list", "GET", "sk_read", http.StatusOK},
+		{"Read key cannot create", "POST", "sk_read", http.StatusForbidden},
+		{"Write key can create", "POST", "sk_write", http.StatusCreated},
+		{"Key without scope defaults to write", "POST", "sk_legacy", http.StatusCreated},
+		{"Unknown key is rejected", "POST", "sk_unknown", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/api/v1/subscriptions", nil)
+			req.Header.Set("X-API-Key", tt.key)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestCreateAPIKey_InvalidScope(t *testing.T) {
+	_, settingsService := setupAPIKeyTest(t)
+
+	_, err := settingsService.CreateAPIKey("Bad", "sk_bad", "admin")
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tokenBucket holds the remaining request allowance for one API key
+type tokenBucket struct {
+	tokens   float64
+	lastFill time.Time
+}
+
+// RateLimiter is an in-memory token bucket limiter keyed by API key ID.
+// Each key may burst up to the per-minute limit, refilling continuously.
+type RateLimiter struct {
+	mu       sync.Mutex
+	buckets  map[uint]*tokenBucket
+	capacity float64
+	perSec   float64
+	now      func() time.Time
+}
+
+// NewRateLimiter creates a limiter allowing requestsPerMinute requests per API key.
+// A limit of zero or less disables rate limiting.
+func NewRateLimiter(requestsPerMinute int) *RateLimiter {
+	return &RateLimiter{
+		buckets:  make(map[uint]*tokenBucket),
+		capacity: float64(requestsPerMinute),
+		perSec:   float64(requestsPerMinute) / 60,
+		now:      time.Now,
+	}
+}
+
+// allow consumes a token for the key, returning how long to wait when none are left
+func (l *RateLimiter) allow(keyID uint) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	bucket, ok := l.buckets[keyID]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.capacity, lastFill: now}
+		l.buckets[keyID] = bucket
+	}
+
+	elapsed := now.Sub(bucket.lastFill).Seconds()
+	bucket.tokens = math.Min(l.capacity, bucket.tokens+elapsed*l.perSec)
+	bucket.lastFill = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - bucket.tokens) / l.perSec * float64(time.Second))
+	return false, wait
+}
+
+// Middleware returns a handler that throttles requests per API key.
+// It must run after APIKeyAuth, which records the authenticated key ID.
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.capacity <= 0 {
+			c.Next()
+			return
+		}
+
+		keyID, ok := c.Get(APIKeyIDContextKey)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		allowed, wait := l.allow(keyID.(uint))
+		if !allowed {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRateLimitRouter(limiter *RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		// Stand in for APIKeyAuth, taking the key ID from a test header
+		if c.GetHeader("X-Test-Key") == "2" {
+			c.Set(APIKeyIDContextKey, uint(2))
+		} else {
+			c.Set(APIKeyIDContextKey, uint(1))
+		}
+		c.Next()
+	})
+	router.Use(limiter.Middleware())
+	router.GET("/api/v1/stats", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func doRequest(router *gin.Engine, key string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/stats", nil)
+	req.Header.Set("X-Test-Key", key)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiter_RejectsAfterLimit(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(60)
+	limiter.now = func() time.Time { return now }
+	router := setupRateLimitRouter(limiter)
+
+	for i := 0; i < 60; i++ {
+		assert.Equal(t, http.StatusOK, doRequest(router, "1").Code, "request %d", i+1)
+	}
+
+	w := doRequest(router, "1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	// Other keys have their own bucket
+	assert.Equal(t, http.StatusOK, doRequest(router, "2").Code)
+
+	// After a full window the bucket is refilled
+	now = now.Add(time.Minute)
+	for i := 0; i < 60; i++ {
+		assert.Equal(t, http.StatusOK, doRequest(router, "1").Code, "request %d after reset", i+1)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(router, "1").Code)
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	router := setupRateLimitRouter(NewRateLimiter(0))
+
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, http.StatusOK, doRequest(router, "1").Code)
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panic in a handler into a 500 response. Browser and htmx
+// requests get the error page, API requests get JSON. The panic is logged with
+// the request path and stack.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		slog.Error("Panic while handling request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"panic", recovered,
+			"stack", string(debug.Stack()))
+
+		if wantsJSON(c.Request) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": "An unexpected error occurred. Please try again."})
+		c.Abort()
+	})
+}
+
+// wantsJSON reports whether an error response should be JSON rather than HTML
+func wantsJSON(r *http.Request) bool {
+	if r.Header.Get("HX-Request") != "" {
+		return false
+	}
+	return strings.HasPrefix(r.URL.Path, "/api/") || !isHTMLRequest(r)
+}
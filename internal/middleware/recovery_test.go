@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRecoveryTest() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.LoadHTMLFiles("../../templates/error.html")
+	router.Use(Recovery())
+
+	panics := func(c *gin.Context) { panic("boom") }
+	router.GET("/dashboard", panics)
+	router.GET("/api/subscriptions", panics)
+	return router
+}
+
+func TestRecovery(t *testing.T) {
+	router := setupRecoveryTest()
+
+	tests := []struct {
+		name     string
+		path     string
+		headers  map[string]string
+		wantHTML bool
+	}{
+		{"Browser request gets the error page", "/dashboard", map[string]string{"Accept": "text/html,application/xhtml+xml"}, true},
+		{"htmx request to the API gets the error page", "/api/subscriptions", map[string]string{"HX-Request": "true"}, true},
+		{"API request gets JSON", "/api/subscriptions", map[string]string{"Accept": "application/json"}, false},
+		{"API request without Accept gets JSON", "/api/subscriptions", nil, false},
+		{"Non-HTML client gets JSON", "/dashboard", map[string]string{"Accept": "application/json"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+			if tt.wantHTML {
+				assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+				assert.Contains(t, w.Body.String(), "Something went wrong")
+				assert.NotContains(t, w.Body.String(), "boom")
+			} else {
+				var body map[string]string
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, "Internal server error", body["error"])
+			}
+		})
+	}
+}
@@ -1,11 +1,47 @@
 package models
 
-import "time"
+import (
+	"hash/fnv"
+	"regexp"
+	"time"
+)
 
 // Category represents a subscription category
 type Category struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	Name      string    `json:"name" gorm:"uniqueIndex;not null"`
+	Color     string    `json:"color"` // #rrggbb, derived from the name when empty
+	Icon      string    `json:"icon"`
+	SortOrder int       `json:"sort_order" gorm:"default:0"` // Position in lists, lowest first
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
+
+// categoryPalette holds the colors handed out to categories without one
+var categoryPalette = []string{
+	"#3b82f6", "#10b981", "#f59e0b", "#ef4444", "#8b5cf6",
+	"#ec4899", "#14b8a6", "#f97316", "#6366f1", "#84cc16",
+}
+
+var categoryColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// IsValidCategoryColor reports whether color is a #rrggbb hex string
+func IsValidCategoryColor(color string) bool {
+	return categoryColorPattern.MatchString(color)
+}
+
+// DefaultCategoryColor picks a palette color from the name, so a category keeps
+// the same color across page loads without one being stored
+func DefaultCategoryColor(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return categoryPalette[h.Sum32()%uint32(len(categoryPalette))]
+}
+
+// DisplayColor returns the category's color, or its name-derived default
+func (c Category) DisplayColor() string {
+	if c.Color != "" {
+		return c.Color
+	}
+	return DefaultCategoryColor(c.Name)
+}
@@ -75,6 +75,13 @@ func (dmsc *DateMigrationSafetyCheck) CompareCalculationVersions(subscriptionID
 		return nil, nil, err
 	}
 
+	V1Date, V2Date = compareCalculationVersions(sub)
+	return V1Date, V2Date, nil
+}
+
+// compareCalculationVersions calculates the next renewal date of an already
+// loaded subscription with both V1 and V2, without changing it
+func compareCalculationVersions(sub Subscription) (V1Date, V2Date *time.Time) {
 	// Calculate V1
 	subV1 := sub
 	subV1.DateCalculationVersion = 1
@@ -87,7 +94,46 @@ func (dmsc *DateMigrationSafetyCheck) CompareCalculationVersions(subscriptionID
 	subV2.calculateNextRenewalDate()
 	V2Date = subV2.RenewalDate
 
-	return V1Date, V2Date, nil
+	return V1Date, V2Date
+}
+
+// VersionComparison is the V1 and V2 renewal date calculated for one subscription
+type VersionComparison struct {
+	SubscriptionID uint       `json:"subscription_id"`
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`
+	CurrentVersion int        `json:"current_version"`
+	V1Date         *time.Time `json:"v1_date"`
+	V2Date         *time.Time `json:"v2_date"`
+	DiffDays       float64    `json:"diff_days"` // V2 minus V1, in whole days
+}
+
+// CompareAllCalculationVersions compares V1 and V2 calculations for every subscription without changing data.
+// Subscriptions are loaded in a single query.
+func (dmsc *DateMigrationSafetyCheck) CompareAllCalculationVersions() ([]VersionComparison, error) {
+	var subscriptions []Subscription
+	if err := dmsc.db.Order("id ASC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	comparisons := make([]VersionComparison, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		v1Date, v2Date := compareCalculationVersions(sub)
+
+		comparison := VersionComparison{
+			SubscriptionID: sub.ID,
+			Name:           sub.Name,
+			Schedule:       sub.Schedule,
+			CurrentVersion: sub.DateCalculationVersion,
+			V1Date:         v1Date,
+			V2Date:         v2Date,
+		}
+		if v1Date != nil && v2Date != nil {
+			comparison.DiffDays = v2Date.Sub(*v1Date).Truncate(24*time.Hour).Hours() / 24
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	return comparisons, nil
 }
 
 // BatchMigrateToV2WithAudit migrates all subscriptions to V2 with comprehensive auditing
@@ -202,4 +248,4 @@ func (dmsc *DateMigrationSafetyCheck) GetMigrationStats() (map[string]interface{
 	stats["rollbacks"] = rollbackCount
 
 	return stats, nil
-}
\ No newline at end of file
+}
@@ -63,6 +63,33 @@ func TestCompareCalculationVersions(t *testing.T) {
 	assert.True(t, v2Date.After(time.Now()), "V2 date should be in future")
 }
 
+func TestCompareAllCalculationVersions_SingleQuery(t *testing.T) {
+	db := setupAuditTestDB(t)
+	safety := NewDateMigrationSafetyCheck(db)
+
+	startDate := time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC)
+	for _, schedule := range []string{"Monthly", "Annual", "Weekly"} {
+		sub := &Subscription{Name: schedule, Cost: 10, Schedule: schedule, Status: "Active", StartDate: &startDate, DateCalculationVersion: 1}
+		assert.NoError(t, db.Create(sub).Error)
+	}
+
+	queries := 0
+	assert.NoError(t, db.Callback().Query().After("gorm:query").Register("count_queries", func(*gorm.DB) { queries++ }))
+
+	comparisons, err := safety.CompareAllCalculationVersions()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, queries, "Subscriptions should be loaded once, not once per subscription")
+
+	if assert.Len(t, comparisons, 3) {
+		for _, comparison := range comparisons {
+			v1Date, v2Date, err := safety.CompareCalculationVersions(comparison.SubscriptionID)
+			assert.NoError(t, err)
+			assert.Equal(t, v1Date, comparison.V1Date, comparison.Name)
+			assert.Equal(t, v2Date, comparison.V2Date, comparison.Name)
+		}
+	}
+}
+
 func TestGetMigrationStats(t *testing.T) {
 	db := setupAuditTestDB(t)
 	safety := NewDateMigrationSafetyCheck(db)
@@ -98,4 +125,4 @@ func TestGetMigrationStats(t *testing.T) {
 	assert.Equal(t, int64(2), stats["v1_subscriptions"], "Should have 2 V1 subscriptions")
 	assert.Equal(t, int64(1), stats["v2_subscriptions"], "Should have 1 V2 subscription")
 	assert.Equal(t, int64(1), stats["total_migrations"], "Should have 1 migration logged")
-}
\ No newline at end of file
+}
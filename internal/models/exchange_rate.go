@@ -4,6 +4,9 @@ import (
 	"time"
 )
 
+// ExchangeRateMaxAge is how long a cached exchange rate is used before it's refetched
+const ExchangeRateMaxAge = 24 * time.Hour
+
 // ExchangeRate represents currency exchange rate data
 type ExchangeRate struct {
 	ID           uint      `json:"id" gorm:"primaryKey"`
@@ -15,7 +18,12 @@ type ExchangeRate struct {
 	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
-// IsStale checks if the exchange rate is older than 24 hours
+// IsStale checks if the exchange rate is older than ExchangeRateMaxAge
 func (er *ExchangeRate) IsStale() bool {
-	return time.Since(er.Date) > 24*time.Hour
-}
\ No newline at end of file
+	return IsRateStale(er.Date)
+}
+
+// IsRateStale checks if a rate published at date is older than ExchangeRateMaxAge
+func IsRateStale(date time.Time) bool {
+	return time.Since(date) > ExchangeRateMaxAge
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// NotificationLog records one attempt to deliver a notification through one
+// channel, so failed reminders can be diagnosed after the fact
+type NotificationLog struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	Channel          string    `json:"channel" gorm:"size:20;not null"`
+	Event            string    `json:"event" gorm:"size:50;not null"`
+	SubscriptionID   uint      `json:"subscription_id"`
+	SubscriptionName string    `json:"subscription_name" gorm:"size:255"`
+	Success          bool      `json:"success"`
+	Error            string    `json:"error,omitempty"`
+	Receipt          string    `json:"receipt,omitempty" gorm:"size:64"` // Pushover emergency receipt, for tracking acknowledgement
+	CreatedAt        time.Time `json:"created_at" gorm:"index"`
+}
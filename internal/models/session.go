@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Session is a server-side record of a logged-in browser session.
+// Deleting the record revokes the session even if its cookie is still valid.
+type Session struct {
+	ID        string    `json:"id" gorm:"primaryKey;size:64"`
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	LastSeen  time.Time `json:"last_seen"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	IsCurrent bool      `json:"is_current" gorm:"-"` // Not stored in DB, just for display
+}
@@ -26,14 +26,33 @@ type SMTPConfig struct {
 
 // PushoverConfig represents Pushover notification configuration
 type PushoverConfig struct {
-	UserKey  string `json:"pushover_user_key"`  // Pushover user key
-	AppToken string `json:"pushover_app_token"` // Pushover application token
+	UserKey           string `json:"pushover_user_key"`                      // Pushover user key
+	AppToken          string `json:"pushover_app_token"`                     // Pushover application token
+	Device            string `json:"pushover_device,omitempty"`              // Device name(s) to notify; all devices when empty
+	Sound             string `json:"pushover_sound,omitempty"`               // Notification sound; the user's default when empty
+	HighPrioritySound string `json:"pushover_high_priority_sound,omitempty"` // Sound for high-priority notifications such as high-cost alerts; Sound when empty
+
+	// High-cost alerts above EmergencyThreshold use emergency priority, which
+	// repeats every EmergencyRetry seconds until acknowledged or EmergencyExpire
+	// seconds pass. A zero threshold turns emergency alerts off.
+	EmergencyThreshold float64 `json:"pushover_emergency_threshold,omitempty"`
+	EmergencyRetry     int     `json:"pushover_emergency_retry,omitempty"`
+	EmergencyExpire    int     `json:"pushover_emergency_expire,omitempty"`
 }
 
 // WebhookConfig represents generic webhook notification configuration
 type WebhookConfig struct {
-	URL     string            `json:"webhook_url"`
-	Headers map[string]string `json:"webhook_headers"`
+	URL                string            `json:"webhook_url"`
+	Headers            map[string]string `json:"webhook_headers"`
+	TimeoutSeconds     int               `json:"webhook_timeout_seconds,omitempty"`      // 0 uses the default timeout
+	InsecureSkipVerify bool              `json:"webhook_insecure_skip_verify,omitempty"` // Accept any TLS certificate, e.g. a self-signed one; insecure
+}
+
+// AppriseConfig represents Apprise notification configuration. Notifications go
+// through an Apprise API server, which delivers them to every URL.
+type AppriseConfig struct {
+	URLs      []string `json:"apprise_urls"`       // Apprise service URLs, e.g. discord://... or tgram://...
+	ServerURL string   `json:"apprise_server_url"` // Apprise API server; the default is used when empty
 }
 
 // NotificationSettings represents notification preferences
@@ -41,19 +60,56 @@ type NotificationSettings struct {
 	RenewalReminders         bool    `json:"renewal_reminders"`
 	HighCostAlerts           bool    `json:"high_cost_alerts"`
 	HighCostThreshold        float64 `json:"high_cost_threshold"`
+	HighCostBasis            string  `json:"high_cost_basis"` // "monthly" or "annual"
 	ReminderDays             int     `json:"reminder_days"`
 	CancellationReminders    bool    `json:"cancellation_reminders"`
 	CancellationReminderDays int     `json:"cancellation_reminder_days"`
+	// Per-channel switches; a disabled channel sends no alerts or reminders
+	NotifyEmail    bool `json:"notify_email"`
+	NotifyPushover bool `json:"notify_pushover"`
+	NotifyWebhook  bool `json:"notify_webhook"`
+	NotifyApprise  bool `json:"notify_apprise"`
+	// Push notifications are held back from QuietHoursStart to QuietHoursEnd
+	// ("HH:MM", empty when off)
+	QuietHoursStart string `json:"quiet_hours_start"`
+	QuietHoursEnd   string `json:"quiet_hours_end"`
+	// Hour of the day (0-23) reminders are sent at, nil when they're sent daily from startup
+	ReminderSendHour *int `json:"reminder_send_hour"`
 }
 
+// API key scopes
+const (
+	APIKeyScopeRead  = "read"
+	APIKeyScopeWrite = "write"
+)
+
 // APIKey represents an API key for external access
 type APIKey struct {
 	ID         uint       `json:"id" gorm:"primaryKey"`
 	Name       string     `json:"name" gorm:"not null"`
 	Key        string     `json:"key" gorm:"uniqueIndex;not null"`
+	Scope      string     `json:"scope" gorm:"not null;default:'write'"` // "read" or "write"
 	LastUsed   *time.Time `json:"last_used"`
 	UsageCount int        `json:"usage_count" gorm:"default:0"`
 	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 	IsNew      bool       `json:"is_new" gorm:"-"` // Not stored in DB, just for display
 }
+
+// apiKeyStaleAfter is how long a key can go unused before it is flagged as stale
+const apiKeyStaleAfter = 90 * 24 * time.Hour
+
+// IsStale reports whether the key has gone unused for a long time,
+// counting from creation for keys that were never used
+func (k *APIKey) IsStale() bool {
+	since := k.CreatedAt
+	if k.LastUsed != nil {
+		since = *k.LastUsed
+	}
+	return time.Since(since) > apiKeyStaleAfter
+}
+
+// CanWrite reports whether the key may create, update or delete data
+func (k *APIKey) CanWrite() bool {
+	return k.Scope != APIKeyScopeRead
+}
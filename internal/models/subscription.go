@@ -1,20 +1,100 @@
 package models
 
 import (
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/dromara/carbon/v2"
 	"gorm.io/gorm"
 )
 
+// location is the time zone renewal dates are calculated in
+var location atomic.Pointer[time.Location]
+
+// SetLocation sets the time zone used when computing "now" for renewal dates. Nil means UTC.
+func SetLocation(loc *time.Location) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	location.Store(loc)
+}
+
+// Location returns the time zone renewal dates are calculated in (UTC unless set)
+func Location() *time.Location {
+	if loc := location.Load(); loc != nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// nowInLocation returns the current time in the renewal time zone
+func nowInLocation() time.Time {
+	return time.Now().In(Location())
+}
+
+// Cost bases for converting weekly and daily costs to a month
+const (
+	CostBasisAverage  = "average"  // 30.44 days or 4.33 weeks, the same every month
+	CostBasisCalendar = "calendar" // The actual length of the current month
+)
+
+// calendarCostBasis is set when monthly costs use the current month's length
+var calendarCostBasis atomic.Bool
+
+// SetCostBasis sets how many days a month has in cost calculations. Anything
+// other than CostBasisCalendar uses the average month.
+func SetCostBasis(basis string) {
+	calendarCostBasis.Store(basis == CostBasisCalendar)
+}
+
+// CostBasis returns the cost basis in use
+func CostBasis() string {
+	if calendarCostBasis.Load() {
+		return CostBasisCalendar
+	}
+	return CostBasisAverage
+}
+
+// DaysPerMonth returns the days in a month under the current cost basis
+func DaysPerMonth() float64 {
+	return daysPerMonth(CostBasis(), nowInLocation())
+}
+
+// WeeksPerMonth returns the weeks in a month under the current cost basis
+func WeeksPerMonth() float64 {
+	return weeksPerMonth(CostBasis(), nowInLocation())
+}
+
+// daysPerMonth returns 30.44 for the average basis, or the number of days in
+// now's month for the calendar basis
+func daysPerMonth(basis string, now time.Time) float64 {
+	if basis == CostBasisCalendar {
+		return float64(time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()).Day())
+	}
+	return 30.44
+}
+
+// weeksPerMonth returns 4.33 for the average basis, or the weeks in now's
+// month for the calendar basis
+func weeksPerMonth(basis string, now time.Time) float64 {
+	if basis == CostBasisCalendar {
+		return daysPerMonth(basis, now) / 7
+	}
+	return 4.33
+}
+
 type Subscription struct {
 	ID                           uint       `json:"id" gorm:"primaryKey"`
-	Name                         string     `json:"name" gorm:"not null" validate:"required"`
-	Cost                         float64    `json:"cost" gorm:"not null" validate:"required,gt=0"`
+	Name                         string     `json:"name" gorm:"not null" validate:"notblank"`
+	Cost                         float64    `json:"cost" gorm:"not null" validate:"required_unless=Status Trial,gte=0"` // Only trials may be free
+	NextChargeAmount             *float64   `json:"next_charge_amount" gorm:"" validate:"omitempty,gte=0"`              // One-off amount for the next renewal only (promo ending, proration)
+	FirstChargeAmount            *float64   `json:"first_charge_amount" gorm:"" validate:"omitempty,gte=0"`             // Prorated amount of a partial first period
+	FirstChargeDate              *time.Time `json:"first_charge_date" gorm:""`                                          // When the prorated first charge is taken; regular renewals follow it
 	OriginalCurrency             string     `json:"original_currency" gorm:"size:3;default:'USD'"`
-	Schedule                     string     `json:"schedule" gorm:"not null" validate:"required,oneof=Monthly Annual Weekly Daily Quarterly"`
-	Status                       string     `json:"status" gorm:"not null" validate:"required,oneof=Active Cancelled Paused Trial"`
+	Schedule                     string     `json:"schedule" gorm:"not null" validate:"oneof=Monthly Annual Weekly Daily Quarterly"`
+	Status                       string     `json:"status" gorm:"not null" validate:"oneof=Active Cancelled Paused Trial Archived"`
 	CategoryID                   uint       `json:"category_id"`
 	Category                     Category   `json:"category" gorm:"foreignKey:CategoryID"`
 	PaymentMethod                string     `json:"payment_method" gorm:""`
@@ -23,11 +103,16 @@ type Subscription struct {
 	RenewalDate                  *time.Time `json:"renewal_date" gorm:""`
 	CancellationDate             *time.Time `json:"cancellation_date" gorm:""`
 	URL                          string     `json:"url" gorm:""`
+	CancellationURL              string     `json:"cancellation_url" gorm:""`
 	IconURL                      string     `json:"icon_url" gorm:""` // URL to subscription icon/logo
 	Notes                        string     `json:"notes" gorm:""`
 	Usage                        string     `json:"usage" gorm:"" validate:"omitempty,oneof=High Medium Low None"`
 	ScheduleInterval             int        `json:"schedule_interval" gorm:"default:1"`
+	SharedWith                   int        `json:"shared_with" gorm:"default:1" validate:"gte=0"` // People splitting the cost, including me
 	ReminderEnabled              bool       `json:"reminder_enabled" gorm:"default:true"`
+	ReminderDaysOverride         *int       `json:"reminder_days_override" gorm:"" validate:"omitempty,gte=1,lte=365"` // Days before renewal to remind, in place of the reminder_days setting
+	LockRenewalDate              bool       `json:"lock_renewal_date" gorm:"default:false"`                            // Keep a manually set renewal date when the schedule or start date changes
+	DisplayInOriginal            bool       `json:"display_in_original" gorm:"default:false"`                          // Show in the original currency instead of the display currency
 	DateCalculationVersion       int        `json:"date_calculation_version" gorm:"default:1"`
 	LastReminderSent             *time.Time `json:"last_reminder_sent" gorm:""`              // Tracks when the last reminder was sent
 	LastReminderRenewalDate      *time.Time `json:"last_reminder_renewal_date" gorm:""`      // Tracks which renewal date the last reminder was for
@@ -90,22 +175,124 @@ func (s *Subscription) MonthlyCost() float64 {
 	case "Monthly":
 		return s.Cost / float64(interval)
 	case "Weekly":
-		return s.Cost * 4.33 / float64(interval)
+		return s.Cost * WeeksPerMonth() / float64(interval)
 	case "Daily":
-		return s.Cost * 30.44 / float64(interval)
+		return s.Cost * DaysPerMonth() / float64(interval)
 	default:
 		return s.Cost / float64(interval)
 	}
 }
 
+// effectiveSharedWith returns the number of people splitting the cost, at least 1
+func (s *Subscription) effectiveSharedWith() int {
+	if s.SharedWith <= 0 {
+		return 1
+	}
+	return s.SharedWith
+}
+
+// MyShareMonthly returns my part of the monthly cost when it is split between
+// SharedWith people
+func (s *Subscription) MyShareMonthly() float64 {
+	return s.MonthlyCost() / float64(s.effectiveSharedWith())
+}
+
 // DailyCost calculates the daily cost
 func (s *Subscription) DailyCost() float64 {
-	return s.MonthlyCost() / 30.44 // Average days per month
+	return s.MonthlyCost() / DaysPerMonth()
+}
+
+// Bases the high-cost threshold can be measured against
+const (
+	HighCostBasisMonthly = "monthly"
+	HighCostBasisAnnual  = "annual"
+)
+
+// CostForBasis returns the annual cost for the annual basis and the monthly cost otherwise
+func (s *Subscription) CostForBasis(basis string) float64 {
+	if basis == HighCostBasisAnnual {
+		return s.AnnualCost()
+	}
+	return s.MonthlyCost()
 }
 
-// IsHighCost determines if this is a high-cost subscription based on the threshold
+// IsHighCost determines if this is a high-cost subscription based on a monthly threshold
 func (s *Subscription) IsHighCost(threshold float64) bool {
-	return s.MonthlyCost() > threshold
+	return s.IsHighCostWithThreshold(threshold, HighCostBasisMonthly)
+}
+
+// IsHighCostWithThreshold reports whether the cost on the given basis exceeds threshold
+func (s *Subscription) IsHighCostWithThreshold(threshold float64, basis string) bool {
+	return s.CostForBasis(basis) > threshold
+}
+
+// ReminderLeadDays returns how many days before renewal to send a reminder:
+// the subscription's own override, or defaultDays when it has none
+func (s *Subscription) ReminderLeadDays(defaultDays int) int {
+	if s.ReminderDaysOverride != nil {
+		return *s.ReminderDaysOverride
+	}
+	return defaultDays
+}
+
+// IsLowUsage reports whether the subscription is rarely or never used
+func (s *Subscription) IsLowUsage() bool {
+	return s.Usage == "Low" || s.Usage == "None"
+}
+
+// NextChargeCost returns the amount of the next renewal: the one-off next
+// charge when set, otherwise the recurring cost
+func (s *Subscription) NextChargeCost() float64 {
+	if s.NextChargeAmount != nil {
+		return *s.NextChargeAmount
+	}
+	return s.Cost
+}
+
+// PendingFirstCharge reports whether the prorated first charge is still to come at now
+func (s *Subscription) PendingFirstCharge(now time.Time) bool {
+	return s.FirstChargeDate != nil && s.FirstChargeDate.After(now)
+}
+
+// FirstChargeCost returns the amount of the first charge: the prorated first
+// charge when set, otherwise the recurring cost
+func (s *Subscription) FirstChargeCost() float64 {
+	if s.FirstChargeAmount != nil {
+		return *s.FirstChargeAmount
+	}
+	return s.Cost
+}
+
+// UpcomingChargeDate returns the date of the next charge after now: the
+// prorated first charge while it is pending, otherwise the renewal date
+func (s *Subscription) UpcomingChargeDate(now time.Time) *time.Time {
+	if s.PendingFirstCharge(now) {
+		return s.FirstChargeDate
+	}
+	return s.RenewalDate
+}
+
+// Validate checks the fields required before a subscription can be saved.
+// Invalid fields are reported together in a *ValidationError.
+func (s *Subscription) Validate() error {
+	return validateStruct(s)
+}
+
+// ValidateUpdate is Validate for an edit of a saved subscription that cost
+// previousCost. Subscriptions saved before a zero cost was limited to trials
+// keep their zero cost as long as the edit leaves it unchanged.
+func (s *Subscription) ValidateUpdate(previousCost float64) error {
+	err := s.Validate()
+	var validationErr *ValidationError
+	if previousCost != 0 || s.Cost != 0 || !errors.As(err, &validationErr) {
+		return err
+	}
+	// A zero cost can only fail the trial rule
+	delete(validationErr.Fields, "cost")
+	if len(validationErr.Fields) == 0 {
+		return nil
+	}
+	return validationErr
 }
 
 // BeforeCreate hook to set renewal date for active subscriptions
@@ -122,17 +309,27 @@ func (s *Subscription) BeforeCreate(tx *gorm.DB) error {
 func (s *Subscription) AfterFind(tx *gorm.DB) error {
 	// Auto-update renewal date if it has passed and subscription is active
 	if s.RenewalDate != nil && s.Status == "Active" && s.ID > 0 {
-		now := time.Now()
+		now := nowInLocation()
 		if s.RenewalDate.Before(now) || s.RenewalDate.Equal(now) {
 			// Renewal date has passed, calculate the next one
 			oldRenewalDate := s.RenewalDate
-			s.calculateNextRenewalDate()
+			if s.LockRenewalDate {
+				s.advanceLockedRenewalDate()
+			} else {
+				s.calculateNextRenewalDate()
+			}
 
 			// Only update if the date actually changed to avoid unnecessary writes
 			if s.RenewalDate != nil && !s.RenewalDate.Equal(*oldRenewalDate) {
 				// Update only the renewal_date field using UpdateColumn to avoid triggering hooks
 				// This prevents infinite recursion and only updates the specific field
 				tx.Model(s).UpdateColumn("renewal_date", s.RenewalDate)
+
+				// The one-off next charge applied to the renewal that just passed
+				if s.NextChargeAmount != nil {
+					s.NextChargeAmount = nil
+					tx.Model(s).UpdateColumn("next_charge_amount", nil)
+				}
 			}
 		}
 	}
@@ -141,34 +338,39 @@ func (s *Subscription) AfterFind(tx *gorm.DB) error {
 
 // BeforeUpdate hook to recalculate renewal date when schedule changes, start date changes, or date passes
 func (s *Subscription) BeforeUpdate(tx *gorm.DB) error {
-	// Get the original values to check for schedule or start date changes
-	var original Subscription
-	if err := tx.Model(&Subscription{}).Where("id = ?", s.ID).First(&original).Error; err == nil {
-		// If schedule changed and status is Active, recalculate renewal date
-		// Use start date if available to preserve billing anniversary
-		if (original.Schedule != s.Schedule || original.ScheduleInterval != s.ScheduleInterval) && s.Status == "Active" {
-			s.calculateNextRenewalDate()
-		}
+	// A locked renewal date set by the user is never recalculated from the schedule or start date
+	locked := s.LockRenewalDate && s.RenewalDate != nil
+
+	if !locked {
+		// Get the original values to check for schedule or start date changes
+		var original Subscription
+		if err := tx.Model(&Subscription{}).Where("id = ?", s.ID).First(&original).Error; err == nil {
+			// If schedule changed and status is Active, recalculate renewal date
+			// Use start date if available to preserve billing anniversary
+			if (original.Schedule != s.Schedule || original.ScheduleInterval != s.ScheduleInterval) && s.Status == "Active" {
+				s.calculateNextRenewalDate()
+			}
 
-		// If start date changed and status is Active, recalculate renewal date
-		// This ensures renewal dates update when start dates are modified
-		if s.Status == "Active" {
-			startDateChanged := false
-			if original.StartDate == nil && s.StartDate != nil {
-				// Start date was added
-				startDateChanged = true
-			} else if original.StartDate != nil && s.StartDate == nil {
-				// Start date was removed
-				startDateChanged = true
-			} else if original.StartDate != nil && s.StartDate != nil {
-				// Both exist, check if they're different
-				if !original.StartDate.Equal(*s.StartDate) {
+			// If start date changed and status is Active, recalculate renewal date
+			// This ensures renewal dates update when start dates are modified
+			if s.Status == "Active" {
+				startDateChanged := false
+				if original.StartDate == nil && s.StartDate != nil {
+					// Start date was added
+					startDateChanged = true
+				} else if original.StartDate != nil && s.StartDate == nil {
+					// Start date was removed
 					startDateChanged = true
+				} else if original.StartDate != nil && s.StartDate != nil {
+					// Both exist, check if they're different
+					if !original.StartDate.Equal(*s.StartDate) {
+						startDateChanged = true
+					}
 				}
-			}
 
-			if startDateChanged {
-				s.calculateNextRenewalDate()
+				if startDateChanged {
+					s.calculateNextRenewalDate()
+				}
 			}
 		}
 	}
@@ -180,16 +382,34 @@ func (s *Subscription) BeforeUpdate(tx *gorm.DB) error {
 
 	// Auto-update renewal date if it has passed (Issue #29)
 	if s.RenewalDate != nil && s.Status == "Active" {
-		now := time.Now()
+		now := nowInLocation()
 		if s.RenewalDate.Before(now) || s.RenewalDate.Equal(now) {
 			// Renewal date has passed, calculate the next one
-			s.calculateNextRenewalDate()
+			if locked {
+				s.advanceLockedRenewalDate()
+			} else {
+				s.calculateNextRenewalDate()
+			}
+			// The one-off next charge applied to the renewal that just passed
+			s.NextChargeAmount = nil
 		}
 	}
 
 	return nil
 }
 
+// advanceLockedRenewalDate steps a locked renewal date forward one billing period
+// at a time until it is in the future, keeping the billing day the user chose
+func (s *Subscription) advanceLockedRenewalDate() {
+	now := carbon.CreateFromStdTime(nowInLocation())
+	current := carbon.CreateFromStdTime(*s.RenewalDate)
+	for current.Lte(now) {
+		current = s.addScheduleInterval(current)
+	}
+	renewalDate := current.StdTime()
+	s.RenewalDate = &renewalDate
+}
+
 // calculateNextRenewalDate calculates the next renewal date based on schedule and version.
 //
 // Version Selection Logic:
@@ -214,6 +434,35 @@ func (s *Subscription) calculateNextRenewalDate() {
 	}
 }
 
+// RecalculateRenewalDate recomputes the renewal date from the schedule and start
+// date, reporting whether it changed. A locked renewal date is left alone, and
+// without a start date there is nothing to recompute an existing date from.
+func (s *Subscription) RecalculateRenewalDate() bool {
+	if s.RenewalDate != nil && (s.LockRenewalDate || s.StartDate == nil) {
+		return false
+	}
+	oldRenewalDate := s.RenewalDate
+	s.calculateNextRenewalDate()
+	if s.RenewalDate == nil {
+		return oldRenewalDate != nil
+	}
+	return oldRenewalDate == nil || !s.RenewalDate.Equal(*oldRenewalDate)
+}
+
+// PreviewRenewalDate returns the next renewal date a subscription with this
+// schedule and start date would get under the given calculation version,
+// without saving anything. A nil start calculates from now.
+func PreviewRenewalDate(schedule string, interval int, start *time.Time, version int) *time.Time {
+	s := Subscription{
+		Schedule:               schedule,
+		ScheduleInterval:       interval,
+		StartDate:              start,
+		DateCalculationVersion: version,
+	}
+	s.calculateNextRenewalDate()
+	return s.RenewalDate
+}
+
 // calculateNextRenewalDateV1 uses the original calculation logic
 func (s *Subscription) calculateNextRenewalDateV1() {
 	// If we have a start date, calculate renewal from start date
@@ -234,7 +483,7 @@ func (s *Subscription) calculateNextRenewalDateV2() {
 
 	interval := s.effectiveInterval()
 	start := carbon.CreateFromStdTime(*s.StartDate)
-	now := carbon.Now()
+	now := carbon.CreateFromStdTime(nowInLocation())
 
 	switch s.Schedule {
 	case "Monthly":
@@ -297,7 +546,7 @@ func (s *Subscription) calculateNextRenewalDateFromStartDate() {
 	interval := s.effectiveInterval()
 	var renewalDate time.Time
 	baseDate := *s.StartDate
-	now := time.Now()
+	now := nowInLocation()
 
 	switch s.Schedule {
 	case "Annual":
@@ -406,7 +655,7 @@ func (s *Subscription) calculateNextRenewalDateFromStartDate() {
 func (s *Subscription) calculateNextRenewalDateFromNow() {
 	interval := s.effectiveInterval()
 	var renewalDate time.Time
-	baseDate := time.Now()
+	baseDate := nowInLocation()
 
 	switch s.Schedule {
 	case "Annual":
@@ -428,7 +677,7 @@ func (s *Subscription) calculateNextRenewalDateFromNow() {
 // calculateNextRenewalDateFromNowV2 calculates renewal date from now using Carbon
 func (s *Subscription) calculateNextRenewalDateFromNowV2() {
 	interval := s.effectiveInterval()
-	now := carbon.Now()
+	now := carbon.CreateFromStdTime(nowInLocation())
 
 	switch s.Schedule {
 	case "Annual":
@@ -452,21 +701,194 @@ func (s *Subscription) calculateNextRenewalDateFromNowV2() {
 	}
 }
 
+// addScheduleInterval advances c by one billing period using the V2 Carbon stepping rules
+func (s *Subscription) addScheduleInterval(c *carbon.Carbon) *carbon.Carbon {
+	interval := s.effectiveInterval()
+
+	switch s.Schedule {
+	case "Annual":
+		return c.AddYearsNoOverflow(interval)
+	case "Quarterly":
+		return c.AddMonthsNoOverflow(3 * interval)
+	case "Weekly":
+		return c.AddWeeks(interval)
+	case "Daily":
+		return c.AddDays(interval)
+	default:
+		return c.AddMonthsNoOverflow(interval)
+	}
+}
+
+// RenewalsBetween returns every renewal occurring within [from, to], oldest first.
+// Occurrences are stepped from the start date when known, otherwise from the
+// stored renewal date, and stop at the cancellation date if one is set.
+func (s *Subscription) RenewalsBetween(from, to time.Time) []time.Time {
+	var anchor time.Time
+	switch {
+	case s.StartDate != nil:
+		anchor = *s.StartDate
+	case s.RenewalDate != nil:
+		anchor = *s.RenewalDate
+	default:
+		return nil
+	}
+	if s.CancellationDate != nil && s.CancellationDate.Before(to) {
+		to = *s.CancellationDate
+	}
+	if to.Before(from) {
+		return nil
+	}
+
+	start := carbon.CreateFromStdTime(from)
+	end := carbon.CreateFromStdTime(to)
+	current := carbon.CreateFromStdTime(anchor)
+	for current.Lt(start) {
+		current = s.addScheduleInterval(current)
+	}
+
+	var renewals []time.Time
+	for current.Lte(end) {
+		renewals = append(renewals, current.StdTime())
+		current = s.addScheduleInterval(current)
+	}
+	return renewals
+}
+
+// ForecastCharge is a single projected renewal charge
+type ForecastCharge struct {
+	SubscriptionID uint      `json:"subscription_id"`
+	Name           string    `json:"name"`
+	Amount         float64   `json:"amount"`
+	Currency       string    `json:"currency"`
+	Date           time.Time `json:"date"`
+}
+
+// RenewalMonth lists the subscriptions charged in one calendar month
+type RenewalMonth struct {
+	Month         string                 `json:"month"` // YYYY-MM
+	Subscriptions []RenewalCalendarEntry `json:"subscriptions"`
+	Total         *float64               `json:"total"` // In the display currency; nil when a charge couldn't be converted to it
+}
+
+// RenewalCalendarEntry is one subscription's charges within a RenewalMonth
+type RenewalCalendarEntry struct {
+	SubscriptionID uint        `json:"subscription_id"`
+	Name           string      `json:"name"`
+	Amount         float64     `json:"amount"` // Sum of the month's charges
+	Currency       string      `json:"currency"`
+	Dates          []time.Time `json:"dates"`
+}
+
 // Stats represents aggregated subscription statistics
 type Stats struct {
 	TotalMonthlySpend      float64            `json:"total_monthly_spend"`
 	TotalAnnualSpend       float64            `json:"total_annual_spend"`
 	ActiveSubscriptions    int                `json:"active_subscriptions"`
 	CancelledSubscriptions int                `json:"cancelled_subscriptions"`
+	PausedSubscriptions    int                `json:"paused_subscriptions"`   // Not billing, excluded from spend totals
+	TrialSubscriptions     int                `json:"trial_subscriptions"`    // Not billing yet, excluded from spend totals
+	ArchivedSubscriptions  int                `json:"archived_subscriptions"` // Kept for reference, excluded from spend totals and savings
 	TotalSaved             float64            `json:"total_saved"`
 	MonthlySaved           float64            `json:"monthly_saved"`
 	UpcomingRenewals       int                `json:"upcoming_renewals"`
+	StaleRenewals          int                `json:"stale_renewals"`    // Active subscriptions with a renewal date before today
+	PotentialSavings       float64            `json:"potential_savings"` // Monthly cost of active low-usage subscriptions
+	AverageMonthlyCost     float64            `json:"average_monthly_cost"`
+	AverageAnnualCost      float64            `json:"average_annual_cost"`
+	MostExpensive          *SubscriptionRef   `json:"most_expensive,omitempty"` // Active subscription with the highest monthly cost
+	YearToDateSpend        float64            `json:"year_to_date_spend"`       // Charges since Jan 1
+	ProjectedYearEndSpend  float64            `json:"projected_year_end_spend"` // YTD plus charges still due through Dec 31
 	CategorySpending       map[string]float64 `json:"category_spending"`
+
+	ProjectedAnnualSpendIncludingTrials float64 `json:"projected_annual_spend_including_trials"` // TotalAnnualSpend plus trials at their regular cost
+}
+
+// SubscriptionRef is a lightweight reference to a subscription used in aggregates
+type SubscriptionRef struct {
+	ID          uint    `json:"id"`
+	Name        string  `json:"name"`
+	MonthlyCost float64 `json:"monthly_cost"`
+}
+
+// MonthlySpend is one point of the month-over-month spending trend
+type MonthlySpend struct {
+	Month string  `json:"month"` // YYYY-MM
+	Total float64 `json:"total"`
+	Count int     `json:"count"`
+}
+
+// MonthlySavings is one point of the cumulative savings timeline
+type MonthlySavings struct {
+	Month string  `json:"month"` // YYYY-MM
+	Saved float64 `json:"saved"` // Monthly cost no longer paid thanks to cancellations so far
+	Count int     `json:"count"` // Subscriptions cancelled so far
+}
+
+// CategoryTrend is monthly spend per category; each slice in Categories is
+// aligned with Months and zero-filled where a category had no spend
+type CategoryTrend struct {
+	Months     []string             `json:"months"` // YYYY-MM, oldest first
+	Categories map[string][]float64 `json:"categories"`
+}
+
+// SubscriptionFilter holds optional criteria for searching subscriptions.
+// Zero values mean "no filter" for that field.
+type SubscriptionFilter struct {
+	Query      string   `json:"q"`
+	Status     string   `json:"status"`
+	CategoryID uint     `json:"category_id"`
+	MinCost    *float64 `json:"min_cost"`
+	MaxCost    *float64 `json:"max_cost"`
+}
+
+// StatsFilter scopes statistics to part of the subscriptions. Zero values mean
+// "no filter" for that field.
+type StatsFilter struct {
+	CategoryID uint `json:"category_id"`
 }
 
 // CategoryStat represents spending by category
 type CategoryStat struct {
 	Category string  `json:"category"`
+	Color    string  `json:"color"`
+	Icon     string  `json:"icon"`
 	Amount   float64 `json:"amount"`
 	Count    int     `json:"count"`
 }
+
+// MyShareStat is my personal monthly spend once shared subscriptions are split
+type MyShareStat struct {
+	MyMonthlyShare    float64 `json:"my_monthly_share"`    // Every active subscription at my share of its cost
+	TotalMonthlySpend float64 `json:"total_monthly_spend"` // The same subscriptions at their full cost
+	SharedCount       int     `json:"shared_count"`        // Active subscriptions split with someone
+}
+
+// Summary is the compact overview served to home dashboard widgets. Its fields
+// are kept stable so widget configurations don't break.
+type Summary struct {
+	Currency            string          `json:"currency"`      // Display currency of monthly_spend
+	MonthlySpend        float64         `json:"monthly_spend"` // Active subscriptions, converted to the display currency
+	ActiveSubscriptions int             `json:"active_subscriptions"`
+	UpcomingRenewals    int             `json:"upcoming_renewals"` // Renewals in the next 7 days
+	NextRenewal         *SummaryRenewal `json:"next_renewal"`      // Null when nothing is due
+}
+
+// SummaryRenewal names the next subscription to renew
+type SummaryRenewal struct {
+	Name string    `json:"name"`
+	Date time.Time `json:"date"`
+}
+
+// AccountStat represents spending by account, such as the login a subscription is billed to
+type AccountStat struct {
+	Account string  `json:"account"`
+	Amount  float64 `json:"amount"`
+	Count   int     `json:"count"`
+}
+
+// PaymentMethodStat represents spending by payment method
+type PaymentMethodStat struct {
+	PaymentMethod string  `json:"payment_method"`
+	Amount        float64 `json:"amount"`
+	Count         int     `json:"count"`
+}
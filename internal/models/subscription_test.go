@@ -228,6 +228,98 @@ func TestSubscription_BeforeUpdate_NilRenewalDate(t *testing.T) {
 	assert.True(t, sub.RenewalDate.After(time.Now()))
 }
 
+func TestSubscription_BeforeUpdate_LockedRenewalDate(t *testing.T) {
+	db := setupTestDB(t)
+
+	startDate := time.Now().AddDate(0, -3, 0)
+	lockedRenewal := time.Now().AddDate(0, 0, 10)
+	sub := &Subscription{
+		Name:            "Locked Subscription",
+		Cost:            9.99,
+		Schedule:        "Monthly",
+		Status:          "Active",
+		StartDate:       &startDate,
+		RenewalDate:     &lockedRenewal,
+		LockRenewalDate: true,
+	}
+	err := db.Create(sub).Error
+	assert.NoError(t, err)
+
+	var existing Subscription
+	err = db.First(&existing, sub.ID).Error
+	assert.NoError(t, err)
+
+	// Schedule, start date and cost changes all leave a locked date alone
+	existing.Schedule = "Annual"
+	newStart := time.Now().AddDate(0, -1, 3)
+	existing.StartDate = &newStart
+	existing.Cost = 99.99
+
+	err = existing.BeforeUpdate(db)
+	assert.NoError(t, err)
+	assert.NotNil(t, existing.RenewalDate)
+	assert.Equal(t, lockedRenewal.Format("2006-01-02"), existing.RenewalDate.Format("2006-01-02"))
+
+	// Once it passes, a locked date moves forward on its own billing day
+	passed := time.Date(2020, 1, 17, 0, 0, 0, 0, time.UTC)
+	existing.Schedule = "Monthly"
+	existing.RenewalDate = &passed
+
+	err = existing.BeforeUpdate(db)
+	assert.NoError(t, err)
+	assert.True(t, existing.RenewalDate.After(time.Now()))
+	assert.Equal(t, 17, existing.RenewalDate.Day())
+
+	// Unlocked, the same schedule change recalculates from the start date
+	existing.LockRenewalDate = false
+	existing.Schedule = "Annual"
+	err = existing.BeforeUpdate(db)
+	assert.NoError(t, err)
+	assert.Equal(t, newStart.Day(), existing.RenewalDate.Day())
+	assert.Equal(t, newStart.Month(), existing.RenewalDate.Month())
+}
+
+func TestSubscription_NextChargeAmountClearedAfterRenewal(t *testing.T) {
+	db := setupTestDB(t)
+
+	startDate := time.Now().AddDate(0, -2, 0)
+	renewal := time.Now().AddDate(0, 0, 5)
+	promoEnd := 14.99
+	sub := &Subscription{
+		Name:             "Promo",
+		Cost:             9.99,
+		Schedule:         "Monthly",
+		Status:           "Active",
+		StartDate:        &startDate,
+		RenewalDate:      &renewal,
+		NextChargeAmount: &promoEnd,
+	}
+	assert.NoError(t, db.Create(sub).Error)
+	assert.Equal(t, 14.99, sub.NextChargeCost())
+
+	// Kept while the renewal it applies to is still ahead
+	var loaded Subscription
+	assert.NoError(t, db.First(&loaded, sub.ID).Error)
+	assert.NotNil(t, loaded.NextChargeAmount)
+
+	// Once that renewal passes, loading advances the date and drops the one-off amount
+	passed := time.Now().AddDate(0, 0, -1)
+	assert.NoError(t, db.Model(&Subscription{}).Where("id = ?", sub.ID).UpdateColumn("renewal_date", passed).Error)
+	assert.NoError(t, db.First(&loaded, sub.ID).Error)
+	assert.Nil(t, loaded.NextChargeAmount)
+	assert.Equal(t, 9.99, loaded.NextChargeCost())
+
+	var stored Subscription
+	assert.NoError(t, db.Session(&gorm.Session{SkipHooks: true}).First(&stored, sub.ID).Error)
+	assert.Nil(t, stored.NextChargeAmount)
+
+	// Saving with a passed renewal date clears it the same way
+	loaded.NextChargeAmount = &promoEnd
+	loaded.RenewalDate = &passed
+	assert.NoError(t, loaded.BeforeUpdate(db))
+	assert.Nil(t, loaded.NextChargeAmount)
+}
+
 func TestSubscription_MonthlyCost(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -274,6 +366,29 @@ func TestSubscription_MonthlyCost(t *testing.T) {
 	}
 }
 
+func TestSubscription_MyShareMonthly(t *testing.T) {
+	tests := []struct {
+		name       string
+		schedule   string
+		cost       float64
+		sharedWith int
+		expected   float64
+	}{
+		{name: "Not shared", schedule: "Monthly", cost: 20, sharedWith: 1, expected: 20},
+		{name: "Split four ways", schedule: "Monthly", cost: 20, sharedWith: 4, expected: 5},
+		{name: "Annual split two ways", schedule: "Annual", cost: 120, sharedWith: 2, expected: 5},
+		{name: "Zero treated as one", schedule: "Monthly", cost: 20, sharedWith: 0, expected: 20},
+		{name: "Negative treated as one", schedule: "Monthly", cost: 20, sharedWith: -3, expected: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &Subscription{Schedule: tt.schedule, Cost: tt.cost, SharedWith: tt.sharedWith}
+			assert.InDelta(t, tt.expected, sub.MyShareMonthly(), 0.001)
+		})
+	}
+}
+
 func TestSubscription_BeforeCreate_WithStartDate(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -505,6 +620,35 @@ func TestSubscription_IsHighCost(t *testing.T) {
 	}
 }
 
+// TestSubscription_IsHighCostWithThreshold_AnnualBasis tests thresholds measured against annual cost
+func TestSubscription_IsHighCostWithThreshold_AnnualBasis(t *testing.T) {
+	threshold := 500.0
+	tests := []struct {
+		name     string
+		schedule string
+		cost     float64
+		expected bool
+	}{
+		{"Monthly under annual threshold", "Monthly", 40.00, false}, // $480/year
+		{"Monthly over annual threshold", "Monthly", 45.00, true},   // $540/year
+		{"Annual exactly at threshold", "Annual", 500.00, false},
+		{"Annual over threshold", "Annual", 600.00, true},
+		{"Weekly over annual threshold", "Weekly", 10.00, true}, // ~$520/year
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &Subscription{Schedule: tt.schedule, Cost: tt.cost}
+			assert.Equal(t, tt.expected, sub.IsHighCostWithThreshold(threshold, HighCostBasisAnnual))
+		})
+	}
+
+	// A $45/month subscription is high cost per year but not against the same number per month
+	sub := &Subscription{Schedule: "Monthly", Cost: 45.00}
+	assert.False(t, sub.IsHighCostWithThreshold(threshold, HighCostBasisMonthly))
+	assert.Equal(t, sub.IsHighCost(50), sub.IsHighCostWithThreshold(50, HighCostBasisMonthly))
+}
+
 // TestSubscription_DateEdgeCases tests critical edge cases for date calculations
 // Note: These tests focus on the core logic, not exact historical sequences
 func TestSubscription_DateEdgeCases(t *testing.T) {
@@ -1037,6 +1181,40 @@ func TestSubscription_CostWithInterval(t *testing.T) {
 	}
 }
 
+func TestDaysPerMonth(t *testing.T) {
+	feb := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	jan := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	leapFeb := time.Date(2028, 2, 10, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 30.44, daysPerMonth(CostBasisAverage, feb))
+	assert.Equal(t, 4.33, weeksPerMonth(CostBasisAverage, feb))
+	assert.Equal(t, 28.0, daysPerMonth(CostBasisCalendar, feb))
+	assert.Equal(t, 29.0, daysPerMonth(CostBasisCalendar, leapFeb))
+	assert.Equal(t, 31.0, daysPerMonth(CostBasisCalendar, jan))
+	assert.Equal(t, 4.0, weeksPerMonth(CostBasisCalendar, feb))
+}
+
+func TestSubscription_CostCalendarBasis(t *testing.T) {
+	SetCostBasis(CostBasisCalendar)
+	t.Cleanup(func() { SetCostBasis(CostBasisAverage) })
+
+	days := DaysPerMonth()
+	assert.GreaterOrEqual(t, days, 28.0)
+	assert.LessOrEqual(t, days, 31.0)
+
+	daily := &Subscription{Schedule: "Daily", Cost: 1.00}
+	assert.InDelta(t, days, daily.MonthlyCost(), 0.0001)
+	assert.InDelta(t, 1.00, daily.DailyCost(), 0.0001)
+
+	weekly := &Subscription{Schedule: "Weekly", Cost: 7.00}
+	assert.InDelta(t, days, weekly.MonthlyCost(), 0.0001)
+
+	// Fixed-period schedules don't depend on the month length
+	monthly := &Subscription{Schedule: "Monthly", Cost: 10.00}
+	assert.Equal(t, 10.00, monthly.MonthlyCost())
+	assert.InDelta(t, 10.00/days, monthly.DailyCost(), 0.0001)
+}
+
 func TestSubscription_RenewalDateWithInterval(t *testing.T) {
 	now := time.Now()
 	pastStart := now.AddDate(0, 0, -10) // 10 days ago
@@ -1099,3 +1277,88 @@ func TestSubscription_RenewalDateV2WithInterval(t *testing.T) {
 	assert.Equal(t, expectedYear, sub.RenewalDate.Year(), "Every 2 Years V2 should be 2 years from start")
 }
 
+
+func TestSubscription_Validate(t *testing.T) {
+	valid := func() Subscription {
+		return Subscription{Name: "Netflix", Cost: 15.99, Schedule: "Monthly", Status: "Active"}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(s *Subscription)
+		wantErr string
+	}{
+		{"Valid subscription", func(s *Subscription) {}, ""},
+		{"Free trial with zero cost", func(s *Subscription) { s.Cost = 0; s.Status = "Trial" }, ""},
+		{"Missing name", func(s *Subscription) { s.Name = "  " }, "name is required"},
+		{"Negative cost", func(s *Subscription) { s.Cost = -1 }, "cost cannot be negative"},
+		{"Zero cost outside a trial", func(s *Subscription) { s.Cost = 0 }, "cost is required unless status is Trial"},
+		{"Negative next charge", func(s *Subscription) { s.NextChargeAmount = new(float64); *s.NextChargeAmount = -5 }, "next charge amount cannot be negative"},
+		{"Unknown schedule", func(s *Subscription) { s.Schedule = "Hourly" }, "invalid schedule"},
+		{"Missing status", func(s *Subscription) { s.Status = "" }, "invalid status"},
+		{"Unknown usage", func(s *Subscription) { s.Usage = "Sometimes" }, "invalid usage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := valid()
+			tt.modify(&sub)
+			err := sub.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubscription_ValidateUpdate(t *testing.T) {
+	// Saved as a free Active subscription before zero cost was limited to trials
+	legacy := Subscription{Name: "Free Tier", Cost: 0, Schedule: "Monthly", Status: "Active"}
+	assert.NoError(t, legacy.ValidateUpdate(0))
+
+	// Other fields are still checked
+	legacy.Name = ""
+	assert.ErrorContains(t, legacy.ValidateUpdate(0), "name is required")
+
+	// Setting a paid subscription's cost to zero is still rejected
+	paid := Subscription{Name: "Netflix", Cost: 0, Schedule: "Monthly", Status: "Active"}
+	assert.ErrorContains(t, paid.ValidateUpdate(15.99), "cost is required unless status is Trial")
+}
+
+func TestSubscription_RenewalsBetween(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	ptr := func(v time.Time) *time.Time { return &v }
+
+	t.Run("Monthly from start date", func(t *testing.T) {
+		sub := &Subscription{Schedule: "Monthly", StartDate: ptr(date(2025, 1, 10))}
+		got := sub.RenewalsBetween(date(2025, 3, 1), date(2025, 5, 31))
+		assert.Equal(t, []time.Time{date(2025, 3, 10), date(2025, 4, 10), date(2025, 5, 10)}, got)
+	})
+
+	t.Run("Weekly with interval", func(t *testing.T) {
+		sub := &Subscription{Schedule: "Weekly", ScheduleInterval: 2, StartDate: ptr(date(2025, 6, 2))}
+		got := sub.RenewalsBetween(date(2025, 6, 3), date(2025, 7, 1))
+		assert.Equal(t, []time.Time{date(2025, 6, 16), date(2025, 6, 30)}, got)
+	})
+
+	t.Run("Falls back to renewal date", func(t *testing.T) {
+		sub := &Subscription{Schedule: "Annual", RenewalDate: ptr(date(2025, 8, 1))}
+		got := sub.RenewalsBetween(date(2025, 1, 1), date(2026, 12, 31))
+		assert.Equal(t, []time.Time{date(2025, 8, 1), date(2026, 8, 1)}, got)
+	})
+
+	t.Run("Stops at cancellation date", func(t *testing.T) {
+		sub := &Subscription{Schedule: "Monthly", StartDate: ptr(date(2025, 1, 5)), CancellationDate: ptr(date(2025, 3, 1))}
+		got := sub.RenewalsBetween(date(2025, 1, 1), date(2025, 6, 1))
+		assert.Equal(t, []time.Time{date(2025, 1, 5), date(2025, 2, 5)}, got)
+	})
+
+	t.Run("No dates", func(t *testing.T) {
+		sub := &Subscription{Schedule: "Monthly"}
+		assert.Empty(t, sub.RenewalsBetween(date(2025, 1, 1), date(2025, 12, 31)))
+	})
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationError lists a model's invalid fields, keyed by JSON field name
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, message := range e.Fields {
+		messages = append(messages, message)
+	}
+	sort.Strings(messages)
+	return strings.Join(messages, "; ")
+}
+
+var structValidator = newStructValidator()
+
+// newStructValidator checks `validate` struct tags, reporting fields by their JSON name
+func newStructValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	// notblank is required that also rejects whitespace-only strings
+	v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+	return v
+}
+
+// validateStruct runs the `validate` tags on s, returning a *ValidationError
+// with a readable message for each failing field
+func validateStruct(s any) error {
+	err := structValidator.Struct(s)
+	if err == nil {
+		return nil
+	}
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	fields := make(map[string]string, len(fieldErrors))
+	for _, fe := range fieldErrors {
+		fields[fe.Field()] = validationMessage(fe)
+	}
+	return &ValidationError{Fields: fields}
+}
+
+func validationMessage(fe validator.FieldError) string {
+	field := strings.ReplaceAll(fe.Field(), "_", " ")
+	switch fe.Tag() {
+	case "required", "notblank":
+		return field + " is required"
+	case "required_unless":
+		// The param lists field/value pairs, e.g. "Status Trial"
+		params := strings.Fields(fe.Param())
+		conditions := make([]string, 0, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			conditions = append(conditions, fmt.Sprintf("%s is %s", strings.ToLower(params[i]), params[i+1]))
+		}
+		return fmt.Sprintf("%s is required unless %s", field, strings.Join(conditions, " and "))
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "gte":
+		if fe.Param() == "0" {
+			return field + " cannot be negative"
+		}
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "lte":
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("invalid %s %q, must be one of %s", field, fmt.Sprint(fe.Value()), strings.ReplaceAll(fe.Param(), " ", ", "))
+	default:
+		return field + " is invalid"
+	}
+}
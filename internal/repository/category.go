@@ -14,7 +14,13 @@ func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
+// Create inserts a category, placing it after every existing one unless a sort order is given
 func (r *CategoryRepository) Create(category *models.Category) (*models.Category, error) {
+	if category.SortOrder == 0 {
+		var last int
+		r.db.Model(&models.Category{}).Select("COALESCE(MAX(sort_order), 0)").Scan(&last)
+		category.SortOrder = last + 1
+	}
 	if err := r.db.Create(category).Error; err != nil {
 		return nil, err
 	}
@@ -23,7 +29,7 @@ func (r *CategoryRepository) Create(category *models.Category) (*models.Category
 
 func (r *CategoryRepository) GetAll() ([]models.Category, error) {
 	var categories []models.Category
-	if err := r.db.Order("name ASC").Find(&categories).Error; err != nil {
+	if err := r.db.Order("sort_order ASC, name ASC").Find(&categories).Error; err != nil {
 		return nil, err
 	}
 	return categories, nil
@@ -44,6 +50,45 @@ func (r *CategoryRepository) Update(id uint, category *models.Category) (*models
 	return r.GetByID(id)
 }
 
+// Reorder puts the given categories first, in the order listed, followed by the
+// rest in their current order. Unknown IDs return gorm.ErrRecordNotFound.
+func (r *CategoryRepository) Reorder(ids []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var categories []models.Category
+		if err := tx.Order("sort_order ASC, name ASC").Find(&categories).Error; err != nil {
+			return err
+		}
+
+		known := make(map[uint]bool, len(categories))
+		for _, cat := range categories {
+			known[cat.ID] = true
+		}
+		order := make([]uint, 0, len(categories))
+		listed := make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			if !known[id] {
+				return gorm.ErrRecordNotFound
+			}
+			if !listed[id] {
+				listed[id] = true
+				order = append(order, id)
+			}
+		}
+		for _, cat := range categories {
+			if !listed[cat.ID] {
+				order = append(order, cat.ID)
+			}
+		}
+
+		for i, id := range order {
+			if err := tx.Model(&models.Category{}).Where("id = ?", id).Update("sort_order", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *CategoryRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Category{}, id).Error
 }
@@ -56,8 +101,43 @@ func (r *CategoryRepository) GetByName(name string) (*models.Category, error) {
 	return &category, nil
 }
 
-func (r *CategoryRepository) HasSubscriptions(id uint) (bool, error) {
+// Merge moves every subscription of sourceID to targetID and deletes the source
+// category in one transaction, returning how many subscriptions were moved.
+func (r *CategoryRepository) Merge(sourceID, targetID uint) (int64, error) {
+	var moved int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var source, target models.Category
+		if err := tx.First(&source, sourceID).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"category_id": target.ID}
+		var hasLegacyColumn bool
+		tx.Raw("SELECT COUNT(*) > 0 FROM pragma_table_info('subscriptions') WHERE name='category'").Scan(&hasLegacyColumn)
+		if hasLegacyColumn {
+			updates["category"] = target.Name
+		}
+
+		result := tx.Model(&models.Subscription{}).Where("category_id = ?", source.ID).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved = result.RowsAffected
+
+		return tx.Delete(&source).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// CountSubscriptions returns how many subscriptions of any status reference the category
+func (r *CategoryRepository) CountSubscriptions(id uint) (int64, error) {
 	var count int64
 	err := r.db.Model(&models.Subscription{}).Where("category_id = ?", id).Count(&count).Error
-	return count > 0, err
+	return count, err
 }
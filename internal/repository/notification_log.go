@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"subtrackr/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationLogRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationLogRepository(db *gorm.DB) *NotificationLogRepository {
+	return &NotificationLogRepository{db: db}
+}
+
+func (r *NotificationLogRepository) Create(entry *models.NotificationLog) error {
+	return r.db.Create(entry).Error
+}
+
+// GetRecent returns up to limit entries, newest first
+func (r *NotificationLogRepository) GetRecent(limit int) ([]models.NotificationLog, error) {
+	var entries []models.NotificationLog
+	err := r.db.Order("created_at DESC, id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// DeleteOlderThan removes entries recorded before cutoff
+func (r *NotificationLogRepository) DeleteOlderThan(cutoff time.Time) error {
+	return r.db.Where("created_at < ?", cutoff).Delete(&models.NotificationLog{}).Error
+}
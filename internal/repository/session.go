@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"subtrackr/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+// GetActive returns the session with the given ID if it exists and has not expired
+func (r *SessionRepository) GetActive(id string, now time.Time) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.Where("id = ? AND expires_at > ?", id, now).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetAllActive returns unexpired sessions, most recently used first
+func (r *SessionRepository) GetAllActive(now time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("expires_at > ?", now).Order("last_seen DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *SessionRepository) UpdateLastSeen(id string, lastSeen time.Time) error {
+	return r.db.Model(&models.Session{}).Where("id = ?", id).Update("last_seen", lastSeen).Error
+}
+
+func (r *SessionRepository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&models.Session{}).Error
+}
+
+// DeleteAllExcept removes every session other than keepID; an empty keepID removes all
+func (r *SessionRepository) DeleteAllExcept(keepID string) error {
+	return r.db.Where("id <> ?", keepID).Delete(&models.Session{}).Error
+}
+
+// DeleteExpired removes sessions whose expiry has passed
+func (r *SessionRepository) DeleteExpired(now time.Time) error {
+	return r.db.Where("expires_at <= ?", now).Delete(&models.Session{}).Error
+}
@@ -89,11 +89,13 @@ func (r *SettingsRepository) DeleteAPIKey(id uint) error {
 	return r.db.Delete(&models.APIKey{}, id).Error
 }
 
-// UpdateAPIKeyUsage updates the usage stats for an API key
-func (r *SettingsRepository) UpdateAPIKeyUsage(id uint) error {
+// UpdateAPIKeyUsage increments the usage count and sets the last-used time in a
+// single UPDATE, so concurrent requests never lose a count. Returns the timestamp recorded.
+func (r *SettingsRepository) UpdateAPIKeyUsage(id uint) (time.Time, error) {
 	now := time.Now()
-	return r.db.Model(&models.APIKey{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"last_used": now,
+	err := r.db.Model(&models.APIKey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used":   now,
 		"usage_count": gorm.Expr("usage_count + ?", 1),
 	}).Error
+	return now, err
 }
\ No newline at end of file
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"log/slog"
+	"regexp"
+	"strconv"
 	"strings"
 	"subtrackr/internal/models"
 	"time"
@@ -11,6 +14,7 @@ import (
 type SubscriptionRepository struct {
 	db              *gorm.DB
 	hasLegacyColumn *bool
+	hasSearchIndex  *bool
 }
 
 func NewSubscriptionRepository(db *gorm.DB) *SubscriptionRepository {
@@ -28,6 +32,36 @@ func (r *SubscriptionRepository) checkLegacyColumn() bool {
 	return exists
 }
 
+// checkSearchIndex reports whether the FTS5 search table is kept in sync. The
+// migrations drop its triggers when SQLite was built without FTS5, leaving a
+// table that can't be queried.
+func (r *SubscriptionRepository) checkSearchIndex() bool {
+	if r.hasSearchIndex != nil {
+		return *r.hasSearchIndex
+	}
+
+	var exists bool
+	r.db.Raw("SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'trigger' AND name = 'subscriptions_fts_ai'").Scan(&exists)
+	r.hasSearchIndex = &exists
+	return exists
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+// Any error returned by fn rolls back every write made through that repository.
+func (r *SubscriptionRepository) Transaction(fn func(txRepo *SubscriptionRepository) error) error {
+	hasLegacyColumn := r.checkLegacyColumn()
+	hasSearchIndex := r.checkSearchIndex()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&SubscriptionRepository{db: tx, hasLegacyColumn: &hasLegacyColumn, hasSearchIndex: &hasSearchIndex})
+	})
+}
+
+// Categories returns a category repository on the same connection, so inside
+// Transaction category writes commit or roll back with the subscriptions
+func (r *SubscriptionRepository) Categories() *CategoryRepository {
+	return NewCategoryRepository(r.db)
+}
+
 func (r *SubscriptionRepository) Create(subscription *models.Subscription) (*models.Subscription, error) {
 	// Check if the old category column exists (for legacy schema support)
 	columnExists := r.checkLegacyColumn()
@@ -95,27 +129,150 @@ func (r *SubscriptionRepository) GetAll() ([]models.Subscription, error) {
 // order: asc, desc
 func (r *SubscriptionRepository) GetAllSorted(sortBy, order string) ([]models.Subscription, error) {
 	var subscriptions []models.Subscription
-	query := r.db.Preload("Category")
+	if err := r.sortedQuery(sortBy, order).Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// GetPaged returns a single page of subscriptions sorted like GetAllSorted,
+// along with the total number of subscriptions across all pages.
+// A limit of 0 or less returns every subscription starting at offset.
+func (r *SubscriptionRepository) GetPaged(sortBy, order string, limit, offset int) ([]models.Subscription, int64, error) {
+	var total int64
+	if err := r.db.Model(&models.Subscription{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
 
-	// Validate and set sort column
-	validSortColumns := map[string]string{
-		"name":         "name",
-		"cost":         "cost",
-		"status":       "status",
-		"renewal_date": "renewal_date",
-		"schedule":     "schedule",
-		"category":     "categories.name",
-		"created_at":   "created_at",
+	query := r.sortedQuery(sortBy, order)
+	if limit > 0 {
+		query = query.Limit(limit)
 	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	var subscriptions []models.Subscription
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return nil, 0, err
+	}
+	return subscriptions, total, nil
+}
 
-	sortColumn, ok := validSortColumns[sortBy]
+// Search returns subscriptions matching the filter, sorted like GetAllSorted.
+// All user-supplied values are bound as query parameters.
+func (r *SubscriptionRepository) Search(filter models.SubscriptionFilter, sortBy, order string) ([]models.Subscription, error) {
+	query := r.sortedQuery(sortBy, order)
+
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		query = r.matchText(query, q)
+	}
+	if filter.Status != "" {
+		query = query.Where("subscriptions.status = ?", filter.Status)
+	}
+	if filter.CategoryID > 0 {
+		query = query.Where("subscriptions.category_id = ?", filter.CategoryID)
+	}
+	if filter.MinCost != nil {
+		query = query.Where("subscriptions.cost >= ?", *filter.MinCost)
+	}
+	if filter.MaxCost != nil {
+		query = query.Where("subscriptions.cost <= ?", *filter.MaxCost)
+	}
+
+	var subscriptions []models.Subscription
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// SearchSubscriptions returns subscriptions whose name, notes, account or
+// payment method match every word of query, sorted by name
+func (r *SubscriptionRepository) SearchSubscriptions(query string) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	if err := r.matchText(r.db.Preload("Category"), query).Order("subscriptions.name ASC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// searchTextColumns are the columns a text search looks in
+var searchTextColumns = []string{"subscriptions.name", "subscriptions.notes", "subscriptions.account", "subscriptions.payment_method"}
+
+// searchWord matches the terms the FTS5 tokenizer keeps whole
+var searchWord = regexp.MustCompile(`^[\p{L}\p{N}]+$`)
+
+// matchText restricts query to subscriptions matching every word of text.
+// Each word must appear as a substring of one of the searched columns. When
+// the FTS5 index exists, plain words also match through it, which adds the
+// tokenizer's case and diacritic folding for non-ASCII text.
+func (r *SubscriptionRepository) matchText(query *gorm.DB, text string) *gorm.DB {
+	useIndex := r.checkSearchIndex()
+	for _, word := range strings.Fields(text) {
+		pattern := "%" + escapeLike(word) + "%"
+		conditions := make([]string, 0, len(searchTextColumns)+1)
+		args := make([]interface{}, 0, len(searchTextColumns)+1)
+		for _, column := range searchTextColumns {
+			conditions = append(conditions, column+" LIKE ? ESCAPE '\\'")
+			args = append(args, pattern)
+		}
+		if useIndex && searchWord.MatchString(word) {
+			conditions = append(conditions, "subscriptions.id IN (SELECT rowid FROM subscriptions_fts WHERE subscriptions_fts MATCH ?)")
+			args = append(args, `"`+word+`"*`)
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+	return query
+}
+
+// escapeLike escapes LIKE wildcards so a search term is matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+// sortColumns maps each accepted sort key to the column it orders by. Only
+// these columns are ever written into an ORDER BY clause.
+var sortColumns = map[string]string{
+	"name":         "name",
+	"cost":         "cost",
+	"status":       "status",
+	"renewal_date": "renewal_date",
+	"schedule":     "schedule",
+	"category":     "categories.name",
+	"created_at":   "created_at",
+}
+
+// IsSortableColumn reports whether subscriptions can be sorted by sortBy in SQL
+func IsSortableColumn(sortBy string) bool {
+	_, ok := sortColumns[sortBy]
+	return ok
+}
+
+// IsSortOrder reports whether order is "asc" or "desc"
+func IsSortOrder(order string) bool {
+	return order == "asc" || order == "desc"
+}
+
+// sortedQuery builds a subscription query ordered by a whitelisted column.
+// Empty or unknown columns and orders fall back to newest first.
+func (r *SubscriptionRepository) sortedQuery(sortBy, order string) *gorm.DB {
+	query := r.db.Preload("Category")
+
+	sortColumn, ok := sortColumns[sortBy]
 	if !ok {
-		sortColumn = "created_at" // default
+		if sortBy != "" {
+			slog.Warn("Ignoring unknown sort column", "sort", sortBy)
+		}
+		sortBy = "created_at"
+		sortColumn = "created_at"
 	}
 
-	// Validate order
-	if order != "asc" && order != "desc" {
-		order = "desc" // default
+	if !IsSortOrder(order) {
+		if order != "" {
+			slog.Warn("Ignoring unknown sort order", "order", order)
+		}
+		order = "desc"
 	}
 
 	// Build order clause
@@ -126,10 +283,7 @@ func (r *SubscriptionRepository) GetAllSorted(sortBy, order string) ([]models.Su
 		query = query.Joins("LEFT JOIN categories ON subscriptions.category_id = categories.id")
 	}
 
-	if err := query.Order(orderClause).Find(&subscriptions).Error; err != nil {
-		return nil, err
-	}
-	return subscriptions, nil
+	return query.Order(orderClause)
 }
 
 func (r *SubscriptionRepository) GetByID(id uint) (*models.Subscription, error) {
@@ -172,6 +326,14 @@ func (r *SubscriptionRepository) Update(id uint, subscription *models.Subscripti
 	existing.Notes = subscription.Notes
 	existing.Usage = subscription.Usage
 	existing.ReminderEnabled = subscription.ReminderEnabled
+	existing.ReminderDaysOverride = subscription.ReminderDaysOverride
+	existing.LockRenewalDate = subscription.LockRenewalDate
+	existing.NextChargeAmount = subscription.NextChargeAmount
+	existing.FirstChargeAmount = subscription.FirstChargeAmount
+	existing.FirstChargeDate = subscription.FirstChargeDate
+	existing.SharedWith = subscription.SharedWith
+	existing.CancellationURL = subscription.CancellationURL
+	existing.DisplayInOriginal = subscription.DisplayInOriginal
 
 	if columnExists && subscription.CategoryID > 0 {
 		// For legacy schema, we need to update the old category column too
@@ -199,6 +361,14 @@ func (r *SubscriptionRepository) Update(id uint, subscription *models.Subscripti
 				"last_reminder_sent":         existing.LastReminderSent,
 				"last_reminder_renewal_date": existing.LastReminderRenewalDate,
 				"reminder_enabled":                    existing.ReminderEnabled,
+				"reminder_days_override":          existing.ReminderDaysOverride,
+				"lock_renewal_date":               existing.LockRenewalDate,
+				"next_charge_amount":              existing.NextChargeAmount,
+				"first_charge_amount":             existing.FirstChargeAmount,
+				"first_charge_date":               existing.FirstChargeDate,
+				"shared_with":                     existing.SharedWith,
+				"cancellation_url":                existing.CancellationURL,
+				"display_in_original":             existing.DisplayInOriginal,
 				"last_cancellation_reminder_sent":     existing.LastCancellationReminderSent,
 				"last_cancellation_reminder_date":     existing.LastCancellationReminderDate,
 				"updated_at":                          time.Now(),
@@ -225,12 +395,28 @@ func (r *SubscriptionRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Subscription{}, id).Error
 }
 
+// DeleteAll removes every subscription and returns how many were deleted
+func (r *SubscriptionRepository) DeleteAll() (int64, error) {
+	result := r.db.Where("1 = 1").Delete(&models.Subscription{})
+	return result.RowsAffected, result.Error
+}
+
 func (r *SubscriptionRepository) Count() int64 {
 	var count int64
 	r.db.Model(&models.Subscription{}).Count(&count)
 	return count
 }
 
+// Scoped returns a repository whose queries only see subscriptions matching filter
+func (r *SubscriptionRepository) Scoped(filter models.StatsFilter) *SubscriptionRepository {
+	if filter.CategoryID == 0 {
+		return r
+	}
+	// A new session lets every query built on the scope reuse the condition without stacking it
+	db := r.db.Where("subscriptions.category_id = ?", filter.CategoryID).Session(&gorm.Session{})
+	return &SubscriptionRepository{db: db, hasLegacyColumn: r.hasLegacyColumn, hasSearchIndex: r.hasSearchIndex}
+}
+
 func (r *SubscriptionRepository) GetActiveSubscriptions() ([]models.Subscription, error) {
 	var subscriptions []models.Subscription
 	if err := r.db.Preload("Category").Where("status = ?", "Active").Find(&subscriptions).Error; err != nil {
@@ -247,12 +433,113 @@ func (r *SubscriptionRepository) GetCancelledSubscriptions() ([]models.Subscript
 	return subscriptions, nil
 }
 
+func (r *SubscriptionRepository) GetPausedSubscriptions() ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	if err := r.db.Preload("Category").Where("status = ?", "Paused").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// GetArchivedSubscriptions returns subscriptions kept for reference only, such as expired ones
+func (r *SubscriptionRepository) GetArchivedSubscriptions() ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	if err := r.db.Preload("Category").Where("status = ?", "Archived").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *SubscriptionRepository) GetTrialSubscriptions() ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	if err := r.db.Preload("Category").Where("status = ?", "Trial").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// SetCategory moves the given subscriptions to categoryID and returns how many rows changed.
+// It returns gorm.ErrRecordNotFound if the category doesn't exist.
+func (r *SubscriptionRepository) SetCategory(ids []uint, categoryID uint) (int64, error) {
+	var category models.Category
+	if err := r.db.First(&category, categoryID).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	updates := map[string]interface{}{"category_id": category.ID}
+	if r.checkLegacyColumn() {
+		updates["category"] = category.Name
+	}
+
+	result := r.db.Model(&models.Subscription{}).Where("id IN ?", ids).Updates(updates)
+	return result.RowsAffected, result.Error
+}
+
+// GetStaleRenewals returns active subscriptions whose stored renewal date is
+// before the given time. Hooks are skipped so AfterFind doesn't roll the dates
+// forward before they can be reported.
+func (r *SubscriptionRepository) GetStaleRenewals(before time.Time) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	if err := r.db.Session(&gorm.Session{SkipHooks: true}).Where("status = ? AND renewal_date IS NOT NULL AND renewal_date < ?",
+		"Active", before).Order("renewal_date ASC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// SetRenewalDate writes only the renewal date of a subscription, without running update hooks
+func (r *SubscriptionRepository) SetRenewalDate(id uint, date *time.Time) error {
+	return r.db.Model(&models.Subscription{}).Where("id = ?", id).UpdateColumn("renewal_date", date).Error
+}
+
 func (r *SubscriptionRepository) GetUpcomingRenewals(days int) ([]models.Subscription, error) {
 	var subscriptions []models.Subscription
 	endDate := time.Now().AddDate(0, 0, days)
 
 	if err := r.db.Where("status = ? AND renewal_date IS NOT NULL AND renewal_date BETWEEN ? AND ?",
-		"Active", time.Now(), endDate).Find(&subscriptions).Error; err != nil {
+		"Active", time.Now(), endDate).Order("renewal_date ASC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// GetUpcomingCharges returns active subscriptions with a renewal or a prorated
+// first charge in the next days days
+func (r *SubscriptionRepository) GetUpcomingCharges(days int) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	now := time.Now()
+	endDate := now.AddDate(0, 0, days)
+
+	if err := r.db.Where("status = ? AND ((renewal_date IS NOT NULL AND renewal_date BETWEEN ? AND ?) OR (first_charge_date IS NOT NULL AND first_charge_date BETWEEN ? AND ?))",
+		"Active", now, endDate, now, endDate).Order("renewal_date ASC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// MaxReminderDaysOverride returns the longest reminder lead time set on any
+// active subscription, or 0 if none has one
+func (r *SubscriptionRepository) MaxReminderDaysOverride() (int, error) {
+	var days *int
+	if err := r.db.Model(&models.Subscription{}).Where("status = ?", "Active").
+		Select("MAX(reminder_days_override)").Scan(&days).Error; err != nil {
+		return 0, err
+	}
+	if days == nil {
+		return 0, nil
+	}
+	return *days, nil
+}
+
+// GetRenewalsBetween returns active subscriptions whose renewal date falls in [from, to), soonest first
+func (r *SubscriptionRepository) GetRenewalsBetween(from, to time.Time) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	// Dates are stored as text with their UTC offset, so compare them as instants
+	if err := r.db.Preload("Category").Where("status = ? AND renewal_date IS NOT NULL AND datetime(renewal_date) >= datetime(?) AND datetime(renewal_date) < datetime(?)",
+		"Active", from, to).Order("datetime(renewal_date) ASC").Find(&subscriptions).Error; err != nil {
 		return nil, err
 	}
 	return subscriptions, nil
@@ -269,13 +556,58 @@ func (r *SubscriptionRepository) GetUpcomingCancellations(days int) ([]models.Su
 	return subscriptions, nil
 }
 
-func (r *SubscriptionRepository) GetCategoryStats() ([]models.CategoryStat, error) {
+// monthlyCostSQL returns the SQL equivalent of Subscription.MonthlyCost for
+// aggregate queries, using the current cost basis
+func monthlyCostSQL() string {
+	weeks := strconv.FormatFloat(models.WeeksPerMonth(), 'f', -1, 64)
+	days := strconv.FormatFloat(models.DaysPerMonth(), 'f', -1, 64)
+	// Like Subscription.effectiveInterval, a missing or non-positive interval counts as 1
+	const interval = "MAX(COALESCE(subscriptions.schedule_interval, 1), 1)"
+	return "(CASE WHEN subscriptions.schedule = 'Annual' THEN subscriptions.cost/12 WHEN subscriptions.schedule = 'Quarterly' THEN subscriptions.cost/3 WHEN subscriptions.schedule = 'Monthly' THEN subscriptions.cost WHEN subscriptions.schedule = 'Weekly' THEN subscriptions.cost*" + weeks + " WHEN subscriptions.schedule = 'Daily' THEN subscriptions.cost*" + days + " ELSE subscriptions.cost END) / " + interval
+}
+
+// GetCategoryStats returns monthly spend per category of the subscriptions with
+// one of statuses, highest first
+func (r *SubscriptionRepository) GetCategoryStats(statuses []string) ([]models.CategoryStat, error) {
 	var stats []models.CategoryStat
 	if err := r.db.Table("subscriptions").
-		Select("categories.name as category, SUM(CASE WHEN subscriptions.schedule = 'Annual' THEN subscriptions.cost/12 WHEN subscriptions.schedule = 'Quarterly' THEN subscriptions.cost/3 WHEN subscriptions.schedule = 'Monthly' THEN subscriptions.cost WHEN subscriptions.schedule = 'Weekly' THEN subscriptions.cost*4.33 WHEN subscriptions.schedule = 'Daily' THEN subscriptions.cost*30.44 ELSE subscriptions.cost END) as amount, COUNT(*) as count").
+		Select("categories.name as category, categories.color as color, categories.icon as icon, SUM("+monthlyCostSQL()+") as amount, COUNT(*) as count").
 		Joins("left join categories on subscriptions.category_id = categories.id").
+		Where("subscriptions.status IN ?", statuses).
+		Group("categories.name, categories.color, categories.icon").
+		Order("amount DESC").
+		Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetAccountStats returns monthly spend of active subscriptions grouped by
+// account, highest first. Subscriptions without one are grouped as "Unspecified".
+func (r *SubscriptionRepository) GetAccountStats() ([]models.AccountStat, error) {
+	const account = "COALESCE(NULLIF(TRIM(subscriptions.account), ''), 'Unspecified')"
+	var stats []models.AccountStat
+	if err := r.db.Table("subscriptions").
+		Select(account+" as account, SUM("+monthlyCostSQL()+") as amount, COUNT(*) as count").
+		Where("subscriptions.status = ?", "Active").
+		Group(account).
+		Order("amount DESC").
+		Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetPaymentMethodStats returns monthly spend of active subscriptions grouped by
+// payment method, highest first. Subscriptions without one are grouped as "Unspecified".
+func (r *SubscriptionRepository) GetPaymentMethodStats() ([]models.PaymentMethodStat, error) {
+	const method = "COALESCE(NULLIF(TRIM(subscriptions.payment_method), ''), 'Unspecified')"
+	var stats []models.PaymentMethodStat
+	if err := r.db.Table("subscriptions").
+		Select(method+" as payment_method, SUM("+monthlyCostSQL()+") as amount, COUNT(*) as count").
 		Where("subscriptions.status = ?", "Active").
-		Group("categories.name").
+		Group(method).
+		Order("amount DESC").
 		Scan(&stats).Error; err != nil {
 		return nil, err
 	}
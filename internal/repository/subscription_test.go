@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"subtrackr/internal/database"
+	"subtrackr/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSortedQuery_OnlyWhitelistedColumnsReachSQL(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	r := NewSubscriptionRepository(db.Session(&gorm.Session{DryRun: true}))
+
+	sql := func(sortBy, order string) string {
+		var subscriptions []models.Subscription
+		return r.sortedQuery(sortBy, order).Find(&subscriptions).Statement.SQL.String()
+	}
+
+	assert.Contains(t, sql("name", "asc"), "ORDER BY name ASC")
+	assert.Contains(t, sql("category", "desc"), "ORDER BY categories.name DESC")
+
+	injected := sql("name; DROP TABLE subscriptions", "asc")
+	assert.NotContains(t, injected, "DROP")
+	assert.Contains(t, injected, "ORDER BY created_at ASC")
+
+	badOrder := sql("cost", "asc, (SELECT 1)")
+	assert.NotContains(t, badOrder, "SELECT 1")
+	assert.Contains(t, badOrder, "ORDER BY cost DESC")
+}
+
+func TestIsSortableColumn(t *testing.T) {
+	assert.True(t, IsSortableColumn("renewal_date"))
+	assert.False(t, IsSortableColumn("monthly_cost"))
+	assert.False(t, IsSortableColumn("id; --"))
+	assert.True(t, IsSortOrder("asc"))
+	assert.False(t, IsSortOrder("ASC"))
+}
+
+func TestSearchSubscriptions_MultiWord(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	seed := []models.Subscription{
+		{Name: "Netflix", Notes: "Family plan shared with parents", PaymentMethod: "Visa", Account: "me@example.com"},
+		{Name: "Spotify Family", Notes: "Music for the kids", PaymentMethod: "Amex", Account: "me@example.com"},
+		{Name: "Adobe Creative Cloud", Notes: "Work expense", PaymentMethod: "Visa", Account: "work@example.com"},
+	}
+	for i := range seed {
+		seed[i].Cost, seed[i].Schedule, seed[i].Status, seed[i].CategoryID = 10, "Monthly", "Active", 1
+		assert.NoError(t, db.Create(&seed[i]).Error)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{"Single word matches name or notes", "family", []string{"Netflix", "Spotify Family"}},
+		{"Every word must match", "family visa", []string{"Netflix"}},
+		{"Words may match different columns", "cloud work", []string{"Adobe Creative Cloud"}},
+		{"Word order does not matter", "parents family", []string{"Netflix"}},
+		{"Prefixes match", "fam par", []string{"Netflix"}},
+		{"Substrings match", "flix", []string{"Netflix"}},
+		{"Extra whitespace is ignored", "  music   amex ", []string{"Spotify Family"}},
+		{"Unmatched word excludes everything", "family hulu", []string{}},
+		{"Punctuation is matched literally", "work@example", []string{"Adobe Creative Cloud"}},
+	}
+
+	for mode, useIndex := range map[string]bool{"LIKE": false, "FTS5": true} {
+		t.Run(mode, func(t *testing.T) {
+			r := NewSubscriptionRepository(db)
+			if useIndex && !r.checkSearchIndex() {
+				t.Skip("SQLite was built without FTS5; build with -tags sqlite_fts5 to run")
+			}
+			r.hasSearchIndex = &useIndex
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					results, err := r.SearchSubscriptions(tt.query)
+					assert.NoError(t, err)
+
+					names := []string{}
+					for _, sub := range results {
+						names = append(names, sub.Name)
+					}
+					assert.Equal(t, tt.expected, names)
+				})
+			}
+		})
+	}
+}
+
+func TestUpdate_PersistsAllEditableFields(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	r := NewSubscriptionRepository(db)
+
+	created, err := r.Create(&models.Subscription{Name: "Netflix", Cost: 15.49, Schedule: "Monthly", Status: "Active"})
+	if err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+
+	nextCharge := 17.99
+	reminderDays := 10
+	firstCharge := 6.5
+	firstChargeDate := time.Date(2030, 1, 5, 0, 0, 0, 0, time.UTC)
+	created.NextChargeAmount = &nextCharge
+	created.FirstChargeAmount = &firstCharge
+	created.FirstChargeDate = &firstChargeDate
+	created.SharedWith = 3
+	created.CancellationURL = "https://netflix.com/cancel"
+	created.DisplayInOriginal = true
+	created.ReminderDaysOverride = &reminderDays
+	if _, err := r.Update(created.ID, created); err != nil {
+		t.Fatalf("Failed to update subscription: %v", err)
+	}
+
+	saved, err := r.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to reload subscription: %v", err)
+	}
+	if assert.NotNil(t, saved.NextChargeAmount) {
+		assert.Equal(t, 17.99, *saved.NextChargeAmount)
+	}
+	assert.Equal(t, 3, saved.SharedWith)
+	assert.Equal(t, "https://netflix.com/cancel", saved.CancellationURL)
+	assert.True(t, saved.DisplayInOriginal)
+	if assert.NotNil(t, saved.ReminderDaysOverride) {
+		assert.Equal(t, 10, *saved.ReminderDaysOverride)
+	}
+	if assert.NotNil(t, saved.FirstChargeAmount) {
+		assert.Equal(t, 6.5, *saved.FirstChargeAmount)
+	}
+	if assert.NotNil(t, saved.FirstChargeDate) {
+		assert.True(t, firstChargeDate.Equal(*saved.FirstChargeDate))
+	}
+}
+
+func TestMonthlyCostSQL_MatchesMonthlyCost(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	seed := []models.Subscription{
+		{Name: "Monthly", Cost: 10, Schedule: "Monthly", ScheduleInterval: 1},
+		{Name: "Every 2 months", Cost: 30, Schedule: "Monthly", ScheduleInterval: 2},
+		{Name: "Annual", Cost: 120, Schedule: "Annual", ScheduleInterval: 1},
+		{Name: "Every 3 years", Cost: 360, Schedule: "Annual", ScheduleInterval: 3},
+		{Name: "Quarterly", Cost: 45, Schedule: "Quarterly", ScheduleInterval: 1},
+		{Name: "Biweekly", Cost: 10, Schedule: "Weekly", ScheduleInterval: 2},
+		{Name: "Daily", Cost: 1, Schedule: "Daily", ScheduleInterval: 1},
+		{Name: "Every 4 days", Cost: 2, Schedule: "Daily", ScheduleInterval: 4},
+	}
+	expected := 0.0
+	for i := range seed {
+		seed[i].Status = "Active"
+		seed[i].Account = "me@example.com"
+		if err := db.Create(&seed[i]).Error; err != nil {
+			t.Fatalf("Failed to seed subscription: %v", err)
+		}
+		expected += seed[i].MonthlyCost()
+	}
+	// A zero interval from an old row is treated as 1, as in MonthlyCost
+	if err := db.Model(&seed[0]).Update("schedule_interval", 0).Error; err != nil {
+		t.Fatalf("Failed to clear interval: %v", err)
+	}
+
+	r := NewSubscriptionRepository(db)
+	for _, s := range seed {
+		var amount float64
+		if err := db.Table("subscriptions").Select(monthlyCostSQL()).Where("id = ?", s.ID).Scan(&amount).Error; err != nil {
+			t.Fatalf("Failed to compute monthly cost: %v", err)
+		}
+		assert.InDelta(t, s.MonthlyCost(), amount, 0.0001, s.Name)
+	}
+
+	accounts, err := r.GetAccountStats()
+	assert.NoError(t, err)
+	if assert.Len(t, accounts, 1) {
+		assert.InDelta(t, expected, accounts[0].Amount, 0.0001)
+	}
+	categories, err := r.GetCategoryStats([]string{"Active"})
+	assert.NoError(t, err)
+	if assert.Len(t, categories, 1) {
+		assert.InDelta(t, expected, categories[0].Amount, 0.0001)
+	}
+	methods, err := r.GetPaymentMethodStats()
+	assert.NoError(t, err)
+	if assert.Len(t, methods, 1) {
+		assert.InDelta(t, expected, methods[0].Amount, 0.0001)
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"subtrackr/internal/models"
+	"time"
+)
+
+// DefaultAppriseServerURL is the Apprise API server used when none is configured
+const DefaultAppriseServerURL = "http://localhost:8000"
+
+// Apprise notification types used by SubTrackr; they set a notification's icon and colour
+const (
+	AppriseTypeInfo    = "info"
+	AppriseTypeWarning = "warning"
+)
+
+// AppriseService handles sending notifications through an Apprise API server,
+// which fans them out to any service Apprise supports
+type AppriseService struct {
+	settingsService *SettingsService
+}
+
+// NewAppriseService creates a new Apprise service
+func NewAppriseService(settingsService *SettingsService) *AppriseService {
+	return &AppriseService{
+		settingsService: settingsService,
+	}
+}
+
+// appriseRequest is the JSON body of the Apprise API's stateless /notify/ endpoint
+type appriseRequest struct {
+	URLs  string `json:"urls"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Type  string `json:"type"`
+}
+
+// SendNotification sends a notification to the configured Apprise URLs
+func (a *AppriseService) SendNotification(title, body, notifyType string) error {
+	config, err := a.settingsService.GetAppriseConfig()
+	if err != nil || len(config.URLs) == 0 {
+		return nil // Not configured, silently skip (matches webhook behavior)
+	}
+	return a.Send(config, title, body, notifyType)
+}
+
+// Send delivers a notification using config rather than the saved configuration,
+// so a configuration can be tested before it is saved
+func (a *AppriseService) Send(config *models.AppriseConfig, title, body, notifyType string) error {
+	if len(config.URLs) == 0 {
+		return fmt.Errorf("no Apprise URLs configured")
+	}
+
+	serverURL := strings.TrimRight(config.ServerURL, "/")
+	if serverURL == "" {
+		serverURL = DefaultAppriseServerURL
+	}
+
+	jsonData, err := json.Marshal(appriseRequest{
+		URLs:  strings.Join(config.URLs, ","),
+		Title: title,
+		Body:  body,
+		Type:  notifyType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Apprise request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", serverURL+"/notify/", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SubTrackr-Apprise/1.0")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send Apprise notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// The API explains failures in the body, e.g. which URLs couldn't be notified
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if msg := strings.TrimSpace(string(detail)); msg != "" {
+			return fmt.Errorf("Apprise returned status %d: %s", resp.StatusCode, msg)
+		}
+		return fmt.Errorf("Apprise returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Skips reports whether event won't be sent through Apprise, because the event
+// or the channel is turned off, it is quiet hours or no Apprise URLs are configured
+func (a *AppriseService) Skips(event string) bool {
+	if !eventEnabled(a.settingsService, event) || !a.settingsService.IsNotificationChannelEnabled(NotificationChannelApprise) {
+		return true
+	}
+	if a.settingsService.IsWithinQuietHours(time.Now()) {
+		return true // Push notifications are held back during quiet hours
+	}
+	config, err := a.settingsService.GetAppriseConfig()
+	return err != nil || len(config.URLs) == 0 // Not configured
+}
+
+// SendHighCostAlert sends an Apprise alert when a high-cost subscription is created
+func (a *AppriseService) SendHighCostAlert(subscription *models.Subscription) error {
+	if a.Skips(NotificationEventHighCost) {
+		return nil
+	}
+
+	title, message := highCostAlertText(subscription, a.settingsService)
+	return a.SendNotification(title, message, AppriseTypeWarning)
+}
+
+// SendRenewalReminder sends an Apprise reminder for an upcoming subscription renewal
+func (a *AppriseService) SendRenewalReminder(subscription *models.Subscription, daysUntilRenewal int) error {
+	if a.Skips(NotificationEventRenewal) {
+		return nil
+	}
+
+	title, message := renewalReminderText(subscription, daysUntilRenewal, a.settingsService)
+	return a.SendNotification(title, message, AppriseTypeInfo)
+}
+
+// SendCancellationReminder sends an Apprise reminder for an upcoming subscription cancellation
+func (a *AppriseService) SendCancellationReminder(subscription *models.Subscription, daysUntilCancellation int) error {
+	if a.Skips(NotificationEventCancellation) {
+		return nil
+	}
+
+	title, message := cancellationReminderText(subscription, daysUntilCancellation, a.settingsService)
+	return a.SendNotification(title, message, AppriseTypeWarning)
+}
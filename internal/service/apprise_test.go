@@ -0,0 +1,138 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAppriseTest(t *testing.T) (*SettingsService, *AppriseService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Settings{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	settingsService := NewSettingsService(repository.NewSettingsRepository(db))
+	return settingsService, NewAppriseService(settingsService)
+}
+
+// newAppriseServer records the requests an Apprise API server receives and
+// answers them with status
+func newAppriseServer(t *testing.T, status int, received *[]appriseRequest) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/notify/", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var req appriseRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		*received = append(*received, req)
+
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			w.Write([]byte("One or more notifications could not be sent"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAppriseService_SendNotification_NoConfig(t *testing.T) {
+	_, as := setupAppriseTest(t)
+
+	err := as.SendNotification("Test", "Test message", AppriseTypeInfo)
+	assert.NoError(t, err, "Should silently skip when Apprise is not configured")
+}
+
+func TestAppriseService_SendNotification(t *testing.T) {
+	ss, as := setupAppriseTest(t)
+
+	var received []appriseRequest
+	server := newAppriseServer(t, http.StatusOK, &received)
+	assert.NoError(t, ss.SaveAppriseConfig(&models.AppriseConfig{
+		URLs:      []string{"tgram://bottoken/ChatID", "discord://id/token"},
+		ServerURL: server.URL + "/",
+	}))
+
+	err := as.SendNotification("Title", "Body", AppriseTypeWarning)
+	assert.NoError(t, err)
+	if assert.Len(t, received, 1) {
+		assert.Equal(t, appriseRequest{
+			URLs:  "tgram://bottoken/ChatID,discord://id/token",
+			Title: "Title",
+			Body:  "Body",
+			Type:  AppriseTypeWarning,
+		}, received[0])
+	}
+}
+
+func TestAppriseService_SendNotification_ServerError(t *testing.T) {
+	ss, as := setupAppriseTest(t)
+
+	var received []appriseRequest
+	server := newAppriseServer(t, http.StatusFailedDependency, &received)
+	assert.NoError(t, ss.SaveAppriseConfig(&models.AppriseConfig{URLs: []string{"tgram://bottoken/ChatID"}, ServerURL: server.URL}))
+
+	err := as.SendNotification("Title", "Body", AppriseTypeInfo)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "424")
+		assert.Contains(t, err.Error(), "could not be sent")
+	}
+}
+
+func TestAppriseService_Send_RequiresURLs(t *testing.T) {
+	_, as := setupAppriseTest(t)
+
+	err := as.Send(&models.AppriseConfig{ServerURL: "http://localhost:8000"}, "Title", "Body", AppriseTypeInfo)
+	assert.Error(t, err)
+}
+
+func TestAppriseService_SendRenewalReminder(t *testing.T) {
+	ss, as := setupAppriseTest(t)
+
+	var received []appriseRequest
+	server := newAppriseServer(t, http.StatusOK, &received)
+	assert.NoError(t, ss.SaveAppriseConfig(&models.AppriseConfig{URLs: []string{"ntfy://topic"}, ServerURL: server.URL}))
+	ss.SetCurrency("USD")
+
+	subscription := &models.Subscription{
+		Name:        "Netflix",
+		Cost:        15.99,
+		Schedule:    "Monthly",
+		Status:      "Active",
+		RenewalDate: timePtr(time.Now().AddDate(0, 0, 1)),
+	}
+
+	t.Run("Skipped when reminders are disabled", func(t *testing.T) {
+		ss.SetBoolSetting("renewal_reminders", false)
+		assert.NoError(t, as.SendRenewalReminder(subscription, 1))
+		assert.Empty(t, received)
+	})
+
+	t.Run("Sent when reminders are enabled", func(t *testing.T) {
+		ss.SetBoolSetting("renewal_reminders", true)
+		assert.NoError(t, as.SendRenewalReminder(subscription, 1))
+		if assert.Len(t, received, 1) {
+			assert.Equal(t, "Renewal Reminder: Netflix", received[0].Title)
+			assert.Contains(t, received[0].Body, "will renew in 1 day.")
+			assert.Equal(t, AppriseTypeInfo, received[0].Type)
+		}
+	})
+
+	t.Run("Skipped when the Apprise channel is disabled", func(t *testing.T) {
+		ss.SetNotificationChannelEnabled(NotificationChannelApprise, false)
+		assert.NoError(t, as.SendRenewalReminder(subscription, 1))
+		assert.Len(t, received, 1, "No further request should reach the server")
+	})
+}
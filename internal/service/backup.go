@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrBackupUnavailable is returned when the database has no file to back up
+var ErrBackupUnavailable = errors.New("in-memory databases cannot be backed up")
+
+const (
+	backupPrefix     = "subtrackr-"
+	backupExt        = ".db"
+	backupTimeLayout = "20060102-150405.000"
+)
+
+// BackupService writes copies of the SQLite database to a backup directory and
+// rotates out the oldest ones
+type BackupService struct {
+	db   *gorm.DB
+	dir  string // Empty for in-memory databases
+	keep int
+}
+
+// NewBackupService backs up db, opened from dbPath, into dir, keeping the newest
+// keep copies (0 keeps all). An empty dir uses a backups directory next to the
+// database file.
+func NewBackupService(db *gorm.DB, dbPath, dir string, keep int) *BackupService {
+	s := &BackupService{db: db, keep: keep}
+	if isInMemoryDatabase(dbPath) {
+		return s
+	}
+	if dir == "" {
+		dir = filepath.Join(filepath.Dir(databaseFile(dbPath)), "backups")
+	}
+	s.dir = dir
+	return s
+}
+
+// Enabled reports whether the database is a file that can be backed up
+func (s *BackupService) Enabled() bool {
+	return s.dir != ""
+}
+
+// Backup writes a consistent copy of the database named after now and prunes
+// old copies. It returns the backup's file name; a failure to prune is only
+// logged, since the new backup was still written.
+func (s *BackupService) Backup(now time.Time) (string, error) {
+	if !s.Enabled() {
+		return "", ErrBackupUnavailable
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", fmt.Errorf("create backup directory: %w", err)
+	}
+
+	name := backupPrefix + now.UTC().Format(backupTimeLayout) + backupExt
+	// VACUUM INTO reads one snapshot, so the copy is consistent while the app keeps writing
+	if err := s.db.Exec("VACUUM INTO ?", filepath.Join(s.dir, name)).Error; err != nil {
+		return "", fmt.Errorf("write backup: %w", err)
+	}
+
+	if err := s.prune(); err != nil {
+		slog.Warn("Failed to remove old database backups", "dir", s.dir, "error", err)
+	}
+	return name, nil
+}
+
+// Backups lists the backup file names, oldest first
+func (s *BackupService) Backups() ([]string, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupExt) {
+			names = append(names, name)
+		}
+	}
+	// The timestamp in the name sorts chronologically
+	sort.Strings(names)
+	return names, nil
+}
+
+// prune deletes all but the newest keep backups
+func (s *BackupService) prune() error {
+	if s.keep <= 0 {
+		return nil
+	}
+	names, err := s.Backups()
+	if err != nil {
+		return err
+	}
+	for len(names) > s.keep {
+		if err := os.Remove(filepath.Join(s.dir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// databaseFile strips the "file:" prefix and query options from a SQLite DSN
+func databaseFile(dbPath string) string {
+	dbPath = strings.TrimPrefix(dbPath, "file:")
+	if i := strings.Index(dbPath, "?"); i >= 0 {
+		dbPath = dbPath[:i]
+	}
+	return dbPath
+}
+
+func isInMemoryDatabase(dbPath string) bool {
+	return databaseFile(dbPath) == ":memory:" || strings.Contains(dbPath, "mode=memory")
+}
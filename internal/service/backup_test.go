@@ -0,0 +1,87 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"subtrackr/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestBackupService_BackupAndRotate(t *testing.T) {
+	dataDir := t.TempDir()
+	dbPath := filepath.Join(dataDir, "subtrackr.db")
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Category{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	assert.NoError(t, db.Create(&models.Category{Name: "Streaming"}).Error)
+
+	s := NewBackupService(db, dbPath+"?_journal_mode=WAL", "", 2)
+	assert.True(t, s.Enabled())
+
+	start := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	var names []string
+	for day := 0; day < 3; day++ {
+		name, err := s.Backup(start.AddDate(0, 0, day))
+		assert.NoError(t, err)
+		names = append(names, name)
+	}
+	assert.Equal(t, "subtrackr-20260301-020000.000.db", names[0])
+
+	// Only the newest two are kept, in the default directory next to the database
+	kept, err := s.Backups()
+	assert.NoError(t, err)
+	assert.Equal(t, names[1:], kept)
+	_, err = os.Stat(filepath.Join(dataDir, "backups", names[0]))
+	assert.True(t, os.IsNotExist(err))
+
+	// A backup is a complete, openable database
+	backupDB, err := gorm.Open(sqlite.Open(filepath.Join(dataDir, "backups", names[2])), &gorm.Config{})
+	assert.NoError(t, err)
+	var category models.Category
+	assert.NoError(t, backupDB.First(&category).Error)
+	assert.Equal(t, "Streaming", category.Name)
+}
+
+func TestBackupService_KeepZeroKeepsAll(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "subtrackr.db")
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	backupDir := filepath.Join(t.TempDir(), "custom")
+
+	s := NewBackupService(db, dbPath, backupDir, 0)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_, err := s.Backup(start.Add(time.Duration(i) * time.Hour))
+		assert.NoError(t, err)
+	}
+
+	entries, err := os.ReadDir(backupDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+func TestBackupService_InMemoryIsSkipped(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+
+	for _, dsn := range []string{":memory:", "file::memory:?cache=shared", "file:test?mode=memory"} {
+		s := NewBackupService(db, dsn, t.TempDir(), 7)
+		assert.False(t, s.Enabled(), dsn)
+		_, err := s.Backup(time.Now())
+		assert.ErrorIs(t, err, ErrBackupUnavailable)
+	}
+}
@@ -2,10 +2,21 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"subtrackr/internal/models"
 	"subtrackr/internal/repository"
 )
 
+// Errors returned when a category fails validation
+var (
+	ErrInvalidCategoryColor = errors.New("color must be a hex value like #3b82f6")
+	ErrCategoryIconTooLong  = errors.New("icon must be at most 32 characters")
+	ErrMergeIntoSelf        = errors.New("cannot merge a category into itself")
+)
+
+const maxCategoryIconLength = 32
+
 // CategoryService provides business logic for categories
 type CategoryService struct {
 	repo *repository.CategoryRepository
@@ -16,6 +27,12 @@ func NewCategoryService(repo *repository.CategoryRepository) *CategoryService {
 }
 
 func (s *CategoryService) Create(category *models.Category) (*models.Category, error) {
+	if err := normalizeCategory(category); err != nil {
+		return nil, err
+	}
+	if category.Color == "" {
+		category.Color = models.DefaultCategoryColor(category.Name)
+	}
 	return s.repo.Create(category)
 }
 
@@ -27,22 +44,68 @@ func (s *CategoryService) GetByID(id uint) (*models.Category, error) {
 	return s.repo.GetByID(id)
 }
 
+// Update applies the non-empty fields of category; an empty color or icon leaves the stored one
 func (s *CategoryService) Update(id uint, category *models.Category) (*models.Category, error) {
+	if err := normalizeCategory(category); err != nil {
+		return nil, err
+	}
 	return s.repo.Update(id, category)
 }
 
+// normalizeCategory trims and validates the color and icon of category
+func normalizeCategory(category *models.Category) error {
+	category.Color = strings.ToLower(strings.TrimSpace(category.Color))
+	category.Icon = strings.TrimSpace(category.Icon)
+	if category.Color != "" && !models.IsValidCategoryColor(category.Color) {
+		return ErrInvalidCategoryColor
+	}
+	if len([]rune(category.Icon)) > maxCategoryIconLength {
+		return ErrCategoryIconTooLong
+	}
+	return nil
+}
+
+// Reorder sets the display order of categories; unlisted ones follow the listed ones
+func (s *CategoryService) Reorder(ids []uint) error {
+	return s.repo.Reorder(ids)
+}
+
 func (s *CategoryService) GetByName(name string) (*models.Category, error) {
 	return s.repo.GetByName(name)
 }
 
+// CategoryInUseError is returned when deleting a category that subscriptions still reference
+type CategoryInUseError struct {
+	Subscriptions int64
+}
+
+func (e *CategoryInUseError) Error() string {
+	return fmt.Sprintf("cannot delete category used by %d subscription(s); reassign them to another category first", e.Subscriptions)
+}
+
+// Delete removes a category, refusing with a *CategoryInUseError while subscriptions reference it
 func (s *CategoryService) Delete(id uint) error {
-	// Check if category has any subscriptions
-	hasSubscriptions, err := s.repo.HasSubscriptions(id)
+	count, err := s.repo.CountSubscriptions(id)
 	if err != nil {
 		return err
 	}
-	if hasSubscriptions {
-		return errors.New("cannot delete category with active subscriptions")
+	if count > 0 {
+		return &CategoryInUseError{Subscriptions: count}
 	}
 	return s.repo.Delete(id)
 }
+
+// DeleteAndReassign moves the category's subscriptions to reassignTo, deletes it,
+// and returns how many subscriptions were moved
+func (s *CategoryService) DeleteAndReassign(id, reassignTo uint) (int64, error) {
+	return s.MergeCategory(id, reassignTo)
+}
+
+// MergeCategory moves every subscription from sourceID to targetID, deletes the
+// source category, and returns how many subscriptions were moved
+func (s *CategoryService) MergeCategory(sourceID, targetID uint) (int64, error) {
+	if sourceID == targetID {
+		return 0, ErrMergeIntoSelf
+	}
+	return s.repo.Merge(sourceID, targetID)
+}
@@ -4,7 +4,7 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
@@ -75,6 +75,20 @@ func init() {
 	}
 }
 
+// symbolAfterCurrencies are conventionally written with the symbol after the amount ("9,99 €")
+var symbolAfterCurrencies = map[string]bool{
+	"EUR": true, "PLN": true, "SEK": true, "NOK": true, "DKK": true,
+	"CZK": true, "HUF": true, "RON": true, "RUB": true,
+}
+
+// DefaultSymbolPosition returns where the currency's symbol conventionally goes
+func DefaultSymbolPosition(code string) string {
+	if symbolAfterCurrencies[code] {
+		return SymbolPositionAfter
+	}
+	return SymbolPositionBefore
+}
+
 // GetCurrencyInfo returns metadata for a currency code, with a fallback for unknown codes
 func GetCurrencyInfo(code string) CurrencyInfo {
 	if info, ok := currencyInfoMap[code]; ok {
@@ -83,6 +97,12 @@ func GetCurrencyInfo(code string) CurrencyInfo {
 	return CurrencyInfo{Code: code, Symbol: code, Name: code}
 }
 
+// IsSupportedCurrency reports whether code is one of the built-in currencies
+func IsSupportedCurrency(code string) bool {
+	_, ok := currencyInfoMap[code]
+	return ok
+}
+
 // GetAvailableCurrencies returns all supported currencies
 func GetAvailableCurrencies() []CurrencyInfo {
 	return BuiltinCurrencies
@@ -96,6 +116,7 @@ func supportedCurrencySymbols() string {
 type CurrencyService struct {
 	repo   *repository.ExchangeRateRepository
 	apiKey string
+	client *http.Client // Fetches rates from Fixer.io
 }
 
 type FixerResponse struct {
@@ -116,9 +137,23 @@ func NewCurrencyService(repo *repository.ExchangeRateRepository) *CurrencyServic
 	return &CurrencyService{
 		repo:   repo,
 		apiKey: os.Getenv("FIXER_API_KEY"),
+		// Configure HTTP client with security and timeout settings
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					MinVersion: tls.VersionTLS12, // Require TLS 1.2 or higher
+				},
+			},
+		},
 	}
 }
 
+// SetHTTPClient replaces the client used to fetch rates from Fixer.io
+func (s *CurrencyService) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
 // IsEnabled returns true if currency conversion is enabled (API key is set)
 func (s *CurrencyService) IsEnabled() bool {
 	return s.apiKey != ""
@@ -126,23 +161,69 @@ func (s *CurrencyService) IsEnabled() bool {
 
 // GetExchangeRate retrieves exchange rate between two currencies
 func (s *CurrencyService) GetExchangeRate(fromCurrency, toCurrency string) (float64, error) {
+	rate, _, err := s.GetExchangeRateWithDate(fromCurrency, toCurrency)
+	return rate, err
+}
+
+// GetExchangeRateWithDate retrieves exchange rate between two currencies along
+// with the time the rate was published
+func (s *CurrencyService) GetExchangeRateWithDate(fromCurrency, toCurrency string) (float64, time.Time, error) {
 	if fromCurrency == toCurrency {
-		return 1.0, nil
+		return 1.0, time.Now(), nil
 	}
 
 	// Try to get cached rate first
-	rate, err := s.repo.GetRate(fromCurrency, toCurrency)
-	if err == nil && !rate.IsStale() {
-		return rate.Rate, nil
+	cachedRate, cachedDate, cached := s.cachedRate(fromCurrency, toCurrency)
+	if cached && !models.IsRateStale(cachedDate) {
+		return cachedRate, cachedDate, nil
 	}
 
 	// If no API key, return error
 	if !s.IsEnabled() {
-		return 0, fmt.Errorf("currency conversion not available - no Fixer API key configured")
+		return 0, time.Time{}, fmt.Errorf("currency conversion not available - no Fixer API key configured")
 	}
 
 	// Fetch from Fixer.io API
-	return s.fetchAndCacheRates(fromCurrency, toCurrency)
+	rate, date, err := s.fetchAndCacheRates(fromCurrency, toCurrency)
+	if err != nil && cached {
+		// An outdated rate beats no conversion at all; callers can tell it's stale from its date
+		slog.Debug("Failed to refresh exchange rate, using stale cached rate", "from", fromCurrency, "to", toCurrency, "date", cachedDate, "error", err)
+		return cachedRate, cachedDate, nil
+	}
+	return rate, date, err
+}
+
+// cachedRate returns the newest cached rate between two currencies, stale or
+// not: either a direct rate or one calculated from the EUR-based rates, since
+// Fixer.io rates are cached with EUR as the base.
+func (s *CurrencyService) cachedRate(fromCurrency, toCurrency string) (rate float64, date time.Time, ok bool) {
+	if direct, err := s.repo.GetRate(fromCurrency, toCurrency); err == nil {
+		rate, date, ok = direct.Rate, direct.Date, true
+	}
+	if crossRate, crossDate, crossOK := s.cachedCrossRate(fromCurrency, toCurrency); crossOK && (!ok || crossDate.After(date)) {
+		rate, date, ok = crossRate, crossDate, true
+	}
+	return rate, date, ok
+}
+
+// cachedCrossRate calculates the rate between two currencies from the cached
+// EUR-based rates. ok is false if either rate is missing.
+func (s *CurrencyService) cachedCrossRate(fromCurrency, toCurrency string) (rate float64, date time.Time, ok bool) {
+	eurToFrom, err := s.repo.GetRate("EUR", fromCurrency)
+	if err != nil || eurToFrom.Rate == 0 {
+		return 0, time.Time{}, false
+	}
+	eurToTo, err := s.repo.GetRate("EUR", toCurrency)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	// A rate is only as recent as the older of the two it's derived from
+	date = eurToFrom.Date
+	if eurToTo.Date.Before(date) {
+		date = eurToTo.Date
+	}
+	return eurToTo.Rate / eurToFrom.Rate, date, true
 }
 
 // ConvertAmount converts an amount from one currency to another
@@ -157,7 +238,7 @@ func (s *CurrencyService) ConvertAmount(amount float64, fromCurrency, toCurrency
 // fetchAndCacheRates fetches rates from Fixer.io and caches them.
 // Note: Free Fixer.io plan only supports EUR base, so baseCurrency parameter
 // is used for cross-rate calculations but API always fetches with EUR base.
-func (s *CurrencyService) fetchAndCacheRates(baseCurrency, targetCurrency string) (float64, error) {
+func (s *CurrencyService) fetchAndCacheRates(baseCurrency, targetCurrency string) (float64, time.Time, error) {
 	// Use supported currencies as comma-separated string
 	symbols := supportedCurrencySymbols()
 
@@ -169,37 +250,28 @@ func (s *CurrencyService) fetchAndCacheRates(baseCurrency, targetCurrency string
 	// Validate URL to ensure we're calling the expected API
 	parsedURL, err := url.Parse(apiURL)
 	if err != nil {
-		return 0, fmt.Errorf("invalid API URL: %w", err)
+		return 0, time.Time{}, fmt.Errorf("invalid API URL: %w", err)
 	}
 	if parsedURL.Host != "data.fixer.io" {
-		return 0, fmt.Errorf("unauthorized API host: %s", parsedURL.Host)
+		return 0, time.Time{}, fmt.Errorf("unauthorized API host: %s", parsedURL.Host)
 	}
 
-	// Configure HTTP client with security and timeout settings
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				MinVersion: tls.VersionTLS12, // Require TLS 1.2 or higher
-			},
-		},
-	}
-	resp, err := client.Get(apiURL)
+	resp, err := s.client.Get(apiURL)
 	if err != nil {
-		return 0, fmt.Errorf("failed to fetch exchange rates: %w", err)
+		return 0, time.Time{}, fmt.Errorf("failed to fetch exchange rates: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var fixerResp FixerResponse
 	if err := json.NewDecoder(resp.Body).Decode(&fixerResp); err != nil {
-		return 0, fmt.Errorf("failed to decode response: %w", err)
+		return 0, time.Time{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if !fixerResp.Success {
 		if fixerResp.Error != nil {
-			return 0, fmt.Errorf("Fixer API error: %s", fixerResp.Error.Info)
+			return 0, time.Time{}, fmt.Errorf("Fixer API error: %s", fixerResp.Error.Info)
 		}
-		return 0, fmt.Errorf("Fixer API request failed")
+		return 0, time.Time{}, fmt.Errorf("Fixer API request failed")
 	}
 
 	// Parse date
@@ -229,7 +301,7 @@ func (s *CurrencyService) fetchAndCacheRates(baseCurrency, targetCurrency string
 	if len(ratesToSave) > 0 {
 		if err := s.repo.SaveRates(ratesToSave); err != nil {
 			// Log error but don't fail the request
-			log.Printf("Warning: failed to cache exchange rates: %v", err)
+			slog.Warn("Failed to cache exchange rates", "error", err)
 		}
 	}
 
@@ -237,12 +309,12 @@ func (s *CurrencyService) fetchAndCacheRates(baseCurrency, targetCurrency string
 	if baseCurrency == "EUR" {
 		// Direct rate from EUR
 		if rate, exists := fixerResp.Rates[targetCurrency]; exists {
-			return rate, nil
+			return rate, rateDate, nil
 		}
 	} else if targetCurrency == "EUR" {
 		// Inverse rate to EUR
 		if rate, exists := fixerResp.Rates[baseCurrency]; exists && rate != 0 {
-			return 1.0 / rate, nil
+			return 1.0 / rate, rateDate, nil
 		}
 	} else {
 		// Cross-rate: base->EUR->target
@@ -251,11 +323,11 @@ func (s *CurrencyService) fetchAndCacheRates(baseCurrency, targetCurrency string
 
 		if exists1 && exists2 && baseToEur != 0 {
 			// Convert: (1/baseToEur) * eurToTarget = cross rate
-			return eurToTarget / baseToEur, nil
+			return eurToTarget / baseToEur, rateDate, nil
 		}
 	}
 
-	return 0, fmt.Errorf("exchange rate for %s to %s not available", baseCurrency, targetCurrency)
+	return 0, time.Time{}, fmt.Errorf("exchange rate for %s to %s not available", baseCurrency, targetCurrency)
 }
 
 // RefreshRates updates all exchange rates from the API
@@ -266,7 +338,7 @@ func (s *CurrencyService) RefreshRates() error {
 
 	// Fetch rates once with EUR base (free Fixer.io plan only supports EUR base)
 	// All cross-rates are calculated from this single API call
-	_, err := s.fetchAndCacheRates("EUR", "USD")
+	_, _, err := s.fetchAndCacheRates("EUR", "USD")
 	if err != nil {
 		return fmt.Errorf("failed to refresh rates: %w", err)
 	}
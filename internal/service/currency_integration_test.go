@@ -287,4 +287,33 @@ func TestSettingsService_SetCurrency_BDT(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestCurrencyService_Integration_ConvertAmount_CrossRateFromEURCache(t *testing.T) {
+	// No API key, so the conversion can only come from the cache
+	os.Unsetenv("FIXER_API_KEY")
+
+	db := setupTestDB(t)
+	repo := repository.NewExchangeRateRepository(db)
+	service := NewCurrencyService(repo)
+
+	// Rates as cached by fetchAndCacheRates: EUR based only
+	now := time.Now()
+	err := repo.SaveRates([]models.ExchangeRate{
+		{BaseCurrency: "EUR", Currency: "EUR", Rate: 1.0, Date: now},
+		{BaseCurrency: "EUR", Currency: "GBP", Rate: 0.85, Date: now},
+		{BaseCurrency: "EUR", Currency: "JPY", Rate: 161.5, Date: now},
+	})
+	assert.NoError(t, err)
+
+	result, err := service.ConvertAmount(10, "GBP", "JPY")
+	assert.NoError(t, err)
+	assert.InDelta(t, 10*161.5/0.85, result, 0.0001)
+
+	result, err = service.ConvertAmount(10, "JPY", "EUR")
+	assert.NoError(t, err)
+	assert.InDelta(t, 10/161.5, result, 0.0001)
+
+	_, err = service.ConvertAmount(10, "GBP", "USD")
+	assert.Error(t, err, "USD isn't cached, so there's nothing to derive the rate from")
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"strings"
 	"subtrackr/internal/models"
 	"subtrackr/internal/repository"
 	"testing"
@@ -71,6 +72,14 @@ func TestSupportedCurrencies_DerivedFromBuiltin(t *testing.T) {
 	}
 }
 
+func TestSupportedCurrencySymbols_IncludesEverySelectableCurrency(t *testing.T) {
+	symbols := strings.Split(supportedCurrencySymbols(), ",")
+	for _, info := range GetAvailableCurrencies() {
+		assert.Contains(t, symbols, info.Code, "Fixer should be asked for every currency that can be selected")
+	}
+	assert.Len(t, symbols, len(BuiltinCurrencies))
+}
+
 func TestCurrencyInfoMap_AllEntriesPresent(t *testing.T) {
 	for _, info := range BuiltinCurrencies {
 		mapped, ok := currencyInfoMap[info.Code]
@@ -98,7 +107,7 @@ func TestCurrencySymbolForCode(t *testing.T) {
 	}
 }
 
-func TestCurrencySymbolForSubscription(t *testing.T) {
+func TestFormatMoneyForSubscription(t *testing.T) {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("Failed to open test database: %v", err)
@@ -115,27 +124,27 @@ func TestCurrencySymbolForSubscription(t *testing.T) {
 	tests := []struct {
 		name             string
 		originalCurrency string
-		expectedSymbol   string
+		expected         string
 	}{
 		{
 			name:             "Same as preferred currency",
 			originalCurrency: "USD",
-			expectedSymbol:   "$",
+			expected:         "$9.99",
 		},
 		{
 			name:             "Different from preferred currency",
 			originalCurrency: "EUR",
-			expectedSymbol:   "€",
+			expected:         "9.99 €",
 		},
 		{
 			name:             "Empty original currency uses preferred",
 			originalCurrency: "",
-			expectedSymbol:   "$",
+			expected:         "$9.99",
 		},
 		{
-			name:             "COP shows COL$ symbol",
+			name:             "COP uses COL$ symbol",
 			originalCurrency: "COP",
-			expectedSymbol:   "COL$",
+			expected:         "COL$9.99",
 		},
 	}
 
@@ -145,8 +154,7 @@ func TestCurrencySymbolForSubscription(t *testing.T) {
 				Name:             "Test",
 				OriginalCurrency: tt.originalCurrency,
 			}
-			symbol := currencySymbolForSubscription(sub, settingsService)
-			assert.Equal(t, tt.expectedSymbol, symbol)
+			assert.Equal(t, tt.expected, formatMoneyForSubscription(9.99, sub, settingsService))
 		})
 	}
 }
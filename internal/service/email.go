@@ -5,31 +5,67 @@ import (
 	"crypto/tls"
 	"fmt"
 	"html/template"
+	"mime"
 	"net/smtp"
+	"strings"
+	"subtrackr/internal/metrics"
 	"subtrackr/internal/models"
+	"time"
 )
 
-// currencySymbolForSubscription returns the appropriate currency symbol for a subscription.
-// If the subscription has an original currency that differs from the preferred currency,
-// use the subscription's own currency symbol to avoid misleading display.
-func currencySymbolForSubscription(subscription *models.Subscription, settings *SettingsService) string {
-	preferred := settings.GetCurrency()
-	if subscription.OriginalCurrency != "" && subscription.OriginalCurrency != preferred {
-		return CurrencySymbolForCode(subscription.OriginalCurrency)
+// formatMoneyForSubscription formats amount in the subscription's own currency,
+// falling back to the preferred currency when it has none
+func formatMoneyForSubscription(amount float64, subscription *models.Subscription, settings *SettingsService) string {
+	currency := settings.GetCurrency()
+	if subscription.OriginalCurrency != "" {
+		currency = subscription.OriginalCurrency
 	}
-	return settings.GetCurrencySymbol()
+	return settings.FormatMoneyIn(amount, currency)
 }
 
 // EmailService handles sending emails via SMTP
 type EmailService struct {
 	settingsService *SettingsService
+	send            func(subject, body string) error // Delivers notification emails; SendEmail unless replaced in tests
 }
 
 // NewEmailService creates a new email service
 func NewEmailService(settingsService *SettingsService) *EmailService {
-	return &EmailService{
+	e := &EmailService{
 		settingsService: settingsService,
 	}
+	e.send = e.SendEmail
+	return e
+}
+
+// subscriptionLink returns a link that opens subscription in SubTrackr, or ""
+// when no base URL is configured, since a scheduled email has no request to
+// infer one from
+func (e *EmailService) subscriptionLink(subscription *models.Subscription) string {
+	baseURL := strings.TrimRight(e.settingsService.GetBaseURL(), "/")
+	if baseURL == "" || subscription.ID == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s/subscriptions?edit=%d", baseURL, subscription.ID)
+}
+
+// buildEmailMessage returns the headers and HTML body of an email. Headers can
+// only carry ASCII, so a subject or sender name with symbols such as € is
+// encoded as an RFC 2047 encoded-word; otherwise mail clients show it mangled.
+func buildEmailMessage(config *models.SMTPConfig, subject, body string) string {
+	fromName := config.FromName
+	if fromName == "" {
+		fromName = "SubTrackr"
+	}
+
+	message := fmt.Sprintf("From: %s <%s>\r\n", mime.QEncoding.Encode("UTF-8", fromName), config.From)
+	message += fmt.Sprintf("To: %s\r\n", config.To)
+	message += fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	message += "MIME-Version: 1.0\r\n"
+	message += "Content-Type: text/html; charset=UTF-8\r\n"
+	message += "\r\n"
+	message += body
+	return message
 }
 
 // SendEmail sends an email using the configured SMTP settings
@@ -89,20 +125,7 @@ func (e *EmailService) SendEmail(subject, body string) error {
 			return fmt.Errorf("failed to get data writer: %w", err)
 		}
 
-		fromName := config.FromName
-		if fromName == "" {
-			fromName = "SubTrackr"
-		}
-
-		message := fmt.Sprintf("From: %s <%s>\r\n", fromName, config.From)
-		message += fmt.Sprintf("To: %s\r\n", config.To)
-		message += fmt.Sprintf("Subject: %s\r\n", subject)
-		message += "MIME-Version: 1.0\r\n"
-		message += "Content-Type: text/html; charset=UTF-8\r\n"
-		message += "\r\n"
-		message += body
-
-		_, err = writer.Write([]byte(message))
+		_, err = writer.Write([]byte(buildEmailMessage(config, subject, body)))
 		if err != nil {
 			return fmt.Errorf("failed to write message: %w", err)
 		}
@@ -146,20 +169,7 @@ func (e *EmailService) SendEmail(subject, body string) error {
 			return fmt.Errorf("failed to get data writer: %w", err)
 		}
 
-		fromName := config.FromName
-		if fromName == "" {
-			fromName = "SubTrackr"
-		}
-
-		message := fmt.Sprintf("From: %s <%s>\r\n", fromName, config.From)
-		message += fmt.Sprintf("To: %s\r\n", config.To)
-		message += fmt.Sprintf("Subject: %s\r\n", subject)
-		message += "MIME-Version: 1.0\r\n"
-		message += "Content-Type: text/html; charset=UTF-8\r\n"
-		message += "\r\n"
-		message += body
-
-		_, err = writer.Write([]byte(message))
+		_, err = writer.Write([]byte(buildEmailMessage(config, subject, body)))
 		if err != nil {
 			return fmt.Errorf("failed to write message: %w", err)
 		}
@@ -172,16 +182,22 @@ func (e *EmailService) SendEmail(subject, body string) error {
 	return nil
 }
 
+// Skips reports whether event won't be sent through email, because the event
+// or the channel is turned off
+func (e *EmailService) Skips(event string) bool {
+	return !eventEnabled(e.settingsService, event) || !e.settingsService.IsNotificationChannelEnabled(NotificationChannelEmail)
+}
+
 // SendHighCostAlert sends an email alert when a high-cost subscription is created
 func (e *EmailService) SendHighCostAlert(subscription *models.Subscription) error {
-	// Check if high cost alerts are enabled
-	enabled, err := e.settingsService.GetBoolSetting("high_cost_alerts", true)
-	if err != nil || !enabled {
-		return nil // Silently skip if disabled
+	if e.Skips(NotificationEventHighCost) {
+		return nil
 	}
 
-	// Get currency symbol - use subscription's own currency if it differs from preferred
-	currencySymbol := currencySymbolForSubscription(subscription, e.settingsService)
+	// Format amounts in the subscription's own currency
+	money := func(amount float64) string {
+		return formatMoneyForSubscription(amount, subscription, e.settingsService)
+	}
 
 	// Build email body
 	tmpl := `
@@ -208,12 +224,13 @@ func (e *EmailService) SendHighCostAlert(subscription *models.Subscription) erro
 		<div class="subscription-details">
 			<h3>Subscription Details</h3>
 			<div class="detail-row"><span class="label">Name:</span> {{.Subscription.Name}}</div>
-			<div class="detail-row"><span class="label">Cost:</span> {{.CurrencySymbol}}{{printf "%.2f" .Subscription.Cost}} {{.Subscription.DisplaySchedule}}</div>
-			<div class="detail-row"><span class="label">Monthly Cost:</span> {{.CurrencySymbol}}{{printf "%.2f" (.Subscription.MonthlyCost)}}</div>
+			<div class="detail-row"><span class="label">Cost:</span> {{money .Subscription.Cost}} {{.Subscription.DisplaySchedule}}</div>
+			<div class="detail-row"><span class="label">{{.BasisLabel}}:</span> {{money .BasisAmount}}</div>
 			{{if and .Subscription.Category .Subscription.Category.Name}}<div class="detail-row"><span class="label">Category:</span> {{.Subscription.Category.Name}}</div>{{end}}
 			{{if .FormattedRenewalDate}}<div class="detail-row"><span class="label">Next Renewal:</span> {{.FormattedRenewalDate}}</div>{{end}}
 			{{if .Subscription.URL}}<div class="detail-row"><span class="label">URL:</span> <a href="{{.Subscription.URL}}">{{.Subscription.URL}}</a></div>{{end}}
 		</div>
+		{{if .SubscriptionLink}}<p><a href="{{.SubscriptionLink}}">View in SubTrackr</a></p>{{end}}
 		<div class="footer">
 			<p>This is an automated notification from SubTrackr.</p>
 			<p>You can manage your notification preferences in the Settings page.</p>
@@ -225,22 +242,27 @@ func (e *EmailService) SendHighCostAlert(subscription *models.Subscription) erro
 
 	type AlertData struct {
 		Subscription        *models.Subscription
-		CurrencySymbol      string
 		FormattedRenewalDate string
+		BasisLabel           string
+		BasisAmount          float64
+		SubscriptionLink     string
 	}
 
 	var formattedRenewal string
 	if subscription.RenewalDate != nil {
-		formattedRenewal = subscription.RenewalDate.Format(e.settingsService.GetGoDateFormatLong())
+		formattedRenewal = subscription.RenewalDate.In(e.settingsService.GetLocation()).Format(e.settingsService.GetGoDateFormatLong())
 	}
 
+	basisLabel, basisAmount := highCostAmount(subscription, e.settingsService)
 	data := AlertData{
 		Subscription:        subscription,
-		CurrencySymbol:      currencySymbol,
 		FormattedRenewalDate: formattedRenewal,
+		BasisLabel:           basisLabel,
+		BasisAmount:          basisAmount,
+		SubscriptionLink:     e.subscriptionLink(subscription),
 	}
 
-	t, err := template.New("highCostAlert").Parse(tmpl)
+	t, err := template.New("highCostAlert").Funcs(template.FuncMap{"money": money}).Parse(tmpl)
 	if err != nil {
 		return fmt.Errorf("failed to parse email template: %w", err)
 	}
@@ -250,20 +272,20 @@ func (e *EmailService) SendHighCostAlert(subscription *models.Subscription) erro
 		return fmt.Errorf("failed to execute email template: %w", err)
 	}
 
-	subject := fmt.Sprintf("High Cost Alert: %s - %s%.2f/month", subscription.Name, currencySymbol, subscription.MonthlyCost())
-	return e.SendEmail(subject, buf.String())
+	subject := fmt.Sprintf("High Cost Alert: %s - %s/month", subscription.Name, money(subscription.MonthlyCost()))
+	return e.send(subject, buf.String())
 }
 
 // SendRenewalReminder sends an email reminder for an upcoming subscription renewal
 func (e *EmailService) SendRenewalReminder(subscription *models.Subscription, daysUntilRenewal int) error {
-	// Check if renewal reminders are enabled
-	enabled, err := e.settingsService.GetBoolSetting("renewal_reminders", false)
-	if err != nil || !enabled {
-		return nil // Silently skip if disabled
+	if e.Skips(NotificationEventRenewal) {
+		return nil
 	}
 
-	// Get currency symbol - use subscription's own currency if it differs from preferred
-	currencySymbol := currencySymbolForSubscription(subscription, e.settingsService)
+	// Format amounts in the subscription's own currency
+	money := func(amount float64) string {
+		return formatMoneyForSubscription(amount, subscription, e.settingsService)
+	}
 
 	// Build email body
 	tmpl := `
@@ -290,12 +312,14 @@ func (e *EmailService) SendRenewalReminder(subscription *models.Subscription, da
 		<div class="subscription-details">
 			<h3>Subscription Details</h3>
 			<div class="detail-row"><span class="label">Name:</span> {{.Subscription.Name}}</div>
-			<div class="detail-row"><span class="label">Cost:</span> {{.CurrencySymbol}}{{printf "%.2f" .Subscription.Cost}} {{.Subscription.DisplaySchedule}}</div>
-			<div class="detail-row"><span class="label">Monthly Cost:</span> {{.CurrencySymbol}}{{printf "%.2f" (.Subscription.MonthlyCost)}}</div>
+			<div class="detail-row"><span class="label">Cost:</span> {{money .Subscription.Cost}} {{.Subscription.DisplaySchedule}}</div>
+			<div class="detail-row"><span class="label">Monthly Cost:</span> {{money .Subscription.MonthlyCost}}</div>
+			{{if .FirstCharge}}<div class="detail-row"><span class="label">First Charge (prorated):</span> {{money .Subscription.FirstChargeCost}}</div>{{else if .Subscription.NextChargeAmount}}<div class="detail-row"><span class="label">Next Charge:</span> {{money .Subscription.NextChargeCost}}</div>{{end}}
 			{{if and .Subscription.Category .Subscription.Category.Name}}<div class="detail-row"><span class="label">Category:</span> {{.Subscription.Category.Name}}</div>{{end}}
-			{{if .FormattedRenewalDate}}<div class="detail-row"><span class="label">Renewal Date:</span> {{.FormattedRenewalDate}}</div>{{end}}
+			{{if .FormattedRenewalDate}}<div class="detail-row"><span class="label">{{if .FirstCharge}}First Charge Date{{else}}Renewal Date{{end}}:</span> {{.FormattedRenewalDate}}</div>{{end}}
 			{{if .Subscription.URL}}<div class="detail-row"><span class="label">URL:</span> <a href="{{.Subscription.URL}}">{{.Subscription.URL}}</a></div>{{end}}
 		</div>
+		{{if .SubscriptionLink}}<p><a href="{{.SubscriptionLink}}">View in SubTrackr</a></p>{{end}}
 		<div class="footer">
 			<p>This is an automated reminder from SubTrackr.</p>
 			<p>You can manage your notification preferences in the Settings page.</p>
@@ -308,23 +332,26 @@ func (e *EmailService) SendRenewalReminder(subscription *models.Subscription, da
 	type ReminderData struct {
 		Subscription         *models.Subscription
 		DaysUntilRenewal     int
-		CurrencySymbol       string
+		FirstCharge          bool // The reminder is for the prorated first charge
 		FormattedRenewalDate string
+		SubscriptionLink     string
 	}
 
+	now := time.Now()
 	var formattedRenewal string
-	if subscription.RenewalDate != nil {
-		formattedRenewal = subscription.RenewalDate.Format(e.settingsService.GetGoDateFormatLong())
+	if chargeDate := subscription.UpcomingChargeDate(now); chargeDate != nil {
+		formattedRenewal = chargeDate.In(e.settingsService.GetLocation()).Format(e.settingsService.GetGoDateFormatLong())
 	}
 
 	data := ReminderData{
 		Subscription:         subscription,
 		DaysUntilRenewal:     daysUntilRenewal,
-		CurrencySymbol:       currencySymbol,
+		FirstCharge:          subscription.PendingFirstCharge(now),
 		FormattedRenewalDate: formattedRenewal,
+		SubscriptionLink:     e.subscriptionLink(subscription),
 	}
 
-	t, err := template.New("renewalReminder").Parse(tmpl)
+	t, err := template.New("renewalReminder").Funcs(template.FuncMap{"money": money}).Parse(tmpl)
 	if err != nil {
 		return fmt.Errorf("failed to parse email template: %w", err)
 	}
@@ -339,19 +366,21 @@ func (e *EmailService) SendRenewalReminder(subscription *models.Subscription, da
 		daysText = "day"
 	}
 	subject := fmt.Sprintf("Renewal Reminder: %s renews in %d %s", subscription.Name, daysUntilRenewal, daysText)
-	return e.SendEmail(subject, buf.String())
+	err = e.send(subject, buf.String())
+	metrics.RecordReminderEmail("renewal", err)
+	return err
 }
 
 // SendCancellationReminder sends an email reminder for an upcoming subscription cancellation
 func (e *EmailService) SendCancellationReminder(subscription *models.Subscription, daysUntilCancellation int) error {
-	// Check if cancellation reminders are enabled
-	enabled, err := e.settingsService.GetBoolSetting("cancellation_reminders", false)
-	if err != nil || !enabled {
-		return nil // Silently skip if disabled
+	if e.Skips(NotificationEventCancellation) {
+		return nil
 	}
 
-	// Get currency symbol - use subscription's own currency if it differs from preferred
-	currencySymbol := currencySymbolForSubscription(subscription, e.settingsService)
+	// Format amounts in the subscription's own currency
+	money := func(amount float64) string {
+		return formatMoneyForSubscription(amount, subscription, e.settingsService)
+	}
 
 	// Build email body
 	tmpl := `
@@ -378,12 +407,14 @@ func (e *EmailService) SendCancellationReminder(subscription *models.Subscriptio
 		<div class="subscription-details">
 			<h3>Subscription Details</h3>
 			<div class="detail-row"><span class="label">Name:</span> {{.Subscription.Name}}</div>
-			<div class="detail-row"><span class="label">Cost:</span> {{.CurrencySymbol}}{{printf "%.2f" .Subscription.Cost}} {{.Subscription.DisplaySchedule}}</div>
-			<div class="detail-row"><span class="label">Monthly Cost:</span> {{.CurrencySymbol}}{{printf "%.2f" (.Subscription.MonthlyCost)}}</div>
+			<div class="detail-row"><span class="label">Cost:</span> {{money .Subscription.Cost}} {{.Subscription.DisplaySchedule}}</div>
+			<div class="detail-row"><span class="label">Monthly Cost:</span> {{money .Subscription.MonthlyCost}}</div>
 			{{if and .Subscription.Category .Subscription.Category.Name}}<div class="detail-row"><span class="label">Category:</span> {{.Subscription.Category.Name}}</div>{{end}}
 			{{if .FormattedCancellationDate}}<div class="detail-row"><span class="label">Cancellation Date:</span> {{.FormattedCancellationDate}}</div>{{end}}
 			{{if .Subscription.URL}}<div class="detail-row"><span class="label">URL:</span> <a href="{{.Subscription.URL}}">{{.Subscription.URL}}</a></div>{{end}}
+			{{if .Subscription.CancellationURL}}<div class="detail-row"><span class="label">Cancel at:</span> <a href="{{.Subscription.CancellationURL}}">{{.Subscription.CancellationURL}}</a></div>{{end}}
 		</div>
+		{{if .SubscriptionLink}}<p><a href="{{.SubscriptionLink}}">View in SubTrackr</a></p>{{end}}
 		<div class="footer">
 			<p>This is an automated reminder from SubTrackr.</p>
 			<p>You can manage your notification preferences in the Settings page.</p>
@@ -396,23 +427,23 @@ func (e *EmailService) SendCancellationReminder(subscription *models.Subscriptio
 	type CancellationReminderData struct {
 		Subscription               *models.Subscription
 		DaysUntilCancellation      int
-		CurrencySymbol             string
 		FormattedCancellationDate  string
+		SubscriptionLink          string
 	}
 
 	var formattedCancellation string
 	if subscription.CancellationDate != nil {
-		formattedCancellation = subscription.CancellationDate.Format(e.settingsService.GetGoDateFormatLong())
+		formattedCancellation = subscription.CancellationDate.In(e.settingsService.GetLocation()).Format(e.settingsService.GetGoDateFormatLong())
 	}
 
 	data := CancellationReminderData{
 		Subscription:              subscription,
 		DaysUntilCancellation:     daysUntilCancellation,
-		CurrencySymbol:            currencySymbol,
 		FormattedCancellationDate: formattedCancellation,
+		SubscriptionLink:          e.subscriptionLink(subscription),
 	}
 
-	t, err := template.New("cancellationReminder").Parse(tmpl)
+	t, err := template.New("cancellationReminder").Funcs(template.FuncMap{"money": money}).Parse(tmpl)
 	if err != nil {
 		return fmt.Errorf("failed to parse email template: %w", err)
 	}
@@ -427,5 +458,7 @@ func (e *EmailService) SendCancellationReminder(subscription *models.Subscriptio
 		daysText = "day"
 	}
 	subject := fmt.Sprintf("Cancellation Reminder: %s ends in %d %s", subscription.Name, daysUntilCancellation, daysText)
-	return e.SendEmail(subject, buf.String())
+	err = e.send(subject, buf.String())
+	metrics.RecordReminderEmail("cancellation", err)
+	return err
 }
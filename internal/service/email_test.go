@@ -0,0 +1,51 @@
+package service
+
+import (
+	"io"
+	"mime"
+	"net/mail"
+	"strings"
+	"subtrackr/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildEmailMessage_EncodesMultiByteHeaders(t *testing.T) {
+	config := &models.SMTPConfig{From: "alerts@example.com", FromName: "Zoë's SubTrackr", To: "me@example.com"}
+	subject := "High Cost Alert: Netflix - €15.99/month"
+	body := "<p>Cost: ₹499.00, 39,99 zł</p>"
+
+	raw := buildEmailMessage(config, subject, body)
+	for _, line := range strings.Split(raw[:strings.Index(raw, "\r\n\r\n")], "\r\n") {
+		for _, r := range line {
+			assert.Less(t, r, rune(128), "Header %q should be plain ASCII", line)
+		}
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if !assert.NoError(t, err) {
+		return
+	}
+	decoder := new(mime.WordDecoder)
+	decodedSubject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, subject, decodedSubject)
+
+	from, err := msg.Header.AddressList("From")
+	if assert.NoError(t, err) && assert.Len(t, from, 1) {
+		assert.Equal(t, "Zoë's SubTrackr", from[0].Name)
+		assert.Equal(t, "alerts@example.com", from[0].Address)
+	}
+
+	assert.Equal(t, "text/html; charset=UTF-8", msg.Header.Get("Content-Type"))
+	decodedBody, _ := io.ReadAll(msg.Body)
+	assert.Equal(t, body, string(decodedBody), "The UTF-8 body is sent as is")
+}
+
+func TestBuildEmailMessage_LeavesASCIIHeadersAlone(t *testing.T) {
+	raw := buildEmailMessage(&models.SMTPConfig{From: "alerts@example.com", To: "me@example.com"}, "Renewal Reminder: Netflix renews in 3 days", "<p>Hi</p>")
+
+	assert.Contains(t, raw, "From: SubTrackr <alerts@example.com>\r\n")
+	assert.Contains(t, raw, "Subject: Renewal Reminder: Netflix renews in 3 days\r\n")
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// idempotencyScope identifies a key per API key, so clients with different API
+// keys can't replay each other's subscriptions by reusing an Idempotency-Key.
+// Requests made without an API key use API key ID 0.
+type idempotencyScope struct {
+	apiKeyID uint
+	key      string
+}
+
+// idempotencyEntry records the subscription created for one Idempotency-Key.
+// A zero subscriptionID means the first request is still in progress.
+type idempotencyEntry struct {
+	subscriptionID uint
+	seenAt         time.Time
+}
+
+// IdempotencyKeys remembers which subscription each Idempotency-Key created, so a
+// retried create returns the original result instead of a duplicate.
+// State is kept in memory and entries expire after the TTL.
+type IdempotencyKeys struct {
+	mu      sync.Mutex
+	entries map[idempotencyScope]*idempotencyEntry
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// NewIdempotencyKeys creates a store that keeps keys for ttl. A ttl of zero or
+// less disables it.
+func NewIdempotencyKeys(ttl time.Duration) *IdempotencyKeys {
+	return &IdempotencyKeys{
+		entries: make(map[idempotencyScope]*idempotencyEntry),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Enabled reports whether keys are being remembered
+func (k *IdempotencyKeys) Enabled() bool {
+	return k != nil && k.ttl > 0
+}
+
+// Claim reserves key for a new request made with the given API key. It returns
+// claimed=true when the caller should go ahead and create; otherwise it returns
+// the subscription the key already created, or zero if that request hasn't
+// finished yet.
+func (k *IdempotencyKeys) Claim(apiKeyID uint, key string) (subscriptionID uint, claimed bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+	k.pruneExpired(now)
+
+	scope := idempotencyScope{apiKeyID: apiKeyID, key: key}
+	if entry, ok := k.entries[scope]; ok {
+		return entry.subscriptionID, false
+	}
+	k.entries[scope] = &idempotencyEntry{seenAt: now}
+	return 0, true
+}
+
+// Complete records the subscription created for a claimed key
+func (k *IdempotencyKeys) Complete(apiKeyID uint, key string, subscriptionID uint) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if entry, ok := k.entries[idempotencyScope{apiKeyID: apiKeyID, key: key}]; ok {
+		entry.subscriptionID = subscriptionID
+	}
+}
+
+// Release forgets a claimed key after a failed request, so it can be retried
+func (k *IdempotencyKeys) Release(apiKeyID uint, key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	delete(k.entries, idempotencyScope{apiKeyID: apiKeyID, key: key})
+}
+
+// pruneExpired drops keys first seen more than the TTL ago
+func (k *IdempotencyKeys) pruneExpired(now time.Time) {
+	for scope, entry := range k.entries {
+		if now.Sub(entry.seenAt) > k.ttl {
+			delete(k.entries, scope)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyKeys_ClaimCompleteExpire(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	k := NewIdempotencyKeys(24 * time.Hour)
+	k.now = func() time.Time { return now }
+
+	id, claimed := k.Claim(1, "abc")
+	assert.True(t, claimed)
+	assert.Zero(t, id)
+
+	id, claimed = k.Claim(1, "abc")
+	assert.False(t, claimed, "a key in progress can't be claimed twice")
+	assert.Zero(t, id)
+
+	k.Complete(1, "abc", 42)
+	id, claimed = k.Claim(1, "abc")
+	assert.False(t, claimed)
+	assert.Equal(t, uint(42), id)
+
+	now = now.Add(24*time.Hour + time.Second)
+	_, claimed = k.Claim(1, "abc")
+	assert.True(t, claimed, "keys expire after the TTL")
+}
+
+func TestIdempotencyKeys_Release(t *testing.T) {
+	k := NewIdempotencyKeys(time.Hour)
+
+	_, claimed := k.Claim(1, "abc")
+	assert.True(t, claimed)
+	k.Release(1, "abc")
+
+	_, claimed = k.Claim(1, "abc")
+	assert.True(t, claimed, "a released key can be retried")
+}
+
+func TestIdempotencyKeys_ScopedByAPIKey(t *testing.T) {
+	k := NewIdempotencyKeys(time.Hour)
+
+	_, claimed := k.Claim(1, "abc")
+	assert.True(t, claimed)
+	k.Complete(1, "abc", 42)
+
+	id, claimed := k.Claim(2, "abc")
+	assert.True(t, claimed, "another API key can use the same Idempotency-Key")
+	assert.Zero(t, id)
+}
+
+func TestIdempotencyKeys_Enabled(t *testing.T) {
+	var nilKeys *IdempotencyKeys
+	assert.False(t, nilKeys.Enabled())
+	assert.False(t, NewIdempotencyKeys(0).Enabled())
+	assert.True(t, NewIdempotencyKeys(time.Hour).Enabled())
+}
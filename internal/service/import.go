@@ -0,0 +1,199 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"subtrackr/internal/models"
+	"time"
+)
+
+// ImportRow is one entry of another tracker's export. Subscription is nil when
+// the row couldn't be mapped, with Error saying why.
+type ImportRow struct {
+	Index        int                  `json:"index"`
+	Name         string               `json:"name,omitempty"`
+	Category     string               `json:"category,omitempty"` // Category name, resolved when the row is saved
+	Subscription *models.Subscription `json:"-"`
+	Error        string               `json:"error,omitempty"`
+}
+
+// ImportMapper translates a tracker's JSON export into subscriptions. It only
+// fails for a payload it can't read at all; bad rows are reported per row.
+type ImportMapper func(data []byte) ([]ImportRow, error)
+
+// importMappers holds the supported import formats by name
+var importMappers = map[string]ImportMapper{
+	"generic": mapGenericImport,
+	"wallos":  mapWallosImport,
+}
+
+// ImportMapperFor returns the mapper for a format name
+func ImportMapperFor(format string) (ImportMapper, bool) {
+	mapper, ok := importMappers[strings.ToLower(format)]
+	return mapper, ok
+}
+
+// ImportFormats lists the supported import format names
+func ImportFormats() []string {
+	formats := make([]string, 0, len(importMappers))
+	for format := range importMappers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// decodeImportRows reads a JSON array of objects, or an object holding one
+// under key (such as SubTrackr's own {"subscriptions": [...]} export)
+func decodeImportRows(data []byte, key string) ([]map[string]any, error) {
+	var rows []map[string]any
+	if err := json.Unmarshal(data, &rows); err == nil {
+		return rows, nil
+	}
+
+	var wrapped map[string]json.RawMessage
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of subscriptions")
+	}
+	if err := json.Unmarshal(wrapped[key], &rows); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of subscriptions or an object with a %q array", key)
+	}
+	return rows, nil
+}
+
+// importField returns the first non-empty value among keys, as a trimmed string
+func importField(row map[string]any, keys ...string) string {
+	for _, key := range keys {
+		switch v := row[key].(type) {
+		case string:
+			if s := strings.TrimSpace(v); s != "" {
+				return s
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			return strconv.FormatBool(v)
+		}
+	}
+	return ""
+}
+
+var importAmountPattern = regexp.MustCompile(`-?\d[\d.,]*`)
+
+// parseImportAmount reads an amount that may carry a currency symbol or code,
+// such as "9.99", "$9.99", "9,99 €" or "EUR 9.99". The currency is empty when
+// none is recognised.
+func parseImportAmount(value string) (amount float64, currency string, err error) {
+	raw := importAmountPattern.FindString(value)
+	number := raw
+	if number == "" {
+		return 0, "", fmt.Errorf("invalid price %q", value)
+	}
+
+	// A comma is the decimal separator when it comes last and is followed by 1-2 digits
+	if i := strings.LastIndex(number, ","); i > strings.LastIndex(number, ".") && len(number)-i-1 <= 2 {
+		number = strings.ReplaceAll(number[:i], ".", "") + "." + number[i+1:]
+	} else {
+		number = strings.ReplaceAll(number, ",", "")
+	}
+	amount, err = strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid price %q", value)
+	}
+
+	return amount, importCurrency(strings.TrimSpace(strings.Replace(value, raw, "", 1))), nil
+}
+
+// importCurrency recognises a currency code or symbol. A symbol shared by
+// several currencies, such as "kr", resolves to the first one listed.
+func importCurrency(value string) string {
+	if value == "" {
+		return ""
+	}
+	upper := strings.ToUpper(value)
+	if _, ok := currencyInfoMap[upper]; ok {
+		return upper
+	}
+	for _, c := range BuiltinCurrencies {
+		if value == c.Symbol {
+			return c.Code
+		}
+	}
+	return ""
+}
+
+var importCyclePattern = regexp.MustCompile(`^every\s+(\d+)\s+(day|week|month|quarter|year)s?$`)
+
+// parseImportCycle maps a billing cycle such as "Monthly", "yearly" or
+// "Every 3 Months" to a schedule and interval
+func parseImportCycle(value string) (schedule string, interval int, err error) {
+	cycle := strings.ToLower(strings.TrimSpace(value))
+	interval = 1
+	if m := importCyclePattern.FindStringSubmatch(cycle); m != nil {
+		interval, _ = strconv.Atoi(m[1])
+		cycle = m[2]
+	}
+
+	switch cycle {
+	case "day", "daily":
+		schedule = "Daily"
+	case "week", "weekly":
+		schedule = "Weekly"
+	case "month", "monthly":
+		schedule = "Monthly"
+	case "quarter", "quarterly":
+		schedule = "Quarterly"
+	case "year", "yearly", "annual", "annually":
+		schedule = "Annual"
+	default:
+		return "", 0, fmt.Errorf("unknown billing cycle %q", value)
+	}
+	if interval < 1 {
+		return "", 0, fmt.Errorf("unknown billing cycle %q", value)
+	}
+	return schedule, interval, nil
+}
+
+// parseImportDate reads a YYYY-MM-DD date or an RFC 3339 timestamp. Empty
+// values give nil.
+func parseImportDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", value, models.Location()); err == nil {
+		return &date, nil
+	}
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return &date, nil
+	}
+	return nil, fmt.Errorf("invalid date %q", value)
+}
+
+// newImportRow finishes a mapped row, reporting it as unmapped if mapping or
+// validation failed
+func newImportRow(index int, subscription *models.Subscription, category string, err error) ImportRow {
+	row := ImportRow{Index: index, Name: subscription.Name, Category: category}
+	if err == nil {
+		err = subscription.Validate()
+	}
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	row.Subscription = subscription
+	return row
+}
+
+// newImportedSubscription returns a subscription with the defaults a create gets
+func newImportedSubscription() *models.Subscription {
+	return &models.Subscription{
+		Status:           "Active",
+		OriginalCurrency: "USD",
+		ScheduleInterval: 1,
+		ReminderEnabled:  true,
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"subtrackr/internal/models"
+)
+
+// mapGenericImport reads a JSON array of flat objects using common field names,
+// which also covers SubTrackr's own JSON export:
+//
+//	name or title                                   required
+//	cost, price or amount                           required, may include a currency symbol
+//	schedule, cycle, billing_cycle or frequency     "monthly", "yearly", "every 2 weeks", ...
+//	interval                                        multiplies the cycle
+//	currency or original_currency                   ISO code, defaults to USD
+//	renewal_date, next_payment or next_billing_date YYYY-MM-DD or RFC 3339
+//	start_date, cancellation_date                   YYYY-MM-DD or RFC 3339
+//	category                                        name, or an object with a name
+//	status                                          active, cancelled, paused or trial
+//	notes, url, payment_method, account
+func mapGenericImport(data []byte) ([]ImportRow, error) {
+	rows, err := decodeImportRows(data, "subscriptions")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ImportRow, len(rows))
+	for i, row := range rows {
+		subscription, category, err := mapGenericRow(row)
+		result[i] = newImportRow(i, subscription, category, err)
+	}
+	return result, nil
+}
+
+func mapGenericRow(row map[string]any) (*models.Subscription, string, error) {
+	s := newImportedSubscription()
+	s.Name = importField(row, "name", "title")
+	s.Notes = importField(row, "notes", "description")
+	s.URL = importField(row, "url", "website")
+	s.PaymentMethod = importField(row, "payment_method")
+	s.Account = importField(row, "account")
+
+	category := importField(row, "category", "category_name")
+	if nested, ok := row["category"].(map[string]any); ok {
+		category = importField(nested, "name")
+	}
+
+	cost := importField(row, "cost", "price", "amount")
+	if cost == "" {
+		return s, category, fmt.Errorf("cost is missing")
+	}
+	amount, currency, err := parseImportAmount(cost)
+	if err != nil {
+		return s, category, err
+	}
+	s.Cost = amount
+	if code := importField(row, "currency", "original_currency"); code != "" {
+		currency = importCurrency(code)
+		if currency == "" {
+			return s, category, fmt.Errorf("unknown currency %q", code)
+		}
+	}
+	if currency != "" {
+		s.OriginalCurrency = currency
+	}
+
+	cycle := importField(row, "schedule", "cycle", "billing_cycle", "frequency")
+	if cycle == "" {
+		return s, category, fmt.Errorf("billing cycle is missing")
+	}
+	if s.Schedule, s.ScheduleInterval, err = parseImportCycle(cycle); err != nil {
+		return s, category, err
+	}
+	if value := importField(row, "interval", "schedule_interval"); value != "" {
+		interval, err := strconv.Atoi(value)
+		if err != nil || interval < 1 {
+			return s, category, fmt.Errorf("invalid interval %q", value)
+		}
+		s.ScheduleInterval *= interval
+	}
+
+	if status := importField(row, "status"); status != "" {
+		if s.Status, err = parseImportStatus(status); err != nil {
+			return s, category, err
+		}
+	}
+
+	if s.RenewalDate, err = parseImportDate(importField(row, "renewal_date", "next_payment", "next_billing_date")); err != nil {
+		return s, category, err
+	}
+	if s.StartDate, err = parseImportDate(importField(row, "start_date")); err != nil {
+		return s, category, err
+	}
+	if s.CancellationDate, err = parseImportDate(importField(row, "cancellation_date")); err != nil {
+		return s, category, err
+	}
+	return s, category, nil
+}
+
+// parseImportStatus maps a status name, in any case, to a subscription status
+func parseImportStatus(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "active", "enabled":
+		return "Active", nil
+	case "cancelled", "canceled", "inactive", "disabled":
+		return "Cancelled", nil
+	case "paused":
+		return "Paused", nil
+	case "trial":
+		return "Trial", nil
+	case "archived", "expired":
+		return "Archived", nil
+	}
+	return "", fmt.Errorf("unknown status %q", value)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const wallosSample = `[
+  {
+    "Name": "Netflix",
+    "Payment Cycle": "Monthly",
+    "Next Payment": "2025-07-15",
+    "Renewal": "Automatic",
+    "Category": "Entertainment",
+    "Payment Method": "PayPal",
+    "Paid By": "Me",
+    "Price": "€15.99",
+    "Notes": "Family plan",
+    "URL": "https://netflix.com",
+    "State": "Enabled",
+    "Notifications": "Enabled",
+    "Cancellation Date": "",
+    "Active": "Yes"
+  },
+  {
+    "Name": "Domain",
+    "Payment Cycle": "Every 2 Years",
+    "Next Payment": "2026-01-01",
+    "Category": "Utilities",
+    "Price": "$24.00",
+    "State": "Disabled"
+  },
+  {
+    "Name": "Gym",
+    "Payment Cycle": "Fortnightly",
+    "Price": "$30"
+  }
+]`
+
+func TestMapWallosImport(t *testing.T) {
+	rows, err := mapWallosImport([]byte(wallosSample))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+
+	netflix := rows[0].Subscription
+	if assert.NotNil(t, netflix, rows[0].Error) {
+		assert.Equal(t, "Netflix", netflix.Name)
+		assert.Equal(t, 15.99, netflix.Cost)
+		assert.Equal(t, "EUR", netflix.OriginalCurrency)
+		assert.Equal(t, "Monthly", netflix.Schedule)
+		assert.Equal(t, 1, netflix.ScheduleInterval)
+		assert.Equal(t, "Active", netflix.Status)
+		assert.Equal(t, "PayPal", netflix.PaymentMethod)
+		assert.Equal(t, "Family plan", netflix.Notes)
+		assert.Equal(t, "https://netflix.com", netflix.URL)
+		assert.Equal(t, "2025-07-15", netflix.RenewalDate.Format("2006-01-02"))
+		assert.Equal(t, "Entertainment", rows[0].Category)
+	}
+
+	domain := rows[1].Subscription
+	if assert.NotNil(t, domain, rows[1].Error) {
+		assert.Equal(t, "Annual", domain.Schedule)
+		assert.Equal(t, 2, domain.ScheduleInterval)
+		assert.Equal(t, "USD", domain.OriginalCurrency)
+		assert.Equal(t, "Cancelled", domain.Status, "disabled subscriptions are imported as cancelled")
+	}
+
+	assert.Nil(t, rows[2].Subscription)
+	assert.Equal(t, 2, rows[2].Index)
+	assert.Equal(t, "Gym", rows[2].Name)
+	assert.Contains(t, rows[2].Error, "unknown billing cycle")
+}
+
+const genericSample = `{
+  "subscriptions": [
+    {"name": "Spotify", "price": 9.99, "cycle": "monthly", "currency": "gbp", "next_payment": "2025-08-01", "category": "Music"},
+    {"name": "Backblaze", "cost": "1.234,50", "schedule": "Annual", "renewal_date": "2025-12-01T00:00:00Z", "category": {"name": "Storage"}, "status": "paused"},
+    {"name": "Newspaper", "amount": "5", "frequency": "weekly", "interval": 2},
+    {"name": "", "price": 3, "cycle": "monthly"},
+    {"name": "Mystery", "price": "free", "cycle": "monthly"}
+  ]
+}`
+
+func TestMapGenericImport(t *testing.T) {
+	rows, err := mapGenericImport([]byte(genericSample))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 5)
+
+	spotify := rows[0].Subscription
+	if assert.NotNil(t, spotify, rows[0].Error) {
+		assert.Equal(t, 9.99, spotify.Cost)
+		assert.Equal(t, "GBP", spotify.OriginalCurrency)
+		assert.Equal(t, "Monthly", spotify.Schedule)
+		assert.Equal(t, "2025-08-01", spotify.RenewalDate.Format("2006-01-02"))
+		assert.Equal(t, "Music", rows[0].Category)
+	}
+
+	backblaze := rows[1].Subscription
+	if assert.NotNil(t, backblaze, rows[1].Error) {
+		assert.Equal(t, 1234.50, backblaze.Cost)
+		assert.Equal(t, "Annual", backblaze.Schedule)
+		assert.Equal(t, "Paused", backblaze.Status)
+		assert.True(t, backblaze.RenewalDate.Equal(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, "Storage", rows[1].Category)
+	}
+
+	newspaper := rows[2].Subscription
+	if assert.NotNil(t, newspaper, rows[2].Error) {
+		assert.Equal(t, "Weekly", newspaper.Schedule)
+		assert.Equal(t, 2, newspaper.ScheduleInterval)
+		assert.Equal(t, "USD", newspaper.OriginalCurrency)
+	}
+
+	assert.Nil(t, rows[3].Subscription)
+	assert.Contains(t, rows[3].Error, "name is required")
+	assert.Nil(t, rows[4].Subscription)
+	assert.Contains(t, rows[4].Error, "invalid price")
+}
+
+func TestMapGenericImport_RejectsUnreadablePayload(t *testing.T) {
+	_, err := mapGenericImport([]byte(`{"items": "nope"}`))
+	assert.Error(t, err)
+}
+
+func TestParseImportAmount(t *testing.T) {
+	tests := []struct {
+		value    string
+		amount   float64
+		currency string
+	}{
+		{"9.99", 9.99, ""},
+		{"$9.99", 9.99, "USD"},
+		{"9,99 €", 9.99, "EUR"},
+		{"A$12.50", 12.50, "AUD"},
+		{"EUR 1.234,56", 1234.56, "EUR"},
+		{"¥1,000", 1000, "JPY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			amount, currency, err := parseImportAmount(tt.value)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.amount, amount, 0.001)
+			assert.Equal(t, tt.currency, currency)
+		})
+	}
+}
+
+func TestImportMapperFor(t *testing.T) {
+	_, ok := ImportMapperFor("Wallos")
+	assert.True(t, ok)
+	_, ok = ImportMapperFor("bobby")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"generic", "wallos"}, ImportFormats())
+}
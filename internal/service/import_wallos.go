@@ -0,0 +1,63 @@
+package service
+
+import (
+	"fmt"
+	"subtrackr/internal/models"
+)
+
+// mapWallosImport reads the JSON file from Wallos's "Export subscriptions"
+// button: an array of objects keyed by column title, with the currency symbol
+// written into Price and cycles such as "Monthly" or "Every 3 Months".
+// Disabled subscriptions are imported as cancelled.
+func mapWallosImport(data []byte) ([]ImportRow, error) {
+	rows, err := decodeImportRows(data, "subscriptions")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ImportRow, len(rows))
+	for i, row := range rows {
+		subscription, category, err := mapWallosRow(row)
+		result[i] = newImportRow(i, subscription, category, err)
+	}
+	return result, nil
+}
+
+func mapWallosRow(row map[string]any) (*models.Subscription, string, error) {
+	s := newImportedSubscription()
+	s.Name = importField(row, "Name")
+	s.Notes = importField(row, "Notes")
+	s.URL = importField(row, "URL")
+	s.PaymentMethod = importField(row, "Payment Method")
+	s.Account = importField(row, "Paid By")
+	category := importField(row, "Category")
+
+	price := importField(row, "Price")
+	if price == "" {
+		return s, category, fmt.Errorf("price is missing")
+	}
+	amount, currency, err := parseImportAmount(price)
+	if err != nil {
+		return s, category, err
+	}
+	s.Cost = amount
+	if currency != "" {
+		s.OriginalCurrency = currency
+	}
+
+	if s.Schedule, s.ScheduleInterval, err = parseImportCycle(importField(row, "Payment Cycle")); err != nil {
+		return s, category, err
+	}
+
+	if state := importField(row, "State"); state == "Disabled" || importField(row, "Active") == "false" {
+		s.Status = "Cancelled"
+	}
+
+	if s.RenewalDate, err = parseImportDate(importField(row, "Next Payment")); err != nil {
+		return s, category, err
+	}
+	if s.CancellationDate, err = parseImportDate(importField(row, "Cancellation Date")); err != nil {
+		return s, category, err
+	}
+	return s, category, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// loginAttempts tracks recent failed logins for one client IP or username
+type loginAttempts struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// LoginLimiter locks out clients after repeated failed logins.
+// State is kept in memory and entries expire after the lockout period.
+type LoginLimiter struct {
+	mu          sync.Mutex
+	attempts    map[string]*loginAttempts
+	maxAttempts int
+	lockout     time.Duration
+	now         func() time.Time
+}
+
+// NewLoginLimiter creates a limiter that locks a key for lockout after maxAttempts
+// consecutive failures. A maxAttempts of zero or less disables lockouts.
+func NewLoginLimiter(maxAttempts int, lockout time.Duration) *LoginLimiter {
+	return &LoginLimiter{
+		attempts:    make(map[string]*loginAttempts),
+		maxAttempts: maxAttempts,
+		lockout:     lockout,
+		now:         time.Now,
+	}
+}
+
+// LockedFor returns how much longer any of the keys is locked out, or zero if none are
+func (l *LoginLimiter) LockedFor(keys ...string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var remaining time.Duration
+	for _, key := range keys {
+		if entry, ok := l.attempts[key]; ok && entry.lockedUntil.After(now) {
+			remaining = max(remaining, entry.lockedUntil.Sub(now))
+		}
+	}
+	return remaining
+}
+
+// RecordFailure counts a failed login against each key, locking any key that
+// reaches the limit
+func (l *LoginLimiter) RecordFailure(keys ...string) {
+	if l.maxAttempts <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.pruneExpired(now)
+
+	for _, key := range keys {
+		entry, ok := l.attempts[key]
+		if !ok {
+			entry = &loginAttempts{}
+			l.attempts[key] = entry
+		}
+
+		entry.failures++
+		entry.lastFailure = now
+		if entry.failures >= l.maxAttempts {
+			entry.lockedUntil = now.Add(l.lockout)
+			entry.failures = 0
+		}
+	}
+}
+
+// Reset clears the failure history for each key after a successful login
+func (l *LoginLimiter) Reset(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		delete(l.attempts, key)
+	}
+}
+
+// pruneExpired drops entries whose last failure and lockout are both older than the lockout period
+func (l *LoginLimiter) pruneExpired(now time.Time) {
+	for key, entry := range l.attempts {
+		if now.After(entry.lockedUntil) && now.Sub(entry.lastFailure) > l.lockout {
+			delete(l.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginLimiter_LocksAfterMaxAttempts(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewLoginLimiter(5, 15*time.Minute)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 4; i++ {
+		l.RecordFailure("ip:1.2.3.4")
+		assert.Zero(t, l.LockedFor("ip:1.2.3.4"), "should not lock after %d failures", i+1)
+	}
+
+	l.RecordFailure("ip:1.2.3.4")
+	assert.Equal(t, 15*time.Minute, l.LockedFor("ip:1.2.3.4"))
+	assert.Zero(t, l.LockedFor("ip:5.6.7.8"), "other clients are unaffected")
+
+	now = now.Add(10 * time.Minute)
+	assert.Equal(t, 5*time.Minute, l.LockedFor("ip:1.2.3.4"))
+
+	now = now.Add(5*time.Minute + time.Second)
+	assert.Zero(t, l.LockedFor("ip:1.2.3.4"), "lockout expires after the cooldown")
+}
+
+func TestLoginLimiter_AnyKeyLocks(t *testing.T) {
+	l := NewLoginLimiter(2, time.Minute)
+
+	l.RecordFailure("ip:1.2.3.4", "user:admin")
+	l.RecordFailure("ip:5.6.7.8", "user:admin")
+
+	assert.Zero(t, l.LockedFor("ip:1.2.3.4"))
+	assert.NotZero(t, l.LockedFor("ip:9.9.9.9", "user:admin"), "username is locked across IPs")
+}
+
+func TestLoginLimiter_ResetClearsFailures(t *testing.T) {
+	l := NewLoginLimiter(3, time.Minute)
+
+	l.RecordFailure("user:admin")
+	l.RecordFailure("user:admin")
+	l.Reset("user:admin")
+	l.RecordFailure("user:admin")
+
+	assert.Zero(t, l.LockedFor("user:admin"))
+}
+
+func TestLoginLimiter_Disabled(t *testing.T) {
+	l := NewLoginLimiter(0, time.Minute)
+
+	for i := 0; i < 10; i++ {
+		l.RecordFailure("user:admin")
+	}
+
+	assert.Zero(t, l.LockedFor("user:admin"))
+}
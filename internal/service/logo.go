@@ -1,17 +1,114 @@
 package service
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// DefaultLogoCacheDir is where locally cached logos are stored; it is served under LogoCachePath
+	DefaultLogoCacheDir = "./web/static/logos"
+	// LogoCachePath is the URL prefix for locally cached logos
+	LogoCachePath = "/static/logos"
+	// LogoProxyPath serves favicons fetched by the server, see GetProxiedLogo
+	LogoProxyPath = "/logo"
+
+	// proxiedLogoTTL is how long a proxied favicon is kept in memory
+	proxiedLogoTTL = 24 * time.Hour
+	// missingLogoTTL is how long a domain without a favicon is remembered
+	missingLogoTTL = time.Hour
+	// maxProxiedLogos caps how many domains are kept in the proxy cache
+	maxProxiedLogos = 1000
+
+	// maxLogoSize caps how much of a remote logo is read
+	maxLogoSize = 1 << 20
+	// MaxLogoUploadSize caps the size of an uploaded logo image
+	MaxLogoUploadSize = 512 << 10
+
+	// maxPageSize caps how much of a web page is read when looking for its name
+	maxPageSize = 512 << 10
+	// maxSiteNameLength caps the length of a suggested subscription name
+	maxSiteNameLength = 100
+
+	// uploadedLogoPrefix marks files saved from uploads, which belong to a single subscription
+	uploadedLogoPrefix = "upload-"
 )
 
+// Errors returned when an uploaded logo is rejected
+var (
+	ErrLogoTooLarge        = fmt.Errorf("logo exceeds %d KB", MaxLogoUploadSize>>10)
+	ErrUnsupportedLogoType = errors.New("logo must be a PNG or JPEG image")
+)
+
+// Errors returned by the logo proxy
+var (
+	ErrInvalidLogoDomain = errors.New("invalid domain")
+	ErrLogoNotFound      = errors.New("no logo found")
+	// ErrPrivateAddress is returned when a user-supplied website resolves to a
+	// loopback, private or link-local address
+	ErrPrivateAddress = errors.New("refusing to connect to a non-public address")
+)
+
+// ProxiedLogo is a favicon served by the logo proxy. Cache entries with nil Data
+// record a domain without a logo.
+type ProxiedLogo struct {
+	Data        []byte
+	ContentType string
+	expires     time.Time
+}
+
+// uploadedLogoExtensions maps the accepted upload content types to file extensions
+var uploadedLogoExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+}
+
+// cachedLogoExtensions maps the sniffed content types of downloaded logos to file
+// extensions, so cached files are served with the right Content-Type
+var cachedLogoExtensions = map[string]string{
+	"image/png":    ".png",
+	"image/jpeg":   ".jpg",
+	"image/gif":    ".gif",
+	"image/webp":   ".webp",
+	"image/bmp":    ".bmp",
+	"image/x-icon": ".ico",
+}
+
+// DefaultLogoProviders is the order favicon providers are tried in
+var DefaultLogoProviders = []string{"duckduckgo", "clearbit", "google"}
+
+// logoProviderURLs maps each favicon provider to its URL format; %s is the escaped domain
+var logoProviderURLs = map[string]string{
+	"duckduckgo": "https://icons.duckduckgo.com/ip3/%s.ico",
+	"clearbit":   "https://logo.clearbit.com/%s",
+	"google":     "https://www.google.com/s2/favicons?domain=%s&sz=64",
+}
+
 // LogoService handles fetching logos/icons for subscriptions
 type LogoService struct {
-	httpClient *http.Client
+	httpClient   *http.Client
+	pageClient   *http.Client
+	cacheDir     string
+	providers    []string
+	providerURLs map[string]string
+
+	proxyMu    sync.Mutex
+	proxyCache map[string]ProxiedLogo
 }
 
 // NewLogoService creates a new logo service
@@ -20,7 +117,43 @@ func NewLogoService() *LogoService {
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		pageClient:   newPublicOnlyClient(10 * time.Second),
+		cacheDir:     DefaultLogoCacheDir,
+		providers:    DefaultLogoProviders,
+		providerURLs: logoProviderURLs,
+		proxyCache:   make(map[string]ProxiedLogo),
+	}
+}
+
+// SetProviders sets the order favicon providers are tried in. Unknown names are
+// rejected and leave the current order unchanged; an empty list restores the default.
+func (s *LogoService) SetProviders(names []string) error {
+	var providers []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := s.providerURLs[name]; !ok {
+			return fmt.Errorf("unknown logo provider %q", name)
+		}
+		providers = append(providers, name)
+	}
+	if len(providers) == 0 {
+		providers = DefaultLogoProviders
 	}
+	s.providers = providers
+	return nil
+}
+
+// GetProviders returns the order favicon providers are tried in
+func (s *LogoService) GetProviders() []string {
+	return s.providers
+}
+
+// SetCacheDir changes the directory locally cached logos are written to
+func (s *LogoService) SetCacheDir(dir string) {
+	s.cacheDir = dir
 }
 
 // FetchLogoFromURL extracts the domain from a website URL and returns a favicon URL
@@ -99,24 +232,30 @@ func (s *LogoService) ValidateLogoURL(logoURL string) bool {
 	defer resp.Body.Close()
 
 	// Check if response is successful (2xx) and is an image
-	return resp.StatusCode >= 200 && resp.StatusCode < 300
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false
+	}
+	return strings.HasPrefix(resp.Header.Get("Content-Type"), "image/")
 }
 
-// FetchAndValidateLogo fetches a logo and validates it's accessible
+// FetchAndValidateLogo tries each configured favicon provider in order and returns
+// the first logo that validates. If none do, it falls back to FetchLogoFromURL.
 func (s *LogoService) FetchAndValidateLogo(websiteURL string) (string, error) {
-	logoURL, err := s.FetchLogoFromURL(websiteURL)
-	if err != nil {
-		return "", err
+	domain := s.ExtractDomain(websiteURL)
+	if domain == "" {
+		return s.FetchLogoFromURL(websiteURL)
 	}
 
-	// Validate the logo URL (check if it's accessible)
-	if !s.ValidateLogoURL(logoURL) {
-		// Still return the URL even if validation fails
-		// The browser will handle broken images gracefully
-		return logoURL, nil
+	for _, provider := range s.providers {
+		logoURL := fmt.Sprintf(s.providerURLs[provider], url.QueryEscape(domain))
+		if s.ValidateLogoURL(logoURL) {
+			return logoURL, nil
+		}
 	}
 
-	return logoURL, nil
+	// Still return a URL even if validation fails
+	// The browser will handle broken images gracefully
+	return s.FetchLogoFromURL(websiteURL)
 }
 
 // ExtractDomain extracts the domain from a URL string
@@ -153,7 +292,6 @@ func (s *LogoService) ExtractDomain(websiteURL string) string {
 }
 
 // DownloadLogo downloads a logo from a URL and returns the image data
-// This is for future use if we want to store logos locally
 func (s *LogoService) DownloadLogo(logoURL string) ([]byte, error) {
 	resp, err := s.httpClient.Get(logoURL)
 	if err != nil {
@@ -165,11 +303,344 @@ func (s *LogoService) DownloadLogo(logoURL string) ([]byte, error) {
 		return nil, fmt.Errorf("failed to download logo: status %d", resp.StatusCode)
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxLogoSize+1))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read logo data: %w", err)
 	}
+	if len(data) > maxLogoSize {
+		return nil, fmt.Errorf("logo exceeds %d bytes", maxLogoSize)
+	}
 
 	return data, nil
 }
 
+// CacheLogo downloads a remote logo into the cache directory and returns its
+// local URL, so pages don't hot-link third-party favicon services. Logos are
+// keyed by a hash of the remote URL and only downloaded once.
+func (s *LogoService) CacheLogo(logoURL string) (string, error) {
+	if logoURL == "" {
+		return "", fmt.Errorf("empty logo URL provided")
+	}
+
+	sum := sha256.Sum256([]byte(logoURL))
+	key := hex.EncodeToString(sum[:16])
+
+	// The extension depends on the downloaded type, so look for any cached file with this key
+	if matches, _ := filepath.Glob(filepath.Join(s.cacheDir, key+".*")); len(matches) > 0 {
+		return LogoCachePath + "/" + filepath.Base(matches[0]), nil
+	}
+
+	data, err := s.DownloadLogo(logoURL)
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("logo is empty")
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := cachedLogoExtensions[contentType]
+	if !ok {
+		return "", fmt.Errorf("logo is not an image: %s", contentType)
+	}
+	filename := key + ext
+	path := filepath.Join(s.cacheDir, filename)
+
+	if err := os.MkdirAll(s.cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create logo cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to save logo: %w", err)
+	}
+
+	return LogoCachePath + "/" + filename, nil
+}
+
+
+// SaveUploadedLogo stores an uploaded PNG or JPEG in the cache directory and
+// returns its local URL. The type is detected from the data, not trusted from the client.
+func (s *LogoService) SaveUploadedLogo(data []byte) (string, error) {
+	if len(data) > MaxLogoUploadSize {
+		return "", ErrLogoTooLarge
+	}
+	ext, ok := uploadedLogoExtensions[http.DetectContentType(data)]
+	if !ok || len(data) == 0 {
+		return "", ErrUnsupportedLogoType
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	filename := uploadedLogoPrefix + hex.EncodeToString(random) + ext
+
+	if err := os.MkdirAll(s.cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create logo cache directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.cacheDir, filename), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to save logo: %w", err)
+	}
+
+	return LogoCachePath + "/" + filename, nil
+}
+
+// RemoveUploadedLogo deletes the file behind an uploaded logo URL. Other URLs,
+// including shared cached favicons, are left alone.
+func (s *LogoService) RemoveUploadedLogo(iconURL string) error {
+	filename, ok := uploadedLogoFilename(iconURL)
+	if !ok {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(s.cacheDir, filename)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove logo: %w", err)
+	}
+	return nil
+}
+
+// uploadedLogoFilename returns the cache file name behind an uploaded logo URL,
+// reporting false for any other URL
+func uploadedLogoFilename(iconURL string) (string, bool) {
+	filename := strings.TrimPrefix(iconURL, LogoCachePath+"/")
+	if filename == iconURL || !strings.HasPrefix(filename, uploadedLogoPrefix) || filename != filepath.Base(filename) {
+		return "", false
+	}
+	return filename, true
+}
+
+// RemoveAllUploadedLogos deletes every uploaded logo in the cache directory.
+// Cached favicons are shared between subscriptions and are kept.
+func (s *LogoService) RemoveAllUploadedLogos() error {
+	matches, err := filepath.Glob(filepath.Join(s.cacheDir, uploadedLogoPrefix+"*"))
+	if err != nil {
+		return err
+	}
+	for _, path := range matches {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove logo: %w", err)
+		}
+	}
+	return nil
+}
+
+// newPublicOnlyClient returns a client that refuses to connect to non-public
+// addresses. The check runs on the resolved IP of every connection, so it also
+// covers redirects and hostnames that resolve to the local network.
+func newPublicOnlyClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return ErrPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// isPublicIP reports whether ip is a globally routable unicast address
+func isPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast()
+}
+
+// FetchSiteName fetches a web page and returns a name for it: the og:site_name
+// meta tag when present, otherwise the <title>. Only the page head is parsed.
+// The URL comes from the user, so pages on the local network are refused.
+func (s *LogoService) FetchSiteName(websiteURL string) (string, error) {
+	normalizedURL := strings.TrimSpace(websiteURL)
+	if normalizedURL == "" {
+		return "", fmt.Errorf("empty URL provided")
+	}
+	if !strings.HasPrefix(normalizedURL, "http://") && !strings.HasPrefix(normalizedURL, "https://") {
+		normalizedURL = "https://" + normalizedURL
+	}
+	if _, err := url.Parse(normalizedURL); err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+
+	resp, err := s.pageClient.Get(normalizedURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to fetch page: status %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "text/html") {
+		return "", fmt.Errorf("page is not HTML: %s", contentType)
+	}
+
+	siteName, title := parseSiteName(io.LimitReader(resp.Body, maxPageSize))
+	name := siteName
+	if name == "" {
+		name = title
+	}
+	if name == "" {
+		return "", fmt.Errorf("no title found")
+	}
+	return name, nil
+}
+
+// parseSiteName scans an HTML head for the og:site_name content and the title text
+func parseSiteName(r io.Reader) (siteName, title string) {
+	tokenizer := html.NewTokenizer(r)
+	inTitle := false
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return cleanSiteName(siteName), cleanSiteName(title)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = title == ""
+			case "meta":
+				var property, content string
+				for _, attr := range token.Attr {
+					switch strings.ToLower(attr.Key) {
+					case "property", "name":
+						property = strings.ToLower(attr.Val)
+					case "content":
+						content = attr.Val
+					}
+				}
+				if property == "og:site_name" && siteName == "" {
+					siteName = content
+				}
+			case "body":
+				return cleanSiteName(siteName), cleanSiteName(title)
+			}
+		case html.TextToken:
+			if inTitle {
+				title += string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			switch tokenizer.Token().Data {
+			case "title":
+				inTitle = false
+			case "head":
+				return cleanSiteName(siteName), cleanSiteName(title)
+			}
+		}
+	}
+}
+
+// cleanSiteName collapses whitespace and trims a name to a sensible length
+func cleanSiteName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	if runes := []rune(name); len(runes) > maxSiteNameLength {
+		name = strings.TrimSpace(string(runes[:maxSiteNameLength]))
+	}
+	return name
+}
+
+// ProxiedLogoURL returns the app-local URL that serves the favicon for a website,
+// or an empty string if no usable domain can be extracted
+func (s *LogoService) ProxiedLogoURL(websiteURL string) string {
+	domain := s.ExtractDomain(websiteURL)
+	if !IsValidLogoDomain(domain) {
+		return ""
+	}
+	return LogoProxyPath + "?domain=" + url.QueryEscape(strings.ToLower(domain))
+}
+
+// IsValidLogoDomain reports whether domain is a public-looking hostname. IP
+// literals, single-label names and internal suffixes are rejected so the logo
+// proxy can't be pointed at hosts on the local network.
+func IsValidLogoDomain(domain string) bool {
+	domain = strings.ToLower(domain)
+	if domain == "" || len(domain) > 253 || net.ParseIP(domain) != nil {
+		return false
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return false
+			}
+		}
+	}
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return false
+	}
+
+	switch labels[len(labels)-1] {
+	case "localhost", "local", "internal", "lan", "home", "arpa", "test", "invalid":
+		return false
+	}
+	return true
+}
+
+// GetProxiedLogo returns the favicon for a domain, trying each configured provider
+// in order. Results, including misses, are cached in memory.
+func (s *LogoService) GetProxiedLogo(domain string) (*ProxiedLogo, error) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if !IsValidLogoDomain(domain) {
+		return nil, ErrInvalidLogoDomain
+	}
+
+	s.proxyMu.Lock()
+	entry, ok := s.proxyCache[domain]
+	s.proxyMu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		if entry.Data == nil {
+			return nil, ErrLogoNotFound
+		}
+		return &entry, nil
+	}
+
+	entry = ProxiedLogo{expires: time.Now().Add(missingLogoTTL)}
+	for _, provider := range s.providers {
+		data, err := s.DownloadLogo(fmt.Sprintf(s.providerURLs[provider], url.QueryEscape(domain)))
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		contentType := http.DetectContentType(data)
+		if !strings.HasPrefix(contentType, "image/") {
+			continue
+		}
+		entry = ProxiedLogo{Data: data, ContentType: contentType, expires: time.Now().Add(proxiedLogoTTL)}
+		break
+	}
+
+	s.proxyMu.Lock()
+	if len(s.proxyCache) >= maxProxiedLogos {
+		s.evictProxiedLogos()
+	}
+	s.proxyCache[domain] = entry
+	s.proxyMu.Unlock()
+
+	if entry.Data == nil {
+		return nil, ErrLogoNotFound
+	}
+	return &entry, nil
+}
+
+// evictProxiedLogos drops expired entries, or everything if none have expired.
+// The caller must hold proxyMu.
+func (s *LogoService) evictProxiedLogos() {
+	now := time.Now()
+	for domain, entry := range s.proxyCache {
+		if now.After(entry.expires) {
+			delete(s.proxyCache, domain)
+		}
+	}
+	if len(s.proxyCache) >= maxProxiedLogos {
+		s.proxyCache = make(map[string]ProxiedLogo)
+	}
+}
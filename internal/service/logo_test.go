@@ -0,0 +1,391 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePNG is the PNG signature followed by padding, enough for content sniffing
+var fakePNG = append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+
+func newTestLogoService(t *testing.T) *LogoService {
+	s := NewLogoService()
+	s.SetCacheDir(t.TempDir())
+	return s
+}
+
+func TestLogoService_CacheLogo(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(fakePNG)
+	}))
+	defer server.Close()
+
+	s := newTestLogoService(t)
+	localURL, err := s.CacheLogo(server.URL + "/favicon.png")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(localURL, LogoCachePath+"/"))
+	assert.True(t, strings.HasSuffix(localURL, ".png"))
+
+	data, err := os.ReadFile(filepath.Join(s.cacheDir, filepath.Base(localURL)))
+	assert.NoError(t, err)
+	assert.Equal(t, fakePNG, data)
+
+	// A second call reuses the cached file
+	again, err := s.CacheLogo(server.URL + "/favicon.png")
+	assert.NoError(t, err)
+	assert.Equal(t, localURL, again)
+	assert.Equal(t, 1, requests)
+}
+
+func TestLogoService_CacheLogo_ExtensionFromContent(t *testing.T) {
+	fakeGIF := append([]byte("GIF89a"), make([]byte, 32)...)
+	fakeJPEG := append([]byte("\xff\xd8\xff"), make([]byte, 32)...)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// The served Content-Type is ignored in favour of the sniffed one
+		w.Header().Set("Content-Type", "image/png")
+		if r.URL.Path == "/favicon.gif" {
+			w.Write(fakeGIF)
+			return
+		}
+		w.Write(fakeJPEG)
+	}))
+	defer server.Close()
+
+	s := newTestLogoService(t)
+	gifURL, err := s.CacheLogo(server.URL + "/favicon.gif")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(gifURL, ".gif"))
+
+	jpegURL, err := s.CacheLogo(server.URL + "/favicon.png")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(jpegURL, ".jpg"))
+
+	// Cached files are found again whatever their extension
+	again, err := s.CacheLogo(server.URL + "/favicon.gif")
+	assert.NoError(t, err)
+	assert.Equal(t, gifURL, again)
+	assert.Equal(t, 2, requests)
+}
+
+func TestLogoService_CacheLogo_Failures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/html":
+			w.Write([]byte("<html><body>not an icon</body></html>"))
+		case "/empty":
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{"Not found", "/missing", "status 404"},
+		{"Not an image", "/html", "not an image"},
+		{"Empty body", "/empty", "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestLogoService(t)
+			_, err := s.CacheLogo(server.URL + tt.path)
+			assert.ErrorContains(t, err, tt.wantErr)
+
+			entries, _ := os.ReadDir(s.cacheDir)
+			assert.Empty(t, entries)
+		})
+	}
+}
+
+// stubProviders points every logo provider at server, under a path named after the provider
+func stubProviders(s *LogoService, serverURL string) {
+	s.providerURLs = map[string]string{}
+	for name := range logoProviderURLs {
+		s.providerURLs[name] = serverURL + "/" + name + "/%s"
+	}
+}
+
+func TestLogoService_FetchAndValidateLogo_ProviderChain(t *testing.T) {
+	// responses maps a provider to how its stub answers
+	tests := []struct {
+		name      string
+		responses map[string]string
+		order     []string
+		want      string
+	}{
+		{"First provider wins", map[string]string{"duckduckgo": "image", "clearbit": "image", "google": "image"}, nil, "duckduckgo"},
+		{"Skips a missing icon", map[string]string{"duckduckgo": "404", "clearbit": "image", "google": "image"}, nil, "clearbit"},
+		{"Skips a non-image response", map[string]string{"duckduckgo": "html", "clearbit": "404", "google": "image"}, nil, "google"},
+		{"Honours a custom order", map[string]string{"duckduckgo": "image", "clearbit": "image", "google": "image"}, []string{"google", "duckduckgo"}, "google"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				provider := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")[0]
+				switch tt.responses[provider] {
+				case "image":
+					w.Header().Set("Content-Type", "image/png")
+				case "html":
+					w.Header().Set("Content-Type", "text/html")
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			s := newTestLogoService(t)
+			stubProviders(s, server.URL)
+			if tt.order != nil {
+				assert.NoError(t, s.SetProviders(tt.order))
+			}
+
+			logoURL, err := s.FetchAndValidateLogo("https://www.example.com/account")
+			assert.NoError(t, err)
+			assert.Equal(t, server.URL+"/"+tt.want+"/example.com", logoURL)
+		})
+	}
+}
+
+func TestLogoService_FetchAndValidateLogo_FallsBackToGoogle(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	s := newTestLogoService(t)
+	stubProviders(s, server.URL)
+
+	logoURL, err := s.FetchAndValidateLogo("example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.google.com/s2/favicons?domain=example.com&sz=64", logoURL)
+}
+
+func TestLogoService_SetProviders(t *testing.T) {
+	s := NewLogoService()
+	assert.Equal(t, DefaultLogoProviders, s.GetProviders())
+
+	assert.NoError(t, s.SetProviders([]string{" Google ", "clearbit"}))
+	assert.Equal(t, []string{"google", "clearbit"}, s.GetProviders())
+
+	assert.ErrorContains(t, s.SetProviders([]string{"google", "bing"}), "unknown logo provider")
+	assert.Equal(t, []string{"google", "clearbit"}, s.GetProviders())
+
+	assert.NoError(t, s.SetProviders([]string{""}))
+	assert.Equal(t, DefaultLogoProviders, s.GetProviders())
+}
+
+func TestLogoService_RemoveUploadedLogo(t *testing.T) {
+	s := newTestLogoService(t)
+
+	uploaded, err := s.SaveUploadedLogo(fakePNG)
+	assert.NoError(t, err)
+	cached := filepath.Join(s.cacheDir, "cached.png")
+	assert.NoError(t, os.WriteFile(cached, fakePNG, 0644))
+
+	// Shared cached favicons and remote URLs are never deleted
+	assert.NoError(t, s.RemoveUploadedLogo(LogoCachePath+"/cached.png"))
+	assert.NoError(t, s.RemoveUploadedLogo("https://example.com/upload-x.png"))
+	assert.NoError(t, s.RemoveUploadedLogo(LogoCachePath+"/upload-../cached.png"))
+	_, err = os.Stat(cached)
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.RemoveUploadedLogo(uploaded))
+	_, err = os.Stat(filepath.Join(s.cacheDir, filepath.Base(uploaded)))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLogoService_RemoveAllUploadedLogos(t *testing.T) {
+	s := newTestLogoService(t)
+
+	first, err := s.SaveUploadedLogo(fakePNG)
+	assert.NoError(t, err)
+	second, err := s.SaveUploadedLogo(fakePNG)
+	assert.NoError(t, err)
+	cached := filepath.Join(s.cacheDir, "cached.png")
+	assert.NoError(t, os.WriteFile(cached, fakePNG, 0644))
+
+	assert.NoError(t, s.RemoveAllUploadedLogos())
+
+	for _, uploaded := range []string{first, second} {
+		_, err = os.Stat(filepath.Join(s.cacheDir, filepath.Base(uploaded)))
+		assert.True(t, os.IsNotExist(err))
+	}
+	_, err = os.Stat(cached)
+	assert.NoError(t, err)
+}
+
+func TestParseSiteName(t *testing.T) {
+	tests := []struct {
+		name         string
+		page         string
+		wantSiteName string
+		wantTitle    string
+	}{
+		{"Title only", `<html><head><title>  Netflix -
+			Watch TV Shows </title></head></html>`, "", "Netflix - Watch TV Shows"},
+		{"Site name and title", `<head><meta property="og:site_name" content="Spotify"><title>Spotify - Web Player</title></head>`, "Spotify", "Spotify - Web Player"},
+		{"Entities are decoded", `<head><title>Tom &amp; Jerry</title></head>`, "", "Tom & Jerry"},
+		{"Stops at body", `<head></head><body><title>Not this</title></body>`, "", ""},
+		{"Long names are trimmed", `<title>` + strings.Repeat("a", 150) + `</title>`, "", strings.Repeat("a", maxSiteNameLength)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			siteName, title := parseSiteName(strings.NewReader(tt.page))
+			assert.Equal(t, tt.wantSiteName, siteName)
+			assert.Equal(t, tt.wantTitle, title)
+		})
+	}
+}
+
+func TestLogoService_FetchSiteName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/site-name":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<head><title>Home | Acme</title><meta property="og:site_name" content="Acme Cloud"></head>`))
+		case "/title":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<head><title>Acme Music</title></head>`))
+		case "/untitled":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<head></head><body>hello</body>`))
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"title":"nope"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	s := newTestLogoService(t)
+	s.pageClient = server.Client()
+
+	name, err := s.FetchSiteName(server.URL + "/site-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "Acme Cloud", name)
+
+	name, err = s.FetchSiteName(server.URL + "/title")
+	assert.NoError(t, err)
+	assert.Equal(t, "Acme Music", name)
+
+	_, err = s.FetchSiteName(server.URL + "/untitled")
+	assert.ErrorContains(t, err, "no title")
+	_, err = s.FetchSiteName(server.URL + "/json")
+	assert.ErrorContains(t, err, "not HTML")
+	_, err = s.FetchSiteName(server.URL + "/missing")
+	assert.ErrorContains(t, err, "status 404")
+	_, err = s.FetchSiteName("  ")
+	assert.Error(t, err)
+}
+
+func TestLogoService_FetchSiteName_RefusesPrivateAddresses(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<head><title>Router admin</title></head>`))
+	}))
+	defer server.Close()
+
+	s := newTestLogoService(t)
+	for _, target := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		_, err := s.FetchSiteName(target)
+		assert.ErrorIs(t, err, ErrPrivateAddress, target)
+	}
+	assert.Zero(t, requests)
+
+	for _, ip := range []string{"127.0.0.1", "10.0.0.1", "192.168.1.1", "169.254.169.254", "::1", "fd00::1", "0.0.0.0"} {
+		assert.False(t, isPublicIP(net.ParseIP(ip)), ip)
+	}
+	assert.True(t, isPublicIP(net.ParseIP("93.184.216.34")))
+}
+
+func TestIsValidLogoDomain(t *testing.T) {
+	tests := []struct {
+		domain string
+		valid  bool
+	}{
+		{"netflix.com", true},
+		{"Music.Apple.com", true},
+		{"my-site.co.uk", true},
+		{"", false},
+		{"localhost", false},
+		{"intranet", false},
+		{"127.0.0.1", false},
+		{"10.0.0.5", false},
+		{"::1", false},
+		{"printer.local", false},
+		{"db.internal", false},
+		{"1.2.3.4.nip", true},
+		{"example.123", false},
+		{"-bad.com", false},
+		{"bad..com", false},
+		{"evil.com/path", false},
+		{"evil.com:8080", false},
+		{"user@evil.com", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.valid, IsValidLogoDomain(tt.domain), tt.domain)
+	}
+}
+
+func TestLogoService_GetProxiedLogo(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/duckduckgo/netflix.com":
+			w.Write([]byte("<html>blank</html>"))
+		case "/clearbit/netflix.com":
+			w.Write(fakePNG)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	s := newTestLogoService(t)
+	stubProviders(s, server.URL)
+
+	logo, err := s.GetProxiedLogo("Netflix.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", logo.ContentType)
+	assert.Equal(t, fakePNG, logo.Data)
+
+	// Served from memory the second time
+	_, err = s.GetProxiedLogo("netflix.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests["/clearbit/netflix.com"])
+
+	// Misses are remembered too
+	_, err = s.GetProxiedLogo("unknown.example")
+	assert.ErrorIs(t, err, ErrLogoNotFound)
+	_, err = s.GetProxiedLogo("unknown.example")
+	assert.ErrorIs(t, err, ErrLogoNotFound)
+	assert.Equal(t, 1, requests["/google/unknown.example"])
+
+	_, err = s.GetProxiedLogo("192.168.1.1")
+	assert.ErrorIs(t, err, ErrInvalidLogoDomain)
+}
+
+func TestLogoService_ProxiedLogoURL(t *testing.T) {
+	s := NewLogoService()
+	assert.Equal(t, "/logo?domain=netflix.com", s.ProxiedLogoURL("https://www.Netflix.com/browse"))
+	assert.Equal(t, "", s.ProxiedLogoURL("http://192.168.1.10:8080"))
+	assert.Equal(t, "", s.ProxiedLogoURL(""))
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownHeading     = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownBullet      = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	markdownNumbered    = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	markdownLink        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownBold        = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	markdownItalic      = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	markdownSafeSchemes = []string{"http://", "https://", "mailto:"}
+)
+
+// RenderMarkdown renders notes written in a small Markdown subset (paragraphs,
+// headings, lists, bold, italic, inline code and links) to HTML. The source is
+// HTML-escaped before any formatting is applied, so raw HTML in the input is
+// shown as text, and links are only kept for http, https and mailto URLs.
+func RenderMarkdown(src string) template.HTML {
+	var out strings.Builder
+	var paragraph []string
+	listTag := ""
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + strings.Join(paragraph, "<br>") + "</p>")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if listTag != "" {
+			out.WriteString("</" + listTag + ">")
+			listTag = ""
+		}
+	}
+	listItem := func(tag, text string) {
+		flushParagraph()
+		if listTag != tag {
+			closeList()
+			out.WriteString("<" + tag + ">")
+			listTag = tag
+		}
+		out.WriteString("<li>" + renderMarkdownInline(text) + "</li>")
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			flushParagraph()
+			closeList()
+			continue
+		}
+
+		if m := markdownHeading.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			closeList()
+			tag := "h" + string(rune('0'+len(m[1])))
+			out.WriteString("<" + tag + ">" + renderMarkdownInline(m[2]) + "</" + tag + ">")
+		} else if m := markdownBullet.FindStringSubmatch(line); m != nil {
+			listItem("ul", m[1])
+		} else if m := markdownNumbered.FindStringSubmatch(line); m != nil {
+			listItem("ol", m[1])
+		} else {
+			closeList()
+			paragraph = append(paragraph, renderMarkdownInline(strings.TrimSpace(line)))
+		}
+	}
+	flushParagraph()
+	closeList()
+
+	return template.HTML(out.String())
+}
+
+// renderMarkdownInline escapes text and applies inline formatting. Code spans
+// are left unformatted.
+func renderMarkdownInline(text string) string {
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		escaped := html.EscapeString(part)
+		// An unmatched trailing backtick is kept as text
+		if i%2 == 1 && i < len(parts)-1 {
+			parts[i] = "<code>" + escaped + "</code>"
+			continue
+		}
+		if i%2 == 1 {
+			escaped = "`" + escaped
+		}
+		parts[i] = formatMarkdownSpans(escaped)
+	}
+	return strings.Join(parts, "")
+}
+
+// formatMarkdownSpans applies links, bold and italic to already-escaped text
+func formatMarkdownSpans(escaped string) string {
+	escaped = markdownLink.ReplaceAllStringFunc(escaped, func(match string) string {
+		m := markdownLink.FindStringSubmatch(match)
+		label, href := m[1], m[2]
+		if !isSafeMarkdownURL(html.UnescapeString(href)) {
+			return label
+		}
+		return `<a href="` + href + `" target="_blank" rel="noopener noreferrer">` + label + `</a>`
+	})
+	escaped = markdownBold.ReplaceAllString(escaped, "<strong>$1$2</strong>")
+	return markdownItalic.ReplaceAllString(escaped, "<em>$1$2</em>")
+}
+
+func isSafeMarkdownURL(url string) bool {
+	lower := strings.ToLower(strings.TrimSpace(url))
+	for _, scheme := range markdownSafeSchemes {
+		if strings.HasPrefix(lower, scheme) {
+			return true
+		}
+	}
+	return false
+}
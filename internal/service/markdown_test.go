@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{"Empty", "", ""},
+		{"Paragraphs and line breaks", "Call support\nask for retention\n\nThen cancel", "<p>Call support<br>ask for retention</p><p>Then cancel</p>"},
+		{"Heading", "## How to cancel", "<h2>How to cancel</h2>"},
+		{"Bullet list", "- Log in\n- Open *billing*\n- Click **Cancel**", "<ul><li>Log in</li><li>Open <em>billing</em></li><li>Click <strong>Cancel</strong></li></ul>"},
+		{"Numbered list after text", "Steps:\n1. First\n2. Second", "<p>Steps:</p><ol><li>First</li><li>Second</li></ol>"},
+		{"Link", "See [the FAQ](https://example.com/faq?a=1&b=2)", `<p>See <a href="https://example.com/faq?a=1&amp;b=2" target="_blank" rel="noopener noreferrer">the FAQ</a></p>`},
+		{"Inline code is not formatted", "Use `**not bold**` here", "<p>Use <code>**not bold**</code> here</p>"},
+		{"Snake case is not italic", "account_id_value", "<p>account_id_value</p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(RenderMarkdown(tt.src)))
+		})
+	}
+}
+
+func TestRenderMarkdown_StripsScripts(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"Script tag", "<script>alert('xss')</script>"},
+		{"Event handler", `<img src=x onerror="alert(1)">`},
+		{"Script in list item", "- <script>alert(1)</script>"},
+		{"Script in inline code", "`<script>alert(1)</script>`"},
+		{"Script in link label", "[<script>alert(1)</script>](https://example.com)"},
+		{"javascript URL", "[click](javascript:alert(1))"},
+		{"Quote breaking out of href", `[x](https://example.com/"onmouseover="alert(1))`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := string(RenderMarkdown(tt.src))
+			assert.NotContains(t, out, "<script")
+			assert.NotContains(t, out, "<img")
+			assert.NotContains(t, out, "javascript:")
+			assert.NotContains(t, out, `"onmouseover`)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"time"
+)
+
+// Notification events, as recorded in the notification history
+const (
+	NotificationEventHighCost     = "high_cost_alert"
+	NotificationEventRenewal      = "renewal_reminder"
+	NotificationEventCancellation = "cancellation_reminder"
+)
+
+// NotificationHistoryRetention is how long notification history entries are kept
+const NotificationHistoryRetention = 30 * 24 * time.Hour
+
+// Notifier delivers alerts and reminders through one notification channel.
+// Implementations check their own event and channel settings and return nil
+// when there is nothing to send.
+type Notifier interface {
+	SendHighCostAlert(subscription *models.Subscription) error
+	SendRenewalReminder(subscription *models.Subscription, daysUntilRenewal int) error
+	SendCancellationReminder(subscription *models.Subscription, daysUntilCancellation int) error
+}
+
+// receiptNotifier is implemented by notifiers whose high-cost alerts can return
+// a receipt, such as Pushover emergency-priority notifications, so it's recorded
+// in the history
+type receiptNotifier interface {
+	SendHighCostAlertWithReceipt(subscription *models.Subscription) (string, error)
+}
+
+// skipper is implemented by notifiers that can tell ahead of time that they
+// won't send an event, so skipped channels are left out of the history
+type skipper interface {
+	Skips(event string) bool
+}
+
+// eventEnabled reports whether notifications for event are turned on
+func eventEnabled(settings *SettingsService, event string) bool {
+	var enabled bool
+	var err error
+	switch event {
+	case NotificationEventHighCost:
+		enabled, err = settings.GetBoolSetting("high_cost_alerts", true)
+	case NotificationEventRenewal:
+		enabled, err = settings.GetBoolSetting("renewal_reminders", false)
+	case NotificationEventCancellation:
+		enabled, err = settings.GetBoolSetting("cancellation_reminders", false)
+	}
+	return err == nil && enabled
+}
+
+// channelNotifier is a Notifier and the channel it delivers through
+type channelNotifier struct {
+	channel  string
+	notifier Notifier
+}
+
+// NotificationService sends each alert or reminder to every notification channel
+type NotificationService struct {
+	notifiers []channelNotifier
+	history   *repository.NotificationLogRepository
+}
+
+// NewNotificationService creates a notification service that sends through
+// email, Pushover, webhooks and Apprise
+func NewNotificationService(settingsService *SettingsService) *NotificationService {
+	return &NotificationService{
+		notifiers: []channelNotifier{
+			{NotificationChannelEmail, NewEmailService(settingsService)},
+			{NotificationChannelPushover, NewPushoverService(settingsService)},
+			{NotificationChannelWebhook, NewWebhookService(settingsService)},
+			{NotificationChannelApprise, NewAppriseService(settingsService)},
+		},
+	}
+}
+
+// SetHistory records every delivery attempt in history
+func (n *NotificationService) SetHistory(history *repository.NotificationLogRepository) {
+	n.history = history
+}
+
+// GetHistory returns up to limit recorded delivery attempts, newest first
+func (n *NotificationService) GetHistory(limit int) ([]models.NotificationLog, error) {
+	if n.history == nil {
+		return []models.NotificationLog{}, nil
+	}
+	return n.history.GetRecent(limit)
+}
+
+// SetCurrencyService provides the exchange rates the channels use to compare
+// costs in other currencies with their thresholds
+func (n *NotificationService) SetCurrencyService(currencyService *CurrencyService) {
+	for _, cn := range n.notifiers {
+		if p, ok := cn.notifier.(*PushoverService); ok {
+			p.SetCurrencyService(currencyService)
+		}
+	}
+}
+
+// NotificationError reports the channels that failed to deliver a notification
+type NotificationError struct {
+	Channels  []string // Failed channels, in the order they were tried
+	Errors    []error  // The error from each failed channel
+	AllFailed bool     // No channel delivered the notification
+}
+
+func (e *NotificationError) Error() string {
+	failed := make([]string, len(e.Channels))
+	for i, channel := range e.Channels {
+		failed[i] = fmt.Sprintf("%s=%v", channel, e.Errors[i])
+	}
+	return strings.Join(failed, ", ")
+}
+
+// SendHighCostAlert alerts every channel about a high-cost subscription
+func (n *NotificationService) SendHighCostAlert(subscription *models.Subscription) error {
+	return n.notify(NotificationEventHighCost, subscription, func(notifier Notifier) (string, error) {
+		if r, ok := notifier.(receiptNotifier); ok {
+			return r.SendHighCostAlertWithReceipt(subscription)
+		}
+		return "", notifier.SendHighCostAlert(subscription)
+	})
+}
+
+// SendRenewalReminder reminds every channel about an upcoming renewal
+func (n *NotificationService) SendRenewalReminder(subscription *models.Subscription, daysUntilRenewal int) error {
+	return n.notify(NotificationEventRenewal, subscription, func(notifier Notifier) (string, error) {
+		return "", notifier.SendRenewalReminder(subscription, daysUntilRenewal)
+	})
+}
+
+// SendCancellationReminder reminds every channel about an upcoming cancellation
+func (n *NotificationService) SendCancellationReminder(subscription *models.Subscription, daysUntilCancellation int) error {
+	return n.notify(NotificationEventCancellation, subscription, func(notifier Notifier) (string, error) {
+		return "", notifier.SendCancellationReminder(subscription, daysUntilCancellation)
+	})
+}
+
+// SendHeldRenewalReminder sends a renewal reminder through the push channels
+// only, once quiet hours have ended. The other channels sent it on schedule.
+func (n *NotificationService) SendHeldRenewalReminder(subscription *models.Subscription, daysUntilRenewal int) error {
+	return n.notifyThrough(n.pushNotifiers(), NotificationEventRenewal, subscription, func(notifier Notifier) (string, error) {
+		return "", notifier.SendRenewalReminder(subscription, daysUntilRenewal)
+	})
+}
+
+// SendHeldCancellationReminder sends a cancellation reminder through the push
+// channels only, once quiet hours have ended. The other channels sent it on schedule.
+func (n *NotificationService) SendHeldCancellationReminder(subscription *models.Subscription, daysUntilCancellation int) error {
+	return n.notifyThrough(n.pushNotifiers(), NotificationEventCancellation, subscription, func(notifier Notifier) (string, error) {
+		return "", notifier.SendCancellationReminder(subscription, daysUntilCancellation)
+	})
+}
+
+// isPushChannel reports whether channel sends push notifications, which are
+// held back during quiet hours
+func isPushChannel(channel string) bool {
+	return channel == NotificationChannelPushover || channel == NotificationChannelApprise
+}
+
+// pushNotifiers returns the notifiers of the push channels
+func (n *NotificationService) pushNotifiers() []channelNotifier {
+	var push []channelNotifier
+	for _, cn := range n.notifiers {
+		if isPushChannel(cn.channel) {
+			push = append(push, cn)
+		}
+	}
+	return push
+}
+
+// notify calls send for every channel
+func (n *NotificationService) notify(event string, subscription *models.Subscription, send func(Notifier) (string, error)) error {
+	return n.notifyThrough(n.notifiers, event, subscription, send)
+}
+
+// notifyThrough calls send for each of notifiers. One channel failing doesn't
+// stop the others; the failures are returned together as a *NotificationError.
+// Each channel that doesn't skip the event is recorded in the history.
+func (n *NotificationService) notifyThrough(notifiers []channelNotifier, event string, subscription *models.Subscription, send func(Notifier) (string, error)) error {
+	var notifyErr NotificationError
+	for _, cn := range notifiers {
+		skipped := false
+		if s, ok := cn.notifier.(skipper); ok {
+			skipped = s.Skips(event)
+		}
+		receipt, err := send(cn.notifier)
+		if err != nil {
+			notifyErr.Channels = append(notifyErr.Channels, cn.channel)
+			notifyErr.Errors = append(notifyErr.Errors, err)
+		}
+		if !skipped {
+			n.record(cn.channel, event, subscription, receipt, err)
+		}
+	}
+	n.pruneHistory()
+	if len(notifyErr.Channels) == 0 {
+		return nil
+	}
+	notifyErr.AllFailed = len(notifyErr.Channels) == len(notifiers)
+	return &notifyErr
+}
+
+// record adds a delivery attempt and any receipt to the history; a failure to record is logged
+// rather than returned so it never hides the delivery result
+func (n *NotificationService) record(channel, event string, subscription *models.Subscription, receipt string, sendErr error) {
+	if n.history == nil {
+		return
+	}
+	entry := &models.NotificationLog{
+		Channel:          channel,
+		Event:            event,
+		SubscriptionID:   subscription.ID,
+		SubscriptionName: subscription.Name,
+		Success:          sendErr == nil,
+		Receipt:          receipt,
+	}
+	if sendErr != nil {
+		entry.Error = sendErr.Error()
+	}
+	if err := n.history.Create(entry); err != nil {
+		slog.Warn("Failed to record notification history", "channel", channel, "event", event, "error", err)
+	}
+}
+
+// pruneHistory drops history entries older than NotificationHistoryRetention
+func (n *NotificationService) pruneHistory() {
+	if n.history == nil {
+		return
+	}
+	if err := n.history.DeleteOlderThan(time.Now().Add(-NotificationHistoryRetention)); err != nil {
+		slog.Warn("Failed to prune notification history", "error", err)
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeNotifier records the events it receives and fails them with err
+type fakeNotifier struct {
+	events []string
+	err    error
+}
+
+func (f *fakeNotifier) SendHighCostAlert(subscription *models.Subscription) error {
+	f.events = append(f.events, "high_cost:"+subscription.Name)
+	return f.err
+}
+
+func (f *fakeNotifier) SendRenewalReminder(subscription *models.Subscription, daysUntilRenewal int) error {
+	f.events = append(f.events, "renewal:"+subscription.Name)
+	return f.err
+}
+
+func (f *fakeNotifier) SendCancellationReminder(subscription *models.Subscription, daysUntilCancellation int) error {
+	f.events = append(f.events, "cancellation:"+subscription.Name)
+	return f.err
+}
+
+func TestNotificationService_SendsToEveryChannel(t *testing.T) {
+	email, pushover, webhook := &fakeNotifier{}, &fakeNotifier{}, &fakeNotifier{}
+	ns := &NotificationService{notifiers: []channelNotifier{
+		{NotificationChannelEmail, email},
+		{NotificationChannelPushover, pushover},
+		{NotificationChannelWebhook, webhook},
+	}}
+	sub := &models.Subscription{Name: "Netflix"}
+
+	assert.NoError(t, ns.SendHighCostAlert(sub))
+	assert.NoError(t, ns.SendRenewalReminder(sub, 3))
+	assert.NoError(t, ns.SendCancellationReminder(sub, 3))
+
+	for _, notifier := range []*fakeNotifier{email, pushover, webhook} {
+		assert.Equal(t, []string{"high_cost:Netflix", "renewal:Netflix", "cancellation:Netflix"}, notifier.events)
+	}
+}
+
+func TestNotificationService_SendHeldReminders_PushChannelsOnly(t *testing.T) {
+	email, pushover, webhook, apprise := &fakeNotifier{}, &fakeNotifier{}, &fakeNotifier{}, &fakeNotifier{}
+	ns := &NotificationService{notifiers: []channelNotifier{
+		{NotificationChannelEmail, email},
+		{NotificationChannelPushover, pushover},
+		{NotificationChannelWebhook, webhook},
+		{NotificationChannelApprise, apprise},
+	}}
+	sub := &models.Subscription{Name: "Netflix"}
+
+	assert.NoError(t, ns.SendHeldRenewalReminder(sub, 3))
+	assert.NoError(t, ns.SendHeldCancellationReminder(sub, 3))
+
+	assert.Empty(t, email.events)
+	assert.Empty(t, webhook.events)
+	for _, notifier := range []*fakeNotifier{pushover, apprise} {
+		assert.Equal(t, []string{"renewal:Netflix", "cancellation:Netflix"}, notifier.events)
+	}
+}
+
+func TestNotificationService_ReportsFailedChannels(t *testing.T) {
+	sub := &models.Subscription{Name: "Netflix"}
+
+	t.Run("Some channels failed", func(t *testing.T) {
+		webhook := &fakeNotifier{}
+		ns := &NotificationService{notifiers: []channelNotifier{
+			{NotificationChannelEmail, &fakeNotifier{err: errors.New("smtp down")}},
+			{NotificationChannelWebhook, webhook},
+		}}
+
+		err := ns.SendHighCostAlert(sub)
+		var notifyErr *NotificationError
+		if assert.ErrorAs(t, err, &notifyErr) {
+			assert.Equal(t, []string{NotificationChannelEmail}, notifyErr.Channels)
+			assert.False(t, notifyErr.AllFailed)
+			assert.EqualError(t, err, "email=smtp down")
+		}
+		assert.Len(t, webhook.events, 1, "A failing channel shouldn't stop the others")
+	})
+
+	t.Run("Every channel failed", func(t *testing.T) {
+		ns := &NotificationService{notifiers: []channelNotifier{
+			{NotificationChannelEmail, &fakeNotifier{err: errors.New("smtp down")}},
+			{NotificationChannelPushover, &fakeNotifier{err: errors.New("invalid token")}},
+		}}
+
+		err := ns.SendRenewalReminder(sub, 3)
+		var notifyErr *NotificationError
+		if assert.ErrorAs(t, err, &notifyErr) {
+			assert.True(t, notifyErr.AllFailed)
+			assert.EqualError(t, err, "email=smtp down, pushover=invalid token")
+		}
+	})
+}
+
+// setupNotificationTestDB creates a settings service backed by an in-memory
+// database that can also hold notification history
+func setupNotificationTestDB(t *testing.T) (*SettingsService, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Settings{}, &models.NotificationLog{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return NewSettingsService(repository.NewSettingsRepository(db)), db
+}
+
+func TestNotificationService_SendHighCostAlert_ReachesConfiguredChannels(t *testing.T) {
+	ss, _ := setupNotificationTestDB(t)
+	ss.SetCurrency("USD")
+	ss.SetBoolSetting("high_cost_alerts", true)
+
+	webhookRequests := 0
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webhookRequests++
+	}))
+	defer webhookServer.Close()
+	assert.NoError(t, ss.SaveWebhookConfig(&models.WebhookConfig{URL: webhookServer.URL}))
+
+	var appriseRequests []appriseRequest
+	appriseServer := newAppriseServer(t, http.StatusOK, &appriseRequests)
+	assert.NoError(t, ss.SaveAppriseConfig(&models.AppriseConfig{URLs: []string{"ntfy://topic"}, ServerURL: appriseServer.URL}))
+
+	// Email and Pushover aren't configured, so leave them out rather than have them fail
+	ss.SetNotificationChannelEnabled(NotificationChannelEmail, false)
+	ss.SetNotificationChannelEnabled(NotificationChannelPushover, false)
+
+	sub := &models.Subscription{
+		Name:     "Adobe Creative Cloud",
+		Cost:     89.99,
+		Schedule: "Monthly",
+		Status:   "Active",
+		Category: models.Category{Name: "Software"},
+	}
+
+	assert.NoError(t, NewNotificationService(ss).SendHighCostAlert(sub))
+	assert.Equal(t, 1, webhookRequests, "The webhook should receive the alert once")
+	if assert.Len(t, appriseRequests, 1, "Apprise should receive the alert once") {
+		assert.Equal(t, "High Cost Alert: Adobe Creative Cloud", appriseRequests[0].Title)
+	}
+}
+
+func TestNotificationService_RecordsFailedWebhookInHistory(t *testing.T) {
+	ss, db := setupNotificationTestDB(t)
+	ss.SetCurrency("USD")
+	ss.SetBoolSetting("renewal_reminders", true)
+
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer webhookServer.Close()
+	assert.NoError(t, ss.SaveWebhookConfig(&models.WebhookConfig{URL: webhookServer.URL}))
+
+	// Email and Pushover are switched off and Apprise isn't configured, so only
+	// the webhook is tried and recorded
+	ss.SetNotificationChannelEnabled(NotificationChannelEmail, false)
+	ss.SetNotificationChannelEnabled(NotificationChannelPushover, false)
+
+	ns := NewNotificationService(ss)
+	ns.SetHistory(repository.NewNotificationLogRepository(db))
+	sub := &models.Subscription{ID: 7, Name: "Netflix", Cost: 15.49, Schedule: "Monthly", Status: "Active"}
+
+	assert.Error(t, ns.SendRenewalReminder(sub, 3))
+
+	history, err := ns.GetHistory(10)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		entry := history[0]
+		assert.Equal(t, NotificationChannelWebhook, entry.Channel)
+		assert.Equal(t, NotificationEventRenewal, entry.Event)
+		assert.Equal(t, uint(7), entry.SubscriptionID)
+		assert.Equal(t, "Netflix", entry.SubscriptionName)
+		assert.False(t, entry.Success)
+		assert.Contains(t, entry.Error, "500")
+	}
+}
+
+func TestNotificationService_RecordsPushoverReceiptInHistory(t *testing.T) {
+	ss, db := setupNotificationTestDB(t)
+	ss.SetCurrency("USD")
+	ss.SetBoolSetting("high_cost_alerts", true)
+	assert.NoError(t, ss.SavePushoverConfig(&models.PushoverConfig{UserKey: "user-key", AppToken: "app-token", EmergencyThreshold: 100}))
+
+	var received url.Values
+	newPushoverStub(t, `{"status":1,"request":"test","receipt":"rcpt123"}`, &received)
+
+	// Only Pushover is tried and recorded
+	ss.SetNotificationChannelEnabled(NotificationChannelEmail, false)
+	ss.SetNotificationChannelEnabled(NotificationChannelWebhook, false)
+
+	ns := NewNotificationService(ss)
+	ns.SetHistory(repository.NewNotificationLogRepository(db))
+	sub := &models.Subscription{ID: 3, Name: "Enterprise Suite", Cost: 250, Schedule: "Monthly", Status: "Active"}
+
+	assert.NoError(t, ns.SendHighCostAlert(sub))
+
+	history, err := ns.GetHistory(10)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, NotificationChannelPushover, history[0].Channel)
+		assert.True(t, history[0].Success)
+		assert.Equal(t, "rcpt123", history[0].Receipt)
+	}
+}
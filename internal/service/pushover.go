@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"subtrackr/internal/models"
 	"time"
 )
@@ -14,6 +16,7 @@ import (
 // PushoverService handles sending notifications via Pushover
 type PushoverService struct {
 	settingsService *SettingsService
+	currencyService *CurrencyService // Converts costs for the emergency threshold; nil compares them unconverted
 }
 
 // NewPushoverService creates a new Pushover service
@@ -23,27 +26,70 @@ func NewPushoverService(settingsService *SettingsService) *PushoverService {
 	}
 }
 
+// SetCurrencyService provides the exchange rates used to compare costs in other
+// currencies with the emergency threshold
+func (p *PushoverService) SetCurrencyService(currencyService *CurrencyService) {
+	p.currencyService = currencyService
+}
+
 // PushoverResponse represents the response from Pushover API
 type PushoverResponse struct {
 	Status  int      `json:"status"`
 	Request string   `json:"request"`
+	Receipt string   `json:"receipt,omitempty"` // Identifies an emergency-priority notification until it's acknowledged
 	Errors  []string `json:"errors,omitempty"`
 }
 
+// PushoverAPIURL is the Pushover messages API endpoint
+var PushoverAPIURL = "https://api.pushover.net/1/messages.json"
+
+// Pushover message priorities used by SubTrackr
+const (
+	PushoverPriorityNormal    = 0
+	PushoverPriorityHigh      = 1 // Bypasses the user's quiet hours
+	PushoverPriorityEmergency = 2 // Repeats until acknowledged; requires retry and expire
+)
+
+// Limits and defaults, in seconds, for repeating emergency-priority notifications
+const (
+	PushoverMinRetry      = 30
+	PushoverMaxExpire     = 10800
+	DefaultPushoverRetry  = 60
+	DefaultPushoverExpire = 3600
+)
+
+// PushoverSounds are Pushover's built-in notification sounds. Users can also
+// upload their own, so other names are left for the API to accept or reject.
+var PushoverSounds = []string{
+	"pushover", "bike", "bugle", "cashregister", "classical", "cosmic", "falling",
+	"gamelan", "incoming", "intermission", "magic", "mechanical", "pianobar",
+	"siren", "spacealarm", "tugboat", "alien", "climb", "persistent", "echo",
+	"updown", "vibrate", "none",
+}
+
 // SendNotification sends a notification via Pushover
 func (p *PushoverService) SendNotification(title, message string, priority int) error {
 	config, err := p.settingsService.GetPushoverConfig()
 	if err != nil {
 		return fmt.Errorf("failed to get Pushover config: %w", err)
 	}
+	return p.Send(config, title, message, priority)
+}
+
+// Send delivers a notification using config rather than the saved configuration,
+// so a configuration can be tested before it is saved
+func (p *PushoverService) Send(config *models.PushoverConfig, title, message string, priority int) error {
+	_, err := p.send(config, title, message, priority)
+	return err
+}
 
+// send delivers a notification using config and returns the receipt Pushover
+// issues for emergency-priority notifications, or "" for other priorities
+func (p *PushoverService) send(config *models.PushoverConfig, title, message string, priority int) (string, error) {
 	if config.UserKey == "" || config.AppToken == "" {
-		return fmt.Errorf("Pushover not configured: user key and app token required")
+		return "", fmt.Errorf("Pushover not configured: user key and app token required")
 	}
 
-	// Pushover API endpoint
-	apiURL := "https://api.pushover.net/1/messages.json"
-
 	// Prepare form data
 	formData := url.Values{}
 	formData.Set("token", config.AppToken)
@@ -51,11 +97,22 @@ func (p *PushoverService) SendNotification(title, message string, priority int)
 	formData.Set("title", title)
 	formData.Set("message", message)
 	formData.Set("priority", strconv.Itoa(priority))
+	if config.Device != "" {
+		formData.Set("device", config.Device)
+	}
+	if sound := pushoverSound(config, priority); sound != "" {
+		formData.Set("sound", sound)
+	}
+	if priority == PushoverPriorityEmergency {
+		retry, expire := pushoverEmergencyTiming(config)
+		formData.Set("retry", strconv.Itoa(retry))
+		formData.Set("expire", strconv.Itoa(expire))
+	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBufferString(formData.Encode()))
+	req, err := http.NewRequest("POST", PushoverAPIURL, bytes.NewBufferString(formData.Encode()))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -66,127 +123,227 @@ func (p *PushoverService) SendNotification(title, message string, priority int)
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send Pushover notification: %w", err)
+		return "", fmt.Errorf("failed to send Pushover notification: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Parse response
 	var pushoverResp PushoverResponse
 	if err := json.NewDecoder(resp.Body).Decode(&pushoverResp); err != nil {
-		return fmt.Errorf("failed to decode Pushover response: %w", err)
+		return "", fmt.Errorf("Pushover returned status %d with an unreadable response", resp.StatusCode)
 	}
 
 	if pushoverResp.Status != 1 {
-		errorMsg := "Pushover API error"
+		// The API explains rejections, e.g. "application token is invalid"
 		if len(pushoverResp.Errors) > 0 {
-			errorMsg = pushoverResp.Errors[0]
+			return "", fmt.Errorf("Pushover API error: %s", strings.Join(pushoverResp.Errors, "; "))
+		}
+		return "", fmt.Errorf("Pushover API error (status %d)", resp.StatusCode)
+	}
+
+	if pushoverResp.Receipt != "" {
+		slog.Info("Sent Pushover emergency notification", "title", title, "receipt", pushoverResp.Receipt)
+	}
+
+	return pushoverResp.Receipt, nil
+}
+
+// pushoverEmergencyTiming returns the configured retry and expire intervals,
+// or the defaults when they're unset
+func pushoverEmergencyTiming(config *models.PushoverConfig) (retry, expire int) {
+	retry, expire = config.EmergencyRetry, config.EmergencyExpire
+	if retry <= 0 {
+		retry = DefaultPushoverRetry
+	}
+	if expire <= 0 {
+		expire = DefaultPushoverExpire
+	}
+	return retry, expire
+}
+
+// highCostPriority returns emergency priority for subscriptions above the
+// emergency threshold and high priority otherwise. Like the high-cost threshold,
+// it applies to the high-cost basis in the display currency, so the cost is
+// converted when conversion is available.
+func (p *PushoverService) highCostPriority(subscription *models.Subscription) int {
+	config, err := p.settingsService.GetPushoverConfig()
+	if err != nil || config.EmergencyThreshold <= 0 {
+		return PushoverPriorityHigh
+	}
+
+	cost := subscription.CostForBasis(p.settingsService.GetHighCostBasis())
+	displayCurrency := p.settingsService.GetCurrency()
+	if subscription.OriginalCurrency != "" && subscription.OriginalCurrency != displayCurrency && p.currencyService != nil && p.currencyService.IsEnabled() {
+		if converted, err := p.currencyService.ConvertAmount(cost, subscription.OriginalCurrency, displayCurrency); err == nil {
+			cost = converted
+		} else {
+			slog.Warn("Failed to convert currency for emergency threshold, using direct comparison", "from", subscription.OriginalCurrency, "to", displayCurrency, "error", err)
 		}
-		return fmt.Errorf("%s", errorMsg)
 	}
 
-	return nil
+	if cost > config.EmergencyThreshold {
+		return PushoverPriorityEmergency
+	}
+	return PushoverPriorityHigh
+}
+
+// pushoverSound picks the configured sound for a notification of priority
+func pushoverSound(config *models.PushoverConfig, priority int) string {
+	if priority > 0 && config.HighPrioritySound != "" {
+		return config.HighPrioritySound
+	}
+	return config.Sound
+}
+
+// Skips reports whether event won't be sent through Pushover, because the event
+// or the channel is turned off or it is quiet hours
+func (p *PushoverService) Skips(event string) bool {
+	if !eventEnabled(p.settingsService, event) || !p.settingsService.IsNotificationChannelEnabled(NotificationChannelPushover) {
+		return true
+	}
+	// Push notifications are held back during quiet hours
+	return p.settingsService.IsWithinQuietHours(time.Now())
 }
 
 // SendHighCostAlert sends a Pushover alert when a high-cost subscription is created
 func (p *PushoverService) SendHighCostAlert(subscription *models.Subscription) error {
-	// Check if high cost alerts are enabled
-	enabled, err := p.settingsService.GetBoolSetting("high_cost_alerts", true)
-	if err != nil || !enabled {
-		return nil // Silently skip if disabled
+	_, err := p.SendHighCostAlertWithReceipt(subscription)
+	return err
+}
+
+// SendHighCostAlertWithReceipt is SendHighCostAlert, also returning the receipt
+// of an emergency-priority alert so its acknowledgement can be tracked
+func (p *PushoverService) SendHighCostAlertWithReceipt(subscription *models.Subscription) (string, error) {
+	if p.Skips(NotificationEventHighCost) {
+		return "", nil
+	}
+
+	config, err := p.settingsService.GetPushoverConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to get Pushover config: %w", err)
+	}
+	title, message := highCostAlertText(subscription, p.settingsService)
+	// High priority (with sound and vibration), or emergency priority above the emergency threshold
+	return p.send(config, title, message, p.highCostPriority(subscription))
+}
+
+// SendRenewalReminder sends a Pushover reminder for an upcoming subscription renewal
+func (p *PushoverService) SendRenewalReminder(subscription *models.Subscription, daysUntilRenewal int) error {
+	if p.Skips(NotificationEventRenewal) {
+		return nil
+	}
+
+	title, message := renewalReminderText(subscription, daysUntilRenewal, p.settingsService)
+	return p.SendNotification(title, message, PushoverPriorityNormal)
+}
+
+// SendCancellationReminder sends a Pushover reminder for an upcoming subscription cancellation
+func (p *PushoverService) SendCancellationReminder(subscription *models.Subscription, daysUntilCancellation int) error {
+	if p.Skips(NotificationEventCancellation) {
+		return nil
 	}
 
-	// Get currency symbol - use subscription's own currency if it differs from preferred
-	currencySymbol := currencySymbolForSubscription(subscription, p.settingsService)
+	title, message := cancellationReminderText(subscription, daysUntilCancellation, p.settingsService)
+	return p.SendNotification(title, message, PushoverPriorityNormal)
+}
 
-	// Build message
-	message := "⚠️ High Cost Alert\n\n"
+// highCostAlertText builds the plain-text title and message of a high-cost alert
+// for push notification channels
+func highCostAlertText(subscription *models.Subscription, settings *SettingsService) (title, message string) {
+	// Format amounts in the subscription's own currency
+	money := func(amount float64) string {
+		return formatMoneyForSubscription(amount, subscription, settings)
+	}
+
+	message = "⚠️ High Cost Alert\n\n"
 	message += fmt.Sprintf("Subscription: %s\n", subscription.Name)
-	message += fmt.Sprintf("Cost: %s%.2f %s\n", currencySymbol, subscription.Cost, subscription.DisplaySchedule())
-	message += fmt.Sprintf("Monthly Cost: %s%.2f\n", currencySymbol, subscription.MonthlyCost())
+	message += fmt.Sprintf("Cost: %s %s\n", money(subscription.Cost), subscription.DisplaySchedule())
+	basisLabel, basisAmount := highCostAmount(subscription, settings)
+	message += fmt.Sprintf("%s: %s\n", basisLabel, money(basisAmount))
 	if subscription.Category.Name != "" {
 		message += fmt.Sprintf("Category: %s\n", subscription.Category.Name)
 	}
 	if subscription.RenewalDate != nil {
-		message += fmt.Sprintf("Next Renewal: %s\n", subscription.RenewalDate.Format(p.settingsService.GetGoDateFormatLong()))
+		message += fmt.Sprintf("Next Renewal: %s\n", subscription.RenewalDate.In(settings.GetLocation()).Format(settings.GetGoDateFormatLong()))
 	}
 	if subscription.URL != "" {
 		message += fmt.Sprintf("URL: %s", subscription.URL)
 	}
 
-	title := fmt.Sprintf("High Cost Alert: %s", subscription.Name)
-	// Priority 1 = high priority (with sound and vibration)
-	return p.SendNotification(title, message, 1)
+	return fmt.Sprintf("High Cost Alert: %s", subscription.Name), message
 }
 
-// SendRenewalReminder sends a Pushover reminder for an upcoming subscription renewal
-func (p *PushoverService) SendRenewalReminder(subscription *models.Subscription, daysUntilRenewal int) error {
-	// Check if renewal reminders are enabled
-	enabled, err := p.settingsService.GetBoolSetting("renewal_reminders", false)
-	if err != nil || !enabled {
-		return nil // Silently skip if disabled
+// renewalReminderText builds the plain-text title and message of a renewal
+// reminder for push notification channels
+func renewalReminderText(subscription *models.Subscription, daysUntilRenewal int, settings *SettingsService) (title, message string) {
+	// Format amounts in the subscription's own currency
+	money := func(amount float64) string {
+		return formatMoneyForSubscription(amount, subscription, settings)
 	}
 
-	// Get currency symbol - use subscription's own currency if it differs from preferred
-	currencySymbol := currencySymbolForSubscription(subscription, p.settingsService)
-
-	// Build message
 	daysText := "days"
 	if daysUntilRenewal == 1 {
 		daysText = "day"
 	}
-	message := "🔔 Renewal Reminder\n\n"
+	message = "🔔 Renewal Reminder\n\n"
 	message += fmt.Sprintf("Your subscription %s will renew in %d %s.\n\n", subscription.Name, daysUntilRenewal, daysText)
 	message += "Subscription Details:\n"
-	message += fmt.Sprintf("Cost: %s%.2f %s\n", currencySymbol, subscription.Cost, subscription.DisplaySchedule())
-	message += fmt.Sprintf("Monthly Cost: %s%.2f\n", currencySymbol, subscription.MonthlyCost())
+	message += fmt.Sprintf("Cost: %s %s\n", money(subscription.Cost), subscription.DisplaySchedule())
+	message += fmt.Sprintf("Monthly Cost: %s\n", money(subscription.MonthlyCost()))
+	now := time.Now()
+	firstCharge := subscription.PendingFirstCharge(now)
+	if firstCharge {
+		message += fmt.Sprintf("First Charge (prorated): %s\n", money(subscription.FirstChargeCost()))
+	} else if subscription.NextChargeAmount != nil {
+		message += fmt.Sprintf("Next Charge: %s\n", money(subscription.NextChargeCost()))
+	}
 	if subscription.Category.Name != "" {
 		message += fmt.Sprintf("Category: %s\n", subscription.Category.Name)
 	}
-	if subscription.RenewalDate != nil {
-		message += fmt.Sprintf("Renewal Date: %s\n", subscription.RenewalDate.Format(p.settingsService.GetGoDateFormatLong()))
+	if chargeDate := subscription.UpcomingChargeDate(now); chargeDate != nil {
+		label := "Renewal Date"
+		if firstCharge {
+			label = "First Charge Date"
+		}
+		message += fmt.Sprintf("%s: %s\n", label, chargeDate.In(settings.GetLocation()).Format(settings.GetGoDateFormatLong()))
 	}
 	if subscription.URL != "" {
 		message += fmt.Sprintf("URL: %s", subscription.URL)
 	}
 
-	title := fmt.Sprintf("Renewal Reminder: %s", subscription.Name)
-	// Priority 0 = normal priority
-	return p.SendNotification(title, message, 0)
+	return fmt.Sprintf("Renewal Reminder: %s", subscription.Name), message
 }
 
-// SendCancellationReminder sends a Pushover reminder for an upcoming subscription cancellation
-func (p *PushoverService) SendCancellationReminder(subscription *models.Subscription, daysUntilCancellation int) error {
-	// Check if cancellation reminders are enabled
-	enabled, err := p.settingsService.GetBoolSetting("cancellation_reminders", false)
-	if err != nil || !enabled {
-		return nil // Silently skip if disabled
+// cancellationReminderText builds the plain-text title and message of a
+// cancellation reminder for push notification channels
+func cancellationReminderText(subscription *models.Subscription, daysUntilCancellation int, settings *SettingsService) (title, message string) {
+	// Format amounts in the subscription's own currency
+	money := func(amount float64) string {
+		return formatMoneyForSubscription(amount, subscription, settings)
 	}
 
-	// Get currency symbol - use subscription's own currency if it differs from preferred
-	currencySymbol := currencySymbolForSubscription(subscription, p.settingsService)
-
-	// Build message
 	daysText := "days"
 	if daysUntilCancellation == 1 {
 		daysText = "day"
 	}
-	message := "⚠️ Cancellation Reminder\n\n"
+	message = "⚠️ Cancellation Reminder\n\n"
 	message += fmt.Sprintf("Your subscription %s will end in %d %s.\n\n", subscription.Name, daysUntilCancellation, daysText)
 	message += "Subscription Details:\n"
-	message += fmt.Sprintf("Cost: %s%.2f %s\n", currencySymbol, subscription.Cost, subscription.DisplaySchedule())
-	message += fmt.Sprintf("Monthly Cost: %s%.2f\n", currencySymbol, subscription.MonthlyCost())
+	message += fmt.Sprintf("Cost: %s %s\n", money(subscription.Cost), subscription.DisplaySchedule())
+	message += fmt.Sprintf("Monthly Cost: %s\n", money(subscription.MonthlyCost()))
 	if subscription.Category.Name != "" {
 		message += fmt.Sprintf("Category: %s\n", subscription.Category.Name)
 	}
 	if subscription.CancellationDate != nil {
-		message += fmt.Sprintf("Cancellation Date: %s\n", subscription.CancellationDate.Format(p.settingsService.GetGoDateFormatLong()))
+		message += fmt.Sprintf("Cancellation Date: %s\n", subscription.CancellationDate.In(settings.GetLocation()).Format(settings.GetGoDateFormatLong()))
 	}
 	if subscription.URL != "" {
-		message += fmt.Sprintf("URL: %s", subscription.URL)
+		message += fmt.Sprintf("URL: %s\n", subscription.URL)
+	}
+	if subscription.CancellationURL != "" {
+		message += fmt.Sprintf("Cancel at: %s", subscription.CancellationURL)
 	}
 
-	title := fmt.Sprintf("Cancellation Reminder: %s", subscription.Name)
-	// Priority 0 = normal priority
-	return p.SendNotification(title, message, 0)
+	return fmt.Sprintf("Cancellation Reminder: %s", subscription.Name), message
 }
 
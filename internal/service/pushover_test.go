@@ -1,6 +1,9 @@
 package service
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"subtrackr/internal/models"
 	"subtrackr/internal/repository"
@@ -183,6 +186,202 @@ func TestPushoverService_SendRenewalReminder_EnabledButNoConfig(t *testing.T) {
 	assert.Error(t, err, "Should return error when Pushover is not configured")
 }
 
+func TestPushoverService_SendRenewalReminder_ChannelDisabled(t *testing.T) {
+	db := setupPushoverTestDB(t)
+	settingsRepo := repository.NewSettingsRepository(db)
+	settingsService := NewSettingsService(settingsRepo)
+	pushoverService := NewPushoverService(settingsService)
+
+	// Enable renewal reminders but switch off the Pushover channel. Pushover
+	// isn't configured, so any attempt to send would return an error.
+	settingsService.SetBoolSetting("renewal_reminders", true)
+	settingsService.SetNotificationChannelEnabled(NotificationChannelPushover, false)
+
+	subscription := &models.Subscription{
+		Name:        "Test Subscription",
+		Cost:        10.00,
+		Schedule:    "Monthly",
+		Status:      "Active",
+		RenewalDate: timePtr(time.Now().AddDate(0, 0, 3)),
+		Category:    models.Category{Name: "Test"},
+	}
+
+	err := pushoverService.SendRenewalReminder(subscription, 3)
+	assert.NoError(t, err, "Should return nil when the Pushover channel is disabled")
+}
+
+func TestPushoverService_SendRenewalReminder_QuietHours(t *testing.T) {
+	db := setupPushoverTestDB(t)
+	settingsRepo := repository.NewSettingsRepository(db)
+	settingsService := NewSettingsService(settingsRepo)
+	pushoverService := NewPushoverService(settingsService)
+
+	// Enable renewal reminders during quiet hours. Pushover isn't configured, so
+	// any attempt to send would return an error.
+	settingsService.SetBoolSetting("renewal_reminders", true)
+	now := time.Now().UTC()
+	assert.NoError(t, settingsService.SetQuietHours(now.Add(-time.Hour).Format("15:04"), now.Add(time.Hour).Format("15:04")))
+
+	subscription := &models.Subscription{
+		Name:        "Test Subscription",
+		Cost:        10.00,
+		Schedule:    "Monthly",
+		Status:      "Active",
+		RenewalDate: timePtr(time.Now().AddDate(0, 0, 3)),
+		Category:    models.Category{Name: "Test"},
+	}
+
+	err := pushoverService.SendRenewalReminder(subscription, 3)
+	assert.NoError(t, err, "Should hold back Pushover notifications during quiet hours")
+}
+
+func TestPushoverService_Send_DeviceAndSound(t *testing.T) {
+	db := setupPushoverTestDB(t)
+	pushoverService := NewPushoverService(NewSettingsService(repository.NewSettingsRepository(db)))
+
+	var received url.Values
+	newPushoverStub(t, `{"status":1,"request":"test"}`, &received)
+
+	config := &models.PushoverConfig{
+		UserKey:           "user-key",
+		AppToken:          "app-token",
+		Device:            "iphone",
+		Sound:             "cosmic",
+		HighPrioritySound: "siren",
+	}
+
+	t.Run("Reminders use the device and sound", func(t *testing.T) {
+		assert.NoError(t, pushoverService.Send(config, "Title", "Message", 0))
+		assert.Equal(t, "iphone", received.Get("device"))
+		assert.Equal(t, "cosmic", received.Get("sound"))
+	})
+
+	t.Run("High-priority alerts use the high-priority sound", func(t *testing.T) {
+		assert.NoError(t, pushoverService.Send(config, "Title", "Message", 1))
+		assert.Equal(t, "iphone", received.Get("device"))
+		assert.Equal(t, "siren", received.Get("sound"))
+	})
+
+	t.Run("High-priority alerts fall back to the sound", func(t *testing.T) {
+		noUrgentSound := *config
+		noUrgentSound.HighPrioritySound = ""
+		assert.NoError(t, pushoverService.Send(&noUrgentSound, "Title", "Message", 1))
+		assert.Equal(t, "cosmic", received.Get("sound"))
+	})
+
+	t.Run("Omitted when not configured", func(t *testing.T) {
+		assert.NoError(t, pushoverService.Send(&models.PushoverConfig{UserKey: "user-key", AppToken: "app-token"}, "Title", "Message", 1))
+		assert.NotContains(t, received, "device")
+		assert.NotContains(t, received, "sound")
+	})
+}
+
+// newPushoverStub points the Pushover API at a test server that records the
+// form of each request and answers with response
+func newPushoverStub(t *testing.T, response string, received *url.Values) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		*received = r.PostForm
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	original := PushoverAPIURL
+	PushoverAPIURL = server.URL
+	t.Cleanup(func() { PushoverAPIURL = original })
+}
+
+func TestPushoverService_Send_EmergencyPriority(t *testing.T) {
+	db := setupPushoverTestDB(t)
+	pushoverService := NewPushoverService(NewSettingsService(repository.NewSettingsRepository(db)))
+
+	var received url.Values
+	newPushoverStub(t, `{"status":1,"request":"test","receipt":"rcpt123"}`, &received)
+
+	t.Run("Includes retry and expire", func(t *testing.T) {
+		config := &models.PushoverConfig{UserKey: "user-key", AppToken: "app-token", EmergencyRetry: 120, EmergencyExpire: 7200}
+		assert.NoError(t, pushoverService.Send(config, "Title", "Message", PushoverPriorityEmergency))
+		assert.Equal(t, "2", received.Get("priority"))
+		assert.Equal(t, "120", received.Get("retry"))
+		assert.Equal(t, "7200", received.Get("expire"))
+	})
+
+	t.Run("Defaults retry and expire", func(t *testing.T) {
+		config := &models.PushoverConfig{UserKey: "user-key", AppToken: "app-token"}
+		assert.NoError(t, pushoverService.Send(config, "Title", "Message", PushoverPriorityEmergency))
+		assert.Equal(t, "60", received.Get("retry"))
+		assert.Equal(t, "3600", received.Get("expire"))
+	})
+
+	t.Run("Omitted below emergency priority", func(t *testing.T) {
+		config := &models.PushoverConfig{UserKey: "user-key", AppToken: "app-token", EmergencyRetry: 120, EmergencyExpire: 7200}
+		assert.NoError(t, pushoverService.Send(config, "Title", "Message", PushoverPriorityHigh))
+		assert.NotContains(t, received, "retry")
+		assert.NotContains(t, received, "expire")
+	})
+}
+
+func TestPushoverService_SendHighCostAlert_EmergencyThreshold(t *testing.T) {
+	db := setupPushoverTestDB(t)
+	settingsService := NewSettingsService(repository.NewSettingsRepository(db))
+	pushoverService := NewPushoverService(settingsService)
+	settingsService.SetBoolSetting("high_cost_alerts", true)
+	settingsService.SetCurrency("USD")
+	assert.NoError(t, settingsService.SavePushoverConfig(&models.PushoverConfig{
+		UserKey:            "user-key",
+		AppToken:           "app-token",
+		EmergencyThreshold: 200,
+	}))
+
+	var received url.Values
+	newPushoverStub(t, `{"status":1,"request":"test"}`, &received)
+
+	expensive := &models.Subscription{Name: "Expensive", Cost: 100, Schedule: "Monthly", Status: "Active"}
+	assert.NoError(t, pushoverService.SendHighCostAlert(expensive))
+	assert.Equal(t, "1", received.Get("priority"), "Alerts below the emergency threshold use high priority")
+
+	veryExpensive := &models.Subscription{Name: "Very Expensive", Cost: 250, Schedule: "Monthly", Status: "Active"}
+	assert.NoError(t, pushoverService.SendHighCostAlert(veryExpensive))
+	assert.Equal(t, "2", received.Get("priority"), "Alerts above the emergency threshold use emergency priority")
+	assert.Equal(t, "60", received.Get("retry"))
+	assert.Equal(t, "3600", received.Get("expire"))
+}
+
+func TestPushoverService_SendHighCostAlert_EmergencyThresholdConvertsCurrency(t *testing.T) {
+	t.Setenv("FIXER_API_KEY", "test-key")
+	db := setupPushoverTestDB(t)
+	if err := db.AutoMigrate(&models.ExchangeRate{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	rateRepo := repository.NewExchangeRateRepository(db)
+	assert.NoError(t, rateRepo.SaveRates([]models.ExchangeRate{{BaseCurrency: "EUR", Currency: "USD", Rate: 1.1, Date: time.Now()}}))
+
+	settingsService := NewSettingsService(repository.NewSettingsRepository(db))
+	pushoverService := NewPushoverService(settingsService)
+	settingsService.SetBoolSetting("high_cost_alerts", true)
+	settingsService.SetCurrency("USD")
+	assert.NoError(t, settingsService.SavePushoverConfig(&models.PushoverConfig{
+		UserKey:            "user-key",
+		AppToken:           "app-token",
+		EmergencyThreshold: 200,
+	}))
+
+	var received url.Values
+	newPushoverStub(t, `{"status":1,"request":"test","receipt":"rcpt123"}`, &received)
+
+	// €190 is below the threshold as a number but $209 in the display currency
+	sub := &models.Subscription{Name: "Euro Service", Cost: 190, OriginalCurrency: "EUR", Schedule: "Monthly", Status: "Active"}
+
+	assert.NoError(t, pushoverService.SendHighCostAlert(sub))
+	assert.Equal(t, "1", received.Get("priority"), "Without conversion the cost is compared unconverted")
+
+	pushoverService.SetCurrencyService(NewCurrencyService(rateRepo))
+	receipt, err := pushoverService.SendHighCostAlertWithReceipt(sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", received.Get("priority"), "The converted cost is above the emergency threshold")
+	assert.Equal(t, "rcpt123", receipt)
+}
+
 func TestPushoverService_SendHighCostAlert_MessageFormat(t *testing.T) {
 	db := setupPushoverTestDB(t)
 	settingsRepo := repository.NewSettingsRepository(db)
@@ -291,6 +291,66 @@ func TestEmailService_SendRenewalReminder_WithSMTPConfig(t *testing.T) {
 	assert.NotContains(t, err.Error(), "disabled", "Error should not be about being disabled")
 }
 
+func TestEmailService_SendRenewalReminder_SubscriptionLink(t *testing.T) {
+	db := setupRenewalReminderTestDB(t)
+	settingsService := NewSettingsService(repository.NewSettingsRepository(db))
+	settingsService.SetBoolSetting("renewal_reminders", true)
+
+	var body string
+	emailService := NewEmailService(settingsService)
+	emailService.send = func(subject, b string) error {
+		body = b
+		return nil
+	}
+
+	subscription := &models.Subscription{
+		ID:          42,
+		Name:        "Test Subscription",
+		Cost:        10.00,
+		Schedule:    "Monthly",
+		Status:      "Active",
+		RenewalDate: timePtr(time.Now().AddDate(0, 0, 3)),
+	}
+
+	t.Run("Links back when a base URL is configured", func(t *testing.T) {
+		assert.NoError(t, settingsService.SetBaseURL("https://subtrackr.example.com/"))
+		assert.NoError(t, emailService.SendRenewalReminder(subscription, 3))
+		assert.Contains(t, body, `<a href="https://subtrackr.example.com/subscriptions?edit=42">View in SubTrackr</a>`)
+	})
+
+	t.Run("Omits the link without a base URL", func(t *testing.T) {
+		assert.NoError(t, settingsService.SetBaseURL(""))
+		assert.NoError(t, emailService.SendRenewalReminder(subscription, 3))
+		assert.NotContains(t, body, "View in SubTrackr")
+	})
+}
+
+func TestEmailService_SendRenewalReminder_ChannelDisabled(t *testing.T) {
+	db := setupRenewalReminderTestDB(t)
+	settingsService := NewSettingsService(repository.NewSettingsRepository(db))
+	settingsService.SetBoolSetting("renewal_reminders", true)
+	settingsService.SetNotificationChannelEnabled(NotificationChannelEmail, false)
+
+	sent := false
+	emailService := NewEmailService(settingsService)
+	emailService.send = func(subject, body string) error {
+		sent = true
+		return nil
+	}
+
+	subscription := &models.Subscription{
+		Name:        "Test Subscription",
+		Cost:        10.00,
+		Schedule:    "Monthly",
+		Status:      "Active",
+		RenewalDate: timePtr(time.Now().AddDate(0, 0, 3)),
+	}
+
+	err := emailService.SendRenewalReminder(subscription, 3)
+	assert.NoError(t, err, "Should return nil when the email channel is disabled")
+	assert.False(t, sent, "Should not send an email when the channel is disabled")
+}
+
 func TestSubscriptionService_GetSubscriptionsNeedingReminders_DaysCalculation(t *testing.T) {
 	db := setupRenewalReminderTestDB(t)
 	subscriptionRepo := repository.NewSubscriptionRepository(db)
@@ -497,7 +557,112 @@ func TestSubscriptionService_GetSubscriptionsNeedingReminders_ReminderDisabled(t
 	assert.Equal(t, 1, len(result), "Should only find subscription with reminders enabled")
 }
 
+func TestSubscriptionService_GetSubscriptionsNeedingReminders_DaysOverride(t *testing.T) {
+	db := setupRenewalReminderTestDB(t)
+	subscriptionRepo := repository.NewSubscriptionRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	categoryService := NewCategoryService(categoryRepo)
+	subscriptionService := NewSubscriptionService(subscriptionRepo, categoryService)
+
+	now := time.Now()
+	intPtr := func(i int) *int { return &i }
+
+	subs := []*models.Subscription{
+		// Uses the 3 day default
+		{Name: "Default In Window", RenewalDate: timePtr(now.AddDate(0, 0, 2))},
+		{Name: "Default Outside Window", RenewalDate: timePtr(now.AddDate(0, 0, 10))},
+		// Longer lead time than the default
+		{Name: "Override In Window", RenewalDate: timePtr(now.AddDate(0, 0, 10)), ReminderDaysOverride: intPtr(14)},
+		{Name: "Override Outside Window", RenewalDate: timePtr(now.AddDate(0, 0, 20)), ReminderDaysOverride: intPtr(14)},
+		// Shorter lead time than the default
+		{Name: "Short Override Outside Window", RenewalDate: timePtr(now.AddDate(0, 0, 2)), ReminderDaysOverride: intPtr(1)},
+	}
+	for _, sub := range subs {
+		sub.Cost = 10.00
+		sub.Schedule = "Monthly"
+		sub.Status = "Active"
+		sub.ReminderEnabled = true
+		assert.NoError(t, db.Create(sub).Error)
+	}
+
+	result, err := subscriptionService.GetSubscriptionsNeedingReminders(3)
+	assert.NoError(t, err)
+
+	found := make(map[string]int)
+	for sub, days := range result {
+		found[sub.Name] = days
+	}
+	assert.Equal(t, map[string]int{
+		"Default In Window":  2,
+		"Override In Window": 10,
+	}, found)
+}
+
+func TestSubscriptionService_GetSubscriptionsNeedingReminders_FirstCharge(t *testing.T) {
+	db := setupRenewalReminderTestDB(t)
+	subscriptionRepo := repository.NewSubscriptionRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	categoryService := NewCategoryService(categoryRepo)
+	subscriptionService := NewSubscriptionService(subscriptionRepo, categoryService)
+
+	now := time.Now()
+	prorated := 4.0
+	subs := []*models.Subscription{
+		// The prorated first charge is due before the renewal leaves the window
+		{Name: "First Charge In Window", RenewalDate: timePtr(now.AddDate(0, 0, 20)), FirstChargeDate: timePtr(now.AddDate(0, 0, 2))},
+		{Name: "First Charge Outside Window", RenewalDate: timePtr(now.AddDate(0, 0, 30)), FirstChargeDate: timePtr(now.AddDate(0, 0, 10))},
+		// Already reminded about the first charge
+		{Name: "First Charge Reminded", RenewalDate: timePtr(now.AddDate(0, 0, 20)), FirstChargeDate: timePtr(now.AddDate(0, 0, 1))},
+	}
+	subs[2].LastReminderRenewalDate = subs[2].FirstChargeDate
+	for _, sub := range subs {
+		sub.Cost = 12.00
+		sub.FirstChargeAmount = &prorated
+		sub.Schedule = "Monthly"
+		sub.Status = "Active"
+		sub.ReminderEnabled = true
+		assert.NoError(t, db.Create(sub).Error)
+	}
+
+	result, err := subscriptionService.GetSubscriptionsNeedingReminders(3)
+	assert.NoError(t, err)
+
+	found := make(map[string]int)
+	for sub, days := range result {
+		found[sub.Name] = days
+	}
+	assert.Equal(t, map[string]int{"First Charge In Window": 2}, found)
+}
+
 // Helper function to create time pointer
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
+
+func TestCalendarDaysUntil(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	// 2025-03-08 20:00 UTC is already the 9th in Tokyo but still the 8th in New York
+	now := time.Date(2025, 3, 8, 20, 0, 0, 0, time.UTC)
+	renewal := time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 1, calendarDaysUntil(now, renewal, time.UTC))
+	assert.Equal(t, 0, calendarDaysUntil(now, renewal, tokyo))
+	assert.Equal(t, 1, calendarDaysUntil(now, renewal, newYork))
+
+	// Counting calendar days is unaffected by the DST change on 2025-03-09 in New York
+	assert.Equal(t, 7, calendarDaysUntil(now, now.AddDate(0, 0, 7), newYork))
+}
+
+func TestSubscriptionService_DaysUntil(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	// Midnight tomorrow is a day away however few hours remain today
+	now := time.Now().In(s.location())
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, s.location())
+	assert.Equal(t, 1, s.DaysUntil(tomorrow))
+	assert.Equal(t, 0, s.DaysUntil(now))
+}
@@ -1,24 +1,34 @@
 package service
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"net/http"
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"time"
 
 	"github.com/gorilla/sessions"
 )
 
 const (
-	SessionName     = "subtrackr_session"
-	SessionUserKey  = "user_authenticated"
-	SessionMaxAge   = 24 * 60 * 60 // 24 hours in seconds
-	RememberMeMaxAge = 30 * 24 * 60 * 60 // 30 days in seconds
+	SessionName    = "subtrackr_session"
+	SessionUserKey = "user_authenticated"
+	SessionMaxAge  = 24 * 60 * 60 // 24 hours in seconds
+	SessionIDKey   = "session_id"
 )
 
+// lastSeenInterval limits how often a session's last-seen time is written
+const lastSeenInterval = time.Minute
+
 type SessionService struct {
-	store *sessions.CookieStore
+	store           *sessions.CookieStore
+	repo            *repository.SessionRepository
+	settingsService *SettingsService
 }
 
 // NewSessionService creates a new session service
-func NewSessionService(secretKey string) *SessionService {
+func NewSessionService(secretKey string, repo *repository.SessionRepository, settingsService *SettingsService) *SessionService {
 	store := sessions.NewCookieStore([]byte(secretKey))
 
 	// Configure session options
@@ -30,37 +40,108 @@ func NewSessionService(secretKey string) *SessionService {
 		SameSite: http.SameSiteStrictMode,
 	}
 
-	return &SessionService{store: store}
+	return &SessionService{store: store, repo: repo, settingsService: settingsService}
 }
 
-// CreateSession creates a new authenticated session
-func (s *SessionService) CreateSession(w http.ResponseWriter, r *http.Request, rememberMe bool) error {
+// CreateSession creates a new authenticated session and records it server-side
+// so it can later be listed and revoked
+func (s *SessionService) CreateSession(w http.ResponseWriter, r *http.Request, rememberMe bool, clientIP string) error {
 	session, err := s.store.Get(r, SessionName)
 	if err != nil {
 		return err
 	}
 
-	session.Values[SessionUserKey] = true
-
 	// Extend session if "remember me" is checked
 	if rememberMe {
-		session.Options.MaxAge = RememberMeMaxAge
+		session.Options.MaxAge = s.settingsService.GetRememberMeDays() * 24 * 60 * 60
 	} else {
 		session.Options.MaxAge = SessionMaxAge
 	}
 
+	id, err := generateSessionID()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	record := &models.Session{
+		ID:        id,
+		UserAgent: r.UserAgent(),
+		IP:        clientIP,
+		LastSeen:  now,
+		ExpiresAt: now.Add(time.Duration(session.Options.MaxAge) * time.Second),
+	}
+	if err := s.repo.Create(record); err != nil {
+		return err
+	}
+
+	// Replace any session this browser held before and clean up expired ones
+	if previousID, ok := session.Values[SessionIDKey].(string); ok {
+		s.repo.Delete(previousID)
+	}
+	s.repo.DeleteExpired(now)
+
+	session.Values[SessionUserKey] = true
+	session.Values[SessionIDKey] = id
+
 	return session.Save(r, w)
 }
 
-// IsAuthenticated checks if the user is authenticated
+// IsAuthenticated checks if the user is authenticated with a session that has not been revoked
 func (s *SessionService) IsAuthenticated(r *http.Request) bool {
-	session, err := s.store.Get(r, SessionName)
+	id := s.CurrentSessionID(r)
+	if id == "" {
+		return false
+	}
+
+	now := time.Now()
+	record, err := s.repo.GetActive(id, now)
 	if err != nil {
 		return false
 	}
 
-	auth, ok := session.Values[SessionUserKey].(bool)
-	return ok && auth
+	if now.Sub(record.LastSeen) > lastSeenInterval {
+		s.repo.UpdateLastSeen(id, now)
+	}
+	return true
+}
+
+// CurrentSessionID returns the ID of the authenticated session on the request, if any
+func (s *SessionService) CurrentSessionID(r *http.Request) string {
+	session, err := s.store.Get(r, SessionName)
+	if err != nil {
+		return ""
+	}
+
+	if auth, ok := session.Values[SessionUserKey].(bool); !ok || !auth {
+		return ""
+	}
+	id, _ := session.Values[SessionIDKey].(string)
+	return id
+}
+
+// ListSessions returns all active sessions, marking the one making the request
+func (s *SessionService) ListSessions(r *http.Request) ([]models.Session, error) {
+	sessionList, err := s.repo.GetAllActive(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	currentID := s.CurrentSessionID(r)
+	for i := range sessionList {
+		sessionList[i].IsCurrent = sessionList[i].ID == currentID
+	}
+	return sessionList, nil
+}
+
+// RevokeSession ends the session with the given ID on whichever device holds it
+func (s *SessionService) RevokeSession(id string) error {
+	return s.repo.Delete(id)
+}
+
+// RevokeAllSessions ends every session, including the current one
+func (s *SessionService) RevokeAllSessions() error {
+	return s.repo.DeleteAllExcept("")
 }
 
 // DestroySession destroys the user session
@@ -70,9 +151,16 @@ func (s *SessionService) DestroySession(w http.ResponseWriter, r *http.Request)
 		return err
 	}
 
+	if id, ok := session.Values[SessionIDKey].(string); ok {
+		if err := s.repo.Delete(id); err != nil {
+			return err
+		}
+	}
+
 	// Mark session as expired
 	session.Options.MaxAge = -1
 	delete(session.Values, SessionUserKey)
+	delete(session.Values, SessionIDKey)
 
 	return session.Save(r, w)
 }
@@ -106,3 +194,12 @@ func (s *SessionService) UpdateSessionExpiry(maxAge int) {
 func (s *SessionService) GetSession(r *http.Request) (*sessions.Session, error) {
 	return s.store.Get(r, SessionName)
 }
+
+// generateSessionID returns a random 32-byte hex session identifier
+func generateSessionID() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupSessionTest(t *testing.T) *SessionService {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Session{}, &models.Settings{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	settingsService := NewSettingsService(repository.NewSettingsRepository(db))
+	return NewSessionService("test-secret-key-for-sessions", repository.NewSessionRepository(db), settingsService)
+}
+
+// login creates a session and returns a request carrying its cookie
+func login(t *testing.T, s *SessionService, userAgent string) *http.Request {
+	req := httptest.NewRequest("POST", "/api/auth/login", nil)
+	req.Header.Set("User-Agent", userAgent)
+	w := httptest.NewRecorder()
+	assert.NoError(t, s.CreateSession(w, req, false, "192.0.2.1"))
+
+	authed := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		authed.AddCookie(cookie)
+	}
+	return authed
+}
+
+func TestSessionService_CreateAndList(t *testing.T) {
+	s := setupSessionTest(t)
+
+	laptop := login(t, s, "Laptop")
+	phone := login(t, s, "Phone")
+
+	assert.True(t, s.IsAuthenticated(laptop))
+	assert.True(t, s.IsAuthenticated(phone))
+	assert.False(t, s.IsAuthenticated(httptest.NewRequest("GET", "/", nil)))
+
+	list, err := s.ListSessions(laptop)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	for _, session := range list {
+		assert.Equal(t, "192.0.2.1", session.IP)
+		assert.Equal(t, session.UserAgent == "Laptop", session.IsCurrent)
+	}
+}
+
+func TestSessionService_RevokeSession(t *testing.T) {
+	s := setupSessionTest(t)
+
+	laptop := login(t, s, "Laptop")
+	phone := login(t, s, "Phone")
+
+	assert.NoError(t, s.RevokeSession(s.CurrentSessionID(phone)))
+
+	assert.False(t, s.IsAuthenticated(phone), "revoked cookie must be rejected")
+	assert.True(t, s.IsAuthenticated(laptop))
+}
+
+func TestSessionService_RevokeAllSessions(t *testing.T) {
+	s := setupSessionTest(t)
+
+	laptop := login(t, s, "Laptop")
+	phone := login(t, s, "Phone")
+
+	assert.NoError(t, s.RevokeAllSessions())
+
+	assert.False(t, s.IsAuthenticated(laptop))
+	assert.False(t, s.IsAuthenticated(phone))
+}
+
+func TestSessionService_DestroySessionRemovesRecord(t *testing.T) {
+	s := setupSessionTest(t)
+
+	laptop := login(t, s, "Laptop")
+	phone := login(t, s, "Phone")
+
+	assert.NoError(t, s.DestroySession(httptest.NewRecorder(), laptop))
+
+	list, err := s.ListSessions(phone)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.True(t, list[0].IsCurrent)
+}
+
+func TestSessionService_RememberMeDuration(t *testing.T) {
+	s := setupSessionTest(t)
+	assert.NoError(t, s.settingsService.SetRememberMeDays(90))
+
+	cookieMaxAge := func(rememberMe bool) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/auth/login", nil)
+		assert.NoError(t, s.CreateSession(w, req, rememberMe, "192.0.2.1"))
+		cookies := w.Result().Cookies()
+		assert.Len(t, cookies, 1)
+		return cookies[0].MaxAge
+	}
+
+	assert.Equal(t, 90*24*60*60, cookieMaxAge(true))
+	assert.Equal(t, SessionMaxAge, cookieMaxAge(false))
+}
@@ -2,24 +2,33 @@ package service
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 	"subtrackr/internal/models"
 	"subtrackr/internal/repository"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 type SettingsService struct {
-	repo *repository.SettingsRepository
+	repo                 *repository.SettingsRepository
+	authDisabledByConfig bool
+	passwordHashCost     int
+	resetMu              sync.Mutex
 }
 
 func NewSettingsService(repo *repository.SettingsRepository) *SettingsService {
-	return &SettingsService{repo: repo}
+	return &SettingsService{repo: repo, passwordHashCost: bcrypt.DefaultCost}
 }
 
 // SaveSMTPConfig saves SMTP configuration
@@ -145,11 +154,20 @@ func (s *SettingsService) GetFloatSettingWithDefault(key string, defaultValue fl
 	return value
 }
 
-// CreateAPIKey creates a new API key
-func (s *SettingsService) CreateAPIKey(name, key string) (*models.APIKey, error) {
+// CreateAPIKey creates a new API key with the given scope ("read" or "write").
+// An empty scope creates a read-write key.
+func (s *SettingsService) CreateAPIKey(name, key, scope string) (*models.APIKey, error) {
+	if scope == "" {
+		scope = models.APIKeyScopeWrite
+	}
+	if scope != models.APIKeyScopeRead && scope != models.APIKeyScopeWrite {
+		return nil, fmt.Errorf("invalid API key scope: %s", scope)
+	}
+
 	apiKey := &models.APIKey{
-		Name: name,
-		Key:  key,
+		Name:  name,
+		Key:   key,
+		Scope: scope,
 	}
 	return s.repo.CreateAPIKey(apiKey)
 }
@@ -172,11 +190,15 @@ func (s *SettingsService) ValidateAPIKey(key string) (*models.APIKey, error) {
 	}
 	
 	// Update usage stats
-	err = s.repo.UpdateAPIKeyUsage(apiKey.ID)
+	now, err := s.repo.UpdateAPIKeyUsage(apiKey.ID)
 	if err != nil {
 		return nil, err
 	}
 	
+	// Reflect the recorded usage in the returned key
+	apiKey.LastUsed = &now
+	apiKey.UsageCount++
+
 	return apiKey, nil
 }
 
@@ -227,6 +249,181 @@ func (s *SettingsService) GetDateFormat() string {
 	return format
 }
 
+// SetWeekStart saves the first day of the week shown in the calendar ("sunday" or "monday")
+func (s *SettingsService) SetWeekStart(day string) error {
+	switch day {
+	case "sunday", "monday":
+		return s.repo.Set("week_start", day)
+	default:
+		return fmt.Errorf("invalid week start: %s", day)
+	}
+}
+
+// GetWeekStart retrieves the first day of the week, defaulting to Sunday
+func (s *SettingsService) GetWeekStart() time.Weekday {
+	day, err := s.repo.Get("week_start")
+	if err != nil || day != "monday" {
+		return time.Sunday
+	}
+	return time.Monday
+}
+
+// SetCostBasis saves how weekly and daily costs are converted to a month
+// ("average" or "calendar") and applies it to cost calculations
+func (s *SettingsService) SetCostBasis(basis string) error {
+	switch basis {
+	case models.CostBasisAverage, models.CostBasisCalendar:
+	default:
+		return fmt.Errorf("invalid cost basis: %s", basis)
+	}
+	if err := s.repo.Set("cost_basis", basis); err != nil {
+		return err
+	}
+	models.SetCostBasis(basis)
+	return nil
+}
+
+// GetCostBasis retrieves the cost basis, defaulting to the average month
+func (s *SettingsService) GetCostBasis() string {
+	basis, err := s.repo.Get("cost_basis")
+	if err != nil || basis != models.CostBasisCalendar {
+		return models.CostBasisAverage
+	}
+	return basis
+}
+
+// Number formats accepted by SetNumberFormat, named by how they render 1234.56
+const (
+	NumberFormatCommaThousands  = "1,234.56"
+	NumberFormatPeriodThousands = "1.234,56"
+)
+
+// Currency symbol placements accepted by SetSymbolPosition; SymbolPositionAuto
+// follows each currency's convention (see DefaultSymbolPosition)
+const (
+	SymbolPositionAuto   = "auto"
+	SymbolPositionBefore = "before"
+	SymbolPositionAfter  = "after"
+)
+
+// SetNumberFormat saves the thousands and decimal separators used for amounts
+func (s *SettingsService) SetNumberFormat(format string) error {
+	switch format {
+	case NumberFormatCommaThousands, NumberFormatPeriodThousands:
+		return s.repo.Set("number_format", format)
+	default:
+		return fmt.Errorf("invalid number format: %s", format)
+	}
+}
+
+// GetNumberFormat retrieves the number format preference
+func (s *SettingsService) GetNumberFormat() string {
+	format, err := s.repo.Get("number_format")
+	if err != nil || format != NumberFormatPeriodThousands {
+		return NumberFormatCommaThousands
+	}
+	return format
+}
+
+// SetSymbolPosition saves whether the currency symbol goes before or after amounts
+func (s *SettingsService) SetSymbolPosition(position string) error {
+	switch position {
+	case SymbolPositionAuto, SymbolPositionBefore, SymbolPositionAfter:
+		return s.repo.Set("symbol_position", position)
+	default:
+		return fmt.Errorf("invalid symbol position: %s", position)
+	}
+}
+
+// GetSymbolPosition retrieves the symbol position preference, defaulting to auto
+func (s *SettingsService) GetSymbolPosition() string {
+	position, err := s.repo.Get("symbol_position")
+	if err != nil || (position != SymbolPositionBefore && position != SymbolPositionAfter) {
+		return SymbolPositionAuto
+	}
+	return position
+}
+
+// SymbolPositionForCurrency resolves where the symbol goes for amounts in currency
+func (s *SettingsService) SymbolPositionForCurrency(currency string) string {
+	if position := s.GetSymbolPosition(); position != SymbolPositionAuto {
+		return position
+	}
+	return DefaultSymbolPosition(currency)
+}
+
+// FormatMoney formats amount in the display currency using the number format and symbol position settings
+func (s *SettingsService) FormatMoney(amount float64) string {
+	return s.FormatMoneyIn(amount, s.GetCurrency())
+}
+
+// FormatMoneyIn is FormatMoney for an amount in the given currency
+func (s *SettingsService) FormatMoneyIn(amount float64, currency string) string {
+	return formatMoney(amount, CurrencySymbolForCode(currency), s.GetNumberFormat(), s.SymbolPositionForCurrency(currency))
+}
+
+// formatMoney renders amount with two decimals, grouped thousands, and the symbol
+// before ("-$1,234.56") or after ("-1.234,56 €") the number
+func formatMoney(amount float64, symbol, format, position string) string {
+	thousands, decimal := ",", "."
+	if format == NumberFormatPeriodThousands {
+		thousands, decimal = ".", ","
+	}
+
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', 2, 64)
+	whole, fraction := digits[:len(digits)-3], digits[len(digits)-2:]
+
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(thousands)
+		}
+		b.WriteRune(r)
+	}
+	number := b.String() + decimal + fraction
+
+	sign := ""
+	if amount < 0 && digits != "0.00" {
+		sign = "-"
+	}
+	if position == SymbolPositionAfter {
+		return sign + number + " " + symbol
+	}
+	return sign + symbol + number
+}
+
+// SetTimezone saves the IANA time zone (e.g. "Asia/Tokyo") dates are calculated
+// and displayed in, and applies it to renewal date calculations
+func (s *SettingsService) SetTimezone(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "" || name == "Local" {
+		return fmt.Errorf("invalid timezone: %s", name)
+	}
+	if err := s.repo.Set("timezone", name); err != nil {
+		return err
+	}
+	models.SetLocation(loc)
+	return nil
+}
+
+// GetTimezone retrieves the configured IANA time zone name, defaulting to UTC
+func (s *SettingsService) GetTimezone() string {
+	name, err := s.repo.Get("timezone")
+	if err != nil || name == "" {
+		return "UTC"
+	}
+	return name
+}
+
+// GetLocation returns the configured time zone, falling back to UTC if it can't be loaded
+func (s *SettingsService) GetLocation() *time.Location {
+	loc, err := time.LoadLocation(s.GetTimezone())
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 // GetGoDateFormat returns the Go time format string for the current date format
 func (s *SettingsService) GetGoDateFormat() string {
 	return DateFormatToGo(s.GetDateFormat())
@@ -271,13 +468,38 @@ func (s *SettingsService) IsDarkModeEnabled() bool {
 	return s.GetBoolSettingWithDefault("dark_mode", false)
 }
 
+// IsTrialSpendIncluded returns whether trials count towards the spending totals
+func (s *SettingsService) IsTrialSpendIncluded() bool {
+	return s.GetBoolSettingWithDefault("include_trials_in_spend", false)
+}
+
+// SetTrialSpendIncluded sets whether trials count towards the spending totals
+func (s *SettingsService) SetTrialSpendIncluded(included bool) error {
+	return s.SetBoolSetting("include_trials_in_spend", included)
+}
+
 // Auth-related methods
 
-// IsAuthEnabled returns whether authentication is enabled
+// IsAuthEnabled returns whether authentication is enabled.
+// Always false when authentication is disabled by configuration.
 func (s *SettingsService) IsAuthEnabled() bool {
+	if s.authDisabledByConfig {
+		return false
+	}
 	return s.GetBoolSettingWithDefault("auth_enabled", false)
 }
 
+// SetAuthDisabledByConfig turns off built-in login for deployments that rely on
+// an external auth proxy, regardless of the stored auth settings
+func (s *SettingsService) SetAuthDisabledByConfig(disabled bool) {
+	s.authDisabledByConfig = disabled
+}
+
+// IsAuthDisabledByConfig reports whether built-in login is turned off by configuration
+func (s *SettingsService) IsAuthDisabledByConfig() bool {
+	return s.authDisabledByConfig
+}
+
 // SetAuthEnabled enables or disables authentication
 func (s *SettingsService) SetAuthEnabled(enabled bool) error {
 	return s.SetBoolSetting("auth_enabled", enabled)
@@ -293,9 +515,28 @@ func (s *SettingsService) SetAuthUsername(username string) error {
 	return s.repo.Set("auth_username", username)
 }
 
+// SetPasswordHashCost sets the bcrypt work factor for new password hashes.
+// Zero selects the bcrypt default; other values are clamped to bcrypt's range.
+func (s *SettingsService) SetPasswordHashCost(cost int) {
+	switch {
+	case cost == 0:
+		cost = bcrypt.DefaultCost
+	case cost < bcrypt.MinCost:
+		cost = bcrypt.MinCost
+	case cost > bcrypt.MaxCost:
+		cost = bcrypt.MaxCost
+	}
+	s.passwordHashCost = cost
+}
+
+// GetPasswordHashCost returns the bcrypt work factor used for new password hashes
+func (s *SettingsService) GetPasswordHashCost() int {
+	return s.passwordHashCost
+}
+
 // HashPassword hashes a password using bcrypt
 func (s *SettingsService) HashPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.passwordHashCost)
 	if err != nil {
 		return "", err
 	}
@@ -308,7 +549,11 @@ func (s *SettingsService) SetAuthPassword(password string) error {
 	if err != nil {
 		return err
 	}
-	return s.repo.Set("auth_password_hash", hash)
+	if err := s.repo.Set("auth_password_hash", hash); err != nil {
+		return err
+	}
+	// Any pending reset link was issued for the old password
+	return s.ClearResetToken()
 }
 
 // ValidatePassword checks if a password matches the stored hash
@@ -320,6 +565,34 @@ func (s *SettingsService) ValidatePassword(password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
 
+// UpgradePasswordHash rehashes password with the configured work factor when the
+// stored hash uses a different one. Call it only once the password has been
+// validated. A pending reset token stays valid across the upgrade.
+func (s *SettingsService) UpgradePasswordHash(password string) error {
+	hash, err := s.repo.Get("auth_password_hash")
+	if err != nil {
+		return fmt.Errorf("no password configured")
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil || cost == s.passwordHashCost {
+		return err
+	}
+
+	newHash, err := s.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	fingerprint, _ := s.repo.Get("auth_reset_token_password")
+	resetPending := fingerprint != "" && fingerprint == s.passwordFingerprint()
+	if err := s.repo.Set("auth_password_hash", newHash); err != nil {
+		return err
+	}
+	if resetPending {
+		return s.repo.Set("auth_reset_token_password", s.passwordFingerprint())
+	}
+	return nil
+}
+
 // GetOrGenerateSessionSecret returns the session secret, generating one if it doesn't exist
 func (s *SettingsService) GetOrGenerateSessionSecret() (string, error) {
 	secret, err := s.repo.Get("auth_session_secret")
@@ -377,6 +650,229 @@ func (s *SettingsService) DisableAuth() error {
 	return nil
 }
 
+// DefaultDateCalculationVersion is the renewal date algorithm stamped on new subscriptions.
+// Version 2 uses Carbon's no-overflow month arithmetic (Jan 31 + 1 month = Feb 28).
+const DefaultDateCalculationVersion = 2
+
+// DefaultHighCostThreshold is the high-cost threshold used until one is configured
+const DefaultHighCostThreshold = 50.0
+
+// GetHighCostThreshold returns the high-cost threshold in the display currency
+func (s *SettingsService) GetHighCostThreshold() float64 {
+	return s.GetFloatSettingWithDefault("high_cost_threshold", DefaultHighCostThreshold)
+}
+
+// GetHighCostBasis returns whether the high-cost threshold applies to monthly or annual cost
+func (s *SettingsService) GetHighCostBasis() string {
+	basis, err := s.repo.Get("high_cost_basis")
+	if err != nil || basis != models.HighCostBasisAnnual {
+		return models.HighCostBasisMonthly
+	}
+	return basis
+}
+
+// SetHighCostBasis saves the cost basis the high-cost threshold is compared against
+func (s *SettingsService) SetHighCostBasis(basis string) error {
+	if basis != models.HighCostBasisMonthly && basis != models.HighCostBasisAnnual {
+		return fmt.Errorf("high cost basis must be %q or %q", models.HighCostBasisMonthly, models.HighCostBasisAnnual)
+	}
+	return s.repo.Set("high_cost_basis", basis)
+}
+
+// highCostAmount returns a label and sub's cost on the configured high-cost basis, for alert messages
+func highCostAmount(sub *models.Subscription, settings *SettingsService) (string, float64) {
+	basis := settings.GetHighCostBasis()
+	if basis == models.HighCostBasisAnnual {
+		return "Annual Cost", sub.CostForBasis(basis)
+	}
+	return "Monthly Cost", sub.CostForBasis(basis)
+}
+
+// Notification channels that can be switched off individually with
+// SetNotificationChannelEnabled
+const (
+	NotificationChannelEmail    = "email"
+	NotificationChannelPushover = "pushover"
+	NotificationChannelWebhook  = "webhook"
+	NotificationChannelApprise  = "apprise"
+)
+
+// IsNotificationChannelEnabled returns whether alerts and reminders are sent
+// through channel. Channels are enabled until switched off, so every configured
+// channel receives them by default.
+func (s *SettingsService) IsNotificationChannelEnabled(channel string) bool {
+	return s.GetBoolSettingWithDefault("notify_"+channel, true)
+}
+
+// SetNotificationChannelEnabled sets whether alerts and reminders are sent through channel
+func (s *SettingsService) SetNotificationChannelEnabled(channel string, enabled bool) error {
+	return s.SetBoolSetting("notify_"+channel, enabled)
+}
+
+// GetQuietHours returns the quiet hours window as "HH:MM" times in the
+// configured time zone. Both are empty when quiet hours are off.
+func (s *SettingsService) GetQuietHours() (start, end string) {
+	start, _ = s.repo.Get("quiet_hours_start")
+	end, _ = s.repo.Get("quiet_hours_end")
+	if _, err := parseClock(start); err != nil {
+		return "", ""
+	}
+	if _, err := parseClock(end); err != nil {
+		return "", ""
+	}
+	return start, end
+}
+
+// SetQuietHours saves the window, as "HH:MM" times, during which push
+// notifications are held back. Empty times turn quiet hours off.
+func (s *SettingsService) SetQuietHours(start, end string) error {
+	if start == "" && end == "" {
+		if err := s.repo.Set("quiet_hours_start", ""); err != nil {
+			return err
+		}
+		return s.repo.Set("quiet_hours_end", "")
+	}
+	startMinute, err := parseClock(start)
+	if err != nil {
+		return fmt.Errorf("invalid quiet hours start: %w", err)
+	}
+	endMinute, err := parseClock(end)
+	if err != nil {
+		return fmt.Errorf("invalid quiet hours end: %w", err)
+	}
+	if startMinute == endMinute {
+		return fmt.Errorf("quiet hours must start and end at different times")
+	}
+	if err := s.repo.Set("quiet_hours_start", start); err != nil {
+		return err
+	}
+	return s.repo.Set("quiet_hours_end", end)
+}
+
+// IsWithinQuietHours returns whether now falls in the quiet hours window. A
+// window whose end is before its start, such as 22:00-07:00, spans midnight.
+func (s *SettingsService) IsWithinQuietHours(now time.Time) bool {
+	start, end := s.GetQuietHours()
+	if start == "" {
+		return false
+	}
+	startMinute, _ := parseClock(start)
+	endMinute, _ := parseClock(end)
+
+	local := now.In(s.GetLocation())
+	minute := local.Hour()*60 + local.Minute()
+	if startMinute < endMinute {
+		return minute >= startMinute && minute < endMinute
+	}
+	return minute >= startMinute || minute < endMinute
+}
+
+// QuietHoursEnd returns when the quiet hours window containing now ends, or
+// now itself when now isn't within quiet hours
+func (s *SettingsService) QuietHoursEnd(now time.Time) time.Time {
+	if !s.IsWithinQuietHours(now) {
+		return now
+	}
+	_, end := s.GetQuietHours()
+	endMinute, _ := parseClock(end)
+
+	local := now.In(s.GetLocation())
+	t := time.Date(local.Year(), local.Month(), local.Day(), endMinute/60, endMinute%60, 0, 0, local.Location())
+	if !t.After(local) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// GetReminderSendHour returns the hour of the day (0-23, in the configured time
+// zone) reminders are sent at. ok is false when no send hour is set.
+func (s *SettingsService) GetReminderSendHour() (hour int, ok bool) {
+	value, err := s.repo.Get("reminder_send_hour")
+	if err != nil || value == "" {
+		return 0, false
+	}
+	hour, err = strconv.Atoi(value)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, false
+	}
+	return hour, true
+}
+
+// SetReminderSendHour saves the hour of the day, "0" to "23", reminders are
+// sent at. An empty hour clears it, so reminders go out daily from startup.
+func (s *SettingsService) SetReminderSendHour(hour string) error {
+	if hour == "" {
+		return s.repo.Set("reminder_send_hour", "")
+	}
+	h, err := strconv.Atoi(hour)
+	if err != nil || h < 0 || h > 23 {
+		return fmt.Errorf("reminder send hour must be a whole number from 0 to 23")
+	}
+	return s.repo.Set("reminder_send_hour", strconv.Itoa(h))
+}
+
+// NextReminderSendTime returns the first time after now at the reminder send
+// hour in the configured time zone. ok is false when no send hour is set.
+func (s *SettingsService) NextReminderSendTime(now time.Time) (next time.Time, ok bool) {
+	hour, ok := s.GetReminderSendHour()
+	if !ok {
+		return time.Time{}, false
+	}
+
+	local := now.In(s.GetLocation())
+	next = time.Date(local.Year(), local.Month(), local.Day(), hour, 0, 0, 0, local.Location())
+	if !next.After(local) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, hour, 0, 0, 0, local.Location())
+	}
+	return next, true
+}
+
+// parseClock parses an "HH:MM" time of day into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// GetDateCalculationVersion returns the date calculation version given to new subscriptions
+func (s *SettingsService) GetDateCalculationVersion() int {
+	version := s.GetIntSettingWithDefault("date_calculation_version", DefaultDateCalculationVersion)
+	if version != 1 && version != 2 {
+		return DefaultDateCalculationVersion
+	}
+	return version
+}
+
+// SetDateCalculationVersion saves the date calculation version given to new subscriptions
+func (s *SettingsService) SetDateCalculationVersion(version int) error {
+	if version != 1 && version != 2 {
+		return fmt.Errorf("date calculation version must be 1 or 2")
+	}
+	return s.SetIntSetting("date_calculation_version", version)
+}
+
+// Bounds for how long a "remember me" login lasts
+const (
+	DefaultRememberMeDays = 30
+	MaxRememberMeDays     = 365
+)
+
+// GetRememberMeDays returns how many days a "remember me" login lasts
+func (s *SettingsService) GetRememberMeDays() int {
+	days := s.GetIntSettingWithDefault("remember_me_days", DefaultRememberMeDays)
+	return min(max(days, 1), MaxRememberMeDays)
+}
+
+// SetRememberMeDays saves how many days a "remember me" login lasts
+func (s *SettingsService) SetRememberMeDays(days int) error {
+	if days < 1 || days > MaxRememberMeDays {
+		return fmt.Errorf("remember me duration must be between 1 and %d days", MaxRememberMeDays)
+	}
+	return s.SetIntSetting("remember_me_days", days)
+}
+
 // GenerateResetToken generates a password reset token
 func (s *SettingsService) GenerateResetToken() (string, error) {
 	bytes := make([]byte, 32)
@@ -395,6 +891,11 @@ func (s *SettingsService) GenerateResetToken() (string, error) {
 		return "", err
 	}
 
+	// Bind the token to the current password so it dies if the password changes
+	if err := s.repo.Set("auth_reset_token_password", s.passwordFingerprint()); err != nil {
+		return "", err
+	}
+
 	return token, nil
 }
 
@@ -415,16 +916,47 @@ func (s *SettingsService) ValidateResetToken(token string) error {
 		return fmt.Errorf("token expired")
 	}
 
+	fingerprint, err := s.repo.Get("auth_reset_token_password")
+	if err != nil || subtle.ConstantTimeCompare([]byte(fingerprint), []byte(s.passwordFingerprint())) != 1 {
+		return fmt.Errorf("invalid token")
+	}
+
 	return nil
 }
 
+// ErrInvalidResetToken is returned when a password reset token is missing, expired or already used
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+// ResetPasswordWithToken stores password if token is a valid reset token. The
+// token is only cleared once the new password has been stored, so a failed
+// reset can be retried with the same link but a successful one cannot be replayed.
+func (s *SettingsService) ResetPasswordWithToken(token, password string) error {
+	s.resetMu.Lock()
+	defer s.resetMu.Unlock()
+
+	if err := s.ValidateResetToken(token); err != nil {
+		return ErrInvalidResetToken
+	}
+	// SetAuthPassword clears the token once the hash is saved
+	return s.SetAuthPassword(password)
+}
+
 // ClearResetToken removes the reset token after use
 func (s *SettingsService) ClearResetToken() error {
 	s.repo.Delete("auth_reset_token")
 	s.repo.Delete("auth_reset_token_expiry")
+	s.repo.Delete("auth_reset_token_password")
 	return nil
 }
 
+// passwordFingerprint returns a digest of the stored password hash, or of the
+// empty string when no password is configured
+func (s *SettingsService) passwordFingerprint() string {
+	hash, _ := s.repo.Get("auth_password_hash")
+	sum := sha256.Sum256([]byte(hash))
+	return hex.EncodeToString(sum[:])
+}
+
 // GetBaseURL returns the configured base URL for external links, or empty string if not set
 func (s *SettingsService) GetBaseURL() string {
 	baseURL, err := s.repo.Get("base_url")
@@ -451,6 +983,16 @@ func (s *SettingsService) SetICalSubscriptionEnabled(enabled bool) error {
 	return s.SetBoolSetting("ical_subscription_enabled", enabled)
 }
 
+// IsLogoCacheEnabled returns whether fetched logos are stored locally instead of hot-linked
+func (s *SettingsService) IsLogoCacheEnabled() bool {
+	return s.GetBoolSettingWithDefault("logo_cache_enabled", false)
+}
+
+// SetLogoCacheEnabled enables or disables local logo caching
+func (s *SettingsService) SetLogoCacheEnabled(enabled bool) error {
+	return s.SetBoolSetting("logo_cache_enabled", enabled)
+}
+
 // GetOrGenerateICalToken returns the iCal token, generating one if it doesn't exist
 func (s *SettingsService) GetOrGenerateICalToken() (string, error) {
 	token, err := s.repo.Get("ical_subscription_token")
@@ -545,3 +1087,26 @@ func (s *SettingsService) GetWebhookConfig() (*models.WebhookConfig, error) {
 	}
 	return &config, nil
 }
+
+// SaveAppriseConfig saves Apprise configuration
+func (s *SettingsService) SaveAppriseConfig(config *models.AppriseConfig) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return s.repo.Set("apprise_config", string(data))
+}
+
+// GetAppriseConfig retrieves Apprise configuration
+func (s *SettingsService) GetAppriseConfig() (*models.AppriseConfig, error) {
+	data, err := s.repo.Get("apprise_config")
+	if err != nil {
+		return nil, err
+	}
+	var config models.AppriseConfig
+	err = json.Unmarshal([]byte(data), &config)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
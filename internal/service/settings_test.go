@@ -1,11 +1,14 @@
 package service
 
 import (
+	"strings"
 	"subtrackr/internal/models"
 	"subtrackr/internal/repository"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -15,7 +18,7 @@ func setupSettingsTestDB(t *testing.T) *SettingsService {
 	if err != nil {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
-	err = db.AutoMigrate(&models.Settings{})
+	err = db.AutoMigrate(&models.Settings{}, &models.APIKey{})
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -165,3 +168,438 @@ func TestWebhookConfig_NotConfigured(t *testing.T) {
 	_, err := s.GetWebhookConfig()
 	assert.Error(t, err, "Should error when webhook not configured")
 }
+
+func TestNotificationChannelEnabled(t *testing.T) {
+	s := setupSettingsTestDB(t)
+
+	assert.True(t, s.IsNotificationChannelEnabled(NotificationChannelPushover), "Channels should be enabled by default")
+
+	assert.NoError(t, s.SetNotificationChannelEnabled(NotificationChannelPushover, false))
+	assert.False(t, s.IsNotificationChannelEnabled(NotificationChannelPushover))
+	assert.True(t, s.IsNotificationChannelEnabled(NotificationChannelEmail), "Other channels should be unaffected")
+}
+
+func TestValidateAPIKey_TracksUsage(t *testing.T) {
+	s := setupSettingsTestDB(t)
+
+	created, err := s.CreateAPIKey("Script", "sk_test", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, created.UsageCount)
+	assert.Nil(t, created.LastUsed)
+
+	_, err = s.ValidateAPIKey("sk_test")
+	assert.NoError(t, err)
+	validated, err := s.ValidateAPIKey("sk_test")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, validated.UsageCount)
+	assert.NotNil(t, validated.LastUsed)
+
+	keys, err := s.GetAllAPIKeys()
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, 2, keys[0].UsageCount)
+	assert.NotNil(t, keys[0].LastUsed)
+	assert.False(t, keys[0].IsStale())
+}
+
+func TestValidateAPIKey_Unknown(t *testing.T) {
+	s := setupSettingsTestDB(t)
+
+	_, err := s.ValidateAPIKey("sk_missing")
+	assert.Error(t, err)
+}
+
+func TestRememberMeDays(t *testing.T) {
+	s := setupSettingsTestDB(t)
+
+	assert.Equal(t, DefaultRememberMeDays, s.GetRememberMeDays())
+
+	assert.NoError(t, s.SetRememberMeDays(7))
+	assert.Equal(t, 7, s.GetRememberMeDays())
+
+	assert.Error(t, s.SetRememberMeDays(0))
+	assert.Error(t, s.SetRememberMeDays(MaxRememberMeDays+1))
+
+	// Out-of-range stored values are clamped
+	assert.NoError(t, s.SetIntSetting("remember_me_days", 10000))
+	assert.Equal(t, MaxRememberMeDays, s.GetRememberMeDays())
+}
+
+func TestResetToken_SingleUse(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	assert.NoError(t, s.SetAuthPassword("original-password"))
+
+	token, err := s.GenerateResetToken()
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.ResetPasswordWithToken(token, "new-password"))
+	assert.NoError(t, s.ValidatePassword("new-password"))
+
+	// Replaying the same token must fail
+	assert.ErrorIs(t, s.ResetPasswordWithToken(token, "another-password"), ErrInvalidResetToken)
+	assert.Error(t, s.ValidateResetToken(token))
+}
+
+func TestResetToken_KeptWhenPasswordNotStored(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	assert.NoError(t, s.SetAuthPassword("original-password"))
+
+	token, err := s.GenerateResetToken()
+	assert.NoError(t, err)
+
+	// bcrypt rejects passwords longer than 72 bytes, so nothing is stored
+	assert.Error(t, s.ResetPasswordWithToken(token, strings.Repeat("x", 73)))
+	assert.NoError(t, s.ValidatePassword("original-password"))
+	assert.NoError(t, s.ValidateResetToken(token), "A failed reset must not use up the token")
+
+	assert.NoError(t, s.ResetPasswordWithToken(token, "new-password"))
+	assert.NoError(t, s.ValidatePassword("new-password"))
+}
+
+func TestResetToken_InvalidatedByPasswordChange(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	assert.NoError(t, s.SetAuthPassword("original-password"))
+
+	token, err := s.GenerateResetToken()
+	assert.NoError(t, err)
+	assert.NoError(t, s.ValidateResetToken(token))
+
+	assert.NoError(t, s.SetAuthPassword("changed-password"))
+	assert.Error(t, s.ValidateResetToken(token))
+}
+
+func TestResetToken_RejectedWhenPasswordHashChanges(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	assert.NoError(t, s.SetAuthPassword("original-password"))
+
+	token, err := s.GenerateResetToken()
+	assert.NoError(t, err)
+
+	// Simulate the hash changing without going through SetAuthPassword
+	assert.NoError(t, s.repo.Set("auth_password_hash", "$2a$10$differenthash"))
+	assert.Error(t, s.ValidateResetToken(token))
+}
+
+func TestPasswordHash_NotPlaintext(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	assert.NoError(t, s.SetAuthPassword("correct-horse"))
+
+	hash, err := s.repo.Get("auth_password_hash")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "correct-horse", hash)
+	assert.NotContains(t, hash, "correct-horse")
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	assert.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+
+	assert.NoError(t, s.ValidatePassword("correct-horse"))
+	assert.Error(t, s.ValidatePassword("wrong-horse"))
+}
+
+func TestPasswordHash_UpgradedOnLogin(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	s.SetPasswordHashCost(bcrypt.MinCost)
+	assert.NoError(t, s.SetAuthPassword("correct-horse"))
+	oldHash, _ := s.repo.Get("auth_password_hash")
+
+	s.SetPasswordHashCost(bcrypt.MinCost + 1)
+
+	// Validating never touches the stored hash
+	assert.Error(t, s.ValidatePassword("wrong-horse"))
+	assert.NoError(t, s.ValidatePassword("correct-horse"))
+	unchanged, _ := s.repo.Get("auth_password_hash")
+	assert.Equal(t, oldHash, unchanged)
+
+	assert.NoError(t, s.UpgradePasswordHash("correct-horse"))
+	newHash, _ := s.repo.Get("auth_password_hash")
+	assert.NotEqual(t, oldHash, newHash)
+	cost, err := bcrypt.Cost([]byte(newHash))
+	assert.NoError(t, err)
+	assert.Equal(t, bcrypt.MinCost+1, cost)
+
+	// The upgraded hash still verifies
+	assert.NoError(t, s.ValidatePassword("correct-horse"))
+	assert.Error(t, s.ValidatePassword("wrong-horse"))
+
+	// Nothing to do once the work factor matches
+	assert.NoError(t, s.UpgradePasswordHash("correct-horse"))
+	same, _ := s.repo.Get("auth_password_hash")
+	assert.Equal(t, newHash, same)
+}
+
+func TestPasswordHash_UpgradeKeepsResetToken(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	s.SetPasswordHashCost(bcrypt.MinCost)
+	assert.NoError(t, s.SetAuthPassword("correct-horse"))
+	token, err := s.GenerateResetToken()
+	assert.NoError(t, err)
+
+	s.SetPasswordHashCost(bcrypt.MinCost + 1)
+	assert.NoError(t, s.UpgradePasswordHash("correct-horse"))
+
+	assert.NoError(t, s.ValidateResetToken(token), "Rehashing the same password shouldn't invalidate a pending reset")
+}
+
+func TestSetPasswordHashCost_Bounds(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	assert.Equal(t, bcrypt.DefaultCost, s.GetPasswordHashCost())
+
+	s.SetPasswordHashCost(1)
+	assert.Equal(t, bcrypt.MinCost, s.GetPasswordHashCost())
+	s.SetPasswordHashCost(99)
+	assert.Equal(t, bcrypt.MaxCost, s.GetPasswordHashCost())
+	s.SetPasswordHashCost(0)
+	assert.Equal(t, bcrypt.DefaultCost, s.GetPasswordHashCost())
+}
+
+func TestSetTimezone(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	t.Cleanup(func() { models.SetLocation(time.UTC) })
+
+	assert.Equal(t, "UTC", s.GetTimezone(), "Default timezone should be UTC")
+
+	assert.NoError(t, s.SetTimezone("America/New_York"))
+	assert.Equal(t, "America/New_York", s.GetTimezone())
+	assert.Equal(t, "America/New_York", s.GetLocation().String())
+	assert.Equal(t, "America/New_York", models.Location().String(), "Renewal calculations should follow the setting")
+
+	for _, name := range []string{"", "Local", "Mars/Olympus_Mons"} {
+		assert.Error(t, s.SetTimezone(name), name)
+	}
+	assert.Equal(t, "America/New_York", s.GetTimezone(), "Invalid timezones should not be stored")
+}
+
+func TestHighCostBasis(t *testing.T) {
+	s := setupSettingsTestDB(t)
+
+	assert.Equal(t, models.HighCostBasisMonthly, s.GetHighCostBasis(), "Default basis should be monthly")
+	assert.Equal(t, DefaultHighCostThreshold, s.GetHighCostThreshold())
+
+	assert.NoError(t, s.SetHighCostBasis(models.HighCostBasisAnnual))
+	assert.Equal(t, models.HighCostBasisAnnual, s.GetHighCostBasis())
+
+	assert.Error(t, s.SetHighCostBasis("weekly"))
+	assert.Equal(t, models.HighCostBasisAnnual, s.GetHighCostBasis())
+
+	sub := &models.Subscription{Name: "Adobe", Cost: 55, Schedule: "Monthly"}
+	label, amount := highCostAmount(sub, s)
+	assert.Equal(t, "Annual Cost", label)
+	assert.InDelta(t, 660, amount, 0.001)
+}
+
+func TestFormatMoney(t *testing.T) {
+	s := setupSettingsTestDB(t)
+
+	tests := []struct {
+		name     string
+		format   string
+		position string
+		amount   float64
+		expected string
+	}{
+		{"Comma thousands prefix", NumberFormatCommaThousands, SymbolPositionBefore, 1200, "$1,200.00"},
+		{"Comma thousands millions", NumberFormatCommaThousands, SymbolPositionBefore, 1234567.891, "$1,234,567.89"},
+		{"Small amount", NumberFormatCommaThousands, SymbolPositionBefore, 9.5, "$9.50"},
+		{"Negative prefix", NumberFormatCommaThousands, SymbolPositionBefore, -1234.5, "-$1,234.50"},
+		{"Negative rounding to zero", NumberFormatCommaThousands, SymbolPositionBefore, -0.001, "$0.00"},
+		{"Period thousands suffix", NumberFormatPeriodThousands, SymbolPositionAfter, 1234.56, "1.234,56 $"},
+		{"Period thousands prefix", NumberFormatPeriodThousands, SymbolPositionBefore, 999999.99, "$999.999,99"},
+		{"Negative suffix", NumberFormatPeriodThousands, SymbolPositionAfter, -50, "-50,00 $"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, s.SetNumberFormat(tt.format))
+			assert.NoError(t, s.SetSymbolPosition(tt.position))
+			assert.Equal(t, tt.expected, s.FormatMoney(tt.amount))
+		})
+	}
+
+	assert.Equal(t, "-50,00 €", s.FormatMoneyIn(-50, "EUR"))
+}
+
+func TestNumberFormat_DefaultsAndValidation(t *testing.T) {
+	s := setupSettingsTestDB(t)
+
+	assert.Equal(t, NumberFormatCommaThousands, s.GetNumberFormat())
+	assert.Equal(t, SymbolPositionAuto, s.GetSymbolPosition())
+	assert.Equal(t, "$1,234.56", s.FormatMoney(1234.56))
+
+	assert.Error(t, s.SetNumberFormat("1 234,56"))
+	assert.Error(t, s.SetSymbolPosition("middle"))
+}
+
+func TestSymbolPosition(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	assert.NoError(t, s.SetNumberFormat(NumberFormatPeriodThousands))
+
+	// Automatic placement follows each currency's convention
+	for code, expected := range map[string]string{
+		"EUR": "9,99 €",
+		"PLN": "9,99 zł",
+		"SEK": "9,99 kr",
+		"USD": "$9,99",
+		"GBP": "£9,99",
+		"JPY": "¥9,99",
+	} {
+		assert.Equal(t, expected, s.FormatMoneyIn(9.99, code), code)
+	}
+
+	assert.NoError(t, s.SetSymbolPosition(SymbolPositionBefore))
+	assert.Equal(t, "€9,99", s.FormatMoneyIn(9.99, "EUR"))
+	assert.Equal(t, "$9,99", s.FormatMoneyIn(9.99, "USD"))
+
+	assert.NoError(t, s.SetSymbolPosition(SymbolPositionAfter))
+	assert.Equal(t, "9,99 €", s.FormatMoneyIn(9.99, "EUR"))
+	assert.Equal(t, "9,99 $", s.FormatMoneyIn(9.99, "USD"))
+
+	assert.NoError(t, s.SetSymbolPosition(SymbolPositionAuto))
+	assert.NoError(t, s.SetCurrency("EUR"))
+	assert.Equal(t, "1.234,56 €", s.FormatMoney(1234.56))
+}
+
+func TestWeekStart(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	assert.Equal(t, time.Sunday, s.GetWeekStart())
+
+	assert.NoError(t, s.SetWeekStart("monday"))
+	assert.Equal(t, time.Monday, s.GetWeekStart())
+
+	assert.Error(t, s.SetWeekStart("friday"))
+	assert.Equal(t, time.Monday, s.GetWeekStart())
+
+	assert.NoError(t, s.SetWeekStart("sunday"))
+	assert.Equal(t, time.Sunday, s.GetWeekStart())
+}
+
+func TestSetCostBasis(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	t.Cleanup(func() { models.SetCostBasis(models.CostBasisAverage) })
+
+	assert.Equal(t, models.CostBasisAverage, s.GetCostBasis())
+
+	assert.NoError(t, s.SetCostBasis(models.CostBasisCalendar))
+	assert.Equal(t, models.CostBasisCalendar, s.GetCostBasis())
+	assert.Equal(t, models.CostBasisCalendar, models.CostBasis())
+
+	err := s.SetCostBasis("fortnightly")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid cost basis")
+	assert.Equal(t, models.CostBasisCalendar, s.GetCostBasis())
+}
+
+func TestIsWithinQuietHours(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 3, 10, hour, minute, 0, 0, time.UTC)
+	}
+
+	assert.False(t, s.IsWithinQuietHours(at(3, 0)), "Quiet hours should be off by default")
+
+	t.Run("Window spanning midnight", func(t *testing.T) {
+		assert.NoError(t, s.SetQuietHours("22:00", "07:00"))
+		assert.False(t, s.IsWithinQuietHours(at(21, 59)))
+		assert.True(t, s.IsWithinQuietHours(at(22, 0)))
+		assert.True(t, s.IsWithinQuietHours(at(23, 59)))
+		assert.True(t, s.IsWithinQuietHours(at(0, 0)))
+		assert.True(t, s.IsWithinQuietHours(at(3, 0)))
+		assert.True(t, s.IsWithinQuietHours(at(6, 59)))
+		assert.False(t, s.IsWithinQuietHours(at(7, 0)))
+		assert.False(t, s.IsWithinQuietHours(at(12, 0)))
+	})
+
+	t.Run("Window within one day", func(t *testing.T) {
+		assert.NoError(t, s.SetQuietHours("13:00", "14:30"))
+		assert.False(t, s.IsWithinQuietHours(at(12, 59)))
+		assert.True(t, s.IsWithinQuietHours(at(13, 0)))
+		assert.True(t, s.IsWithinQuietHours(at(14, 29)))
+		assert.False(t, s.IsWithinQuietHours(at(14, 30)))
+		assert.False(t, s.IsWithinQuietHours(at(3, 0)))
+	})
+
+	t.Run("Uses the configured timezone", func(t *testing.T) {
+		assert.NoError(t, s.SetQuietHours("22:00", "07:00"))
+		assert.NoError(t, s.SetTimezone("Asia/Tokyo"))
+		defer s.SetTimezone("UTC")
+
+		// 14:00 UTC is 23:00 in Tokyo, 23:00 UTC is 08:00 the next day
+		assert.True(t, s.IsWithinQuietHours(at(14, 0)))
+		assert.False(t, s.IsWithinQuietHours(at(23, 0)))
+	})
+
+	t.Run("Turned off", func(t *testing.T) {
+		assert.NoError(t, s.SetQuietHours("", ""))
+		assert.False(t, s.IsWithinQuietHours(at(3, 0)))
+	})
+}
+
+func TestQuietHoursEnd(t *testing.T) {
+	s := setupSettingsTestDB(t)
+	assert.NoError(t, s.SetQuietHours("22:00", "07:00"))
+
+	lateEvening := time.Date(2025, 3, 10, 23, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 11, 7, 0, 0, 0, time.UTC), s.QuietHoursEnd(lateEvening).UTC(), "Should end the next morning")
+
+	earlyMorning := time.Date(2025, 3, 11, 2, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 11, 7, 0, 0, 0, time.UTC), s.QuietHoursEnd(earlyMorning).UTC(), "Should end the same morning")
+
+	noon := time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, noon, s.QuietHoursEnd(noon), "Should return now outside quiet hours")
+}
+
+func TestNextReminderSendTime(t *testing.T) {
+	s := setupSettingsTestDB(t)
+
+	_, ok := s.NextReminderSendTime(time.Now())
+	assert.False(t, ok, "No send hour should be set by default")
+
+	assert.NoError(t, s.SetReminderSendHour("8"))
+
+	earlyMorning := time.Date(2025, 3, 10, 6, 30, 0, 0, time.UTC)
+	next, ok := s.NextReminderSendTime(earlyMorning)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC), next.UTC(), "Should send later the same day")
+
+	onTheHour := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	next, _ = s.NextReminderSendTime(onTheHour)
+	assert.Equal(t, time.Date(2025, 3, 11, 8, 0, 0, 0, time.UTC), next.UTC(), "Should send the next day once the hour has come")
+
+	lastDayOfMonth := time.Date(2025, 3, 31, 23, 30, 0, 0, time.UTC)
+	next, _ = s.NextReminderSendTime(lastDayOfMonth)
+	assert.Equal(t, time.Date(2025, 4, 1, 8, 0, 0, 0, time.UTC), next.UTC(), "Should roll over into the next month")
+
+	t.Run("Uses the configured timezone", func(t *testing.T) {
+		assert.NoError(t, s.SetTimezone("Asia/Tokyo"))
+		defer s.SetTimezone("UTC")
+
+		// 22:00 UTC is 07:00 the next day in Tokyo, so 08:00 Tokyo is an hour away
+		next, _ := s.NextReminderSendTime(time.Date(2025, 3, 10, 22, 0, 0, 0, time.UTC))
+		assert.Equal(t, time.Date(2025, 3, 10, 23, 0, 0, 0, time.UTC), next.UTC())
+	})
+
+	t.Run("Cleared or invalid", func(t *testing.T) {
+		assert.Error(t, s.SetReminderSendHour("24"))
+		assert.Error(t, s.SetReminderSendHour("morning"))
+		hour, ok := s.GetReminderSendHour()
+		assert.True(t, ok)
+		assert.Equal(t, 8, hour, "An invalid hour shouldn't replace the saved one")
+
+		assert.NoError(t, s.SetReminderSendHour(""))
+		_, ok = s.NextReminderSendTime(time.Now())
+		assert.False(t, ok)
+	})
+}
+
+func TestSetQuietHours_Invalid(t *testing.T) {
+	s := setupSettingsTestDB(t)
+
+	assert.Error(t, s.SetQuietHours("22:00", ""))
+	assert.Error(t, s.SetQuietHours("25:00", "07:00"))
+	assert.Error(t, s.SetQuietHours("10pm", "07:00"))
+	assert.Error(t, s.SetQuietHours("07:00", "07:00"))
+
+	start, end := s.GetQuietHours()
+	assert.Empty(t, start)
+	assert.Empty(t, end)
+}
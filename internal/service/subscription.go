@@ -1,21 +1,38 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
 	"subtrackr/internal/models"
 	"subtrackr/internal/repository"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type SubscriptionService struct {
 	repo            *repository.SubscriptionRepository
 	categoryService *CategoryService
+	settingsService *SettingsService
 }
 
 func NewSubscriptionService(repo *repository.SubscriptionRepository, categoryService *CategoryService) *SubscriptionService {
 	return &SubscriptionService{repo: repo, categoryService: categoryService}
 }
 
+// SetSettingsService provides the settings used to stamp new subscriptions
+// with the configured date calculation version and to decide whether trials
+// count towards the spending totals
+func (s *SubscriptionService) SetSettingsService(settingsService *SettingsService) {
+	s.settingsService = settingsService
+}
+
 func (s *SubscriptionService) Create(subscription *models.Subscription) (*models.Subscription, error) {
+	if subscription.DateCalculationVersion == 0 && s.settingsService != nil {
+		subscription.DateCalculationVersion = s.settingsService.GetDateCalculationVersion()
+	}
 	return s.repo.Create(subscription)
 }
 
@@ -23,14 +40,129 @@ func (s *SubscriptionService) GetAll() ([]models.Subscription, error) {
 	return s.repo.GetAll()
 }
 
+// SortByMonthlyCost sorts by the monthly-equivalent cost. It is derived from
+// the cost and schedule rather than stored, so it is sorted in Go.
+const SortByMonthlyCost = "monthly_cost"
+
+// ErrInvalidSort is returned for a sort column or order that is not supported
+var ErrInvalidSort = errors.New("invalid sort parameter")
+
+// ValidateSort checks sortBy against the sortable columns and order against asc/desc
+func ValidateSort(sortBy, order string) error {
+	if sortBy != SortByMonthlyCost && !repository.IsSortableColumn(sortBy) {
+		return fmt.Errorf("%w: unknown sort column %q", ErrInvalidSort, sortBy)
+	}
+	if !repository.IsSortOrder(order) {
+		return fmt.Errorf("%w: order must be asc or desc", ErrInvalidSort)
+	}
+	return nil
+}
+
 func (s *SubscriptionService) GetAllSorted(sortBy, order string) ([]models.Subscription, error) {
-	return s.repo.GetAllSorted(sortBy, order)
+	if sortBy != SortByMonthlyCost {
+		return s.repo.GetAllSorted(sortBy, order)
+	}
+	subscriptions, err := s.repo.GetAllSorted("created_at", "desc")
+	if err != nil {
+		return nil, err
+	}
+	sortByMonthlyCost(subscriptions, order)
+	return subscriptions, nil
+}
+
+// GetPaged returns one page of sorted subscriptions and the total count
+func (s *SubscriptionService) GetPaged(sortBy, order string, limit, offset int) ([]models.Subscription, int64, error) {
+	if sortBy != SortByMonthlyCost {
+		return s.repo.GetPaged(sortBy, order, limit, offset)
+	}
+	subscriptions, err := s.GetAllSorted(sortBy, order)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := int64(len(subscriptions))
+	subscriptions = subscriptions[min(max(offset, 0), len(subscriptions)):]
+	if limit > 0 && limit < len(subscriptions) {
+		subscriptions = subscriptions[:limit]
+	}
+	return subscriptions, total, nil
+}
+
+// Search returns subscriptions matching the given filter
+func (s *SubscriptionService) Search(filter models.SubscriptionFilter, sortBy, order string) ([]models.Subscription, error) {
+	if sortBy != SortByMonthlyCost {
+		return s.repo.Search(filter, sortBy, order)
+	}
+	subscriptions, err := s.repo.Search(filter, "created_at", "desc")
+	if err != nil {
+		return nil, err
+	}
+	sortByMonthlyCost(subscriptions, order)
+	return subscriptions, nil
+}
+
+// sortByMonthlyCost orders subscriptions by MonthlyCost, most expensive first
+// unless order is "asc". Ties keep their existing order.
+func sortByMonthlyCost(subscriptions []models.Subscription, order string) {
+	sort.SliceStable(subscriptions, func(i, j int) bool {
+		if order == "asc" {
+			return subscriptions[i].MonthlyCost() < subscriptions[j].MonthlyCost()
+		}
+		return subscriptions[i].MonthlyCost() > subscriptions[j].MonthlyCost()
+	})
 }
 
 func (s *SubscriptionService) GetByID(id uint) (*models.Subscription, error) {
 	return s.repo.GetByID(id)
 }
 
+// Duplicate creates a copy of the subscription with the given ID, named with a
+// " (copy)" suffix. The copy gets its own renewal date and reminder history;
+// the one-off next and prorated first charges stay with the original.
+func (s *SubscriptionService) Duplicate(id uint) (*models.Subscription, error) {
+	original, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicate := *original
+	duplicate.ID = 0
+	duplicate.Name = original.Name + " (copy)"
+	duplicate.Category = models.Category{}
+	duplicate.NextChargeAmount = nil
+	duplicate.FirstChargeAmount = nil
+	duplicate.FirstChargeDate = nil
+	duplicate.LastReminderSent = nil
+	duplicate.LastReminderRenewalDate = nil
+	duplicate.LastCancellationReminderSent = nil
+	duplicate.LastCancellationReminderDate = nil
+	duplicate.CreatedAt = time.Time{}
+	duplicate.UpdatedAt = time.Time{}
+	duplicate.StartDate = copyTimePtr(original.StartDate)
+	duplicate.CancellationDate = copyTimePtr(original.CancellationDate)
+	// Left empty so it is recalculated from the start date and schedule on create
+	duplicate.RenewalDate = nil
+	// Uploaded logos belong to a single subscription and are deleted with it, so
+	// the copy falls back to its website's favicon instead of sharing the file
+	if _, uploaded := uploadedLogoFilename(original.IconURL); uploaded {
+		duplicate.IconURL = ""
+	}
+
+	created, err := s.Create(&duplicate)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(created.ID)
+}
+
+// copyTimePtr returns a pointer to a copy of t, so the copy can be changed independently
+func copyTimePtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
 func (s *SubscriptionService) Update(id uint, subscription *models.Subscription) (*models.Subscription, error) {
 	return s.repo.Update(id, subscription)
 }
@@ -39,27 +171,144 @@ func (s *SubscriptionService) Delete(id uint) error {
 	return s.repo.Delete(id)
 }
 
+// Transaction runs fn with a service whose reads and writes share one database transaction
+func (s *SubscriptionService) Transaction(fn func(txService *SubscriptionService) error) error {
+	return s.repo.Transaction(func(txRepo *repository.SubscriptionRepository) error {
+		return fn(&SubscriptionService{repo: txRepo, categoryService: s.categoryService, settingsService: s.settingsService})
+	})
+}
+
+// FindOrCreateCategory returns the category named name, creating it if it
+// doesn't exist yet. Inside Transaction a new category is rolled back with the
+// rest of the transaction.
+func (s *SubscriptionService) FindOrCreateCategory(name string) (*models.Category, error) {
+	categories := NewCategoryService(s.repo.Categories())
+	category, err := categories.GetByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		category, err = categories.Create(&models.Category{Name: name})
+	}
+	return category, err
+}
+
+// Recategorize moves the given subscriptions to categoryID in one transaction and
+// returns how many were updated. Unknown subscription IDs are ignored.
+func (s *SubscriptionService) Recategorize(ids []uint, categoryID uint) (int64, error) {
+	var updated int64
+	err := s.repo.Transaction(func(txRepo *repository.SubscriptionRepository) error {
+		n, err := txRepo.SetCategory(ids, categoryID)
+		updated = n
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+// DeleteAll removes every subscription in one transaction and returns how many were deleted
+func (s *SubscriptionService) DeleteAll() (int64, error) {
+	var deleted int64
+	err := s.repo.Transaction(func(txRepo *repository.SubscriptionRepository) error {
+		n, err := txRepo.DeleteAll()
+		deleted = n
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// GetStaleRenewals returns active subscriptions whose renewal date is before
+// today, which means renewals stopped being rolled forward for them
+func (s *SubscriptionService) GetStaleRenewals() ([]models.Subscription, error) {
+	return s.repo.GetStaleRenewals(startOfToday())
+}
+
+// startOfToday returns midnight at the start of today in the renewal time zone
+func startOfToday() time.Time {
+	now := time.Now().In(models.Location())
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// RecalculateRenewals recomputes the renewal date of every active subscription
+// in one transaction and returns how many changed. Locked renewal dates are kept.
+func (s *SubscriptionService) RecalculateRenewals() (int, error) {
+	changed := 0
+	err := s.repo.Transaction(func(txRepo *repository.SubscriptionRepository) error {
+		subscriptions, err := txRepo.GetActiveSubscriptions()
+		if err != nil {
+			return err
+		}
+		for i := range subscriptions {
+			if !subscriptions[i].RecalculateRenewalDate() {
+				continue
+			}
+			if err := txRepo.SetRenewalDate(subscriptions[i].ID, subscriptions[i].RenewalDate); err != nil {
+				return err
+			}
+			changed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
 func (s *SubscriptionService) Count() int64 {
 	return s.repo.Count()
 }
 
 func (s *SubscriptionService) GetStats() (*models.Stats, error) {
-	activeSubscriptions, err := s.repo.GetActiveSubscriptions()
+	return s.GetStatsFiltered(models.StatsFilter{})
+}
+
+// GetStatsFiltered computes the same statistics as GetStats over only the
+// subscriptions matching filter. An empty scope yields zero totals.
+func (s *SubscriptionService) GetStatsFiltered(filter models.StatsFilter) (*models.Stats, error) {
+	repo := s.repo.Scoped(filter)
+
+	// Counted first, since loading the subscriptions rolls passed renewal dates forward
+	staleRenewals, err := repo.GetStaleRenewals(startOfToday())
+	if err != nil {
+		return nil, err
+	}
+
+	activeSubscriptions, err := repo.GetActiveSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+
+	cancelledSubscriptions, err := repo.GetCancelledSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+
+	pausedSubscriptions, err := repo.GetPausedSubscriptions()
 	if err != nil {
 		return nil, err
 	}
 
-	cancelledSubscriptions, err := s.repo.GetCancelledSubscriptions()
+	trialSubscriptions, err := repo.GetTrialSubscriptions()
 	if err != nil {
 		return nil, err
 	}
 
-	upcomingRenewals, err := s.repo.GetUpcomingRenewals(7)
+	// Archived subscriptions are only counted; unlike cancelled ones they don't count as savings
+	archivedSubscriptions, err := repo.GetArchivedSubscriptions()
 	if err != nil {
 		return nil, err
 	}
 
-	categoryStats, err := s.repo.GetCategoryStats()
+	upcomingRenewals, err := repo.GetUpcomingRenewals(7)
+	if err != nil {
+		return nil, err
+	}
+
+	includeTrials := s.isTrialSpendIncluded()
+	categoryStats, err := repo.GetCategoryStats(spendStatuses(includeTrials))
 	if err != nil {
 		return nil, err
 	}
@@ -67,16 +316,47 @@ func (s *SubscriptionService) GetStats() (*models.Stats, error) {
 	stats := &models.Stats{
 		ActiveSubscriptions:    len(activeSubscriptions),
 		CancelledSubscriptions: len(cancelledSubscriptions),
+		PausedSubscriptions:    len(pausedSubscriptions),
+		TrialSubscriptions:     len(trialSubscriptions),
+		ArchivedSubscriptions:  len(archivedSubscriptions),
 		UpcomingRenewals:       len(upcomingRenewals),
+		StaleRenewals:          len(staleRenewals),
 		CategorySpending:       make(map[string]float64),
 	}
 
+	// Trials count as spend when configured, as if they had already converted
+	spending := activeSubscriptions
+	if includeTrials {
+		spending = append(spending[:len(spending):len(spending)], trialSubscriptions...)
+	}
+
 	// Calculate totals
-	for _, sub := range activeSubscriptions {
+	for _, sub := range spending {
 		stats.TotalMonthlySpend += sub.MonthlyCost()
 		stats.TotalAnnualSpend += sub.AnnualCost()
+		if sub.IsLowUsage() {
+			stats.PotentialSavings += sub.MonthlyCost()
+		}
+		if stats.MostExpensive == nil || sub.MonthlyCost() > stats.MostExpensive.MonthlyCost {
+			stats.MostExpensive = &models.SubscriptionRef{ID: sub.ID, Name: sub.Name, MonthlyCost: sub.MonthlyCost()}
+		}
 	}
 
+	// Assume every trial converts at its regular cost
+	stats.ProjectedAnnualSpendIncludingTrials = stats.TotalAnnualSpend
+	if !includeTrials {
+		for _, sub := range trialSubscriptions {
+			stats.ProjectedAnnualSpendIncludingTrials += sub.AnnualCost()
+		}
+	}
+
+	if len(spending) > 0 {
+		stats.AverageMonthlyCost = stats.TotalMonthlySpend / float64(len(spending))
+		stats.AverageAnnualCost = stats.TotalAnnualSpend / float64(len(spending))
+	}
+
+	stats.YearToDateSpend, stats.ProjectedYearEndSpend = yearSpend(activeSubscriptions, cancelledSubscriptions, time.Now())
+
 	// Calculate savings from cancelled subscriptions
 	for _, sub := range cancelledSubscriptions {
 		stats.TotalSaved += sub.AnnualCost()
@@ -91,46 +371,512 @@ func (s *SubscriptionService) GetStats() (*models.Stats, error) {
 	return stats, nil
 }
 
+// isTrialSpendIncluded reports whether trials count towards the spending totals
+func (s *SubscriptionService) isTrialSpendIncluded() bool {
+	return s.settingsService != nil && s.settingsService.IsTrialSpendIncluded()
+}
+
+// spendStatuses returns the statuses whose subscriptions count as spend
+func spendStatuses(includeTrials bool) []string {
+	if includeTrials {
+		return []string{"Active", "Trial"}
+	}
+	return []string{"Active"}
+}
+
+// yearSpend sums the renewal charges of now's calendar year: those already
+// billed by now, and those plus the charges still scheduled through Dec 31.
+// Cancelled subscriptions contribute charges up to their cancellation date, and
+// subscriptions without a start date are assumed to have been active all year.
+func yearSpend(active, cancelled []models.Subscription, now time.Time) (ytd, projected float64) {
+	yearStart := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+	yearEnd := yearStart.AddDate(1, 0, 0).Add(-time.Nanosecond)
+
+	add := func(sub models.Subscription) {
+		if sub.StartDate == nil {
+			sub.StartDate = &yearStart
+		}
+		for _, date := range sub.RenewalsBetween(yearStart, yearEnd) {
+			if !date.After(now) {
+				ytd += sub.Cost
+			}
+			projected += sub.Cost
+		}
+	}
+
+	for _, sub := range active {
+		add(sub)
+	}
+	for _, sub := range cancelled {
+		if sub.CancellationDate != nil && !sub.CancellationDate.Before(yearStart) {
+			add(sub)
+		}
+	}
+	return ytd, projected
+}
+
+// GetSavingsTimeline returns, for each of the last months months (oldest first),
+// the cumulative monthly savings from subscriptions cancelled by the end of that month
+func (s *SubscriptionService) GetSavingsTimeline(months int) ([]models.MonthlySavings, error) {
+	if months <= 0 {
+		months = DefaultTrendMonths
+	}
+	if months > MaxTrendMonths {
+		months = MaxTrendMonths
+	}
+
+	cancelled, err := s.repo.GetCancelledSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+	return savingsTimeline(cancelled, months, time.Now()), nil
+}
+
+// savingsTimeline builds the savings timeline for the months up to and including now's month.
+// Cancelled subscriptions without a cancellation date can't be placed and are skipped.
+func savingsTimeline(cancelled []models.Subscription, months int, now time.Time) []models.MonthlySavings {
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	timeline := make([]models.MonthlySavings, 0, months)
+
+	for i := months - 1; i >= 0; i-- {
+		monthStart := currentMonth.AddDate(0, -i, 0)
+		monthEnd := monthStart.AddDate(0, 1, 0)
+		point := models.MonthlySavings{Month: monthStart.Format("2006-01")}
+
+		for j := range cancelled {
+			sub := &cancelled[j]
+			if sub.CancellationDate == nil || !sub.CancellationDate.Before(monthEnd) {
+				continue
+			}
+			point.Saved += sub.MonthlyCost()
+			point.Count++
+		}
+
+		timeline = append(timeline, point)
+	}
+	return timeline
+}
+
+// GetLowUsageSubscriptions returns active subscriptions with "Low" or "None" usage,
+// most expensive first, as candidates to cancel
+func (s *SubscriptionService) GetLowUsageSubscriptions() ([]models.Subscription, error) {
+	active, err := s.repo.GetActiveSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+
+	lowUsage := []models.Subscription{}
+	for _, sub := range active {
+		if sub.IsLowUsage() {
+			lowUsage = append(lowUsage, sub)
+		}
+	}
+	sort.SliceStable(lowUsage, func(i, j int) bool {
+		return lowUsage[i].MonthlyCost() > lowUsage[j].MonthlyCost()
+	})
+	return lowUsage, nil
+}
+
+// GetCategoryStats returns monthly spend of active subscriptions, and of trials
+// when they count as spend, per category, highest first, with every entry
+// carrying a display color
+func (s *SubscriptionService) GetCategoryStats() ([]models.CategoryStat, error) {
+	stats, err := s.repo.GetCategoryStats(spendStatuses(s.isTrialSpendIncluded()))
+	if err != nil {
+		return nil, err
+	}
+	for i := range stats {
+		if stats[i].Category == "" {
+			stats[i].Category = "Uncategorized"
+		}
+		if stats[i].Color == "" {
+			stats[i].Color = models.DefaultCategoryColor(stats[i].Category)
+		}
+	}
+	return stats, nil
+}
+
+// GetActive returns every active subscription
+func (s *SubscriptionService) GetActive() ([]models.Subscription, error) {
+	return s.repo.GetActiveSubscriptions()
+}
+
+// GetMyShare returns my monthly share of the active subscriptions, counting
+// shared ones at their cost divided by the number of people sharing them
+func (s *SubscriptionService) GetMyShare() (*models.MyShareStat, error) {
+	active, err := s.repo.GetActiveSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+
+	stat := &models.MyShareStat{}
+	for _, sub := range active {
+		stat.MyMonthlyShare += sub.MyShareMonthly()
+		stat.TotalMonthlySpend += sub.MonthlyCost()
+		if sub.SharedWith > 1 {
+			stat.SharedCount++
+		}
+	}
+	return stat, nil
+}
+
+// GetPaymentMethodStats returns monthly spend of active subscriptions per payment method
+func (s *SubscriptionService) GetPaymentMethodStats() ([]models.PaymentMethodStat, error) {
+	return s.repo.GetPaymentMethodStats()
+}
+
+// GetAccountStats returns monthly spend of active subscriptions per account
+func (s *SubscriptionService) GetAccountStats() ([]models.AccountStat, error) {
+	return s.repo.GetAccountStats()
+}
+
+// Bounds for the spending trend window
+const (
+	DefaultTrendMonths = 6
+	MaxTrendMonths     = 60
+)
+
+// GetMonthlyTrend returns total monthly spend for each of the last months months,
+// oldest first and ending with the current month. A subscription counts toward a
+// month only if it had started by the month's end and wasn't cancelled before it began.
+func (s *SubscriptionService) GetMonthlyTrend(months int) ([]models.MonthlySpend, error) {
+	if months <= 0 {
+		months = DefaultTrendMonths
+	}
+	if months > MaxTrendMonths {
+		months = MaxTrendMonths
+	}
+
+	subscriptions, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return monthlyTrend(subscriptions, months, time.Now()), nil
+}
+
+// monthlyTrend builds the spending trend for the months up to and including now's month
+func monthlyTrend(subscriptions []models.Subscription, months int, now time.Time) []models.MonthlySpend {
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	trend := make([]models.MonthlySpend, 0, months)
+
+	for i := months - 1; i >= 0; i-- {
+		monthStart := currentMonth.AddDate(0, -i, 0)
+		monthEnd := monthStart.AddDate(0, 1, 0)
+		point := models.MonthlySpend{Month: monthStart.Format("2006-01")}
+
+		for j := range subscriptions {
+			sub := &subscriptions[j]
+			if !billedInMonth(sub, monthStart, monthEnd) {
+				continue
+			}
+			point.Total += sub.MonthlyCost()
+			point.Count++
+		}
+
+		trend = append(trend, point)
+	}
+	return trend
+}
+
+// billedInMonth reports whether sub counts toward the spend of the month [monthStart, monthEnd):
+// it must be active, or cancelled with a known date, have started by the month's end
+// and not have been cancelled before the month began
+func billedInMonth(sub *models.Subscription, monthStart, monthEnd time.Time) bool {
+	switch {
+	case sub.Status == "Active":
+	case sub.Status == "Cancelled" && sub.CancellationDate != nil:
+	default:
+		return false
+	}
+	if sub.StartDate != nil && !sub.StartDate.Before(monthEnd) {
+		return false
+	}
+	if sub.CancellationDate != nil && sub.CancellationDate.Before(monthStart) {
+		return false
+	}
+	return true
+}
+
+// GetCategoryTrend returns monthly spend per category for each of the last months
+// months, oldest first. Every category present in any month has a value for every month.
+func (s *SubscriptionService) GetCategoryTrend(months int) (*models.CategoryTrend, error) {
+	if months <= 0 {
+		months = DefaultTrendMonths
+	}
+	if months > MaxTrendMonths {
+		months = MaxTrendMonths
+	}
+
+	subscriptions, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return categoryTrend(subscriptions, months, time.Now()), nil
+}
+
+// categoryTrend buckets monthly spend by category for the months up to and including now's month
+func categoryTrend(subscriptions []models.Subscription, months int, now time.Time) *models.CategoryTrend {
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	trend := &models.CategoryTrend{
+		Months:     make([]string, months),
+		Categories: make(map[string][]float64),
+	}
+
+	for i := 0; i < months; i++ {
+		monthStart := currentMonth.AddDate(0, i-months+1, 0)
+		monthEnd := monthStart.AddDate(0, 1, 0)
+		trend.Months[i] = monthStart.Format("2006-01")
+
+		for j := range subscriptions {
+			sub := &subscriptions[j]
+			if !billedInMonth(sub, monthStart, monthEnd) {
+				continue
+			}
+			name := sub.Category.Name
+			if name == "" {
+				name = "Uncategorized"
+			}
+			values, ok := trend.Categories[name]
+			if !ok {
+				values = make([]float64, months)
+				trend.Categories[name] = values
+			}
+			values[i] += sub.MonthlyCost()
+		}
+	}
+	return trend
+}
+
+// Bounds for the charge forecast window
+const (
+	DefaultForecastDays = 30
+	MaxForecastDays     = 366
+)
+
+// GetForecast projects every renewal charge of active subscriptions over the
+// next days days, sorted by date. Amounts are in each subscription's own currency.
+func (s *SubscriptionService) GetForecast(days int) ([]models.ForecastCharge, error) {
+	if days <= 0 {
+		days = DefaultForecastDays
+	}
+	if days > MaxForecastDays {
+		days = MaxForecastDays
+	}
+
+	subscriptions, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return forecastCharges(subscriptions, days, time.Now()), nil
+}
+
+// forecastCharges lists the renewals of active subscriptions between now and now+days
+func forecastCharges(subscriptions []models.Subscription, days int, now time.Time) []models.ForecastCharge {
+	until := now.AddDate(0, 0, days)
+	charges := []models.ForecastCharge{}
+
+	for i := range subscriptions {
+		sub := &subscriptions[i]
+		if sub.Status != "Active" {
+			continue
+		}
+		renewals := sub.RenewalsBetween(now, until)
+		if sub.PendingFirstCharge(now) {
+			// The prorated first charge comes first; regular renewals start after it
+			firstCharge := *sub.FirstChargeDate
+			if !firstCharge.After(until) {
+				charges = append(charges, models.ForecastCharge{
+					SubscriptionID: sub.ID,
+					Name:           sub.Name,
+					Amount:         sub.FirstChargeCost(),
+					Currency:       sub.OriginalCurrency,
+					Date:           firstCharge,
+				})
+			}
+			for len(renewals) > 0 && !renewals[0].After(firstCharge) {
+				renewals = renewals[1:]
+			}
+		}
+		for j, date := range renewals {
+			// A one-off next charge only applies to the first upcoming renewal
+			amount := sub.Cost
+			if j == 0 {
+				amount = sub.NextChargeCost()
+			}
+			charges = append(charges, models.ForecastCharge{
+				SubscriptionID: sub.ID,
+				Name:           sub.Name,
+				Amount:         amount,
+				Currency:       sub.OriginalCurrency,
+				Date:           date,
+			})
+		}
+	}
+
+	sort.SliceStable(charges, func(i, j int) bool {
+		return charges[i].Date.Before(charges[j].Date)
+	})
+	return charges
+}
+
+// Bounds for the renewal calendar window
+const (
+	DefaultRenewalCalendarMonths = 12
+	MaxRenewalCalendarMonths     = 24
+)
+
+// GetRenewalCalendar groups the upcoming charges of active subscriptions by calendar
+// month for months months, starting with the current one. Amounts are in each
+// subscription's own currency, so the monthly totals are left to the caller.
+func (s *SubscriptionService) GetRenewalCalendar(months int) ([]models.RenewalMonth, error) {
+	if months <= 0 {
+		months = DefaultRenewalCalendarMonths
+	}
+	if months > MaxRenewalCalendarMonths {
+		months = MaxRenewalCalendarMonths
+	}
+
+	subscriptions, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return renewalCalendar(subscriptions, months, time.Now(), s.location()), nil
+}
+
+// renewalCalendar buckets the forecast charges from now to the end of the last of
+// months months, taking calendar months in loc; months without charges are kept
+// with an empty list
+func renewalCalendar(subscriptions []models.Subscription, months int, now time.Time, loc *time.Location) []models.RenewalMonth {
+	now = now.In(loc)
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	calendar := make([]models.RenewalMonth, months)
+	index := make(map[string]int, months)
+	for i := range calendar {
+		month := currentMonth.AddDate(0, i, 0).Format("2006-01")
+		calendar[i] = models.RenewalMonth{Month: month, Subscriptions: []models.RenewalCalendarEntry{}}
+		index[month] = i
+	}
+
+	days := int(math.Ceil(currentMonth.AddDate(0, months, 0).Sub(now).Hours() / 24))
+	for _, charge := range forecastCharges(subscriptions, days, now) {
+		i, ok := index[charge.Date.In(loc).Format("2006-01")]
+		if !ok {
+			continue
+		}
+		month := &calendar[i]
+
+		var entry *models.RenewalCalendarEntry
+		for j := range month.Subscriptions {
+			if month.Subscriptions[j].SubscriptionID == charge.SubscriptionID {
+				entry = &month.Subscriptions[j]
+				break
+			}
+		}
+		if entry == nil {
+			month.Subscriptions = append(month.Subscriptions, models.RenewalCalendarEntry{
+				SubscriptionID: charge.SubscriptionID,
+				Name:           charge.Name,
+				Currency:       charge.Currency,
+			})
+			entry = &month.Subscriptions[len(month.Subscriptions)-1]
+		}
+		entry.Amount += charge.Amount
+		entry.Dates = append(entry.Dates, charge.Date)
+	}
+	return calendar
+}
+
 func (s *SubscriptionService) GetAllCategories() ([]models.Category, error) {
 	return s.categoryService.GetAll()
 }
 
-// GetSubscriptionsNeedingReminders returns subscriptions that need renewal reminders
-// based on the reminder_days setting. It returns a map of subscription to days until renewal.
+// DefaultUpcomingRenewalDays is the window used when no positive window is given
+const DefaultUpcomingRenewalDays = 7
+
+// GetUpcomingRenewals returns active subscriptions renewing within the next
+// days days, soonest first. A non-positive window falls back to DefaultUpcomingRenewalDays.
+func (s *SubscriptionService) GetUpcomingRenewals(days int) ([]models.Subscription, error) {
+	if days <= 0 {
+		days = DefaultUpcomingRenewalDays
+	}
+	return s.repo.GetUpcomingRenewals(days)
+}
+
+// GetRenewalsInMonth returns active subscriptions whose renewal date falls in the
+// given calendar month of the configured time zone, soonest first
+func (s *SubscriptionService) GetRenewalsInMonth(year, month int) ([]models.Subscription, error) {
+	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.location())
+	return s.repo.GetRenewalsBetween(from, from.AddDate(0, 1, 0))
+}
+
+// location returns the configured time zone, or the renewal calculation zone without settings
+func (s *SubscriptionService) location() *time.Location {
+	if s.settingsService != nil {
+		return s.settingsService.GetLocation()
+	}
+	return models.Location()
+}
+
+// DaysUntil returns how many calendar days in the configured time zone lie
+// between today and t, as renewal and cancellation reminders count them
+func (s *SubscriptionService) DaysUntil(t time.Time) int {
+	return calendarDaysUntil(time.Now(), t, s.location())
+}
+
+// calendarDaysUntil returns how many calendar days in loc lie between now's date and t's date
+func calendarDaysUntil(now, t time.Time, loc *time.Location) int {
+	from := now.In(loc)
+	to := t.In(loc)
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDay.Sub(fromDay).Hours() / 24)
+}
+
+// GetSubscriptionsNeedingReminders returns subscriptions that need renewal reminders.
+// Each subscription is reminded its own ReminderDaysOverride days ahead, or
+// reminderDays (the reminder_days setting) when it has no override. It returns
+// a map of subscription to days until renewal.
 func (s *SubscriptionService) GetSubscriptionsNeedingReminders(reminderDays int) (map[*models.Subscription]int, error) {
-	if reminderDays <= 0 {
+	// Look as far ahead as the longest lead time, then check each subscription against its own
+	maxOverride, err := s.repo.MaxReminderDaysOverride()
+	if err != nil {
+		return nil, err
+	}
+	window := max(reminderDays, maxOverride)
+	if window <= 0 {
 		return make(map[*models.Subscription]int), nil
 	}
 
-	// Get all subscriptions with renewals in the next reminderDays
-	subscriptions, err := s.repo.GetUpcomingRenewals(reminderDays)
+	subscriptions, err := s.repo.GetUpcomingCharges(window)
 	if err != nil {
 		return nil, err
 	}
 
 	result := make(map[*models.Subscription]int)
+	loc := s.location()
+	now := time.Now()
 
 	for i := range subscriptions {
 		sub := &subscriptions[i]
-		if sub.RenewalDate == nil {
+		// A pending prorated first charge is reminded about before the regular renewals
+		chargeDate := sub.UpcomingChargeDate(now)
+		if chargeDate == nil {
 			continue
 		}
 		if !sub.ReminderEnabled {
 			continue
 		}
 
-		// Calculate days until renewal using proper date arithmetic
-		// Use time.Until for more accurate calculation (handles timezone differences better)
-		daysUntil := int(time.Until(*sub.RenewalDate).Hours() / 24)
+		// Count calendar days in the configured time zone so the boundary follows the user's midnight
+		daysUntil := calendarDaysUntil(now, *chargeDate, loc)
 
-		// Only include if within the reminder window and not past due
-		if daysUntil >= 0 && daysUntil <= reminderDays {
-			// Check if we've already sent a reminder for this renewal date
-			// Skip if we've sent a reminder for the same renewal date
+		// Only include if within the subscription's reminder window and not past due
+		if daysUntil >= 0 && daysUntil <= sub.ReminderLeadDays(reminderDays) {
+			// Check if we've already sent a reminder for this charge date
+			// Skip if we've sent a reminder for the same charge date
 			if sub.LastReminderRenewalDate != nil &&
-				sub.RenewalDate != nil &&
-				sub.LastReminderRenewalDate.Equal(*sub.RenewalDate) {
-				// Already sent reminder for this renewal date, skip
+				sub.LastReminderRenewalDate.Equal(*chargeDate) {
+				// Already sent reminder for this charge date, skip
 				continue
 			}
 
@@ -155,6 +901,7 @@ func (s *SubscriptionService) GetSubscriptionsNeedingCancellationReminders(remin
 	}
 
 	result := make(map[*models.Subscription]int)
+	loc := s.location()
 
 	for i := range subscriptions {
 		sub := &subscriptions[i]
@@ -166,7 +913,7 @@ func (s *SubscriptionService) GetSubscriptionsNeedingCancellationReminders(remin
 		}
 
 		// Calculate days until cancellation
-		daysUntil := int(time.Until(*sub.CancellationDate).Hours() / 24)
+		daysUntil := calendarDaysUntil(time.Now(), *sub.CancellationDate, loc)
 
 		// Only include if within the reminder window and not past due
 		if daysUntil >= 0 && daysUntil <= reminderDays {
@@ -0,0 +1,1184 @@
+package service
+
+import (
+	"errors"
+	"subtrackr/internal/models"
+	"subtrackr/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupSubscriptionServiceTest(t *testing.T) (*SubscriptionService, *CategoryService) {
+	db := setupRenewalReminderTestDB(t)
+	categoryService := NewCategoryService(repository.NewCategoryRepository(db))
+	subscriptionService := NewSubscriptionService(repository.NewSubscriptionRepository(db), categoryService)
+	return subscriptionService, categoryService
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+// utcDate returns a pointer to midnight UTC on the given day
+func utcDate(y int, m time.Month, d int) *time.Time {
+	v := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return &v
+}
+
+// createSubscriptions saves each of subs, filling in their IDs
+func createSubscriptions(t *testing.T, s *SubscriptionService, subs []models.Subscription) {
+	t.Helper()
+	for i := range subs {
+		_, err := s.Create(&subs[i])
+		assert.NoError(t, err)
+	}
+}
+
+func TestSubscriptionService_Search(t *testing.T) {
+	s, cs := setupSubscriptionServiceTest(t)
+
+	streaming, err := cs.Create(&models.Category{Name: "Streaming"})
+	assert.NoError(t, err)
+	software, err := cs.Create(&models.Category{Name: "Software"})
+	assert.NoError(t, err)
+
+	seed := []models.Subscription{
+		{Name: "Netflix", Cost: 15.99, Schedule: "Monthly", Status: "Active", CategoryID: streaming.ID},
+		{Name: "Disney Plus", Cost: 7.99, Schedule: "Monthly", Status: "Cancelled", CategoryID: streaming.ID},
+		{Name: "JetBrains", Cost: 249, Schedule: "Annual", Status: "Active", CategoryID: software.ID},
+		{Name: "100%_Pure", Cost: 5, Schedule: "Monthly", Status: "Paused", CategoryID: software.ID},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   models.SubscriptionFilter
+		expected []string
+	}{
+		{"Empty filter returns all", models.SubscriptionFilter{}, []string{"Netflix", "Disney Plus", "JetBrains", "100%_Pure"}},
+		{"Name is case-insensitive substring", models.SubscriptionFilter{Query: "net"}, []string{"Netflix"}},
+		{"Wildcards are matched literally", models.SubscriptionFilter{Query: "%_"}, []string{"100%_Pure"}},
+		{"Status", models.SubscriptionFilter{Status: "Active"}, []string{"Netflix", "JetBrains"}},
+		{"Category", models.SubscriptionFilter{CategoryID: streaming.ID}, []string{"Netflix", "Disney Plus"}},
+		{"Cost range", models.SubscriptionFilter{MinCost: floatPtr(6), MaxCost: floatPtr(20)}, []string{"Netflix", "Disney Plus"}},
+		{"Combined", models.SubscriptionFilter{Status: "Active", CategoryID: software.ID, MinCost: floatPtr(100)}, []string{"JetBrains"}},
+		{"Injection attempt is treated as text", models.SubscriptionFilter{Query: "' OR 1=1 --"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := s.Search(tt.filter, "created_at", "asc")
+			assert.NoError(t, err)
+
+			names := []string{}
+			for _, sub := range results {
+				names = append(names, sub.Name)
+			}
+			assert.ElementsMatch(t, tt.expected, names)
+		})
+	}
+}
+
+func TestSubscriptionService_TransactionRollsBack(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	existing, err := s.Create(&models.Subscription{Name: "Keep", Cost: 5, Schedule: "Monthly", Status: "Active"})
+	assert.NoError(t, err)
+
+	err = s.Transaction(func(tx *SubscriptionService) error {
+		if _, err := tx.Create(&models.Subscription{Name: "Discard", Cost: 9, Schedule: "Monthly", Status: "Active"}); err != nil {
+			return err
+		}
+		if err := tx.Delete(existing.ID); err != nil {
+			return err
+		}
+		return errors.New("fatal")
+	})
+	assert.EqualError(t, err, "fatal")
+
+	all, err := s.GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, all, 1)
+	assert.Equal(t, "Keep", all[0].Name)
+
+	err = s.Transaction(func(tx *SubscriptionService) error {
+		_, err := tx.Create(&models.Subscription{Name: "Commit", Cost: 9, Schedule: "Monthly", Status: "Active"})
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), s.Count())
+}
+
+func TestSubscriptionService_DeleteAll(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	for _, name := range []string{"Netflix", "Hulu", "Spotify"} {
+		_, err := s.Create(&models.Subscription{Name: name, Cost: 9.99, Schedule: "Monthly", Status: "Active"})
+		assert.NoError(t, err)
+	}
+
+	// A failure later in the same transaction leaves every subscription in place
+	err := s.Transaction(func(tx *SubscriptionService) error {
+		if _, err := tx.DeleteAll(); err != nil {
+			return err
+		}
+		return errors.New("fatal")
+	})
+	assert.EqualError(t, err, "fatal")
+	assert.Equal(t, int64(3), s.Count())
+
+	deleted, err := s.DeleteAll()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.Zero(t, s.Count())
+
+	deleted, err = s.DeleteAll()
+	assert.NoError(t, err)
+	assert.Zero(t, deleted)
+}
+
+func TestSubscriptionService_GetUpcomingRenewals(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	in := func(days int) *time.Time {
+		d := time.Now().AddDate(0, 0, days)
+		return &d
+	}
+	seed := []models.Subscription{
+		{Name: "Later", Cost: 5, Schedule: "Monthly", Status: "Active", RenewalDate: in(5)},
+		{Name: "Soon", Cost: 5, Schedule: "Monthly", Status: "Active", RenewalDate: in(2)},
+		{Name: "Next Month", Cost: 5, Schedule: "Monthly", Status: "Active", RenewalDate: in(20)},
+		{Name: "Cancelled", Cost: 5, Schedule: "Monthly", Status: "Cancelled", RenewalDate: in(3)},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	names := func(subs []models.Subscription) []string {
+		out := make([]string, len(subs))
+		for i, sub := range subs {
+			out[i] = sub.Name
+		}
+		return out
+	}
+
+	subs, err := s.GetUpcomingRenewals(7)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Soon", "Later"}, names(subs))
+
+	// Non-positive windows fall back to the default
+	subs, err = s.GetUpcomingRenewals(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Soon", "Later"}, names(subs))
+
+	subs, err = s.GetUpcomingRenewals(30)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Soon", "Later", "Next Month"}, names(subs))
+}
+
+func TestSubscriptionService_Recategorize(t *testing.T) {
+	s, cs := setupSubscriptionServiceTest(t)
+
+	streaming, err := cs.Create(&models.Category{Name: "Streaming"})
+	assert.NoError(t, err)
+	entertainment, err := cs.Create(&models.Category{Name: "Entertainment"})
+	assert.NoError(t, err)
+
+	netflix, err := s.Create(&models.Subscription{Name: "Netflix", Cost: 15.99, Schedule: "Monthly", Status: "Active", CategoryID: streaming.ID})
+	assert.NoError(t, err)
+	hulu, err := s.Create(&models.Subscription{Name: "Hulu", Cost: 7.99, Schedule: "Monthly", Status: "Active", CategoryID: streaming.ID})
+	assert.NoError(t, err)
+	other, err := s.Create(&models.Subscription{Name: "Other", Cost: 1, Schedule: "Monthly", Status: "Active", CategoryID: streaming.ID})
+	assert.NoError(t, err)
+
+	t.Run("Moves only the listed subscriptions", func(t *testing.T) {
+		updated, err := s.Recategorize([]uint{netflix.ID, hulu.ID, 9999}, entertainment.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), updated)
+
+		for _, id := range []uint{netflix.ID, hulu.ID} {
+			sub, err := s.GetByID(id)
+			assert.NoError(t, err)
+			assert.Equal(t, entertainment.ID, sub.CategoryID)
+		}
+		sub, err := s.GetByID(other.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, streaming.ID, sub.CategoryID)
+	})
+
+	t.Run("Unknown category changes nothing", func(t *testing.T) {
+		_, err := s.Recategorize([]uint{other.ID}, 9999)
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+		sub, err := s.GetByID(other.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, streaming.ID, sub.CategoryID)
+	})
+}
+
+func TestSubscriptionService_RecalculateRenewals(t *testing.T) {
+	db := setupRenewalReminderTestDB(t)
+	s := NewSubscriptionService(repository.NewSubscriptionRepository(db), nil)
+
+	start := time.Now().AddDate(0, -2, 0)
+	active, err := s.Create(&models.Subscription{Name: "Netflix", Cost: 15.99, Schedule: "Monthly", Status: "Active", StartDate: &start})
+	assert.NoError(t, err)
+	cancelled, err := s.Create(&models.Subscription{Name: "Hulu", Cost: 7.99, Schedule: "Monthly", Status: "Cancelled"})
+	assert.NoError(t, err)
+
+	// Clear the dates behind the hooks' back, as a bad import or old schema might
+	for _, id := range []uint{active.ID, cancelled.ID} {
+		assert.NoError(t, db.Model(&models.Subscription{}).Where("id = ?", id).UpdateColumn("renewal_date", nil).Error)
+	}
+
+	changed, err := s.RecalculateRenewals()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	sub, err := s.GetByID(active.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, sub.RenewalDate) {
+		assert.True(t, sub.RenewalDate.After(time.Now()))
+	}
+	sub, err = s.GetByID(cancelled.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, sub.RenewalDate, "only active subscriptions are touched")
+
+	changed, err = s.RecalculateRenewals()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, changed, "a second run has nothing to change")
+}
+
+func TestSubscriptionService_GetStaleRenewals(t *testing.T) {
+	db := setupRenewalReminderTestDB(t)
+	s := NewSubscriptionService(repository.NewSubscriptionRepository(db), nil)
+
+	stale, err := s.Create(&models.Subscription{Name: "Netflix", Cost: 15.99, Schedule: "Monthly", Status: "Active"})
+	assert.NoError(t, err)
+	_, err = s.Create(&models.Subscription{Name: "Spotify", Cost: 9.99, Schedule: "Monthly", Status: "Active"})
+	assert.NoError(t, err)
+	paused, err := s.Create(&models.Subscription{Name: "Hulu", Cost: 7.99, Schedule: "Monthly", Status: "Paused"})
+	assert.NoError(t, err)
+
+	// A renewal the scheduler never rolled forward
+	past := time.Now().AddDate(0, 0, -10)
+	for _, id := range []uint{stale.ID, paused.ID} {
+		assert.NoError(t, db.Model(&models.Subscription{}).Where("id = ?", id).UpdateColumn("renewal_date", past).Error)
+	}
+
+	subs, err := s.GetStaleRenewals()
+	assert.NoError(t, err)
+	if assert.Len(t, subs, 1) {
+		assert.Equal(t, stale.ID, subs[0].ID)
+		assert.True(t, subs[0].RenewalDate.Before(time.Now()), "the stored date is reported, not a rolled-forward one")
+	}
+
+	stats, err := s.GetStats()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.StaleRenewals)
+}
+
+func TestMonthlyTrend(t *testing.T) {
+	date := func(y int, m time.Month, d int) *time.Time {
+		v := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return &v
+	}
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	subs := []models.Subscription{
+		// Counts every month
+		{Name: "Old", Cost: 10, Schedule: "Monthly", Status: "Active", StartDate: date(2024, 1, 1)},
+		// Started three months ago, counts from April
+		{Name: "New", Cost: 120, Schedule: "Annual", Status: "Active", StartDate: date(2025, 4, 20)},
+		// Cancelled in March, counts through March
+		{Name: "Gone", Cost: 5, Schedule: "Monthly", Status: "Cancelled", StartDate: date(2024, 6, 1), CancellationDate: date(2025, 3, 10)},
+		// No start date counts every month
+		{Name: "Undated", Cost: 1, Schedule: "Monthly", Status: "Active"},
+		// Never counted
+		{Name: "Paused", Cost: 50, Schedule: "Monthly", Status: "Paused", StartDate: date(2024, 1, 1)},
+		{Name: "Cancelled undated", Cost: 50, Schedule: "Monthly", Status: "Cancelled"},
+	}
+
+	trend := monthlyTrend(subs, 4, now)
+	assert.Equal(t, []models.MonthlySpend{
+		{Month: "2025-03", Total: 16, Count: 3},
+		{Month: "2025-04", Total: 21, Count: 3},
+		{Month: "2025-05", Total: 21, Count: 3},
+		{Month: "2025-06", Total: 21, Count: 3},
+	}, trend)
+}
+
+func TestForecastCharges(t *testing.T) {
+	date := func(y int, m time.Month, d int) *time.Time {
+		v := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return &v
+	}
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	subs := []models.Subscription{
+		{ID: 1, Name: "Weekly", Cost: 2, OriginalCurrency: "USD", Schedule: "Weekly", Status: "Active", StartDate: date(2025, 5, 28)},
+		{ID: 2, Name: "Monthly", Cost: 10, OriginalCurrency: "EUR", Schedule: "Monthly", Status: "Active", StartDate: date(2025, 1, 15)},
+		{ID: 3, Name: "Annual", Cost: 100, OriginalCurrency: "USD", Schedule: "Annual", Status: "Active", StartDate: date(2024, 9, 1)},
+		{ID: 4, Name: "Paused", Cost: 50, OriginalCurrency: "USD", Schedule: "Monthly", Status: "Paused", StartDate: date(2025, 1, 10)},
+	}
+
+	charges := forecastCharges(subs, 14, now)
+	assert.Equal(t, []models.ForecastCharge{
+		{SubscriptionID: 1, Name: "Weekly", Amount: 2, Currency: "USD", Date: *date(2025, 6, 4)},
+		{SubscriptionID: 1, Name: "Weekly", Amount: 2, Currency: "USD", Date: *date(2025, 6, 11)},
+		{SubscriptionID: 2, Name: "Monthly", Amount: 10, Currency: "EUR", Date: *date(2025, 6, 15)},
+	}, charges)
+
+	assert.Empty(t, forecastCharges(subs[3:], 30, now))
+}
+
+func TestForecastCharges_NextChargeAmountAppliesOnce(t *testing.T) {
+	start := time.Date(2025, 5, 28, 0, 0, 0, 0, time.UTC)
+	promoEnd := 9.5
+	subs := []models.Subscription{
+		{ID: 1, Name: "Weekly", Cost: 2, OriginalCurrency: "USD", Schedule: "Weekly", Status: "Active", StartDate: &start, NextChargeAmount: &promoEnd},
+	}
+
+	charges := forecastCharges(subs, 21, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.Len(t, charges, 3)
+	assert.Equal(t, 9.5, charges[0].Amount)
+	assert.Equal(t, 2.0, charges[1].Amount)
+	assert.Equal(t, 2.0, charges[2].Amount)
+}
+
+func TestForecastCharges_ProratedFirstCharge(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	prorated := 4.0
+
+	t.Run("Full cost follows the first charge", func(t *testing.T) {
+		subs := []models.Subscription{
+			{ID: 1, Name: "Gym", Cost: 12, OriginalCurrency: "USD", Schedule: "Monthly", Status: "Active",
+				RenewalDate: utcDate(2025, 6, 15), FirstChargeDate: utcDate(2025, 6, 5), FirstChargeAmount: &prorated},
+		}
+
+		assert.Equal(t, []models.ForecastCharge{
+			{SubscriptionID: 1, Name: "Gym", Amount: 4, Currency: "USD", Date: *utcDate(2025, 6, 5)},
+			{SubscriptionID: 1, Name: "Gym", Amount: 12, Currency: "USD", Date: *utcDate(2025, 6, 15)},
+			{SubscriptionID: 1, Name: "Gym", Amount: 12, Currency: "USD", Date: *utcDate(2025, 7, 15)},
+		}, forecastCharges(subs, 50, now))
+	})
+
+	t.Run("Replaces a renewal on the same day", func(t *testing.T) {
+		promoEnd := 9.5
+		subs := []models.Subscription{
+			{ID: 1, Name: "Gym", Cost: 12, OriginalCurrency: "USD", Schedule: "Monthly", Status: "Active",
+				RenewalDate: utcDate(2025, 6, 15), FirstChargeDate: utcDate(2025, 6, 15), FirstChargeAmount: &prorated, NextChargeAmount: &promoEnd},
+		}
+
+		charges := forecastCharges(subs, 50, now)
+		if assert.Len(t, charges, 2) {
+			assert.Equal(t, 4.0, charges[0].Amount)
+			assert.Equal(t, 9.5, charges[1].Amount, "The one-off next charge applies to the first regular renewal")
+		}
+	})
+
+	t.Run("A past first charge is ignored", func(t *testing.T) {
+		subs := []models.Subscription{
+			{ID: 1, Name: "Gym", Cost: 12, OriginalCurrency: "USD", Schedule: "Monthly", Status: "Active",
+				RenewalDate: utcDate(2025, 6, 15), FirstChargeDate: utcDate(2025, 5, 20), FirstChargeAmount: &prorated},
+		}
+
+		charges := forecastCharges(subs, 20, now)
+		if assert.Len(t, charges, 1) {
+			assert.Equal(t, 12.0, charges[0].Amount)
+		}
+	})
+}
+
+func TestRenewalCalendar(t *testing.T) {
+	now := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Groups charges by month", func(t *testing.T) {
+		subs := []models.Subscription{
+			{ID: 1, Name: "Monthly", Cost: 10, OriginalCurrency: "USD", Schedule: "Monthly", Status: "Active", RenewalDate: utcDate(2025, 6, 20)},
+			{ID: 2, Name: "Annual", Cost: 100, OriginalCurrency: "USD", Schedule: "Annual", Status: "Active", StartDate: utcDate(2024, 8, 5)},
+			{ID: 3, Name: "Paused", Cost: 50, OriginalCurrency: "USD", Schedule: "Monthly", Status: "Paused", StartDate: utcDate(2025, 1, 12)},
+		}
+
+		assert.Equal(t, []models.RenewalMonth{
+			{Month: "2025-06", Subscriptions: []models.RenewalCalendarEntry{
+				{SubscriptionID: 1, Name: "Monthly", Amount: 10, Currency: "USD", Dates: []time.Time{*utcDate(2025, 6, 20)}},
+			}},
+			{Month: "2025-07", Subscriptions: []models.RenewalCalendarEntry{
+				{SubscriptionID: 1, Name: "Monthly", Amount: 10, Currency: "USD", Dates: []time.Time{*utcDate(2025, 7, 20)}},
+			}},
+			{Month: "2025-08", Subscriptions: []models.RenewalCalendarEntry{
+				{SubscriptionID: 2, Name: "Annual", Amount: 100, Currency: "USD", Dates: []time.Time{*utcDate(2025, 8, 5)}},
+				{SubscriptionID: 1, Name: "Monthly", Amount: 10, Currency: "USD", Dates: []time.Time{*utcDate(2025, 8, 20)}},
+			}},
+		}, renewalCalendar(subs, 3, now, time.UTC))
+	})
+
+	t.Run("Sums several charges in one month", func(t *testing.T) {
+		subs := []models.Subscription{
+			{ID: 1, Name: "Weekly", Cost: 2, OriginalCurrency: "USD", Schedule: "Weekly", Status: "Active", StartDate: utcDate(2025, 6, 4)},
+		}
+
+		calendar := renewalCalendar(subs, 1, now, time.UTC)
+		if assert.Len(t, calendar, 1) && assert.Len(t, calendar[0].Subscriptions, 1) {
+			entry := calendar[0].Subscriptions[0]
+			assert.Equal(t, 6.0, entry.Amount)
+			assert.Equal(t, []time.Time{*utcDate(2025, 6, 11), *utcDate(2025, 6, 18), *utcDate(2025, 6, 25)}, entry.Dates)
+		}
+	})
+
+	t.Run("Keeps months without charges", func(t *testing.T) {
+		calendar := renewalCalendar(nil, 2, now, time.UTC)
+		assert.Equal(t, []models.RenewalMonth{
+			{Month: "2025-06", Subscriptions: []models.RenewalCalendarEntry{}},
+			{Month: "2025-07", Subscriptions: []models.RenewalCalendarEntry{}},
+		}, calendar)
+	})
+
+	t.Run("Takes months in the configured time zone", func(t *testing.T) {
+		loc := time.FixedZone("UTC-5", -5*60*60)
+		// Still June 30th in loc, though already July in UTC
+		lateJune := time.Date(2025, 7, 1, 2, 0, 0, 0, time.UTC)
+		renewal := time.Date(2025, 7, 1, 3, 0, 0, 0, time.UTC)
+		subs := []models.Subscription{
+			{ID: 1, Name: "Monthly", Cost: 10, OriginalCurrency: "USD", Schedule: "Monthly", Status: "Active", RenewalDate: &renewal},
+		}
+
+		calendar := renewalCalendar(subs, 2, lateJune, loc)
+		if assert.Len(t, calendar, 2) {
+			assert.Equal(t, "2025-06", calendar[0].Month)
+			assert.Len(t, calendar[0].Subscriptions, 1, "The charge falls on June 30th in loc")
+			assert.Equal(t, "2025-07", calendar[1].Month)
+		}
+	})
+}
+
+func TestSubscriptionService_GetRenewalCalendar_ClampsMonths(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	calendar, err := s.GetRenewalCalendar(0)
+	assert.NoError(t, err)
+	assert.Len(t, calendar, DefaultRenewalCalendarMonths)
+	assert.Equal(t, time.Now().Format("2006-01"), calendar[0].Month)
+
+	calendar, err = s.GetRenewalCalendar(1000)
+	assert.NoError(t, err)
+	assert.Len(t, calendar, MaxRenewalCalendarMonths)
+}
+
+func TestSubscriptionService_GetMonthlyTrend_DefaultsMonths(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	trend, err := s.GetMonthlyTrend(0)
+	assert.NoError(t, err)
+	assert.Len(t, trend, DefaultTrendMonths)
+	assert.Equal(t, time.Now().Format("2006-01"), trend[len(trend)-1].Month)
+
+	trend, err = s.GetMonthlyTrend(1000)
+	assert.NoError(t, err)
+	assert.Len(t, trend, MaxTrendMonths)
+}
+
+func TestSubscriptionService_GetPaymentMethodStats(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	seed := []models.Subscription{
+		{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active", PaymentMethod: "Visa"},
+		{Name: "JetBrains", Cost: 240, Schedule: "Annual", Status: "Active", PaymentMethod: "Visa"},
+		{Name: "Spotify", Cost: 10, Schedule: "Monthly", Status: "Active", PaymentMethod: "Amex"},
+		{Name: "Hulu", Cost: 8, Schedule: "Monthly", Status: "Active"},
+		{Name: "Gym", Cost: 30, Schedule: "Monthly", Status: "Cancelled", PaymentMethod: "Amex"},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	stats, err := s.GetPaymentMethodStats()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.PaymentMethodStat{
+		{PaymentMethod: "Visa", Amount: 35, Count: 2},
+		{PaymentMethod: "Amex", Amount: 10, Count: 1},
+		{PaymentMethod: "Unspecified", Amount: 8, Count: 1},
+	}, stats)
+}
+
+func TestSubscriptionService_GetAccountStats(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	seed := []models.Subscription{
+		{Name: "YouTube Premium", Cost: 14, Schedule: "Monthly", Status: "Active", Account: "me@gmail.com"},
+		{Name: "Google One", Cost: 120, Schedule: "Annual", Status: "Active", Account: "me@gmail.com"},
+		{Name: "Spotify", Cost: 10, Schedule: "Monthly", Status: "Active", Account: "spotify-user"},
+		{Name: "Hulu", Cost: 8, Schedule: "Monthly", Status: "Active", Account: "  "},
+		{Name: "Stadia", Cost: 10, Schedule: "Monthly", Status: "Cancelled", Account: "me@gmail.com"},
+	}
+	createSubscriptions(t, s, seed)
+
+	stats, err := s.GetAccountStats()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.AccountStat{
+		{Account: "me@gmail.com", Amount: 24, Count: 2},
+		{Account: "spotify-user", Amount: 10, Count: 1},
+		{Account: "Unspecified", Amount: 8, Count: 1},
+	}, stats)
+}
+
+func TestSubscriptionService_SortByMonthlyCost(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	// Monthly equivalents: Cloud 20, Gym 30, Music 10, News 8
+	seed := []models.Subscription{
+		{Name: "Cloud", Cost: 240, Schedule: "Annual", Status: "Active"},
+		{Name: "Gym", Cost: 30, Schedule: "Monthly", Status: "Active"},
+		{Name: "Music", Cost: 10, Schedule: "Monthly", Status: "Active"},
+		{Name: "News", Cost: 96, Schedule: "Annual", Status: "Active"},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	names := func(subs []models.Subscription) []string {
+		out := make([]string, len(subs))
+		for i, sub := range subs {
+			out[i] = sub.Name
+		}
+		return out
+	}
+
+	subs, err := s.GetAllSorted(SortByMonthlyCost, "asc")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"News", "Music", "Cloud", "Gym"}, names(subs))
+
+	subs, err = s.GetAllSorted(SortByMonthlyCost, "desc")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Gym", "Cloud", "Music", "News"}, names(subs))
+
+	// Sorting by the stored cost puts the annual plans last instead
+	subs, err = s.GetAllSorted("cost", "asc")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Music", "Gym", "News", "Cloud"}, names(subs))
+
+	page, total, err := s.GetPaged(SortByMonthlyCost, "desc", 2, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	assert.Equal(t, []string{"Cloud", "Music"}, names(page))
+
+	found, err := s.Search(models.SubscriptionFilter{Status: "Active"}, SortByMonthlyCost, "asc")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"News", "Music", "Cloud", "Gym"}, names(found))
+}
+
+func TestSubscriptionService_Duplicate(t *testing.T) {
+	s, cs := setupSubscriptionServiceTest(t)
+
+	streaming, err := cs.Create(&models.Category{Name: "Streaming"})
+	assert.NoError(t, err)
+
+	start := time.Now().AddDate(0, -2, 0)
+	original, err := s.Create(&models.Subscription{
+		Name:       "Netflix",
+		Cost:       15.99,
+		Schedule:   "Monthly",
+		Status:     "Active",
+		CategoryID: streaming.ID,
+		StartDate:  &start,
+		Notes:      "Family plan",
+	})
+	assert.NoError(t, err)
+
+	duplicate, err := s.Duplicate(original.ID)
+	assert.NoError(t, err)
+	assert.NotZero(t, duplicate.ID)
+	assert.NotEqual(t, original.ID, duplicate.ID)
+	assert.Equal(t, "Netflix (copy)", duplicate.Name)
+	assert.Equal(t, original.Cost, duplicate.Cost)
+	assert.Equal(t, streaming.ID, duplicate.CategoryID)
+	assert.Equal(t, "Family plan", duplicate.Notes)
+	assert.NotNil(t, duplicate.RenewalDate)
+	assert.True(t, duplicate.RenewalDate.After(time.Now()))
+
+	// Editing the copy leaves the original untouched
+	duplicate.Cost = 22.99
+	_, err = s.Update(duplicate.ID, duplicate)
+	assert.NoError(t, err)
+
+	reloaded, err := s.GetByID(original.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Netflix", reloaded.Name)
+	assert.Equal(t, 15.99, reloaded.Cost)
+	assert.Equal(t, int64(2), s.Count())
+
+	_, err = s.Duplicate(9999)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
+
+func TestSubscriptionService_Duplicate_UploadedLogo(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	uploaded, err := s.Create(&models.Subscription{Name: "Gym", Cost: 30, Schedule: "Monthly", Status: "Active", IconURL: LogoCachePath + "/upload-0123abcd.png"})
+	assert.NoError(t, err)
+	cached, err := s.Create(&models.Subscription{Name: "Netflix", Cost: 15.99, Schedule: "Monthly", Status: "Active", IconURL: LogoCachePath + "/netflix.com.png"})
+	assert.NoError(t, err)
+
+	// The copy must not share a file that is removed when either subscription is deleted
+	duplicate, err := s.Duplicate(uploaded.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, duplicate.IconURL)
+
+	// Cached favicons are shared anyway, so they are kept
+	duplicate, err = s.Duplicate(cached.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, cached.IconURL, duplicate.IconURL)
+}
+
+func TestSubscriptionService_GetMyShare(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	seed := []models.Subscription{
+		{Name: "Family Plan", Cost: 24, Schedule: "Monthly", Status: "Active", SharedWith: 4},
+		{Name: "Streaming", Cost: 120, Schedule: "Annual", Status: "Active", SharedWith: 2},
+		{Name: "Gym", Cost: 30, Schedule: "Monthly", Status: "Active"},
+		{Name: "Old Shared", Cost: 50, Schedule: "Monthly", Status: "Cancelled", SharedWith: 5},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	stat, err := s.GetMyShare()
+	assert.NoError(t, err)
+	assert.InDelta(t, 6+5+30, stat.MyMonthlyShare, 0.001)
+	assert.InDelta(t, 24+10+30, stat.TotalMonthlySpend, 0.001)
+	assert.Equal(t, 2, stat.SharedCount)
+}
+
+func TestSubscriptionService_GetStatsFiltered(t *testing.T) {
+	s, cs := setupSubscriptionServiceTest(t)
+
+	work, err := cs.Create(&models.Category{Name: "Work"})
+	assert.NoError(t, err)
+	home, err := cs.Create(&models.Category{Name: "Home"})
+	assert.NoError(t, err)
+	empty, err := cs.Create(&models.Category{Name: "Empty"})
+	assert.NoError(t, err)
+
+	seed := []models.Subscription{
+		{Name: "Slack", Cost: 8, Schedule: "Monthly", Status: "Active", CategoryID: work.ID},
+		{Name: "JetBrains", Cost: 240, Schedule: "Annual", Status: "Active", CategoryID: work.ID, Usage: "Low"},
+		{Name: "Zoom", Cost: 15, Schedule: "Monthly", Status: "Cancelled", CategoryID: work.ID},
+		{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active", CategoryID: home.ID},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	stats, err := s.GetStatsFiltered(models.StatsFilter{CategoryID: work.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.ActiveSubscriptions)
+	assert.Equal(t, 1, stats.CancelledSubscriptions)
+	assert.InDelta(t, 28, stats.TotalMonthlySpend, 0.001)
+	assert.InDelta(t, 336, stats.TotalAnnualSpend, 0.001)
+	assert.InDelta(t, 20, stats.PotentialSavings, 0.001)
+	assert.InDelta(t, 15, stats.MonthlySaved, 0.001)
+	assert.Equal(t, "JetBrains", stats.MostExpensive.Name)
+	assert.Equal(t, map[string]float64{"Work": 28}, stats.CategorySpending)
+
+	// The unscoped stats still cover every category
+	all, err := s.GetStats()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, all.ActiveSubscriptions)
+	assert.InDelta(t, 43, all.TotalMonthlySpend, 0.001)
+
+	none, err := s.GetStatsFiltered(models.StatsFilter{CategoryID: empty.ID})
+	assert.NoError(t, err)
+	assert.Zero(t, none.ActiveSubscriptions)
+	assert.Zero(t, none.TotalMonthlySpend)
+	assert.Zero(t, none.AverageMonthlyCost)
+	assert.Nil(t, none.MostExpensive)
+	assert.Empty(t, none.CategorySpending)
+}
+
+func TestSubscriptionService_GetStats_IncludeTrials(t *testing.T) {
+	db := setupRenewalReminderTestDB(t)
+	settings := NewSettingsService(repository.NewSettingsRepository(db))
+	s := NewSubscriptionService(repository.NewSubscriptionRepository(db), nil)
+	s.SetSettingsService(settings)
+
+	seed := []models.Subscription{
+		{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active"},
+		{Name: "Notion", Cost: 120, Schedule: "Annual", Status: "Trial"},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	stats, err := s.GetStats()
+	assert.NoError(t, err)
+	assert.InDelta(t, 15, stats.TotalMonthlySpend, 0.001, "trials are excluded by default")
+	assert.InDelta(t, 180, stats.TotalAnnualSpend, 0.001)
+	assert.InDelta(t, 300, stats.ProjectedAnnualSpendIncludingTrials, 0.001)
+	assert.InDelta(t, 15, stats.AverageMonthlyCost, 0.001)
+	assert.InDelta(t, 15, stats.CategorySpending[""], 0.001)
+
+	assert.NoError(t, settings.SetTrialSpendIncluded(true))
+	stats, err = s.GetStats()
+	assert.NoError(t, err)
+	assert.InDelta(t, 25, stats.TotalMonthlySpend, 0.001)
+	assert.InDelta(t, 300, stats.TotalAnnualSpend, 0.001)
+	assert.InDelta(t, 300, stats.ProjectedAnnualSpendIncludingTrials, 0.001, "trials aren't counted twice")
+	assert.InDelta(t, 12.5, stats.AverageMonthlyCost, 0.001)
+	assert.InDelta(t, 25, stats.CategorySpending[""], 0.001, "category spending uses the same subscriptions as the totals")
+	assert.Equal(t, 1, stats.ActiveSubscriptions)
+	assert.Equal(t, 1, stats.TrialSubscriptions)
+}
+
+func TestSubscriptionService_GetLowUsageSubscriptions(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	seed := []models.Subscription{
+		{Name: "Gym", Cost: 40, Schedule: "Monthly", Status: "Active", Usage: "None"},
+		{Name: "Magazine", Cost: 60, Schedule: "Annual", Status: "Active", Usage: "Low"},
+		{Name: "Streaming", Cost: 120, Schedule: "Annual", Status: "Active", Usage: "Low"},
+		{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active", Usage: "High"},
+		{Name: "Unrated", Cost: 9, Schedule: "Monthly", Status: "Active"},
+		{Name: "Old", Cost: 20, Schedule: "Monthly", Status: "Cancelled", Usage: "None"},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	subs, err := s.GetLowUsageSubscriptions()
+	assert.NoError(t, err)
+	names := make([]string, len(subs))
+	for i, sub := range subs {
+		names[i] = sub.Name
+	}
+	assert.Equal(t, []string{"Gym", "Streaming", "Magazine"}, names)
+
+	stats, err := s.GetStats()
+	assert.NoError(t, err)
+	assert.InDelta(t, 55, stats.PotentialSavings, 0.001)
+}
+
+func TestSubscriptionService_GetStats(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	stats, err := s.GetStats()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.ActiveSubscriptions)
+	assert.Zero(t, stats.AverageMonthlyCost)
+	assert.Zero(t, stats.AverageAnnualCost)
+	assert.Nil(t, stats.MostExpensive)
+
+	seed := []models.Subscription{
+		{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active"},
+		{Name: "JetBrains", Cost: 300, Schedule: "Annual", Status: "Active"},
+		{Name: "Gym", Cost: 40, Schedule: "Monthly", Status: "Active"},
+		{Name: "Hulu", Cost: 8, Schedule: "Monthly", Status: "Cancelled"},
+		{Name: "Figma", Cost: 20, Schedule: "Monthly", Status: "Trial"},
+		{Name: "Spotify", Cost: 12, Schedule: "Monthly", Status: "Paused"},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	stats, err = s.GetStats()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats.ActiveSubscriptions)
+	assert.Equal(t, 1, stats.CancelledSubscriptions)
+	assert.Equal(t, 1, stats.PausedSubscriptions)
+	assert.Equal(t, 1, stats.TrialSubscriptions)
+	assert.InDelta(t, 80, stats.TotalMonthlySpend, 0.001)
+	assert.InDelta(t, 960, stats.TotalAnnualSpend, 0.001)
+	assert.InDelta(t, 960+240, stats.ProjectedAnnualSpendIncludingTrials, 0.001)
+	assert.InDelta(t, 80.0/3, stats.AverageMonthlyCost, 0.001)
+	assert.InDelta(t, 320, stats.AverageAnnualCost, 0.001)
+	if assert.NotNil(t, stats.MostExpensive) {
+		assert.Equal(t, "Gym", stats.MostExpensive.Name)
+		assert.Equal(t, seed[2].ID, stats.MostExpensive.ID)
+		assert.InDelta(t, 40, stats.MostExpensive.MonthlyCost, 0.001)
+	}
+}
+
+func TestSubscriptionService_GetStats_ArchivedIsNotSavings(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	seed := []models.Subscription{
+		{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active"},
+		{Name: "Hulu", Cost: 8, Schedule: "Monthly", Status: "Cancelled"},
+		{Name: "Old Domain", Cost: 12, Schedule: "Annual", Status: "Archived"},
+		{Name: "Stadia", Cost: 10, Schedule: "Monthly", Status: "Archived"},
+	}
+	createSubscriptions(t, s, seed)
+
+	stats, err := s.GetStats()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.ActiveSubscriptions)
+	assert.Equal(t, 1, stats.CancelledSubscriptions)
+	assert.Equal(t, 2, stats.ArchivedSubscriptions)
+	assert.InDelta(t, 15, stats.TotalMonthlySpend, 0.001, "Archived subscriptions aren't spend")
+	assert.InDelta(t, 8, stats.MonthlySaved, 0.001, "Only cancelled subscriptions count as savings")
+	assert.InDelta(t, 96, stats.TotalSaved, 0.001)
+}
+
+func TestCategoryService_Color(t *testing.T) {
+	_, cs := setupSubscriptionServiceTest(t)
+
+	defaulted, err := cs.Create(&models.Category{Name: "Streaming"})
+	assert.NoError(t, err)
+	assert.Equal(t, models.DefaultCategoryColor("Streaming"), defaulted.Color)
+	assert.True(t, models.IsValidCategoryColor(defaulted.Color))
+
+	custom, err := cs.Create(&models.Category{Name: "Software", Color: " #AABBCC ", Icon: "💻"})
+	assert.NoError(t, err)
+	assert.Equal(t, "#aabbcc", custom.Color)
+	assert.Equal(t, "💻", custom.Icon)
+
+	for _, color := range []string{"red", "#abc", "#abcdeg", "aabbcc"} {
+		_, err := cs.Create(&models.Category{Name: "Bad " + color, Color: color})
+		assert.ErrorIs(t, err, ErrInvalidCategoryColor, color)
+		_, err = cs.Update(custom.ID, &models.Category{Color: color})
+		assert.ErrorIs(t, err, ErrInvalidCategoryColor, color)
+	}
+
+	// An empty color on update keeps the stored one
+	updated, err := cs.Update(custom.ID, &models.Category{Icon: "🛠"})
+	assert.NoError(t, err)
+	assert.Equal(t, "#aabbcc", updated.Color)
+	assert.Equal(t, "🛠", updated.Icon)
+}
+
+func TestCategoryService_Reorder(t *testing.T) {
+	_, cs := setupSubscriptionServiceTest(t)
+
+	names := func() []string {
+		categories, err := cs.GetAll()
+		assert.NoError(t, err)
+		out := make([]string, 0, len(categories))
+		for _, cat := range categories {
+			out = append(out, cat.Name)
+		}
+		return out
+	}
+
+	var ids []uint
+	for _, name := range []string{"Streaming", "Cloud", "Utilities", "Gaming"} {
+		cat, err := cs.Create(&models.Category{Name: name})
+		assert.NoError(t, err)
+		ids = append(ids, cat.ID)
+	}
+	assert.Equal(t, []string{"Streaming", "Cloud", "Utilities", "Gaming"}, names(), "New categories go to the end")
+
+	assert.NoError(t, cs.Reorder([]uint{ids[3], ids[1]}))
+	assert.Equal(t, []string{"Gaming", "Cloud", "Streaming", "Utilities"}, names(), "Unlisted categories follow in their previous order")
+
+	_, err := cs.Create(&models.Category{Name: "Audio"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Audio", names()[4])
+
+	assert.ErrorIs(t, cs.Reorder([]uint{ids[0], 9999}), gorm.ErrRecordNotFound)
+	assert.Equal(t, []string{"Gaming", "Cloud", "Streaming", "Utilities", "Audio"}, names(), "A rejected reorder changes nothing")
+}
+
+func TestCategoryService_MergeCategory(t *testing.T) {
+	s, cs := setupSubscriptionServiceTest(t)
+
+	streaming, err := cs.Create(&models.Category{Name: "Streaming"})
+	assert.NoError(t, err)
+	entertainment, err := cs.Create(&models.Category{Name: "Entertainment"})
+	assert.NoError(t, err)
+
+	seed := []models.Subscription{
+		{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active", CategoryID: streaming.ID},
+		{Name: "Hulu", Cost: 10, Schedule: "Monthly", Status: "Cancelled", CategoryID: streaming.ID},
+		{Name: "Concerts", Cost: 20, Schedule: "Monthly", Status: "Active", CategoryID: entertainment.ID},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	_, err = cs.MergeCategory(streaming.ID, streaming.ID)
+	assert.ErrorIs(t, err, ErrMergeIntoSelf)
+	_, err = cs.MergeCategory(streaming.ID, 9999)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	moved, err := cs.MergeCategory(streaming.ID, entertainment.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), moved)
+
+	_, err = cs.GetByID(streaming.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Source category should be deleted")
+	for _, sub := range seed {
+		got, err := s.GetByID(sub.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, entertainment.ID, got.CategoryID, sub.Name)
+	}
+}
+
+func TestCategoryService_Delete(t *testing.T) {
+	s, cs := setupSubscriptionServiceTest(t)
+
+	music, err := cs.Create(&models.Category{Name: "Music"})
+	assert.NoError(t, err)
+	other, err := cs.Create(&models.Category{Name: "Other"})
+	assert.NoError(t, err)
+	unused, err := cs.Create(&models.Category{Name: "Unused"})
+	assert.NoError(t, err)
+
+	for _, sub := range []models.Subscription{
+		{Name: "Spotify", Cost: 10, Schedule: "Monthly", Status: "Active", CategoryID: music.ID},
+		{Name: "Tidal", Cost: 11, Schedule: "Monthly", Status: "Cancelled", CategoryID: music.ID},
+	} {
+		_, err := s.Create(&sub)
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, cs.Delete(unused.ID))
+
+	var inUse *CategoryInUseError
+	err = cs.Delete(music.ID)
+	if assert.ErrorAs(t, err, &inUse) {
+		assert.Equal(t, int64(2), inUse.Subscriptions)
+		assert.Contains(t, err.Error(), "2 subscription(s)")
+	}
+	_, err = cs.GetByID(music.ID)
+	assert.NoError(t, err, "Blocked delete should keep the category")
+
+	moved, err := cs.DeleteAndReassign(music.ID, other.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), moved)
+	_, err = cs.GetByID(music.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	subs, err := s.GetAll()
+	assert.NoError(t, err)
+	for _, sub := range subs {
+		assert.Equal(t, other.ID, sub.CategoryID, sub.Name)
+	}
+}
+
+func TestSubscriptionService_GetCategoryStats(t *testing.T) {
+	s, cs := setupSubscriptionServiceTest(t)
+
+	streaming, err := cs.Create(&models.Category{Name: "Streaming", Color: "#ff0000", Icon: "🎬"})
+	assert.NoError(t, err)
+
+	seed := []models.Subscription{
+		{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active", CategoryID: streaming.ID},
+		{Name: "Hulu", Cost: 10, Schedule: "Monthly", Status: "Active", CategoryID: streaming.ID},
+		{Name: "Domain", Cost: 12, Schedule: "Annual", Status: "Active"},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	stats, err := s.GetCategoryStats()
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, "Streaming", stats[0].Category)
+		assert.Equal(t, "#ff0000", stats[0].Color)
+		assert.Equal(t, "🎬", stats[0].Icon)
+		assert.InDelta(t, 25, stats[0].Amount, 0.001)
+		assert.Equal(t, 2, stats[0].Count)
+
+		assert.Equal(t, "Uncategorized", stats[1].Category)
+		assert.Equal(t, models.DefaultCategoryColor("Uncategorized"), stats[1].Color)
+		assert.InDelta(t, 1, stats[1].Amount, 0.001)
+	}
+}
+
+func TestYearSpend(t *testing.T) {
+	date := func(y int, m time.Month, d int) *time.Time {
+		v := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return &v
+	}
+	now := time.Date(2025, 4, 15, 12, 0, 0, 0, time.UTC)
+
+	active := []models.Subscription{
+		// Jan 10 .. Dec 10: 4 billed, 12 total
+		{Name: "Monthly", Cost: 10, Schedule: "Monthly", Status: "Active", StartDate: date(2024, 6, 10)},
+		// Billed on Sep 1 only
+		{Name: "Annual", Cost: 100, Schedule: "Annual", Status: "Active", StartDate: date(2023, 9, 1)},
+		// Started in March: Mar 1, Apr 1 billed, 10 total
+		{Name: "New", Cost: 5, Schedule: "Monthly", Status: "Active", StartDate: date(2025, 3, 1)},
+		// No start date: assumed active from Jan 1, quarterly
+		{Name: "Undated", Cost: 30, Schedule: "Quarterly", Status: "Active"},
+	}
+	cancelled := []models.Subscription{
+		// Jan 20, Feb 20 billed before cancellation
+		{Name: "Gone", Cost: 7, Schedule: "Monthly", Status: "Cancelled", StartDate: date(2024, 1, 20), CancellationDate: date(2025, 3, 1)},
+		// Cancelled last year
+		{Name: "Old", Cost: 50, Schedule: "Monthly", Status: "Cancelled", StartDate: date(2023, 1, 1), CancellationDate: date(2024, 12, 1)},
+	}
+
+	ytd, projected := yearSpend(active, cancelled, now)
+	assert.InDelta(t, 4*10+0+2*5+2*30+2*7, ytd, 0.001)
+	assert.InDelta(t, 12*10+100+10*5+4*30+2*7, projected, 0.001)
+}
+
+func TestCategoryTrend(t *testing.T) {
+	date := func(y int, m time.Month, d int) *time.Time {
+		v := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return &v
+	}
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	streaming := models.Category{Name: "Streaming"}
+	software := models.Category{Name: "Software"}
+
+	subs := []models.Subscription{
+		{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active", Category: streaming, StartDate: date(2024, 1, 1)},
+		{Name: "Hulu", Cost: 8, Schedule: "Monthly", Status: "Cancelled", Category: streaming, StartDate: date(2024, 1, 1), CancellationDate: date(2025, 4, 10)},
+		{Name: "JetBrains", Cost: 240, Schedule: "Annual", Status: "Active", Category: software, StartDate: date(2025, 5, 3)},
+		{Name: "Misc", Cost: 3, Schedule: "Monthly", Status: "Active"},
+		{Name: "Paused", Cost: 50, Schedule: "Monthly", Status: "Paused", Category: software},
+	}
+
+	trend := categoryTrend(subs, 3, now)
+	assert.Equal(t, []string{"2025-04", "2025-05", "2025-06"}, trend.Months)
+	assert.Equal(t, map[string][]float64{
+		"Streaming":     {23, 15, 15},
+		"Software":      {0, 20, 20},
+		"Uncategorized": {3, 3, 3},
+	}, trend.Categories)
+}
+
+func TestSubscriptionService_GetSavingsTimeline(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	twoMonthsAgo := monthStart.AddDate(0, -2, 5)
+	thisMonth := monthStart.AddDate(0, 0, 1)
+	longAgo := monthStart.AddDate(-2, 0, 0)
+
+	seed := []models.Subscription{
+		{Name: "Gym", Cost: 40, Schedule: "Monthly", Status: "Cancelled", CancellationDate: &twoMonthsAgo},
+		{Name: "Magazine", Cost: 120, Schedule: "Annual", Status: "Cancelled", CancellationDate: &thisMonth},
+		{Name: "Ancient", Cost: 5, Schedule: "Monthly", Status: "Cancelled", CancellationDate: &longAgo},
+		{Name: "Undated", Cost: 99, Schedule: "Monthly", Status: "Cancelled"},
+		{Name: "Netflix", Cost: 15, Schedule: "Monthly", Status: "Active"},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	timeline, err := s.GetSavingsTimeline(3)
+	assert.NoError(t, err)
+	assert.Len(t, timeline, 3)
+	assert.Equal(t, monthStart.AddDate(0, -2, 0).Format("2006-01"), timeline[0].Month)
+	assert.Equal(t, monthStart.Format("2006-01"), timeline[2].Month)
+
+	assert.InDelta(t, 45, timeline[0].Saved, 0.001)
+	assert.Equal(t, 2, timeline[0].Count)
+	assert.InDelta(t, 45, timeline[1].Saved, 0.001)
+	assert.InDelta(t, 55, timeline[2].Saved, 0.001)
+	assert.Equal(t, 3, timeline[2].Count)
+}
+
+func TestSubscriptionService_GetRenewalsInMonth(t *testing.T) {
+	s, _ := setupSubscriptionServiceTest(t)
+
+	month := time.Now().AddDate(0, 2, 0)
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	mid := first.AddDate(0, 0, 14)
+	last := first.AddDate(0, 1, 0).Add(-time.Minute)
+	nextMonth := first.AddDate(0, 1, 0)
+
+	seed := []models.Subscription{
+		{Name: "Late", Cost: 5, Schedule: "Monthly", Status: "Active", RenewalDate: &last},
+		{Name: "Early", Cost: 5, Schedule: "Monthly", Status: "Active", RenewalDate: &first},
+		{Name: "Mid", Cost: 5, Schedule: "Annual", Status: "Active", RenewalDate: &mid},
+		{Name: "Next month", Cost: 5, Schedule: "Monthly", Status: "Active", RenewalDate: &nextMonth},
+		{Name: "Paused", Cost: 5, Schedule: "Monthly", Status: "Paused", RenewalDate: &mid},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	subs, err := s.GetRenewalsInMonth(first.Year(), int(first.Month()))
+	assert.NoError(t, err)
+	names := make([]string, len(subs))
+	for i, sub := range subs {
+		names[i] = sub.Name
+	}
+	assert.Equal(t, []string{"Early", "Mid", "Late"}, names)
+}
+
+func TestSubscriptionService_GetRenewalsInMonth_TimeZone(t *testing.T) {
+	db := setupRenewalReminderTestDB(t)
+	s := NewSubscriptionService(repository.NewSubscriptionRepository(db), NewCategoryService(repository.NewCategoryRepository(db)))
+	settings := NewSettingsService(repository.NewSettingsRepository(db))
+	s.SetSettingsService(settings)
+	t.Cleanup(func() { models.SetLocation(time.UTC) })
+	assert.NoError(t, settings.SetTimezone("Pacific/Auckland"))
+
+	// Auckland is UTC+13 in January: these fall in a different month there than in UTC
+	startOfJan := time.Date(2030, 12, 31, 11, 30, 0, 0, time.UTC) // Jan 1 00:30 in Auckland
+	startOfFeb := time.Date(2031, 1, 31, 12, 0, 0, 0, time.UTC)   // Feb 1 01:00 in Auckland
+	seed := []models.Subscription{
+		{Name: "New year", Cost: 5, Schedule: "Annual", Status: "Active", RenewalDate: &startOfJan},
+		{Name: "February", Cost: 5, Schedule: "Annual", Status: "Active", RenewalDate: &startOfFeb},
+	}
+	for i := range seed {
+		_, err := s.Create(&seed[i])
+		assert.NoError(t, err)
+	}
+
+	jan, err := s.GetRenewalsInMonth(2031, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, jan, 1) {
+		assert.Equal(t, "New year", jan[0].Name)
+	}
+
+	feb, err := s.GetRenewalsInMonth(2031, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, feb, 1) {
+		assert.Equal(t, "February", feb[0].Name)
+	}
+}
+
+func TestSubscriptionService_Create_StampsDateCalculationVersion(t *testing.T) {
+	db := setupRenewalReminderTestDB(t)
+	s := NewSubscriptionService(repository.NewSubscriptionRepository(db), NewCategoryService(repository.NewCategoryRepository(db)))
+	settings := NewSettingsService(repository.NewSettingsRepository(db))
+	s.SetSettingsService(settings)
+
+	// Feb 29 renews on Feb 28 in common years under V2, instead of overflowing into March
+	leapDay := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	created, err := s.Create(&models.Subscription{Name: "Default", Cost: 10, Schedule: "Annual", Status: "Active", StartDate: &leapDay})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultDateCalculationVersion, created.DateCalculationVersion)
+	if assert.NotNil(t, created.RenewalDate) {
+		assert.Equal(t, time.February, created.RenewalDate.Month())
+		assert.Equal(t, 28, created.RenewalDate.Day())
+	}
+
+	assert.NoError(t, settings.SetDateCalculationVersion(1))
+	created, err = s.Create(&models.Subscription{Name: "Legacy", Cost: 10, Schedule: "Monthly", Status: "Active"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created.DateCalculationVersion)
+
+	// An explicit version is kept
+	created, err = s.Create(&models.Subscription{Name: "Explicit", Cost: 10, Schedule: "Monthly", Status: "Active", DateCalculationVersion: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, created.DateCalculationVersion)
+
+	assert.Error(t, settings.SetDateCalculationVersion(3))
+}
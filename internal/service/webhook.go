@@ -2,9 +2,12 @@ package service
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"subtrackr/internal/metrics"
 	"subtrackr/internal/models"
 	"time"
 )
@@ -32,29 +35,37 @@ type WebhookPayload struct {
 
 // WebhookSubscription is a simplified subscription for webhook payloads
 type WebhookSubscription struct {
-	ID               uint    `json:"id"`
-	Name             string  `json:"name"`
-	Cost             float64 `json:"cost"`
-	Currency         string  `json:"currency"`
-	CurrencySymbol   string  `json:"currency_symbol"`
-	Schedule         string  `json:"schedule"`
-	MonthlyCost      float64 `json:"monthly_cost"`
-	Category         string  `json:"category,omitempty"`
-	URL              string  `json:"url,omitempty"`
-	RenewalDate      string  `json:"renewal_date,omitempty"`
-	CancellationDate string  `json:"cancellation_date,omitempty"`
+	ID                uint     `json:"id"`
+	Name              string   `json:"name"`
+	Cost              float64  `json:"cost"`
+	Currency          string   `json:"currency"`
+	CurrencySymbol    string   `json:"currency_symbol"`
+	Schedule          string   `json:"schedule"`
+	MonthlyCost       float64  `json:"monthly_cost"`
+	NextChargeAmount  *float64 `json:"next_charge_amount,omitempty"`
+	FirstChargeAmount *float64 `json:"first_charge_amount,omitempty"` // Set while the prorated first charge is pending
+	FirstChargeDate   string   `json:"first_charge_date,omitempty"`
+	Category          string   `json:"category,omitempty"`
+	URL               string   `json:"url,omitempty"`
+	CancellationURL   string   `json:"cancellation_url,omitempty"`
+	RenewalDate       string   `json:"renewal_date,omitempty"`
+	CancellationDate  string   `json:"cancellation_date,omitempty"`
 }
 
 func subscriptionToWebhook(sub *models.Subscription, settings *SettingsService) *WebhookSubscription {
-	currencySymbol := currencySymbolForSubscription(sub, settings)
+	currency := sub.OriginalCurrency
+	if currency == "" {
+		currency = settings.GetCurrency()
+	}
 	ws := &WebhookSubscription{
-		ID:             sub.ID,
-		Name:           sub.Name,
-		Cost:           sub.Cost,
-		Currency:       sub.OriginalCurrency,
-		CurrencySymbol: currencySymbol,
-		Schedule:       sub.Schedule,
-		MonthlyCost:    sub.MonthlyCost(),
+		ID:               sub.ID,
+		Name:             sub.Name,
+		Cost:             sub.Cost,
+		Currency:         sub.OriginalCurrency,
+		CurrencySymbol:   CurrencySymbolForCode(currency),
+		Schedule:         sub.Schedule,
+		MonthlyCost:      sub.MonthlyCost(),
+		NextChargeAmount: sub.NextChargeAmount,
 	}
 	if sub.Category.Name != "" {
 		ws.Category = sub.Category.Name
@@ -62,12 +73,19 @@ func subscriptionToWebhook(sub *models.Subscription, settings *SettingsService)
 	if sub.URL != "" {
 		ws.URL = sub.URL
 	}
+	ws.CancellationURL = sub.CancellationURL
 	dateFormat := settings.GetGoDateFormat()
+	loc := settings.GetLocation()
 	if sub.RenewalDate != nil {
-		ws.RenewalDate = sub.RenewalDate.Format(dateFormat)
+		ws.RenewalDate = sub.RenewalDate.In(loc).Format(dateFormat)
 	}
 	if sub.CancellationDate != nil {
-		ws.CancellationDate = sub.CancellationDate.Format(dateFormat)
+		ws.CancellationDate = sub.CancellationDate.In(loc).Format(dateFormat)
+	}
+	if sub.PendingFirstCharge(time.Now()) {
+		firstChargeCost := sub.FirstChargeCost()
+		ws.FirstChargeAmount = &firstChargeCost
+		ws.FirstChargeDate = sub.FirstChargeDate.In(loc).Format(dateFormat)
 	}
 	return ws
 }
@@ -79,6 +97,38 @@ func (w *WebhookService) SendWebhook(payload *WebhookPayload) error {
 		return nil // Not configured, silently skip (matches email/pushover behavior)
 	}
 
+	if err := w.deliver(config, payload); err != nil {
+		metrics.WebhookFailures.Inc()
+		return err
+	}
+	return nil
+}
+
+// Bounds for how long a webhook request may take
+const (
+	DefaultWebhookTimeout    = 10 * time.Second
+	MaxWebhookTimeoutSeconds = 120
+)
+
+// webhookClient builds an HTTP client for config's timeout and TLS settings
+func webhookClient(config *models.WebhookConfig) *http.Client {
+	timeout := DefaultWebhookTimeout
+	if config.TimeoutSeconds > 0 {
+		timeout = time.Duration(min(config.TimeoutSeconds, MaxWebhookTimeoutSeconds)) * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
+	if config.InsecureSkipVerify {
+		// Opted into for internal receivers with self-signed certificates
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		client.Transport = transport
+	}
+	return client
+}
+
+// deliver POSTs the payload to the webhook URL with the configured headers
+func (w *WebhookService) deliver(config *models.WebhookConfig, payload *WebhookPayload) error {
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal webhook payload: %w", err)
@@ -96,8 +146,7 @@ func (w *WebhookService) SendWebhook(payload *WebhookPayload) error {
 		req.Header.Set(key, value)
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := webhookClient(config).Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send webhook: %w", err)
 	}
@@ -110,18 +159,27 @@ func (w *WebhookService) SendWebhook(payload *WebhookPayload) error {
 	return nil
 }
 
+// Skips reports whether event won't be sent through the webhook, because the event
+// or the channel is turned off or no webhook is configured
+func (w *WebhookService) Skips(event string) bool {
+	if !eventEnabled(w.settingsService, event) || !w.settingsService.IsNotificationChannelEnabled(NotificationChannelWebhook) {
+		return true
+	}
+	config, err := w.settingsService.GetWebhookConfig()
+	return err != nil || config.URL == "" // Not configured
+}
+
 // SendHighCostAlert sends a webhook alert when a high-cost subscription is created
 func (w *WebhookService) SendHighCostAlert(subscription *models.Subscription) error {
-	enabled, err := w.settingsService.GetBoolSetting("high_cost_alerts", true)
-	if err != nil || !enabled {
+	if w.Skips(NotificationEventHighCost) {
 		return nil
 	}
 
-	currencySymbol := currencySymbolForSubscription(subscription, w.settingsService)
+	basisLabel, basisAmount := highCostAmount(subscription, w.settingsService)
 	payload := &WebhookPayload{
 		Event:        "high_cost_alert",
 		Title:        fmt.Sprintf("High Cost Alert: %s", subscription.Name),
-		Message:      fmt.Sprintf("A new high-cost subscription has been added: %s at %s%.2f %s", subscription.Name, currencySymbol, subscription.Cost, subscription.Schedule),
+		Message:      fmt.Sprintf("A new high-cost subscription has been added: %s at %s %s (%s: %s)", subscription.Name, formatMoneyForSubscription(subscription.Cost, subscription, w.settingsService), subscription.Schedule, strings.ToLower(basisLabel), formatMoneyForSubscription(basisAmount, subscription, w.settingsService)),
 		Subscription: subscriptionToWebhook(subscription, w.settingsService),
 		Timestamp:    time.Now().UTC().Format(time.RFC3339),
 	}
@@ -131,8 +189,7 @@ func (w *WebhookService) SendHighCostAlert(subscription *models.Subscription) er
 
 // SendRenewalReminder sends a webhook reminder for an upcoming subscription renewal
 func (w *WebhookService) SendRenewalReminder(subscription *models.Subscription, daysUntilRenewal int) error {
-	enabled, err := w.settingsService.GetBoolSetting("renewal_reminders", false)
-	if err != nil || !enabled {
+	if w.Skips(NotificationEventRenewal) {
 		return nil
 	}
 
@@ -153,8 +210,7 @@ func (w *WebhookService) SendRenewalReminder(subscription *models.Subscription,
 
 // SendCancellationReminder sends a webhook reminder for an upcoming subscription cancellation
 func (w *WebhookService) SendCancellationReminder(subscription *models.Subscription, daysUntilCancellation int) error {
-	enabled, err := w.settingsService.GetBoolSetting("cancellation_reminders", false)
-	if err != nil || !enabled {
+	if w.Skips(NotificationEventCancellation) {
 		return nil
 	}
 
@@ -1,11 +1,18 @@
 package service
 
 import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"subtrackr/internal/metrics"
 	"subtrackr/internal/models"
 	"subtrackr/internal/repository"
 	"testing"
 	"time"
+	"unicode/utf8"
 
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -58,6 +65,31 @@ func TestWebhookService_SendWebhook_EmptyURL(t *testing.T) {
 	assert.NoError(t, err, "Should silently skip when webhook URL is empty")
 }
 
+func TestWebhookService_SendWebhook_CountsFailures(t *testing.T) {
+	ss, ws := setupWebhookTestDB(t)
+
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	assert.NoError(t, ss.SaveWebhookConfig(&models.WebhookConfig{URL: server.URL}))
+
+	failures := func() float64 {
+		var m dto.Metric
+		assert.NoError(t, metrics.WebhookFailures.Write(&m))
+		return m.GetCounter().GetValue()
+	}
+	before := failures()
+
+	assert.NoError(t, ws.SendWebhook(&WebhookPayload{Event: "test"}))
+	assert.Equal(t, before, failures())
+
+	status = http.StatusInternalServerError
+	assert.EqualError(t, ws.SendWebhook(&WebhookPayload{Event: "test"}), "webhook returned status 500")
+	assert.Equal(t, before+1, failures())
+}
+
 func TestWebhookService_SendHighCostAlert_Disabled(t *testing.T) {
 	ss, ws := setupWebhookTestDB(t)
 
@@ -91,6 +123,30 @@ func TestWebhookService_SendHighCostAlert_EnabledNoConfig(t *testing.T) {
 	assert.NoError(t, err, "Should silently skip when webhook is not configured")
 }
 
+func TestWebhookService_SendHighCostAlert_ChannelDisabled(t *testing.T) {
+	ss, ws := setupWebhookTestDB(t)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+	assert.NoError(t, ss.SaveWebhookConfig(&models.WebhookConfig{URL: server.URL}))
+	ss.SetBoolSetting("high_cost_alerts", true)
+	ss.SetNotificationChannelEnabled(NotificationChannelWebhook, false)
+
+	sub := &models.Subscription{
+		Name:     "Test Sub",
+		Cost:     100.00,
+		Schedule: "Monthly",
+		Category: models.Category{Name: "Test"},
+	}
+
+	err := ws.SendHighCostAlert(sub)
+	assert.NoError(t, err, "Should return nil when the webhook channel is disabled")
+	assert.Zero(t, requests, "Should not call the webhook when the channel is disabled")
+}
+
 func TestWebhookService_SendRenewalReminder_Disabled(t *testing.T) {
 	ss, ws := setupWebhookTestDB(t)
 
@@ -185,6 +241,7 @@ func TestSubscriptionToWebhook(t *testing.T) {
 		Schedule:         "Monthly",
 		Category:         models.Category{Name: "Entertainment"},
 		URL:              "https://netflix.com",
+		CancellationURL:  "https://netflix.com/cancelplan",
 		RenewalDate:      &renewalDate,
 		CancellationDate: &cancellationDate,
 	}
@@ -200,6 +257,7 @@ func TestSubscriptionToWebhook(t *testing.T) {
 	assert.Equal(t, "Monthly", ws.Schedule)
 	assert.Equal(t, "Entertainment", ws.Category)
 	assert.Equal(t, "https://netflix.com", ws.URL)
+	assert.Equal(t, "https://netflix.com/cancelplan", ws.CancellationURL)
 	assert.NotEmpty(t, ws.RenewalDate)
 	assert.NotEmpty(t, ws.CancellationDate)
 }
@@ -230,6 +288,7 @@ func TestSubscriptionToWebhook_MinimalFields(t *testing.T) {
 	assert.Equal(t, 5.00, ws.Cost)
 	assert.Empty(t, ws.Category, "Category should be empty when not set")
 	assert.Empty(t, ws.URL, "URL should be empty when not set")
+	assert.Empty(t, ws.CancellationURL, "CancellationURL should be empty when not set")
 	assert.Empty(t, ws.RenewalDate, "RenewalDate should be empty when nil")
 	assert.Empty(t, ws.CancellationDate, "CancellationDate should be empty when nil")
 }
@@ -264,3 +323,74 @@ func TestWebhookService_SendRenewalReminder_DaysText(t *testing.T) {
 		})
 	}
 }
+
+func TestWebhookService_SendHighCostAlert_KeepsMultiByteSymbols(t *testing.T) {
+	tests := []struct {
+		currency string
+		symbol   string
+	}{
+		{"EUR", "€"},
+		{"INR", "₹"},
+		{"PLN", "zł"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			ss, ws := setupWebhookTestDB(t)
+			ss.SetCurrency("USD")
+			ss.SetBoolSetting("high_cost_alerts", true)
+
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+			}))
+			defer server.Close()
+			assert.NoError(t, ss.SaveWebhookConfig(&models.WebhookConfig{URL: server.URL}))
+
+			sub := &models.Subscription{Name: "Streaming", Cost: 15.99, OriginalCurrency: tt.currency, Schedule: "Monthly"}
+			assert.NoError(t, ws.SendHighCostAlert(sub))
+
+			assert.True(t, utf8.Valid(body), "The payload should be valid UTF-8")
+			assert.Contains(t, string(body), `"currency_symbol":"`+tt.symbol+`"`, "The symbol should be sent as UTF-8, not escaped or re-encoded")
+
+			var payload WebhookPayload
+			if assert.NoError(t, json.Unmarshal(body, &payload)) {
+				assert.Equal(t, tt.symbol, payload.Subscription.CurrencySymbol)
+				assert.Contains(t, payload.Message, tt.symbol)
+			}
+		})
+	}
+}
+
+func TestWebhookService_SendWebhook_InsecureSkipVerify(t *testing.T) {
+	ss, ws := setupWebhookTestDB(t)
+
+	received := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+	}))
+	defer server.Close()
+	payload := &WebhookPayload{Event: "test", Title: "SubTrackr Test"}
+
+	t.Run("Rejects a self-signed certificate by default", func(t *testing.T) {
+		assert.NoError(t, ss.SaveWebhookConfig(&models.WebhookConfig{URL: server.URL}))
+		err := ws.SendWebhook(payload)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "certificate")
+		}
+		assert.Equal(t, 0, received)
+	})
+
+	t.Run("Accepts it when verification is skipped", func(t *testing.T) {
+		assert.NoError(t, ss.SaveWebhookConfig(&models.WebhookConfig{URL: server.URL, InsecureSkipVerify: true}))
+		assert.NoError(t, ws.SendWebhook(payload))
+		assert.Equal(t, 1, received)
+	})
+}
+
+func TestWebhookClient_Timeout(t *testing.T) {
+	assert.Equal(t, DefaultWebhookTimeout, webhookClient(&models.WebhookConfig{}).Timeout)
+	assert.Equal(t, 30*time.Second, webhookClient(&models.WebhookConfig{TimeoutSeconds: 30}).Timeout)
+	assert.Equal(t, MaxWebhookTimeoutSeconds*time.Second, webhookClient(&models.WebhookConfig{TimeoutSeconds: 9999}).Timeout)
+	assert.Nil(t, webhookClient(&models.WebhookConfig{}).Transport, "The default transport verifies certificates")
+}